@@ -20,13 +20,60 @@
   [ "$status" -eq 1 ]
 }
 
+@test "Return 2 for warnings with detailed exit codes" {
+  run ./conftest test --detailed-exit-codes -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 2 ]
+}
+
+@test "Return 3 for errors with detailed exit codes" {
+  run ./conftest test --detailed-exit-codes -p examples/kubernetes/missing examples/kubernetes/service.yaml
+  [ "$status" -eq 3 ]
+}
+
+@test "Not fail when the failures do not exceed the fail threshold" {
+  run ./conftest test --fail-threshold 4 -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 0 ]
+}
+
+@test "Fail when the failures exceed the fail threshold" {
+  run ./conftest test --fail-threshold 3 -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+}
+
+@test "Do not fail when failures are found with the no fail flag" {
+  run ./conftest test --no-fail -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "FAIL" ]]
+}
+
+@test "Report failures in JUnit output with the no fail flag" {
+  run ./conftest test --no-fail -o junit -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "<failure" ]]
+}
+
+@test "Report the merged results of previous runs in JUnit output" {
+  ./conftest test --no-fail -o json -p examples/kubernetes/policy examples/kubernetes/deployment.yaml > "$BATS_TMPDIR/deployment.json"
+  ./conftest test --no-fail -o json -p examples/kubernetes/policy examples/kubernetes/service.yaml > "$BATS_TMPDIR/service.json"
+  run ./conftest report --merge -o junit "$BATS_TMPDIR/deployment.json" "$BATS_TMPDIR/service.json"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "<failure" ]]
+  [[ "$output" =~ "examples/kubernetes/service.yaml" ]]
+}
+
 @test "Fail when testing with no policies path" {
   run ./conftest test -p internal/ examples/kubernetes/deployment.yaml
   [ "$status" -eq 1 ]
 }
 
-@test "Pass when testing a blank namespace" {
+@test "Fail when testing a blank namespace" {
   run ./conftest test --namespace notpresent -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "no rules found in the namespaces [notpresent]" ]]
+}
+
+@test "Pass when testing a blank namespace with --allow-empty" {
+  run ./conftest test --allow-empty --namespace notpresent -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
   [ "$status" -eq 0 ]
 }
 
@@ -99,6 +146,12 @@
   [[ "$output" =~ "Terraform plan will change prohibited resources in the following namespaces: google_iam, google_container" ]]
 }
 
+@test "Can parse terraform plan files with the tfplan parser" {
+  run ./conftest test --parser tfplan -p examples/hcl1/policy/base.rego examples/hcl1/gke-show.json
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Terraform plan will change prohibited resources in the following namespaces: google_iam, google_container" ]]
+}
+
 @test "Can parse hcl1 files" {
   run ./conftest test -p examples/hcl1/policy/gke.rego examples/hcl1/gke.tf
   [ "$status" -eq 0 ]
@@ -292,6 +345,12 @@
   [ "${lines[2]}" = "2 tests, 0 passed, 0 warnings, 1 failure, 1 exception" ]
 }
 
+@test "Fail when the ratio of exceptions exceeds the tolerated ratio" {
+  run ./conftest test -p examples/exceptions/policy examples/exceptions/deployments.yaml --no-color --fail-threshold 1 --fail-on-exception-ratio 0.25
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "The ratio of exceptions to tests (0.50) exceeds the tolerated ratio (0.25)" ]]
+}
+
 @test "Can have multiple namespace flags" {
   run ./conftest test -p examples/nested/policy --namespace group1 --namespace group2 examples/nested/data.json
 
@@ -0,0 +1,181 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const checksumSeparator = "@sha256:"
+
+var checksumRegexp = regexp.MustCompile("^[a-fA-F0-9]{64}$")
+
+// splitChecksum splits a URL in the form of url@sha256:<digest> into
+// the URL and the expected digest. When the URL does not include a
+// checksum, the digest is empty.
+//
+// References of OCI artifacts are returned as is, since a digest of a
+// reference, e.g. oci://registry/repo@sha256:<digest>, is the digest of
+// the manifest of the artifact that is pulled, rather than a checksum of
+// the downloaded policies.
+func splitChecksum(url string) (string, string, error) {
+	if isOCIReference(url) {
+		return url, "", nil
+	}
+
+	index := strings.LastIndex(url, checksumSeparator)
+	if index < 0 {
+		return url, "", nil
+	}
+
+	digest := url[index+len(checksumSeparator):]
+	if !checksumRegexp.MatchString(digest) {
+		return "", "", fmt.Errorf("invalid sha256 checksum %q", digest)
+	}
+
+	return url[:index], strings.ToLower(digest), nil
+}
+
+// isOCIReference reports whether the given URL is the reference of an OCI
+// artifact, either with the oci:// scheme or of a known registry.
+func isOCIReference(url string) bool {
+	return strings.HasPrefix(url, "oci://") || containsOCIRegistry(url) || containsLocalRegistry(url)
+}
+
+// computeChecksum returns the SHA-256 digest of the contents of the given directory.
+//
+// When the directory contains a single file, the digest is the digest of that
+// file. Otherwise, the digest is a tree hash: the SHA-256 of a listing of every
+// file, in lexical order, where each line contains the slash separated path of
+// the file relative to the directory and the SHA-256 digest of its contents.
+// Version control metadata (e.g. the .git directory) is not part of the digest.
+func computeChecksum(directory string) (string, error) {
+	var files []string
+	walk := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("walk path: %w", err)
+		}
+
+		if info.IsDir() && (info.Name() == ".git" || info.Name() == ".hg") {
+			return filepath.SkipDir
+		}
+
+		info, err = resolveSymlink(path, info)
+		if err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+
+		return nil
+	}
+
+	if err := filepath.Walk(directory, walk); err != nil {
+		return "", err
+	}
+
+	if len(files) == 1 {
+		return fileChecksum(files[0])
+	}
+
+	var relativePaths []string
+	for _, file := range files {
+		relativePath, err := filepath.Rel(directory, file)
+		if err != nil {
+			return "", fmt.Errorf("get relative path: %w", err)
+		}
+
+		relativePaths = append(relativePaths, filepath.ToSlash(relativePath))
+	}
+	sort.Strings(relativePaths)
+
+	hash := sha256.New()
+	for _, relativePath := range relativePaths {
+		digest, err := fileChecksum(filepath.Join(directory, filepath.FromSlash(relativePath)))
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(hash, "%s  %s\n", digest, relativePath)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("hash file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// copyDirectory copies the contents of the source directory into the
+// destination directory, creating the destination if necessary.
+func copyDirectory(src string, dst string) error {
+	walk := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("walk path: %w", err)
+		}
+
+		relativePath, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("get relative path: %w", err)
+		}
+		target := filepath.Join(dst, relativePath)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, os.ModePerm)
+		}
+
+		info, err = resolveSymlink(path, info)
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+
+		return ioutil.WriteFile(target, contents, info.Mode())
+	}
+
+	return filepath.Walk(src, walk)
+}
+
+// resolveSymlink returns the file info of the file that the given path links
+// to when the path is a symlink, as is the case for files that are downloaded
+// from the local file system.
+func resolveSymlink(path string, info os.FileInfo) (os.FileInfo, error) {
+	if info.Mode()&os.ModeSymlink == 0 {
+		return info, nil
+	}
+
+	resolved, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve symlink: %w", err)
+	}
+
+	return resolved, nil
+}
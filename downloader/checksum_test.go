@@ -0,0 +1,153 @@
+package downloader
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitChecksum(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name             string
+		input            string
+		expectedURL      string
+		expectedChecksum string
+		wantErr          bool
+	}{
+		{"without checksum", "github.com/org/policies", "github.com/org/policies", "", false},
+		{"with checksum", "github.com/org/policies@sha256:" + digest, "github.com/org/policies", digest, false},
+		{"with uppercase checksum", "github.com/org/policies@sha256:" + strings.ToUpper(digest), "github.com/org/policies", digest, false},
+		{"with invalid checksum", "github.com/org/policies@sha256:abcd", "", "", true},
+		{"digest-pinned oci reference", "oci://registry.example.com/policies@sha256:" + digest, "oci://registry.example.com/policies@sha256:" + digest, "", false},
+		{"digest-pinned registry reference", "myregistry.azurecr.io/policies@sha256:" + digest, "myregistry.azurecr.io/policies@sha256:" + digest, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, checksum, err := splitChecksum(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("splitChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if url != tt.expectedURL {
+				t.Errorf("splitChecksum() url = %v, want %v", url, tt.expectedURL)
+			}
+
+			if checksum != tt.expectedChecksum {
+				t.Errorf("splitChecksum() checksum = %v, want %v", checksum, tt.expectedChecksum)
+			}
+		})
+	}
+}
+
+func TestComputeChecksum(t *testing.T) {
+	directory := writeFiles(t, map[string]string{
+		"policy.rego": "package main",
+	})
+	defer os.RemoveAll(directory)
+
+	// The checksum of a single file is the checksum of its contents.
+	actual, err := computeChecksum(directory)
+	if err != nil {
+		t.Fatalf("computeChecksum() error = %v", err)
+	}
+
+	const expected = "512843855fcc92a51c810b1b58e0731c01eac9a6a23c157bfa02aad71edffbe7"
+	if actual != expected {
+		t.Errorf("computeChecksum() = %v, want %v", actual, expected)
+	}
+
+	tree := writeFiles(t, map[string]string{
+		"policy.rego":      "package main",
+		"lib/helpers.rego": "package lib",
+		".git/HEAD":        "ref: refs/heads/master",
+	})
+	defer os.RemoveAll(tree)
+
+	first, err := computeChecksum(tree)
+	if err != nil {
+		t.Fatalf("computeChecksum() error = %v", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(tree, ".git", "HEAD"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	second, err := computeChecksum(tree)
+	if err != nil {
+		t.Fatalf("computeChecksum() error = %v", err)
+	}
+
+	if first != second {
+		t.Errorf("computeChecksum() should ignore version control metadata, got %v and %v", first, second)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(tree, "lib", "helpers.rego"), []byte("package changed"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	third, err := computeChecksum(tree)
+	if err != nil {
+		t.Fatalf("computeChecksum() error = %v", err)
+	}
+
+	if first == third {
+		t.Error("computeChecksum() should change when the contents of a file change")
+	}
+}
+
+func TestDownloadWithChecksum(t *testing.T) {
+	src := writeFiles(t, map[string]string{
+		"policy.rego": "package main",
+	})
+	defer os.RemoveAll(src)
+
+	dst, err := ioutil.TempDir("", "conftest-dst")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dst)
+
+	invalid := "file::" + filepath.Join(src, "policy.rego") + "@sha256:" + strings.Repeat("0", 64)
+	if err := Download(context.Background(), dst, []string{invalid}); err == nil {
+		t.Fatal("Download() should fail when the checksum does not match")
+	}
+
+	if _, err := os.Stat(filepath.Join(dst, "policy.rego")); !os.IsNotExist(err) {
+		t.Error("Download() should not write policies when the checksum does not match")
+	}
+
+	valid := "file::" + filepath.Join(src, "policy.rego") + "@sha256:512843855fcc92a51c810b1b58e0731c01eac9a6a23c157bfa02aad71edffbe7"
+	if err := Download(context.Background(), dst, []string{valid}); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dst, "policy.rego")); err != nil {
+		t.Errorf("Download() should write policies when the checksum matches: %v", err)
+	}
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	directory, err := ioutil.TempDir("", "conftest-checksum")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+
+	for path, contents := range files {
+		path = filepath.Join(directory, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	return directory
+}
@@ -0,0 +1,148 @@
+package downloader
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	getter "github.com/hashicorp/go-getter"
+)
+
+// detectCloudStorage converts s3:// and gs:// URLs into URLs that the S3 and
+// GCS getters understand. Other URLs are returned unchanged.
+//
+// S3 URLs are in the form of s3://<bucket>/<key>, and optionally include the
+// region of the bucket and the version of the object as query parameters, e.g.
+// s3://bucket/policies?region=eu-west-1. GCS URLs are in the form of
+// gs://<bucket>/<object>, and optionally include the generation of the object,
+// e.g. gs://bucket/policy.rego?generation=1600000000000000.
+func detectCloudStorage(src string) (string, error) {
+	switch {
+	case strings.HasPrefix(src, "s3://"):
+		u, err := url.Parse(src)
+		if err != nil {
+			return "", fmt.Errorf("parse s3 url: %w", err)
+		}
+
+		if u.Host == "" || strings.Trim(u.Path, "/") == "" {
+			return "", fmt.Errorf("s3 url must be in the form of s3://<bucket>/<key>: %s", src)
+		}
+
+		query := u.Query()
+		host := "s3.amazonaws.com"
+		if region := query.Get("region"); region != "" {
+			host = "s3-" + region + ".amazonaws.com"
+			query.Del("region")
+		}
+
+		detected := url.URL{Scheme: "https", Host: host, Path: "/" + u.Host + u.Path, RawQuery: query.Encode()}
+		return "s3::" + detected.String(), nil
+
+	case strings.HasPrefix(src, "gs://"):
+		u, err := url.Parse(src)
+		if err != nil {
+			return "", fmt.Errorf("parse gcs url: %w", err)
+		}
+
+		if u.Host == "" || strings.Trim(u.Path, "/") == "" {
+			return "", fmt.Errorf("gcs url must be in the form of gs://<bucket>/<object>: %s", src)
+		}
+
+		detected := url.URL{Scheme: "https", Host: "www.googleapis.com", Path: "/storage/v1/" + u.Host + u.Path, RawQuery: u.RawQuery}
+		return "gcs::" + detected.String(), nil
+
+	default:
+		return src, nil
+	}
+}
+
+// GCSGetter is responsible for handling GCS buckets. In addition to the
+// go-getter implementation, which downloads a single object or all of the
+// objects with a given prefix, it supports downloading a specific generation
+// of an object.
+type GCSGetter struct {
+	getter.GCSGetter
+}
+
+// ClientMode returns the client mode of the given URL. URLs
+// with a generation always refer to a single object.
+func (g *GCSGetter) ClientMode(u *url.URL) (getter.ClientMode, error) {
+	if u.Query().Get("generation") != "" {
+		return getter.ClientModeFile, nil
+	}
+
+	return g.GCSGetter.ClientMode(u)
+}
+
+// GetFile downloads the object at the given URL to the destination.
+func (g *GCSGetter) GetFile(dst string, u *url.URL) error {
+	rawGeneration := u.Query().Get("generation")
+	if rawGeneration == "" {
+		return g.GCSGetter.GetFile(dst, u)
+	}
+
+	generation, err := strconv.ParseInt(rawGeneration, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid generation %q: %w", rawGeneration, err)
+	}
+
+	// The path of the URL is in the form of /storage/<version>/<bucket>/<object>.
+	pathParts := strings.SplitN(u.Path, "/", 5)
+	if len(pathParts) != 5 {
+		return fmt.Errorf("URL is not a valid GCS URL")
+	}
+
+	ctx := g.Context()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("new storage client: %w", err)
+	}
+	defer client.Close()
+
+	reader, err := client.Bucket(pathParts[3]).Object(pathParts[4]).Generation(generation).NewReader(ctx)
+	if err != nil {
+		return fmt.Errorf("read object: %w", err)
+	}
+	defer reader.Close()
+
+	return writeFile(dst, reader)
+}
+
+func writeFile(dst string, reader io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return fmt.Errorf("make directory: %w", err)
+	}
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// credentialsError adds a hint on how to provide credentials to errors that
+// are caused by missing credentials when downloading from S3 or GCS.
+func credentialsError(detectedURL string, err error) error {
+	message := err.Error()
+	switch {
+	case strings.HasPrefix(detectedURL, "s3::") && strings.Contains(message, "NoCredentialProviders"):
+		return fmt.Errorf("no AWS credentials found, configure credentials using the AWS environment variables, shared credentials file or an IAM role: %w", err)
+	case strings.HasPrefix(detectedURL, "gcs::") && strings.Contains(message, "could not find default credentials"):
+		return fmt.Errorf("no Google Cloud credentials found, configure application default credentials using gcloud auth application-default login or GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+	case strings.HasPrefix(detectedURL, "http") && strings.Contains(message, "bad response code: 401"):
+		return fmt.Errorf("unauthorized, set a bearer token to authenticate with using the %s environment variable: %w", HTTPTokenEnv, err)
+	default:
+		return err
+	}
+}
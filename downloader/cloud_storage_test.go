@@ -0,0 +1,64 @@
+package downloader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectCloudStorage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"s3 object", "s3://bucket/policy.rego", "s3::https://s3.amazonaws.com/bucket/policy.rego", false},
+		{"s3 prefix with region", "s3://bucket/policies?region=eu-west-1", "s3::https://s3-eu-west-1.amazonaws.com/bucket/policies", false},
+		{"s3 object with version", "s3://bucket/policy.rego?version=abc", "s3::https://s3.amazonaws.com/bucket/policy.rego?version=abc", false},
+		{"s3 without key", "s3://bucket", "", true},
+		{"gcs object", "gs://bucket/policy.rego", "gcs::https://www.googleapis.com/storage/v1/bucket/policy.rego", false},
+		{"gcs object with generation", "gs://bucket/policy.rego?generation=10", "gcs::https://www.googleapis.com/storage/v1/bucket/policy.rego?generation=10", false},
+		{"gcs without object", "gs://bucket/", "", true},
+		{"other url", "github.com/org/policies", "github.com/org/policies", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := detectCloudStorage(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("detectCloudStorage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if actual != tt.expected {
+				t.Errorf("detectCloudStorage() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCredentialsError(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		err      error
+		expected string
+	}{
+		{"missing aws credentials", "s3::https://s3.amazonaws.com/bucket/key", errors.New("NoCredentialProviders: no valid providers in chain"), "no AWS credentials found"},
+		{"missing google credentials", "gcs::https://www.googleapis.com/storage/v1/bucket/key", errors.New("google: could not find default credentials"), "no Google Cloud credentials found"},
+		{"other error", "s3::https://s3.amazonaws.com/bucket/key", errors.New("access denied"), "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := credentialsError(tt.url, tt.err)
+			if !strings.HasPrefix(err.Error(), tt.expected) {
+				t.Errorf("credentialsError() = %v, want prefix %v", err, tt.expected)
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Error("credentialsError() should wrap the original error")
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/containerd/containerd/remotes"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// cosignSignatureAnnotation is the annotation of the layers of a cosign
+// signature manifest that holds the base64 encoded signature of the layer.
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// cosignPayload is the simple signing payload that cosign signs, which
+// identifies the manifest that the signature belongs to.
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// resolver resolves and fetches the content of OCI references.
+type resolver interface {
+	Resolve(ctx context.Context, ref string) (string, ocispec.Descriptor, error)
+	Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error)
+}
+
+// loadPublicKey loads a PEM encoded public key, such as the
+// cosign.pub file that is created by cosign generate-key-pair.
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("public key %s is not PEM encoded", path)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+
+	return publicKey, nil
+}
+
+// verifyCosignSignature verifies that the manifest with the given digest in
+// the given repository has been signed by cosign with the given public key.
+// The signatures are stored in the repository under the tag that cosign
+// derives from the digest, e.g. sha256-<digest>.sig.
+func verifyCosignSignature(ctx context.Context, resolver resolver, repository string, manifestDigest string, publicKey crypto.PublicKey) error {
+	signatureRef := repository + ":" + strings.Replace(manifestDigest, ":", "-", 1) + ".sig"
+
+	_, descriptor, err := resolver.Resolve(ctx, signatureRef)
+	if err != nil {
+		return fmt.Errorf("resolve signature %s: %w", signatureRef, err)
+	}
+
+	fetcher, err := resolver.Fetcher(ctx, signatureRef)
+	if err != nil {
+		return fmt.Errorf("new fetcher: %w", err)
+	}
+
+	contents, err := fetch(ctx, fetcher, descriptor)
+	if err != nil {
+		return fmt.Errorf("fetch signature manifest: %w", err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		return fmt.Errorf("unmarshal signature manifest: %w", err)
+	}
+
+	// The manifest is trusted when any of the signatures is valid, as an
+	// artifact can be signed more than once, e.g. when a key is rotated.
+	for _, layer := range manifest.Layers {
+		signature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+
+		payload, err := fetch(ctx, fetcher, layer)
+		if err != nil {
+			return fmt.Errorf("fetch signature payload: %w", err)
+		}
+
+		if err := verifyPayload(payload, signature, manifestDigest, publicKey); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no valid signature for %s@%s", repository, manifestDigest)
+}
+
+// verifyPayload verifies the signature of the given payload, and that
+// the payload is for the manifest with the given digest.
+func verifyPayload(payload []byte, signature string, manifestDigest string, publicKey crypto.PublicKey) error {
+	decodedSignature, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+
+	if err := verifySignature(publicKey, payload, decodedSignature); err != nil {
+		return err
+	}
+
+	var signed cosignPayload
+	if err := json.Unmarshal(payload, &signed); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	if signed.Critical.Image.DockerManifestDigest != manifestDigest {
+		return fmt.Errorf("signature is for %s, not %s", signed.Critical.Image.DockerManifestDigest, manifestDigest)
+	}
+
+	return nil
+}
+
+func verifySignature(publicKey crypto.PublicKey, payload []byte, signature []byte) error {
+	digest := sha256.Sum256(payload)
+
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		var ecdsaSignature struct {
+			R, S *big.Int
+		}
+		if _, err := asn1.Unmarshal(signature, &ecdsaSignature); err != nil {
+			return fmt.Errorf("unmarshal ecdsa signature: %w", err)
+		}
+
+		if !ecdsa.Verify(key, digest[:], ecdsaSignature.R, ecdsaSignature.S) {
+			return errors.New("invalid signature")
+		}
+
+		return nil
+
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+
+		return nil
+
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, signature) {
+			return errors.New("invalid signature")
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+func fetch(ctx context.Context, fetcher remotes.Fetcher, descriptor ocispec.Descriptor) ([]byte, error) {
+	reader, err := fetcher.Fetch(ctx, descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", descriptor.Digest, err)
+	}
+	defer reader.Close()
+
+	var contents bytes.Buffer
+	if _, err := io.Copy(&contents, reader); err != nil {
+		return nil, fmt.Errorf("read %s: %w", descriptor.Digest, err)
+	}
+
+	return contents.Bytes(), nil
+}
+
+// repositoryName returns the name of the repository of the given
+// reference, without its tag or digest.
+func repositoryName(ref string) string {
+	if index := strings.Index(ref, "@"); index >= 0 {
+		return ref[:index]
+	}
+
+	if index := strings.LastIndex(ref, ":"); index > strings.LastIndex(ref, "/") {
+		return ref[:index]
+	}
+
+	return ref
+}
@@ -0,0 +1,193 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/remotes"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testManifestDigest = "sha256:2d6a1d3aa9a7e12a1af2a4fcb0d7c16bbd6a6e5c1bbd7a416bcb12cf0cbd0bd7"
+
+// memoryResolver is a resolver that serves content from memory.
+type memoryResolver struct {
+	refs  map[string]ocispec.Descriptor
+	blobs map[godigest.Digest][]byte
+}
+
+func (r *memoryResolver) Resolve(ctx context.Context, ref string) (string, ocispec.Descriptor, error) {
+	descriptor, ok := r.refs[ref]
+	if !ok {
+		return "", ocispec.Descriptor{}, fmt.Errorf("%s: not found", ref)
+	}
+
+	return ref, descriptor, nil
+}
+
+func (r *memoryResolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return r, nil
+}
+
+func (r *memoryResolver) Fetch(ctx context.Context, descriptor ocispec.Descriptor) (io.ReadCloser, error) {
+	blob, ok := r.blobs[descriptor.Digest]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(blob)), nil
+}
+
+func (r *memoryResolver) add(blob []byte) ocispec.Descriptor {
+	descriptor := ocispec.Descriptor{Digest: godigest.FromBytes(blob), Size: int64(len(blob))}
+	r.blobs[descriptor.Digest] = blob
+	return descriptor
+}
+
+// newSignedResolver returns a resolver for a repository that holds the
+// cosign signature of the given manifest digest, signed with the given key.
+func newSignedResolver(t *testing.T, key *ecdsa.PrivateKey, manifestDigest string) *memoryResolver {
+	resolver := &memoryResolver{
+		refs:  make(map[string]ocispec.Descriptor),
+		blobs: make(map[godigest.Digest][]byte),
+	}
+
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"127.0.0.1:5000/policies"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, manifestDigest))
+	hash := sha256.Sum256(payload)
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("sign payload: %v", err)
+	}
+
+	signature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		t.Fatalf("marshal signature: %v", err)
+	}
+
+	layer := resolver.add(payload)
+	layer.MediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	layer.Annotations = map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)}
+
+	manifest, err := json.Marshal(ocispec.Manifest{Layers: []ocispec.Descriptor{layer}})
+	if err != nil {
+		t.Fatalf("marshal manifest: %v", err)
+	}
+
+	resolver.refs["127.0.0.1:5000/policies:sha256-2d6a1d3aa9a7e12a1af2a4fcb0d7c16bbd6a6e5c1bbd7a416bcb12cf0cbd0bd7.sig"] = resolver.add(manifest)
+
+	return resolver
+}
+
+func TestVerifyCosignSignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	ctx := context.Background()
+
+	t.Run("valid signature", func(t *testing.T) {
+		resolver := newSignedResolver(t, key, testManifestDigest)
+		if err := verifyCosignSignature(ctx, resolver, "127.0.0.1:5000/policies", testManifestDigest, &key.PublicKey); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("signed with another key", func(t *testing.T) {
+		resolver := newSignedResolver(t, otherKey, testManifestDigest)
+		if err := verifyCosignSignature(ctx, resolver, "127.0.0.1:5000/policies", testManifestDigest, &key.PublicKey); err == nil {
+			t.Error("expected an error for a signature of another key")
+		}
+	})
+
+	t.Run("signature of another manifest", func(t *testing.T) {
+		resolver := newSignedResolver(t, key, "sha256:0000000000000000000000000000000000000000000000000000000000000000")
+		if err := verifyCosignSignature(ctx, resolver, "127.0.0.1:5000/policies", testManifestDigest, &key.PublicKey); err == nil {
+			t.Error("expected an error for a signature of another manifest")
+		}
+	})
+
+	t.Run("no signature", func(t *testing.T) {
+		resolver := &memoryResolver{refs: make(map[string]ocispec.Descriptor), blobs: make(map[godigest.Digest][]byte)}
+		if err := verifyCosignSignature(ctx, resolver, "127.0.0.1:5000/policies", testManifestDigest, &key.PublicKey); err == nil {
+			t.Error("expected an error for an unsigned manifest")
+		}
+	})
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+
+	directory, err := ioutil.TempDir("", "conftestcosign")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "cosign.pub")
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatalf("write public key: %v", err)
+	}
+
+	publicKey, err := loadPublicKey(path)
+	if err != nil {
+		t.Fatalf("load public key: %v", err)
+	}
+
+	if loaded, ok := publicKey.(*ecdsa.PublicKey); !ok || loaded.X.Cmp(key.X) != 0 || loaded.Y.Cmp(key.Y) != 0 {
+		t.Error("loaded public key does not match")
+	}
+
+	if err := DownloadWithOptions(context.Background(), directory, []string{"https://example.com/policy.rego"}, Options{CosignKey: path}); err == nil {
+		t.Error("expected an error when verifying policies that are not in an OCI registry")
+	}
+}
+
+func TestRepositoryName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"127.0.0.1:5000/policies:latest", "127.0.0.1:5000/policies"},
+		{"127.0.0.1:5000/policies@sha256:abc", "127.0.0.1:5000/policies"},
+		{"127.0.0.1:5000/policies", "127.0.0.1:5000/policies"},
+		{"registry.example.com/org/policies:v1", "registry.example.com/org/policies"},
+	}
+
+	for _, tt := range tests {
+		if actual := repositoryName(tt.input); actual != tt.expected {
+			t.Errorf("repositoryName(%q) = %q, want %q", tt.input, actual, tt.expected)
+		}
+	}
+}
@@ -3,6 +3,10 @@ package downloader
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
 	"strings"
 
 	getter "github.com/hashicorp/go-getter"
@@ -20,8 +24,8 @@ var detectors = []getter.Detector{
 
 var getters = map[string]getter.Getter{
 	"file":  new(getter.FileGetter),
-	"git":   new(getter.GitGetter),
-	"gcs":   new(getter.GCSGetter),
+	"git":   new(GitGetter),
+	"gcs":   new(GCSGetter),
 	"hg":    new(getter.HgGetter),
 	"s3":    new(getter.S3Getter),
 	"oci":   new(OCIGetter),
@@ -29,34 +33,174 @@ var getters = map[string]getter.Getter{
 	"https": new(getter.HttpGetter),
 }
 
+// Options are the options for downloading policies.
+type Options struct {
+	// CosignKey is the path to the public key that OCI artifacts must be
+	// signed with using cosign. When set, artifacts are only written to the
+	// destination when their signature is valid, and policies can only be
+	// downloaded from OCI registries.
+	CosignKey string
+
+	// HTTPToken is the bearer token that is sent in the Authorization header
+	// when downloading policies over HTTP. When it is not set, the token is
+	// read from the CONFTEST_HTTP_TOKEN environment variable.
+	HTTPToken string
+
+	// GitDepth is the depth of the clones of git repositories, unless the
+	// URL includes the depth query parameter. When zero, the full history
+	// is cloned.
+	GitDepth int
+
+	// Logger logs the commits that git repositories were checked out at.
+	// When nil, the commits are not logged.
+	Logger *log.Logger
+}
+
 // Download downloads the given policies into the given destination.
+//
+// A URL can be pinned to a specific version of the policies by appending the
+// expected SHA-256 digest of the download, e.g. <url>@sha256:<digest>. When a
+// digest is given, the policies are only written to the destination if the
+// digest of the downloaded policies matches.
 func Download(ctx context.Context, dst string, urls []string) error {
-	opts := []getter.ClientOption{}
+	return DownloadWithOptions(ctx, dst, urls, Options{})
+}
+
+// DownloadWithOptions downloads the given policies into the given destination
+// using the given options.
+func DownloadWithOptions(ctx context.Context, dst string, urls []string, options Options) error {
+	if options.HTTPToken == "" {
+		options.HTTPToken = strings.TrimSpace(os.Getenv(HTTPTokenEnv))
+	}
+
+	clientGetters, err := newGetters(options)
+	if err != nil {
+		return err
+	}
+
 	for _, url := range urls {
-		detectedURL, err := Detect(url, dst)
+		url, checksum, err := splitChecksum(url)
 		if err != nil {
-			return fmt.Errorf("detecting url: %w", err)
+			return fmt.Errorf("parse checksum: %w", err)
 		}
 
-		client := &getter.Client{
-			Ctx:       ctx,
-			Src:       detectedURL,
-			Dst:       dst,
-			Pwd:       dst,
-			Mode:      getter.ClientModeAny,
-			Detectors: detectors,
-			Getters:   getters,
-			Options:   opts,
+		if checksum == "" {
+			if err := download(ctx, dst, dst, url, clientGetters); err != nil {
+				return err
+			}
+
+			continue
 		}
 
-		if err := client.Get(); err != nil {
-			return fmt.Errorf("client get: %w", err)
+		if err := downloadWithChecksum(ctx, dst, url, checksum, clientGetters); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+func downloadWithChecksum(ctx context.Context, dst string, url string, checksum string, clientGetters map[string]getter.Getter) error {
+	tempDir, err := ioutil.TempDir("", "conftest-download")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Relative URLs are still resolved against the destination so that the
+	// behavior matches downloads without a checksum.
+	downloadDir := filepath.Join(tempDir, "policies")
+	if err := download(ctx, downloadDir, dst, url, clientGetters); err != nil {
+		return err
+	}
+
+	// Local sources are symlinked by the file getter rather than copied.
+	downloadDir, err = filepath.EvalSymlinks(downloadDir)
+	if err != nil {
+		return fmt.Errorf("resolve download: %w", err)
+	}
+
+	actual, err := computeChecksum(downloadDir)
+	if err != nil {
+		return fmt.Errorf("compute checksum: %w", err)
+	}
+
+	if actual != checksum {
+		return fmt.Errorf("checksum mismatch for %s: expected sha256:%s but got sha256:%s", url, checksum, actual)
+	}
+
+	if err := copyDirectory(downloadDir, dst); err != nil {
+		return fmt.Errorf("copy policies: %w", err)
+	}
+
+	return nil
+}
+
+func download(ctx context.Context, dst string, pwd string, url string, clientGetters map[string]getter.Getter) error {
+	detectedURL, err := Detect(url, pwd)
+	if err != nil {
+		return fmt.Errorf("detecting url: %w", err)
+	}
+
+	// Signatures can only be verified for OCI artifacts, so policies from
+	// other sources would be written without being verified.
+	if ociGetter, ok := clientGetters["oci"].(*OCIGetter); ok && ociGetter.PublicKey != nil && !strings.HasPrefix(detectedURL, "oci://") {
+		return fmt.Errorf("signature verification is only supported for OCI registries: %s", url)
+	}
+
+	client := &getter.Client{
+		Ctx:       ctx,
+		Src:       detectedURL,
+		Dst:       dst,
+		Pwd:       pwd,
+		Mode:      getter.ClientModeAny,
+		Detectors: detectors,
+		Getters:   clientGetters,
+		Options:   []getter.ClientOption{},
+	}
+
+	if err := client.Get(); err != nil {
+		return fmt.Errorf("client get: %w", credentialsError(detectedURL, err))
+	}
+
+	return nil
+}
+
+// newGetters returns the getters to download policies with, where the
+// OCI getter verifies the signatures of artifacts when a key is given, and
+// the HTTP getters authenticate with the bearer token when one is given.
+func newGetters(options Options) (map[string]getter.Getter, error) {
+	if options.CosignKey == "" && options.HTTPToken == "" && options.GitDepth == 0 && options.Logger == nil {
+		return getters, nil
+	}
+
+	clientGetters := make(map[string]getter.Getter)
+	for scheme, g := range getters {
+		clientGetters[scheme] = g
+	}
+
+	if options.CosignKey != "" {
+		publicKey, err := loadPublicKey(options.CosignKey)
+		if err != nil {
+			return nil, fmt.Errorf("load cosign key: %w", err)
+		}
+
+		clientGetters["oci"] = &OCIGetter{PublicKey: publicKey}
+	}
+
+	if options.GitDepth != 0 || options.Logger != nil {
+		clientGetters["git"] = &GitGetter{Depth: options.GitDepth, Logger: options.Logger}
+	}
+
+	if options.HTTPToken != "" {
+		httpGetter := newHTTPGetter(options.HTTPToken)
+		clientGetters["http"] = httpGetter
+		clientGetters["https"] = httpGetter
+	}
+
+	return clientGetters, nil
+}
+
 // Detect determines whether a url is a known source url from which we can download files.
 // If a known source is found, the url is formatted, otherwise an error is returned.
 func Detect(url string, dst string) (string, error) {
@@ -70,6 +214,13 @@ func Detect(url string, dst string) (string, error) {
 		url = strings.ReplaceAll(url, "localhost", "127.0.0.1")
 	}
 
+	// URLs with a scheme are not passed to the detectors, so the s3:// and gs://
+	// schemes need to be converted before detecting the url.
+	url, err := detectCloudStorage(url)
+	if err != nil {
+		return "", fmt.Errorf("detect cloud storage: %w", err)
+	}
+
 	result, err := getter.Detect(url, dst, detectors)
 	if err != nil {
 		return "", fmt.Errorf("detect: %w", err)
@@ -0,0 +1,187 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	getter "github.com/hashicorp/go-getter"
+)
+
+// commitRegexp matches the refs that are (abbreviated) commit SHAs, which
+// cannot be looked up on the remote and are not fetched by shallow clones.
+var commitRegexp = regexp.MustCompile("^[a-fA-F0-9]{7,40}$")
+
+// GitGetter downloads policies from git repositories, where the ref to check
+// out is given with the ref query parameter, e.g.
+// git::https://github.com/org/repo//policy?ref=v1.2.3.
+//
+// Unlike the git getter of go-getter, the ref is validated and looked up on
+// the remote before cloning, a shallow clone of a branch or tag fetches that
+// ref rather than the default branch, and the commit that the ref resolves
+// to is logged.
+type GitGetter struct {
+	getter.GitGetter
+
+	// Depth is the depth of the clone when the URL does not include
+	// the depth query parameter. When zero, the full history is cloned.
+	Depth int
+
+	// Logger logs the commit that was checked out. When nil, the commit
+	// is not logged.
+	Logger *log.Logger
+}
+
+// Get clones the repository of the given URL into the destination.
+func (g *GitGetter) Get(dst string, u *url.URL) error {
+	ctx := g.Context()
+
+	query := u.Query()
+	ref := query.Get("ref")
+	if _, ok := query["ref"]; ok {
+		if err := validateRef(ref); err != nil {
+			return err
+		}
+	}
+
+	depth := g.Depth
+	if value, ok := query["depth"]; ok {
+		n, err := strconv.Atoi(value[0])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("depth must be a positive integer: %q", value[0])
+		}
+
+		depth = n
+	}
+
+	// A shallow clone only contains the history of the ref that is cloned,
+	// so commits are always checked out of a full clone.
+	if commitRegexp.MatchString(ref) {
+		depth = 0
+	}
+
+	repository := *u
+	repository.RawQuery = ""
+	remote := repository.String()
+
+	// The remote cannot be queried with the SSH key of the URL, so the
+	// ref is only looked up on remotes that do not need one.
+	sshKey := query.Get("sshkey")
+	if ref != "" && !commitRegexp.MatchString(ref) && sshKey == "" {
+		found, err := lsRemote(ctx, remote, ref)
+		if err != nil {
+			return fmt.Errorf("look up ref %q of %s: %w", ref, remote, err)
+		}
+
+		if !found {
+			return fmt.Errorf("ref %q does not exist in %s", ref, remote)
+		}
+	}
+
+	_, err := os.Stat(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("stat destination: %w", err)
+	}
+
+	if os.IsNotExist(err) && depth > 0 && ref != "" && !commitRegexp.MatchString(ref) && sshKey == "" {
+		if err := cloneRef(ctx, dst, remote, ref, depth); err != nil {
+			return fmt.Errorf("clone ref %q of %s: %w", ref, remote, err)
+		}
+	} else {
+		withDepth := *u
+		if depth > 0 {
+			query.Set("depth", strconv.Itoa(depth))
+		} else {
+			query.Del("depth")
+		}
+		withDepth.RawQuery = query.Encode()
+
+		if err := g.GitGetter.Get(dst, &withDepth); err != nil {
+			if ref != "" {
+				return fmt.Errorf("check out ref %q of %s: %w", ref, remote, err)
+			}
+
+			return err
+		}
+	}
+
+	commit, err := runGit(ctx, dst, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("resolve commit: %w", err)
+	}
+
+	if g.Logger != nil {
+		if ref == "" {
+			ref = "HEAD"
+		}
+
+		g.Logger.Printf("Checked out %s of %s at commit %s", ref, remote, commit)
+	}
+
+	return nil
+}
+
+// validateRef returns an error when the given ref is empty, or is not a
+// valid name of a git branch, tag or commit.
+func validateRef(ref string) error {
+	if ref == "" {
+		return fmt.Errorf("ref must not be empty")
+	}
+
+	if strings.HasPrefix(ref, "-") || strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") ||
+		strings.HasSuffix(ref, ".") || strings.HasSuffix(ref, ".lock") ||
+		strings.Contains(ref, "..") || strings.Contains(ref, "@{") || strings.Contains(ref, "//") ||
+		strings.ContainsAny(ref, " \t\n~^:?*[\\") {
+		return fmt.Errorf("invalid git ref %q", ref)
+	}
+
+	return nil
+}
+
+// lsRemote reports whether the given branch or tag exists in the remote.
+func lsRemote(ctx context.Context, remote string, ref string) (bool, error) {
+	out, err := runGit(ctx, "", "ls-remote", "--heads", "--tags", remote, ref)
+	if err != nil {
+		return false, err
+	}
+
+	return out != "", nil
+}
+
+func cloneRef(ctx context.Context, dst string, remote string, ref string, depth int) error {
+	if _, err := runGit(ctx, "", "clone", "--depth", strconv.Itoa(depth), "--branch", ref, remote, dst); err != nil {
+		return err
+	}
+
+	if _, err := runGit(ctx, dst, "submodule", "update", "--init", "--recursive", "--depth", strconv.Itoa(depth)); err != nil {
+		return fmt.Errorf("update submodules: %w", err)
+	}
+
+	return nil
+}
+
+// runGit runs git with the given arguments in the given directory, and
+// returns its output without the trailing newline.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,148 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadGitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	directory, err := ioutil.TempDir("", "conftestgit")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	repository := filepath.Join(directory, "repository")
+	if err := os.MkdirAll(filepath.Join(repository, "policy"), os.ModePerm); err != nil {
+		t.Fatalf("create repository: %v", err)
+	}
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=conftest", "-c", "user.email=conftest@example.com"}, args...)...)
+		cmd.Dir = repository
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+
+		return strings.TrimSpace(string(out))
+	}
+
+	git("init", "-q")
+	if err := ioutil.WriteFile(filepath.Join(repository, "policy", "v1.rego"), []byte("package main"), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1.0.0")
+	tagged := git("rev-parse", "HEAD")
+
+	if err := ioutil.WriteFile(filepath.Join(repository, "policy", "v2.rego"), []byte("package main"), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "v2")
+
+	remote := "git::file://" + filepath.ToSlash(repository) + "//policy"
+
+	testCases := []struct {
+		name     string
+		url      string
+		depth    int
+		expected []string
+		err      string
+	}{
+		{
+			name:     "tag",
+			url:      remote + "?ref=v1.0.0",
+			expected: []string{"v1.rego"},
+		},
+		{
+			name:     "shallow clone of a tag",
+			url:      remote + "?ref=v1.0.0",
+			depth:    1,
+			expected: []string{"v1.rego"},
+		},
+		{
+			name:     "commit",
+			url:      remote + "?ref=" + tagged,
+			depth:    1,
+			expected: []string{"v1.rego"},
+		},
+		{
+			name:     "default branch",
+			url:      remote + "?depth=1",
+			expected: []string{"v1.rego", "v2.rego"},
+		},
+		{
+			name: "unknown ref",
+			url:  remote + "?ref=v9.9.9",
+			err:  `ref "v9.9.9" does not exist`,
+		},
+		{
+			name: "invalid ref",
+			url:  remote + "?ref=v1..0",
+			err:  `invalid git ref "v1..0"`,
+		},
+		{
+			name: "empty ref",
+			url:  remote + "?ref=",
+			err:  "ref must not be empty",
+		},
+		{
+			name: "invalid depth",
+			url:  remote + "?ref=v1.0.0&depth=-1",
+			err:  "depth must be a positive integer",
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dst := filepath.Join(directory, "policies", strings.Repeat("x", i+1))
+
+			var logs bytes.Buffer
+			options := Options{GitDepth: testCase.depth, Logger: log.New(&logs, "", 0)}
+			err := DownloadWithOptions(context.Background(), dst, []string{testCase.url}, options)
+			if testCase.err != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.err) {
+					t.Fatalf("expected an error that contains %q, got %v", testCase.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("download: %v", err)
+			}
+
+			files, err := ioutil.ReadDir(dst)
+			if err != nil {
+				t.Fatalf("read policies: %v", err)
+			}
+
+			var actual []string
+			for _, file := range files {
+				actual = append(actual, file.Name())
+			}
+
+			if strings.Join(actual, ",") != strings.Join(testCase.expected, ",") {
+				t.Errorf("unexpected policies. expected %v, got %v", testCase.expected, actual)
+			}
+
+			if !strings.Contains(logs.String(), "at commit ") {
+				t.Errorf("expected the commit to be logged, got %q", logs.String())
+			}
+		})
+	}
+}
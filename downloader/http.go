@@ -0,0 +1,42 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/open-policy-agent/conftest/internal/remote"
+
+	getter "github.com/hashicorp/go-getter"
+)
+
+// HTTPTokenEnv is the environment variable that holds the bearer token that
+// is sent in the Authorization header when downloading policies over HTTP.
+const HTTPTokenEnv = remote.TokenEnv
+
+// Get fetches the given URL, sending the bearer token in the HTTPTokenEnv
+// environment variable, when it is set, in the Authorization header of the
+// request. The token is not sent to the servers the request is redirected to.
+// An error is returned when the response does not have a 2xx status. The
+// caller must close the body of the response.
+func Get(ctx context.Context, url string) (*http.Response, error) {
+	return remote.Get(ctx, url)
+}
+
+// NewHTTPClient returns a client that removes the Authorization header of its
+// requests when they are redirected to another server, so that a bearer token
+// can be sent to a server without being leaked to the servers it redirects to.
+func NewHTTPClient() *http.Client {
+	return remote.NewClient()
+}
+
+// newHTTPGetter returns a getter for policies over HTTP, which sends
+// the given bearer token in the Authorization header of its requests.
+func newHTTPGetter(token string) *getter.HttpGetter {
+	header := make(http.Header)
+	header.Set("Authorization", "Bearer "+token)
+
+	return &getter.HttpGetter{
+		Client: NewHTTPClient(),
+		Header: header,
+	}
+}
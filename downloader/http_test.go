@@ -0,0 +1,80 @@
+package downloader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadHTTPWithToken(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"policy.rego":      "package main",
+		"lib/helpers.rego": "package lib",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	directory, err := ioutil.TempDir("", "conftesthttp")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	ctx := context.Background()
+	if err := DownloadWithOptions(ctx, directory, []string{server.URL + "/policies.tar.gz"}, Options{HTTPToken: "secret"}); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+
+	for _, path := range []string{"policy.rego", filepath.Join("lib", "helpers.rego")} {
+		if _, err := os.Stat(filepath.Join(directory, path)); err != nil {
+			t.Errorf("expected %s to be extracted: %v", path, err)
+		}
+	}
+
+	err = DownloadWithOptions(ctx, directory, []string{server.URL + "/policies.tar.gz"}, Options{HTTPToken: "wrong"})
+	if err == nil || !strings.Contains(err.Error(), HTTPTokenEnv) {
+		t.Errorf("expected an unauthorized error that mentions %s, got %v", HTTPTokenEnv, err)
+	}
+}
+
+func newTarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, contents := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("write header: %v", err)
+		}
+
+		if _, err := tarWriter.Write([]byte(contents)); err != nil {
+			t.Fatalf("write contents: %v", err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("close tar: %v", err)
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("close gzip: %v", err)
+	}
+
+	return buf.Bytes()
+}
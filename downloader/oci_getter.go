@@ -2,20 +2,25 @@ package downloader
 
 import (
 	"context"
+	"crypto"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 
-	auth "github.com/deislabs/oras/pkg/auth/docker"
-	"github.com/deislabs/oras/pkg/content"
-	"github.com/deislabs/oras/pkg/oras"
 	getter "github.com/hashicorp/go-getter"
+	auth "oras.land/oras-go/pkg/auth/docker"
+	"oras.land/oras-go/pkg/content"
+	"oras.land/oras-go/pkg/oras"
 )
 
 // OCIGetter is responsible for handling OCI repositories
 type OCIGetter struct {
 	client *getter.Client
+
+	// PublicKey is the key that the artifact must be signed with using
+	// cosign. When nil, the signature of the artifact is not verified.
+	PublicKey crypto.PublicKey
 }
 
 // ClientMode returns the client mode directory
@@ -27,10 +32,6 @@ func (g *OCIGetter) ClientMode(u *url.URL) (getter.ClientMode, error) {
 func (g *OCIGetter) Get(path string, u *url.URL) error {
 	ctx := g.Context()
 
-	if err := os.MkdirAll(path, os.ModePerm); err != nil {
-		return fmt.Errorf("make policy directory: %w", err)
-	}
-
 	cli, err := auth.NewClient()
 	if err != nil {
 		return fmt.Errorf("new auth client: %w", err)
@@ -41,14 +42,35 @@ func (g *OCIGetter) Get(path string, u *url.URL) error {
 		return fmt.Errorf("new resolver: %w", err)
 	}
 
-	fileStore := content.NewFileStore(path)
-	defer fileStore.Close()
-
 	repository := getRepositoryFromURL(u.Path)
 	pullURL := u.Host + repository
 
-	_, _, err = oras.Pull(ctx, resolver, pullURL, fileStore)
-	if err != nil {
+	// The artifact is verified before anything is written, and is then pulled
+	// by its digest so that the artifact that is pulled is the one that was
+	// verified, even if the tag is moved in the meantime.
+	if g.PublicKey != nil {
+		_, descriptor, err := resolver.Resolve(ctx, pullURL)
+		if err != nil {
+			return fmt.Errorf("resolve policy: %w", err)
+		}
+
+		repositoryURL := repositoryName(pullURL)
+		if err := verifyCosignSignature(ctx, resolver, repositoryURL, descriptor.Digest.String(), g.PublicKey); err != nil {
+			return fmt.Errorf("verify signature: %w", err)
+		}
+
+		pullURL = repositoryURL + "@" + descriptor.Digest.String()
+	}
+
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return fmt.Errorf("make policy directory: %w", err)
+	}
+
+	fileStore := content.NewFile(path)
+	defer fileStore.Close()
+
+	registry := &content.Registry{Resolver: resolver}
+	if _, err := oras.Copy(ctx, registry, pullURL, fileStore, ""); err != nil {
 		return fmt.Errorf("pulling policy: %w", err)
 	}
 
@@ -51,6 +51,8 @@ func NewDefaultCommand() *cobra.Command {
 	cmd.AddCommand(NewPushCommand(ctx, logger))
 	cmd.AddCommand(NewPullCommand(ctx))
 	cmd.AddCommand(NewVerifyCommand(ctx))
+	cmd.AddCommand(NewEvalCommand(ctx))
+	cmd.AddCommand(NewReportCommand())
 	cmd.AddCommand(NewPluginCommand(ctx))
 
 	pluginCmds, err := loadPlugins(ctx)
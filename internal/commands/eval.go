@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-policy-agent/conftest/internal/runner"
+	"github.com/open-policy-agent/conftest/parser"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const evalDesc = `
+This command evaluates a Rego query and prints its result set as JSON.
+
+This can be useful when debugging policies, to inspect the value of any rule or
+data document, similar to 'opa eval', while the input files are parsed the same
+as they are by the test command:
+
+	$ conftest eval data.main.deny deployment.yaml
+	$ conftest eval 'data.kubernetes.is_deployment' --policy policy deployment.yaml
+
+The query is evaluated against each of the given files. When the '--combine' flag
+is given, it is evaluated once against the combined files instead, and when no
+files are given, it is evaluated once without an input, e.g. to inspect the data
+that is loaded with the '--data' flag:
+
+	$ conftest eval data.services --data examples/data/exclusions
+`
+
+// NewEvalCommand creates a new eval command which allows users to
+// evaluate arbitrary Rego queries against their configurations.
+func NewEvalCommand(ctx context.Context) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "eval <query> [file...]",
+		Short: "Evaluate a Rego query against your input files",
+		Long:  evalDesc,
+		Args:  cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flagNames := []string{"combine", "data", "ignore", "parser", "parser-map", "policy"}
+			for _, name := range flagNames {
+				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
+					return fmt.Errorf("bind flag: %w", err)
+				}
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var runner runner.EvalRunner
+			if err := viper.Unmarshal(&runner); err != nil {
+				return fmt.Errorf("unmarshal parameters: %w", err)
+			}
+
+			results, err := runner.Run(ctx, args[0], args[1:])
+			if err != nil {
+				return fmt.Errorf("running eval: %w", err)
+			}
+
+			out, err := json.MarshalIndent(results, "", "\t")
+			if err != nil {
+				return fmt.Errorf("marshal results: %w", err)
+			}
+
+			fmt.Println(string(out))
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("combine", false, "Combine all config files to be evaluated together")
+	cmd.Flags().String("ignore", "", "A regex pattern which can be used for ignoring paths")
+	cmd.Flags().String("parser", "", fmt.Sprintf("Parser to use to parse the configurations. Valid parsers: %s", parser.Parsers()))
+	cmd.Flags().StringSlice("parser-map", []string{}, "Parsers to use for file extensions, in the form of .ext=parser (e.g. .tfvars=hcl2)")
+
+	cmd.Flags().StringSliceP("data", "d", []string{}, "A list of paths from which data for the rego policies will be recursively loaded, or URLs of documents to fetch over HTTP, or oci:// references of artifacts to pull. Paths in the form of name=path are loaded under data.name")
+	cmd.Flags().StringSliceP("policy", "p", []string{"policy"}, "Path to the Rego policy files directory")
+
+	return &cmd
+}
@@ -0,0 +1,16 @@
+package commands
+
+// ExitError is an error of a command that exits with the given code,
+// rather than the exit code of 1 that errors exit with otherwise.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
@@ -30,7 +30,7 @@ func NewParseCommand(ctx context.Context) *cobra.Command {
 		Short: "Print out structured data from your input files",
 		Long:  parseDesc,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			flagNames := []string{"parser", "combine"}
+			flagNames := []string{"parser", "parser-map", "combine", "build-arg", "dockerfile-stages", "proto-descriptor-set", "proto-message"}
 			for _, name := range flagNames {
 				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
 					return fmt.Errorf("bind flag: %w", err)
@@ -40,13 +40,22 @@ func NewParseCommand(ctx context.Context) *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, files []string) error {
-			var configurations map[string]interface{}
-			var err error
-			if viper.GetString("parser") != "" {
-				configurations, err = parser.ParseConfigurationsAs(files, viper.GetString("parser"))
-			} else {
-				configurations, err = parser.ParseConfigurations(files)
+			parserMap, err := parser.ParseParserMap(viper.GetStringSlice("parser-map"))
+			if err != nil {
+				return fmt.Errorf("parse parser map: %w", err)
+			}
+
+			options := parser.Options{
+				Parser:           viper.GetString("parser"),
+				BuildArgs:        parser.ParseBuildArgs(viper.GetStringSlice("build-arg")),
+				DockerfileStages: viper.GetBool("dockerfile-stages"),
+				ParserMap:        parserMap,
+
+				ProtoDescriptorSet: viper.GetString("proto-descriptor-set"),
+				ProtoMessage:       viper.GetString("proto-message"),
 			}
+
+			configurations, err := parser.ParseConfigurationsWithOptions(files, options)
 			if err != nil {
 				return fmt.Errorf("get configurations: %w", err)
 			}
@@ -68,6 +77,11 @@ func NewParseCommand(ctx context.Context) *cobra.Command {
 
 	cmd.Flags().BoolP("combine", "", false, "Combine all config files to be evaluated together")
 	cmd.Flags().String("parser", "", fmt.Sprintf("Parser to use to parse the configurations. Valid parsers: %s", parser.Parsers()))
+	cmd.Flags().StringSlice("parser-map", []string{}, "Parsers to use for file extensions, in the form of .ext=parser (e.g. .tfvars=hcl2)")
+	cmd.Flags().StringSlice("build-arg", []string{}, "Build arguments, in the form of KEY=VALUE, used to resolve the ARG commands of Dockerfiles")
+	cmd.Flags().Bool("dockerfile-stages", false, "Represent Dockerfiles as a list of build stages")
+	cmd.Flags().String("proto-descriptor-set", "", "Path to the compiled FileDescriptorSet that contains the type of protobuf messages")
+	cmd.Flags().String("proto-message", "", "Fully qualified name of the type of protobuf messages, e.g. envoy.config.bootstrap.v3.Bootstrap")
 
 	return &cmd
 }
@@ -3,6 +3,8 @@ package commands
 import (
 	"context"
 	"fmt"
+	"log"
+	"os"
 	"path/filepath"
 
 	"github.com/open-policy-agent/conftest/downloader"
@@ -41,6 +43,17 @@ The policy location defaults to the policy directory in the local folder.
 The location can be overridden with the '--policy' flag, e.g.:
 
 	$ conftest pull --policy <my-directory> <oci-url>
+
+The signature of OCI artifacts that are signed with cosign can be verified
+before the policies are written, using the '--cosign-key' flag, e.g.:
+
+	$ conftest pull --cosign-key cosign.pub <oci-url>
+
+Policies in git repositories can be pinned to a branch, tag or commit with
+the ref query parameter, and the commit that was checked out is logged.
+A shallow clone can be made with the '--git-depth' flag, e.g.:
+
+	$ conftest pull --git-depth 1 'git::https://<my-repo>//policy?ref=v1.2.3'
 `
 
 // NewPullCommand creates a new pull command to allow users
@@ -53,8 +66,11 @@ func NewPullCommand(ctx context.Context) *cobra.Command {
 		Args:  cobra.MinimumNArgs(1),
 
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			if err := viper.BindPFlag("policy", cmd.Flags().Lookup("policy")); err != nil {
-				return fmt.Errorf("bind flag: %w", err)
+			flagNames := []string{"cosign-key", "git-depth", "policy"}
+			for _, name := range flagNames {
+				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
+					return fmt.Errorf("bind flag: %w", err)
+				}
 			}
 
 			return nil
@@ -62,7 +78,17 @@ func NewPullCommand(ctx context.Context) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			policyDir := filepath.Join(".", viper.GetString("policy"))
 
-			if err := downloader.Download(ctx, policyDir, args); err != nil {
+			if viper.GetInt("git-depth") < 0 {
+				return fmt.Errorf("git depth must not be negative: %v", viper.GetInt("git-depth"))
+			}
+
+			options := downloader.Options{
+				CosignKey: viper.GetString("cosign-key"),
+				GitDepth:  viper.GetInt("git-depth"),
+				Logger:    log.New(os.Stderr, "", 0),
+			}
+
+			if err := downloader.DownloadWithOptions(ctx, policyDir, args, options); err != nil {
 				return fmt.Errorf("download policies: %w", err)
 			}
 
@@ -71,6 +97,8 @@ func NewPullCommand(ctx context.Context) *cobra.Command {
 	}
 
 	cmd.Flags().StringP("policy", "p", "policy", "Path to download the policies to")
+	cmd.Flags().String("cosign-key", "", "Path to the public key that OCI artifacts must be signed with using cosign")
+	cmd.Flags().Int("git-depth", 0, "The depth of the clones of git repositories, where 0 clones the full history")
 
 	return &cmd
 }
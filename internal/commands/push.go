@@ -8,13 +8,13 @@ import (
 	"net/http"
 	"strings"
 
-	auth "github.com/deislabs/oras/pkg/auth/docker"
-	"github.com/deislabs/oras/pkg/content"
-	"github.com/deislabs/oras/pkg/oras"
 	"github.com/open-policy-agent/conftest/policy"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	auth "oras.land/oras-go/pkg/auth/docker"
+	"oras.land/oras-go/pkg/content"
+	"oras.land/oras-go/pkg/oras"
 )
 
 const pushDesc = `
@@ -108,22 +108,38 @@ func pushBundle(ctx context.Context, repository string, path string) (*ocispec.D
 		return nil, fmt.Errorf("docker resolver: %w", err)
 	}
 
-	memoryStore := content.NewMemoryStore()
+	memoryStore := content.NewMemory()
 	layers, err := buildLayers(ctx, memoryStore, path)
 	if err != nil {
 		return nil, fmt.Errorf("building layers: %w", err)
 	}
 
-	extraOpts := []oras.PushOpt{oras.WithConfigMediaType(openPolicyAgentConfigMediaType)}
-	manifest, err := oras.Push(ctx, resolver, repository, memoryStore, layers, extraOpts...)
+	config, configDescriptor, err := content.GenerateConfig(nil)
+	if err != nil {
+		return nil, fmt.Errorf("generate config: %w", err)
+	}
+	configDescriptor.MediaType = openPolicyAgentConfigMediaType
+	memoryStore.Set(configDescriptor, config)
+
+	manifest, manifestDescriptor, err := content.GenerateManifest(&configDescriptor, nil, layers...)
+	if err != nil {
+		return nil, fmt.Errorf("generate manifest: %w", err)
+	}
+
+	if err := memoryStore.StoreManifest(repository, manifestDescriptor, manifest); err != nil {
+		return nil, fmt.Errorf("store manifest: %w", err)
+	}
+
+	registry := &content.Registry{Resolver: resolver}
+	pushed, err := oras.Copy(ctx, memoryStore, repository, registry, "")
 	if err != nil {
 		return nil, fmt.Errorf("pushing manifest: %w", err)
 	}
 
-	return &manifest, nil
+	return &pushed, nil
 }
 
-func buildLayers(ctx context.Context, memoryStore *content.Memorystore, path string) ([]ocispec.Descriptor, error) {
+func buildLayers(ctx context.Context, memoryStore *content.Memory, path string) ([]ocispec.Descriptor, error) {
 	engine, err := policy.LoadWithData(ctx, []string{path}, []string{path})
 	if err != nil {
 		return nil, fmt.Errorf("load: %w", err)
@@ -131,11 +147,21 @@ func buildLayers(ctx context.Context, memoryStore *content.Memorystore, path str
 
 	var layers []ocispec.Descriptor
 	for path, contents := range engine.Policies() {
-		layers = append(layers, memoryStore.Add(path, openPolicyAgentPolicyLayerMediaType, []byte(contents)))
+		layer, err := memoryStore.Add(path, openPolicyAgentPolicyLayerMediaType, []byte(contents))
+		if err != nil {
+			return nil, fmt.Errorf("add policy %s: %w", path, err)
+		}
+
+		layers = append(layers, layer)
 	}
 
 	for path, contents := range engine.Documents() {
-		layers = append(layers, memoryStore.Add(path, openPolicyAgentDataLayerMediaType, []byte(contents)))
+		layer, err := memoryStore.Add(path, openPolicyAgentDataLayerMediaType, []byte(contents))
+		if err != nil {
+			return nil, fmt.Errorf("add document %s: %w", path, err)
+		}
+
+		layers = append(layers, layer)
 	}
 
 	return layers, nil
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/open-policy-agent/conftest/internal/runner"
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const reportDesc = `
+This command reports the results of previous runs of the test command, which
+were written in the JSON format, in any of the output formats.
+
+This makes it possible to test the configurations in several steps, and to
+report all of the results at the end, e.g. as a single JUnit report:
+
+	$ conftest test -o json kubernetes/ > kubernetes.json
+	$ conftest test -o json terraform/ > terraform.json
+	$ conftest report --merge kubernetes.json terraform.json --output junit
+
+The '--merge' flag merges the results of the same file and namespace into one,
+so that a file that was tested in more than one step is reported once. The exit
+code is the same as the exit code of the test command for the same results.
+`
+
+// NewReportCommand creates a new report command which reports the
+// results of previous runs of the test command.
+func NewReportCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "report <file> [file...]",
+		Short: "Report the results of previous runs in any output format",
+		Long:  reportDesc,
+		Args:  cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flagNames := []string{"fail-on-warn", "merge", "no-color", "output"}
+			for _, name := range flagNames {
+				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
+					return fmt.Errorf("bind flag: %w", err)
+				}
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var runner runner.ReportRunner
+			if err := viper.Unmarshal(&runner); err != nil {
+				return fmt.Errorf("unmarshal parameters: %w", err)
+			}
+
+			outputter, err := output.New(runner.Output, output.Options{NoColor: runner.NoColor})
+			if err != nil {
+				return fmt.Errorf("get outputter: %w", err)
+			}
+
+			results, err := runner.Run(args)
+			if err != nil {
+				return fmt.Errorf("running report: %w", err)
+			}
+
+			if err := outputter.Output(results); err != nil {
+				return fmt.Errorf("output results: %w", err)
+			}
+
+			exitCode := output.ExitCode(results)
+			if runner.FailOnWarn {
+				exitCode = output.ExitCodeFailOnWarn(results)
+			}
+
+			if exitCode > 0 {
+				os.Exit(exitCode)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("merge", false, "Merge the results of the same file and namespace across all of the files into one")
+	cmd.Flags().Bool("fail-on-warn", false, "Return a non-zero exit code if warnings or errors are found")
+	cmd.Flags().Bool("no-color", false, "Disable color when printing")
+
+	cmd.Flags().StringP("output", "o", output.OutputStandard, fmt.Sprintf("Output format for conftest results - valid options are: %s", output.Outputs()))
+
+	return &cmd
+}
@@ -2,12 +2,16 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/open-policy-agent/conftest/internal/runner"
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/policy"
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/storage"
 	"github.com/spf13/cobra"
@@ -21,6 +25,15 @@ The test command expects one or more input files that will be evaluated
 against Open Policy Agent policies. Directories are also supported as valid
 inputs. 
 
+Glob patterns, including '**' to match any number of directories, are expanded
+by conftest itself, which is useful when the shell does not expand them, e.g.:
+
+	$ conftest test 'deploy/**/*.yaml'
+
+The files that match a pattern are filtered the same as the files in the
+directories that are given, so the ignored files and the files that cannot be
+parsed are skipped.
+
 Policies are written in the Rego language. For more
 information on how to write Rego policies, see the documentation:
 https://www.openpolicyagent.org/docs/latest/policy-language/
@@ -82,9 +95,16 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 		Use:   "test <file> [file...]",
 		Short: "Test your configuration files using Open Policy Agent",
 		Long:  testDesc,
-		Args:  cobra.MinimumNArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			// The environment can be tested without any files.
+			if env, _ := cmd.Flags().GetBool("env"); env {
+				return nil
+			}
+
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			flagNames := []string{"all-namespaces", "combine", "data", "fail-on-warn", "ignore", "namespace", "no-color", "output", "parser", "policy", "trace", "update"}
+			flagNames := []string{"abort-on-error", "all-namespaces", "allow-empty", "baseline", "build-arg", "capabilities", "combine", "combine-by", "cosign-key", "coverage", "data", "data-as", "dedupe", "detailed-exit-codes", "dockerfile-stages", "env", "exclude-namespace", "expand-lists", "fail-fast", "fail-on-exception-ratio", "fail-on-warn", "fail-on-warn-namespace", "fail-severity", "fail-threshold", "file-metadata", "follow-symlinks", "git-depth", "helm", "helm-set", "helm-values", "ignore", "ignore-dir", "input-meta", "list-files", "max-parser-errors", "max-results-per-file", "namespace", "namespace-map", "no-color", "no-fail", "no-progress", "no-sniff", "no-summary", "only-root-namespaces", "output", "output-file", "parallel", "parallel-namespaces", "parser", "parser-map", "policy", "proto-descriptor-set", "proto-message", "rego-version", "report-passes", "resolve-refs", "rewrite-print-to-output", "rule", "rule-prefixes", "show-all-rules", "since", "strict", "timeout", "trace", "trace-output", "update", "update-baseline", "verbose", "watch"}
 			for _, name := range flagNames {
 				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
 					return fmt.Errorf("bind flag: %w", err)
@@ -94,29 +114,101 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 			return nil
 		},
 
-		RunE: func(cmd *cobra.Command, fileList []string) error {
+		RunE: func(cmd *cobra.Command, fileList []string) (err error) {
+			detailedExitCodes := viper.GetBool("detailed-exit-codes")
+
+			// With the detailed exit codes, errors exit with their own exit
+			// code so that they can be told apart from failures.
+			defer func() {
+				if err != nil && detailedExitCodes {
+					err = &ExitError{Code: output.ExitCodeError, Err: err}
+				}
+			}()
+
 			var runner runner.TestRunner
 			if err := viper.Unmarshal(&runner); err != nil {
 				return fmt.Errorf("unmarshal parameters: %w", err)
 			}
 
+			if runner.UpdateBaseline && runner.Baseline == "" {
+				return fmt.Errorf("the --update-baseline flag requires a baseline file to be specified with --baseline")
+			}
+
+			if runner.GitDepth < 0 {
+				return fmt.Errorf("git depth must not be negative: %v", runner.GitDepth)
+			}
+
+			if runner.FailThreshold < 0 {
+				return fmt.Errorf("fail threshold must not be negative: %v", runner.FailThreshold)
+			}
+
+			if runner.FailOnExceptionRatio < 0 || runner.FailOnExceptionRatio > 1 {
+				return fmt.Errorf("exception ratio must be between 0 and 1: %v", runner.FailOnExceptionRatio)
+			}
+
+			if runner.Coverage != "" && runner.Coverage != output.CoverageText && runner.Coverage != output.CoverageJSON {
+				return fmt.Errorf("unknown coverage format %q, valid formats are: %v", runner.Coverage, []string{output.CoverageText, output.CoverageJSON})
+			}
+
+			if runner.ListFiles {
+				return listFiles(runner, fileList)
+			}
+
+			// The outputter is created before running the policies so that an
+			// invalid output template is reported without running any policies.
+			outputter, err := newTestOutputter(runner)
+			if err != nil {
+				return fmt.Errorf("get outputter: %w", err)
+			}
+
+			if runner.TraceOutput != "" {
+				outputter = &output.TraceFile{Path: runner.TraceOutput, Echo: outputter}
+			}
+
+			if viper.GetBool("watch") {
+				return watch(ctx, &runner, outputter, fileList)
+			}
+
 			results, err := runner.Run(ctx, fileList)
 			if err != nil {
 				return fmt.Errorf("running test: %w", err)
 			}
 
-			outputter := output.Get(runner.Output, output.Options{NoColor: runner.NoColor, Tracing: runner.Trace})
 			if err := outputter.Output(results); err != nil {
 				return fmt.Errorf("output results: %w", err)
 			}
 
+			// The coverage report is written to stderr so that it does not
+			// interfere with the results, which may be read by other tools.
+			if runner.Coverage != "" {
+				if err := output.WriteCoverage(os.Stderr, runner.CoverageReport(), runner.Coverage); err != nil {
+					return fmt.Errorf("output coverage: %w", err)
+				}
+			}
+
+			// The warnings of the namespaces that fail on warnings are only
+			// counted as failures for the exit code, and are still output
+			// as warnings.
+			results = runner.WarningsAsFailures(results)
+
 			var exitCode int
-			if runner.FailOnWarn {
-				exitCode = output.ExitCodeFailOnWarn(results)
+			if detailedExitCodes {
+				exitCode = output.ExitCodeDetailed(results, runner.FailThreshold, runner.FailOnWarn)
+			} else if runner.FailOnWarn {
+				exitCode = output.ExitCodeFailOnWarnWithThreshold(results, runner.FailThreshold)
 			} else {
-				exitCode = output.ExitCode(results)
+				exitCode = output.ExitCodeWithThreshold(results, runner.FailThreshold)
+			}
+
+			if ratio := output.ExceptionRatio(results); ratio > runner.FailOnExceptionRatio {
+				fmt.Fprintf(os.Stderr, "The ratio of exceptions to tests (%.2f) exceeds the tolerated ratio (%.2f)\n", ratio, runner.FailOnExceptionRatio)
+
+				exitCode = output.ExitCodeExceptionRatio(exitCode, runner.FailOnWarn, detailedExitCodes)
 			}
-			if exitCode > 0 {
+
+			// The results are only reported, and never fail the test, which
+			// makes it possible to adopt policies without blocking changes.
+			if exitCode > 0 && !runner.NoFail {
 				os.Exit(exitCode)
 			}
 
@@ -124,21 +216,174 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().Bool("fail-fast", false, "Stop evaluating the policies at the first failure")
 	cmd.Flags().Bool("fail-on-warn", false, "Return a non-zero exit code if warnings or errors are found")
+	cmd.Flags().StringSlice("fail-on-warn-namespace", []string{}, "Namespaces whose warnings count as failures towards the exit code, where a namespace that ends with * matches all of the namespaces that start with it")
+	cmd.Flags().Bool("detailed-exit-codes", false, "Return 1 if failures are found, 2 if only warnings are found and 3 if an error occurs")
+	cmd.Flags().Bool("list-files", false, "List the files that would be tested, one per line or as JSON with --output json, without running any policies")
+	cmd.Flags().Bool("follow-symlinks", false, "Follow symbolic links to directories when loading the policies")
+	cmd.Flags().StringSlice("input-meta", []string{}, "Metadata to add to the input under the __meta__ key, in the form of key=value (e.g. environment=production)")
+	cmd.Flags().Bool("file-metadata", false, "Add the metadata of each file, such as its path and extension, to the input under the __file__ key")
 	cmd.Flags().BoolP("trace", "", false, "Enable more verbose trace output for Rego queries")
+	cmd.Flags().Bool("verbose", false, "Log the policies that are loaded to stderr")
+	cmd.Flags().String("trace-output", "", "Path to a file to write the traces of the Rego queries to, with a section for each file and namespace, instead of tracing in the output")
 	cmd.Flags().Bool("no-color", false, "Disable color when printing")
+	cmd.Flags().Bool("no-fail", false, "Always return a zero exit code, even if failures are found")
+	cmd.Flags().Bool("env", false, "Test the environment variables as an input named env, in addition to the config files")
+	cmd.Flags().Bool("expand-lists", false, "Test the items of Kubernetes Lists (e.g. kind: List) as documents of their own instead of the Lists")
+	cmd.Flags().Bool("no-progress", false, "Do not report the progress of the files that are parsed and checked on stderr when it is a terminal")
+	cmd.Flags().Bool("resolve-refs", false, "Replace the $ref references of the config files, e.g. of JSON Schemas and OpenAPI documents, with the values that they refer to")
+	cmd.Flags().Bool("no-sniff", false, "Do not choose the parser of files with an unknown extension based on their contents")
+	cmd.Flags().Bool("no-summary", false, "Do not print a summary of the results to stdout when they are written to --output-file")
 	cmd.Flags().Bool("all-namespaces", false, "Test policies found in all namespaces")
+	cmd.Flags().Bool("allow-empty", false, "Allow testing namespaces without any rules, and policy paths without any policies, instead of returning an error")
+	cmd.Flags().Bool("only-root-namespaces", false, "Only test the namespaces of the policies in the policy directories themselves with --all-namespaces, and not in their subdirectories")
 	cmd.Flags().BoolP("combine", "", false, "Combine all config files to be evaluated together")
-
+	cmd.Flags().String("combine-by", "", "Group the config files by a key before combining them, either dir or a path into the contents such as $.metadata.namespace")
+	cmd.Flags().Bool("strict", false, "Enable strict compilation of the policies, and fail when a namespace does not produce any results")
+	cmd.Flags().Bool("helm", false, "Render the directories of Helm charts with helm template before testing the rendered manifests")
+	cmd.Flags().StringSlice("helm-values", []string{}, "Values files to render the Helm charts with, which are passed to helm template with --values")
+	cmd.Flags().StringArray("helm-set", []string{}, "Values to render the Helm charts with, in the form of key=value, which are passed to helm template with --set")
+	cmd.Flags().String("capabilities", "", "Path to an OPA capabilities file that restricts the builtins that the policies are allowed to use")
+	cmd.Flags().String("rego-version", "", fmt.Sprintf("The version of Rego that the policies are written in, %s unless declared by the manifest of a bundle - valid versions are: %v", policy.DefaultRegoVersion, []string{policy.RegoV0, policy.RegoV1}))
+	cmd.Flags().Bool("dockerfile-stages", false, "Represent Dockerfiles as a list of build stages")
+	cmd.Flags().Bool("update-baseline", false, "Regenerate the baseline file from the failures that are found")
+	cmd.Flags().Bool("watch", false, "Evaluate the policies again every time the policies, the data or the configuration files change")
+
+	cmd.Flags().Float64("fail-on-exception-ratio", 1, "Return a non-zero exit code if the ratio of exceptions to tests exceeds the given ratio (between 0 and 1)")
+	cmd.Flags().Int("fail-threshold", 0, "The number of failures that are tolerated before returning a non-zero exit code")
+	cmd.Flags().Int("max-parser-errors", 0, "The number of files that fail to be parsed which are reported as failures instead of stopping the test")
+	cmd.Flags().Int("parallel", 0, "The number of files to evaluate concurrently, defaults to the number of available CPUs")
+	cmd.Flags().Int("parallel-namespaces", 0, "The number of namespaces to evaluate concurrently, defaults to one at a time")
+	cmd.Flags().Bool("abort-on-error", false, "Stop at the first runtime error of a policy instead of reporting it as a failure of the file")
+	cmd.Flags().Duration("timeout", 0, "The longest time to evaluate the policies of a namespace against a file before reporting it as a failure (e.g. 30s), defaults to no timeout")
+
+	cmd.Flags().String("baseline", "", "Path to a file of known failures that should not fail the test")
+	cmd.Flags().String("cosign-key", "", "Path to the public key that the OCI artifacts to update must be signed with using cosign")
+	cmd.Flags().Int("git-depth", 0, "The depth of the clones of the git repositories to update, where 0 clones the full history")
+	cmd.Flags().String("coverage", "", fmt.Sprintf("Report the coverage of the policies to stderr - valid formats are: %v", []string{output.CoverageText, output.CoverageJSON}))
+	cmd.Flags().Lookup("coverage").NoOptDefVal = output.CoverageText
+	cmd.Flags().String("dedupe", "", fmt.Sprintf("Collapse the results of a file with the same message across namespaces into one - valid keys are: %v", []string{runner.DedupeMessage, runner.DedupeRule}))
+	cmd.Flags().Lookup("dedupe").NoOptDefVal = runner.DedupeMessage
 	cmd.Flags().String("ignore", "", "A regex pattern which can be used for ignoring paths")
+	cmd.Flags().StringSlice("ignore-dir", []string{}, "Names of directories to skip at any depth, including their subdirectories, which may contain wildcards (e.g. testdata,*_test)")
+	cmd.Flags().String("since", "", "Only test the files that have changed since the given git revision, or in the given range of revisions (e.g. origin/main...HEAD)")
 	cmd.Flags().String("parser", "", fmt.Sprintf("Parser to use to parse the configurations. Valid parsers: %s", parser.Parsers()))
 
 	cmd.Flags().StringP("output", "o", output.OutputStandard, fmt.Sprintf("Output format for conftest results - valid options are: %s", output.Outputs()))
+	cmd.Flags().String("output-file", "", "Path to a file to write the results to in the output format, creating its parent directories")
 
-	cmd.Flags().StringSliceP("policy", "p", []string{"policy"}, "Path to the Rego policy files directory")
+	cmd.Flags().StringSliceP("policy", "p", []string{"policy"}, "Path to the Rego policy files directory, or the URL of a bundle to fetch from a bundle server")
 	cmd.Flags().StringSliceP("update", "u", []string{}, "A list of URLs can be provided to the update flag, which will download before the tests run")
 	cmd.Flags().StringSliceP("namespace", "n", []string{"main"}, "Test policies in a specific namespace")
-	cmd.Flags().StringSliceP("data", "d", []string{}, "A list of paths from which data for the rego policies will be recursively loaded")
+	cmd.Flags().StringSlice("namespace-map", []string{}, "Namespaces to move the packages of policy paths under, in the form of path=namespace (e.g. bundles/a=team.a)")
+	cmd.Flags().StringSlice("exclude-namespace", []string{}, "Namespaces to not test, where a trailing * excludes all namespaces with the prefix (e.g. legacy.*)")
+	cmd.Flags().StringSlice("parser-map", []string{}, "Parsers to use for file extensions, in the form of .ext=parser (e.g. .tfvars=hcl2)")
+	cmd.Flags().Int("max-results-per-file", 0, "The number of failures, and of warnings, to output for each file in the standard format, noting how many more were found, defaults to all of them")
+	cmd.Flags().Bool("show-all-rules", false, "Output every rule that was evaluated against each file, including the rules that passed, in the standard output")
+	cmd.Flags().Bool("report-passes", false, "Report the rules that passed for each file in the json, toml and junit outputs, in addition to the results of the rules that did not")
+	cmd.Flags().Bool("rewrite-print-to-output", false, "Output the output of the print statements of the policies as debug lines under each file in the standard output")
+	cmd.Flags().StringSlice("rule", []string{}, "Only evaluate the rules with the given names (e.g. deny or warn_labels)")
+	cmd.Flags().StringSlice("rule-prefixes", []string{}, fmt.Sprintf("Prefixes of additional rules to evaluate, in the form of prefix=severity (e.g. critical=critical). Valid severities: %v", policy.Severities))
+	cmd.Flags().String("fail-severity", policy.DefaultFailSeverity, "The lowest severity of the results of the rules given by --rule-prefixes that are failures, lower severities are warnings")
+	cmd.Flags().StringSliceP("data", "d", []string{}, "A list of paths from which data for the rego policies will be recursively loaded, or URLs of documents to fetch over HTTP, or oci:// references of artifacts to pull. Paths in the form of name=path are loaded under data.name")
+	cmd.Flags().String("data-as", "", fmt.Sprintf("Parser to use to parse all of the data files, regardless of their extension. Valid parsers: %s", parser.Parsers()))
+	cmd.Flags().StringSlice("build-arg", []string{}, "Build arguments, in the form of KEY=VALUE, used to resolve the ARG commands of Dockerfiles")
+	cmd.Flags().String("proto-descriptor-set", "", "Path to the compiled FileDescriptorSet that contains the type of protobuf messages")
+	cmd.Flags().String("proto-message", "", "Fully qualified name of the type of protobuf messages, e.g. envoy.config.bootstrap.v3.Bootstrap")
 
 	return &cmd
 }
+
+// newTestOutputter returns the outputter of the results, which either writes
+// them to stdout, or to the output file along with a summary on stdout.
+func newTestOutputter(testRunner runner.TestRunner) (output.Outputter, error) {
+	options := output.Options{NoColor: testRunner.NoColor, Tracing: testRunner.Trace, ShowAllRules: testRunner.ShowAllRules, ShowPrints: testRunner.RewritePrintToOutput, MaxResultsPerFile: testRunner.MaxResultsPerFile}
+	if testRunner.OutputFile == "" {
+		return output.New(testRunner.Output, options)
+	}
+
+	// Colors are only meant for terminals, so they are never written to files.
+	fileOptions := options
+	fileOptions.NoColor = true
+	file, err := output.NewFile(testRunner.OutputFile, testRunner.Output, fileOptions)
+	if err != nil {
+		return nil, fmt.Errorf("new file: %w", err)
+	}
+
+	if !testRunner.NoSummary {
+		file.Echo = &output.Summary{Writer: os.Stdout, NoColor: testRunner.NoColor}
+	}
+
+	return file, nil
+}
+
+// listFiles prints the files that would be tested, one path per line, or as
+// a JSON array when the output format is JSON.
+func listFiles(testRunner runner.TestRunner, fileList []string) error {
+	files, err := testRunner.Files(fileList)
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+
+	if testRunner.Output == output.OutputJSON {
+		out, err := json.MarshalIndent(files, "", "\t")
+		if err != nil {
+			return fmt.Errorf("marshal files: %w", err)
+		}
+
+		fmt.Fprintln(os.Stdout, string(out))
+		return nil
+	}
+
+	for _, file := range files {
+		fmt.Fprintln(os.Stdout, file)
+	}
+
+	return nil
+}
+
+// watch evaluates the policies, and evaluates them again every time the
+// policies or the configuration files change, until the process is interrupted.
+func watch(ctx context.Context, testRunner *runner.TestRunner, outputter output.Outputter, fileList []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// Errors are reported without stopping the watch, so that mistakes
+	// in the policies can be fixed while conftest is running.
+	report := func(results []output.CheckResult, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: running test: %v\n", err)
+			return
+		}
+
+		if err := outputter.Output(results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: output results: %v\n", err)
+			return
+		}
+
+		if testRunner.Coverage != "" {
+			if err := output.WriteCoverage(os.Stderr, testRunner.CoverageReport(), testRunner.Coverage); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: output coverage: %v\n", err)
+			}
+		}
+	}
+
+	if err := testRunner.Watch(ctx, fileList, report); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/open-policy-agent/conftest/internal/runner"
 	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/policy"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -46,6 +47,12 @@ When debugging policies it can be useful to use a more verbose policy evaluation
 the output will include a detailed trace of how the policy was evaluated, e.g.
 
 	$ conftest verify --trace
+
+To only run some of the tests, the '--run' flag takes a regular expression that
+the names of the tests, e.g. data.main.test_deny, are matched against. The '--verbose'
+flag outputs whether each test passed or failed along with how long it took to run:
+
+	$ conftest verify --run 'test_deny_.*' --verbose
 `
 
 // NewVerifyCommand creates a new verify command which allows users
@@ -56,7 +63,7 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 		Short: "Verify Rego unit tests",
 		Long:  verifyDesc,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			flagNames := []string{"data", "no-color", "output", "policy", "trace"}
+			flagNames := []string{"capabilities", "data", "fail-fast", "follow-symlinks", "no-color", "output", "policy", "rego-version", "run", "trace", "verbose"}
 			for _, name := range flagNames {
 				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
 					return fmt.Errorf("bind flag: %w", err)
@@ -71,12 +78,16 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("unmarshal parameters: %w", err)
 			}
 
+			outputter, err := output.New(runner.Output, output.Options{NoColor: runner.NoColor, Tracing: runner.Trace, ShowAllRules: runner.Verbose})
+			if err != nil {
+				return fmt.Errorf("get outputter: %w", err)
+			}
+
 			results, err := runner.Run(ctx)
 			if err != nil {
 				return fmt.Errorf("running verification: %w", err)
 			}
 
-			outputter := output.Get(runner.Output, output.Options{NoColor: runner.NoColor, Tracing: runner.Trace})
 			if err := outputter.Output(results); err != nil {
 				return fmt.Errorf("output results: %w", err)
 			}
@@ -92,10 +103,16 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 
 	cmd.Flags().Bool("no-color", false, "Disable color when printing")
 	cmd.Flags().Bool("trace", false, "Enable more verbose trace output for Rego queries")
+	cmd.Flags().Bool("verbose", false, "Output whether each test passed or failed along with how long it took to run, in the standard output")
+	cmd.Flags().Bool("fail-fast", false, "Stop running the tests at the first test that failed")
+	cmd.Flags().String("run", "", "Only run the tests whose names, e.g. data.main.test_deny, match the regular expression")
+	cmd.Flags().Bool("follow-symlinks", false, "Follow symbolic links to directories when loading the policies")
+	cmd.Flags().String("capabilities", "", "Path to an OPA capabilities file that restricts the builtins that the policies are allowed to use")
+	cmd.Flags().String("rego-version", "", fmt.Sprintf("The version of Rego that the policies are written in, %s unless declared by the manifest of a bundle - valid versions are: %v", policy.DefaultRegoVersion, []string{policy.RegoV0, policy.RegoV1}))
 
 	cmd.Flags().StringP("output", "o", output.OutputStandard, fmt.Sprintf("Output format for conftest results - valid options are: %s", output.Outputs()))
 
-	cmd.Flags().StringSliceP("data", "d", []string{}, "A list of paths from which data for the rego policies will be recursively loaded")
+	cmd.Flags().StringSliceP("data", "d", []string{}, "A list of paths from which data for the rego policies will be recursively loaded, or URLs of documents to fetch over HTTP, or oci:// references of artifacts to pull. Paths in the form of name=path are loaded under data.name")
 	cmd.Flags().StringSliceP("policy", "p", []string{"policy"}, "Path to the Rego policy files directory")
 
 	return &cmd
@@ -0,0 +1,72 @@
+// Package remote fetches files over HTTP. It is separate from the downloader
+// package so that the parser package can fetch configurations without
+// depending on the clients of the other sources of policies.
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TokenEnv is the environment variable that holds the bearer token that
+// is sent in the Authorization header of the requests over HTTP.
+const TokenEnv = "CONFTEST_HTTP_TOKEN"
+
+// maxRedirects is the number of redirects that are followed before
+// a request fails, which matches the default of the http package.
+const maxRedirects = 10
+
+// Get fetches the given URL, sending the bearer token in the TokenEnv
+// environment variable, when it is set, in the Authorization header of the
+// request. An error is returned when the response does not have a 2xx status.
+// The caller must close the body of the response.
+func Get(ctx context.Context, url string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	request = request.WithContext(ctx)
+
+	if token := strings.TrimSpace(os.Getenv(TokenEnv)); token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := NewClient().Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		response.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	return response, nil
+}
+
+// NewClient returns a client that removes the Authorization header of its
+// requests when they are redirected to another server, so that a bearer token
+// can be sent to a server without being leaked to the servers it redirects to.
+func NewClient() *http.Client {
+	return &http.Client{CheckRedirect: checkRedirect}
+}
+
+// checkRedirect removes the Authorization header when a request is redirected
+// to another host, including the same host on another port, or from HTTPS to
+// HTTP, so that the token is only sent to the server it was intended for.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	original := via[0].URL
+	if req.URL.Host != original.Host || (original.Scheme == "https" && req.URL.Scheme != "https") {
+		req.Header.Del("Authorization")
+	}
+
+	return nil
+}
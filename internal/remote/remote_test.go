@@ -0,0 +1,90 @@
+package remote
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte("contents"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	if _, err := Get(ctx, server.URL); err == nil {
+		t.Fatal("expected an error without a token")
+	}
+
+	os.Setenv(TokenEnv, "secret")
+	defer os.Unsetenv(TokenEnv)
+
+	response, err := Get(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+
+	if string(contents) != "contents" {
+		t.Errorf("unexpected contents %q", contents)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := Get(cancelled, server.URL); err == nil {
+		t.Error("expected an error with a cancelled context")
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	tests := []struct {
+		name         string
+		from         string
+		to           string
+		expectHeader bool
+	}{
+		{"same host", "https://example.com/a", "https://example.com/b", true},
+		{"another host", "https://example.com/a", "https://other.example.com/b", false},
+		{"another port", "https://example.com/a", "https://example.com:8443/b", false},
+		{"downgrade to http", "https://example.com/a", "http://example.com/b", false},
+		{"upgrade to https", "http://example.com/a", "https://example.com/b", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, err := url.Parse(tt.from)
+			if err != nil {
+				t.Fatalf("parse url: %v", err)
+			}
+
+			to, err := url.Parse(tt.to)
+			if err != nil {
+				t.Fatalf("parse url: %v", err)
+			}
+
+			req := &http.Request{URL: to, Header: http.Header{"Authorization": []string{"Bearer secret"}}}
+			if err := checkRedirect(req, []*http.Request{{URL: from}}); err != nil {
+				t.Fatalf("checkRedirect() error = %v", err)
+			}
+
+			if actual := req.Header.Get("Authorization") != ""; actual != tt.expectHeader {
+				t.Errorf("checkRedirect() kept header = %v, want %v", actual, tt.expectHeader)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+// baselineEntry identifies a single known failure. The message is stored as
+// a hash so that the baseline does not need to contain the full messages.
+type baselineEntry struct {
+	FileName    string `json:"filename"`
+	Namespace   string `json:"namespace"`
+	Rule        string `json:"rule"`
+	MessageHash string `json:"message_hash"`
+}
+
+func newBaselineEntry(checkResult output.CheckResult, result output.Result) baselineEntry {
+	hash := sha256.Sum256([]byte(result.Message))
+
+	return baselineEntry{
+		FileName:    filepath.ToSlash(checkResult.FileName),
+		Namespace:   checkResult.Namespace,
+		Rule:        result.Rule,
+		MessageHash: hex.EncodeToString(hash[:]),
+	}
+}
+
+// loadBaseline reads the known failures from the baseline file at the given path.
+func loadBaseline(path string) (map[baselineEntry]bool, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+
+	var entries []baselineEntry
+	if err := json.Unmarshal(contents, &entries); err != nil {
+		return nil, fmt.Errorf("unmarshal baseline: %w", err)
+	}
+
+	baseline := make(map[baselineEntry]bool)
+	for _, entry := range entries {
+		baseline[entry] = true
+	}
+
+	return baseline, nil
+}
+
+// writeBaseline writes all of the failures of the given results to the
+// baseline file at the given path.
+func writeBaseline(path string, results []output.CheckResult) error {
+	entries := []baselineEntry{}
+	seen := make(map[baselineEntry]bool)
+	for _, checkResult := range results {
+		for _, failure := range checkResult.Failures {
+			entry := newBaselineEntry(checkResult, failure)
+			if seen[entry] {
+				continue
+			}
+
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	// For consistency between runs, sort the entries so that regenerating
+	// the baseline does not produce any changes when the failures are the same.
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.FileName != b.FileName {
+			return a.FileName < b.FileName
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+
+		return a.MessageHash < b.MessageHash
+	})
+
+	contents, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return fmt.Errorf("marshal baseline: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, append(contents, '\n'), 0644); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+
+	return nil
+}
+
+// applyBaseline demotes the failures that are found in the baseline to
+// exceptions, so that only new failures are considered to have failed.
+func applyBaseline(results []output.CheckResult, baseline map[baselineEntry]bool) {
+	for i := range results {
+		var failures []output.Result
+		for _, failure := range results[i].Failures {
+			if !baseline[newBaselineEntry(results[i], failure)] {
+				failures = append(failures, failure)
+				continue
+			}
+
+			failure.Baseline = true
+			results[i].Exceptions = append(results[i].Exceptions, failure)
+		}
+
+		results[i].Failures = failures
+	}
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+func TestBaseline(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftestbaseline")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	baselinePath := filepath.Join(directory, "baseline.json")
+	known := []output.CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures:  []output.Result{{Message: "known failure", Rule: "deny"}},
+		},
+	}
+
+	if err := writeBaseline(baselinePath, known); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+
+	baseline, err := loadBaseline(baselinePath)
+	if err != nil {
+		t.Fatalf("load baseline: %v", err)
+	}
+
+	results := []output.CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []output.Result{
+				{Message: "known failure", Rule: "deny"},
+				{Message: "new failure", Rule: "deny"},
+			},
+		},
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "other",
+			Failures:  []output.Result{{Message: "known failure", Rule: "deny"}},
+		},
+	}
+	applyBaseline(results, baseline)
+
+	if len(results[0].Failures) != 1 || results[0].Failures[0].Message != "new failure" {
+		t.Errorf("Unexpected failures. expected only the new failure, actual %v", results[0].Failures)
+	}
+
+	if len(results[0].Exceptions) != 1 || results[0].Exceptions[0].Message != "known failure" || !results[0].Exceptions[0].Baseline {
+		t.Errorf("Unexpected exceptions. expected the known failure, actual %v", results[0].Exceptions)
+	}
+
+	if len(results[1].Failures) != 1 {
+		t.Errorf("Unexpected failures. expected the failure in another namespace to remain, actual %v", results[1].Failures)
+	}
+}
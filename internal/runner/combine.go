@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// combineByDirectory is the key of --combine-by that groups the files by the
+// directory that they are in.
+const combineByDirectory = "dir"
+
+// groupKey returns the key of the group of the given document of the file
+// with the given path, and whether the document has a key.
+type groupKey func(path string, document interface{}) (string, bool)
+
+// parseCombineBy parses the key that the configurations are grouped by before
+// they are combined, which is either dir, to group the files by the directory
+// that they are in, or a path into the contents of the documents, such as
+// $.metadata.namespace, to group the documents by the value at the path.
+func parseCombineBy(combineBy string) (groupKey, error) {
+	if combineBy == combineByDirectory {
+		return func(path string, document interface{}) (string, bool) {
+			return filepath.Dir(path), true
+		}, nil
+	}
+
+	if !strings.HasPrefix(combineBy, "$.") || strings.HasSuffix(combineBy, ".") {
+		return nil, fmt.Errorf("combine by %q must either be %s or a path into the contents, such as $.metadata.namespace", combineBy, combineByDirectory)
+	}
+
+	keys := strings.Split(strings.TrimPrefix(combineBy, "$."), ".")
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("combine by %q contains an empty key", combineBy)
+		}
+	}
+
+	return func(path string, document interface{}) (string, bool) {
+		value, ok := lookupPath(document, keys)
+		if !ok || value == nil {
+			return "", false
+		}
+
+		if s, ok := value.(string); ok {
+			return s, true
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+
+		return string(encoded), true
+	}, nil
+}
+
+// lookupPath returns the value at the given keys of the document.
+func lookupPath(document interface{}, keys []string) (interface{}, bool) {
+	value := document
+	for _, key := range keys {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = object[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// configurationGroup is a group of the configurations that are combined
+// into a single input.
+type configurationGroup struct {
+	// Name is the name of the file that the results of the group are
+	// reported under.
+	Name           string
+	Configurations map[string]interface{}
+}
+
+// groupConfigurations buckets the given configurations by their keys, where
+// each of the documents of a file with several documents is bucketed on its
+// own. The groups are sorted by their keys, and the documents that do not
+// have a key are grouped together after them.
+func groupConfigurations(configurations map[string]interface{}, key groupKey) []configurationGroup {
+	buckets := make(map[string]map[string]interface{})
+	var unkeyed map[string]interface{}
+
+	add := func(path string, document interface{}, multiple bool) {
+		name, ok := key(path, document)
+
+		var bucket map[string]interface{}
+		if ok {
+			bucket = buckets[name]
+			if bucket == nil {
+				bucket = make(map[string]interface{})
+				buckets[name] = bucket
+			}
+		} else {
+			if unkeyed == nil {
+				unkeyed = make(map[string]interface{})
+			}
+			bucket = unkeyed
+		}
+
+		if !multiple {
+			bucket[path] = document
+			return
+		}
+
+		documents, _ := bucket[path].([]interface{})
+		bucket[path] = append(documents, document)
+	}
+
+	for path, config := range configurations {
+		if documents, ok := config.([]interface{}); ok {
+			for _, document := range documents {
+				add(path, document, true)
+			}
+			continue
+		}
+
+		add(path, config, false)
+	}
+
+	var names []string
+	for name := range buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var groups []configurationGroup
+	for _, name := range names {
+		groups = append(groups, configurationGroup{
+			Name:           fmt.Sprintf("Combined (%s)", name),
+			Configurations: buckets[name],
+		})
+	}
+
+	if unkeyed != nil {
+		groups = append(groups, configurationGroup{Name: "Combined", Configurations: unkeyed})
+	}
+
+	return groups
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGroupConfigurations(t *testing.T) {
+	deployment := map[string]interface{}{"kind": "Deployment", "metadata": map[string]interface{}{"namespace": "web"}}
+	service := map[string]interface{}{"kind": "Service", "metadata": map[string]interface{}{"namespace": "api"}}
+	configMap := map[string]interface{}{"kind": "ConfigMap"}
+
+	a := filepath.Join("a", "manifests.yaml")
+	b := filepath.Join("b", "deployment.yaml")
+	configurations := map[string]interface{}{
+		a: []interface{}{deployment, service, configMap},
+		b: deployment,
+	}
+
+	testCases := []struct {
+		name      string
+		combineBy string
+		expected  []configurationGroup
+	}{
+		{
+			name:      "directory",
+			combineBy: "dir",
+			expected: []configurationGroup{
+				{Name: "Combined (a)", Configurations: map[string]interface{}{a: []interface{}{deployment, service, configMap}}},
+				{Name: "Combined (b)", Configurations: map[string]interface{}{b: deployment}},
+			},
+		},
+		{
+			name:      "contents",
+			combineBy: "$.metadata.namespace",
+			expected: []configurationGroup{
+				{Name: "Combined (api)", Configurations: map[string]interface{}{a: []interface{}{service}}},
+				{Name: "Combined (web)", Configurations: map[string]interface{}{a: []interface{}{deployment}, b: deployment}},
+				{Name: "Combined", Configurations: map[string]interface{}{a: []interface{}{configMap}}},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := parseCombineBy(tt.combineBy)
+			if err != nil {
+				t.Fatalf("parse combine by: %v", err)
+			}
+
+			actual := groupConfigurations(configurations, key)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Unexpected groups. expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseCombineByInvalid(t *testing.T) {
+	for _, combineBy := range []string{"namespace", "$.", "$.metadata..namespace", "metadata.namespace"} {
+		if _, err := parseCombineBy(combineBy); err == nil {
+			t.Errorf("expected an error for %q", combineBy)
+		}
+	}
+}
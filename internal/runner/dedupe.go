@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+// The keys that results are deduplicated by.
+const (
+	DedupeMessage = "message"
+	DedupeRule    = "rule"
+)
+
+// dedupeKey identifies the results that are the same across namespaces. The
+// kind of the result is part of the key, so that a failure is never collapsed
+// into a warning with the same message.
+type dedupeKey struct {
+	fileName string
+	kind     string
+	rule     string
+	message  string
+}
+
+// dedupeResults collapses the failures, warnings and exceptions of the same
+// file that have the same message into the first of them, across namespaces.
+// The first result keeps track of all of the namespaces that produced it.
+// When dedupeBy is DedupeRule, the results must also be of the same rule.
+func dedupeResults(results []output.CheckResult, dedupeBy string) ([]output.CheckResult, error) {
+	if dedupeBy != DedupeMessage && dedupeBy != DedupeRule {
+		return nil, fmt.Errorf("unknown dedupe key %q, valid keys are: %v", dedupeBy, []string{DedupeMessage, DedupeRule})
+	}
+
+	newKey := func(checkResult output.CheckResult, kind string, result output.Result) dedupeKey {
+		key := dedupeKey{fileName: checkResult.FileName, kind: kind, message: result.Message}
+		if dedupeBy == DedupeRule {
+			key.rule = result.Rule
+		}
+
+		return key
+	}
+
+	namespaces := make(map[dedupeKey][]string)
+	for _, checkResult := range results {
+		for kind, collection := range resultKinds(checkResult) {
+			for _, result := range collection {
+				key := newKey(checkResult, kind, result)
+				if !contains(namespaces[key], checkResult.Namespace) {
+					namespaces[key] = append(namespaces[key], checkResult.Namespace)
+				}
+			}
+		}
+	}
+
+	kept := make(map[dedupeKey]bool)
+	dedupe := func(checkResult output.CheckResult, kind string, collection []output.Result) []output.Result {
+		var deduped []output.Result
+		for _, result := range collection {
+			key := newKey(checkResult, kind, result)
+			if kept[key] {
+				continue
+			}
+			kept[key] = true
+
+			if len(namespaces[key]) > 1 {
+				result.Namespaces = namespaces[key]
+			}
+
+			deduped = append(deduped, result)
+		}
+
+		return deduped
+	}
+
+	deduped := make([]output.CheckResult, len(results))
+	for i, checkResult := range results {
+		deduped[i] = checkResult
+		deduped[i].Failures = dedupe(checkResult, "failure", checkResult.Failures)
+		deduped[i].Warnings = dedupe(checkResult, "warning", checkResult.Warnings)
+		deduped[i].Exceptions = dedupe(checkResult, "exception", checkResult.Exceptions)
+	}
+
+	return deduped, nil
+}
+
+func resultKinds(checkResult output.CheckResult) map[string][]output.Result {
+	return map[string][]output.Result{
+		"failure":   checkResult.Failures,
+		"warning":   checkResult.Warnings,
+		"exception": checkResult.Exceptions,
+	}
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+func TestDedupeResults(t *testing.T) {
+	results := []output.CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []output.Result{
+				{Message: "containers must not run as root", Rule: "deny"},
+				{Message: "image must be pinned", Rule: "deny"},
+			},
+		},
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "security",
+			Failures:  []output.Result{{Message: "containers must not run as root", Rule: "deny_root"}},
+			Warnings:  []output.Result{{Message: "image must be pinned", Rule: "warn"}},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "security",
+			Failures:  []output.Result{{Message: "containers must not run as root", Rule: "deny"}},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		dedupeBy string
+		expected []output.CheckResult
+	}{
+		{
+			name:     "by message",
+			dedupeBy: DedupeMessage,
+			expected: []output.CheckResult{
+				{
+					FileName:  "deployment.yaml",
+					Namespace: "main",
+					Failures: []output.Result{
+						{Message: "containers must not run as root", Rule: "deny", Namespaces: []string{"main", "security"}},
+						{Message: "image must be pinned", Rule: "deny"},
+					},
+				},
+				{
+					FileName:  "deployment.yaml",
+					Namespace: "security",
+					Warnings:  []output.Result{{Message: "image must be pinned", Rule: "warn"}},
+				},
+				{
+					FileName:  "service.yaml",
+					Namespace: "security",
+					Failures:  []output.Result{{Message: "containers must not run as root", Rule: "deny"}},
+				},
+			},
+		},
+		{
+			name:     "by rule",
+			dedupeBy: DedupeRule,
+			expected: results,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			deduped, err := dedupeResults(results, testCase.dedupeBy)
+			if err != nil {
+				t.Fatalf("dedupe results: %v", err)
+			}
+
+			if !reflect.DeepEqual(deduped, testCase.expected) {
+				t.Errorf("unexpected results. expected %v, got %v", testCase.expected, deduped)
+			}
+		})
+	}
+
+	if _, err := dedupeResults(results, "file"); err == nil {
+		t.Error("expected an error for an unknown dedupe key")
+	}
+}
@@ -0,0 +1,28 @@
+package runner
+
+import (
+	"strings"
+)
+
+// envInputName is the name of the input that contains the environment
+// variables, which is tested the same as a file with that name.
+const envInputName = "env"
+
+// environmentInput returns the input of the given environment variables, in
+// the form of key=value as returned by os.Environ, which is an object of the
+// names of the variables to their values.
+func environmentInput(environ []string) map[string]interface{} {
+	input := make(map[string]interface{})
+	for _, variable := range environ {
+		// On Windows, variables whose names start with = hold the working
+		// directories of the drives, and are not environment variables.
+		keyValue := strings.SplitN(variable, "=", 2)
+		if len(keyValue) != 2 || keyValue[0] == "" {
+			continue
+		}
+
+		input[keyValue[0]] = keyValue[1]
+	}
+
+	return input
+}
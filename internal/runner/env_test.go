@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEnvironmentInput(t *testing.T) {
+	environ := []string{"HOME=/root", "EMPTY=", "URL=https://example.com/?a=b", "=C:=C:\\"}
+
+	expected := map[string]interface{}{
+		"HOME":  "/root",
+		"EMPTY": "",
+		"URL":   "https://example.com/?a=b",
+	}
+	if actual := environmentInput(environ); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected input. expected %v, got %v", expected, actual)
+	}
+}
+
+func TestRunEnv(t *testing.T) {
+	ctx := context.Background()
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	policy := "package main\ndeny[msg] { input.CONFTEST_TEST_PASSWORD; msg := \"password in env\" }\n"
+	if err := ioutil.WriteFile(filepath.Join(directory, "policy.rego"), []byte(policy), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	if err := os.Setenv("CONFTEST_TEST_PASSWORD", "secret"); err != nil {
+		t.Fatalf("set env: %v", err)
+	}
+	defer os.Unsetenv("CONFTEST_TEST_PASSWORD")
+
+	runner := TestRunner{Policy: []string{directory}, Namespace: []string{"main"}, Env: true}
+	results, err := runner.Run(ctx, nil)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(results) != 1 || results[0].FileName != envInputName || len(results[0].Failures) != 1 {
+		t.Errorf("expected the environment to be tested, got %v", results)
+	}
+}
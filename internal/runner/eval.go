@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/policy"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// EvalRunner is the runner for the Eval command, evaluating an
+// arbitrary Rego query against the policies, data and configurations.
+type EvalRunner struct {
+	Policy    []string
+	Data      []string
+	Ignore    string
+	Parser    string
+	ParserMap []string `mapstructure:"parser-map"`
+	Combine   bool
+}
+
+// EvalResult is the result set of the query for a configuration file.
+type EvalResult struct {
+	FileName string         `json:"filename,omitempty"`
+	Result   rego.ResultSet `json:"result"`
+}
+
+// Run evaluates the given query against each of the configuration files
+// in the given list, or against the combined configurations when Combine is
+// set. When no files are given, the query is evaluated once without an input.
+func (r *EvalRunner) Run(ctx context.Context, query string, fileList []string) ([]EvalResult, error) {
+	engine, err := policy.LoadWithData(ctx, r.Policy, r.Data)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	if len(fileList) == 0 {
+		resultSet, err := engine.Eval(ctx, nil, query)
+		if err != nil {
+			return nil, fmt.Errorf("eval: %w", err)
+		}
+
+		return []EvalResult{{Result: resultSet}}, nil
+	}
+
+	parserMap, err := parser.ParseParserMap(r.ParserMap)
+	if err != nil {
+		return nil, fmt.Errorf("parse parser map: %w", err)
+	}
+
+	files, err := parseFileList(fileList, r.Ignore, nil, parser.Options{ParserMap: parserMap})
+	if err != nil {
+		return nil, fmt.Errorf("parse files: %w", err)
+	}
+
+	options := parser.Options{
+		Parser:    r.Parser,
+		ParserMap: parserMap,
+	}
+
+	configurations, err := parser.ParseConfigurationsWithOptions(files, options)
+	if err != nil {
+		return nil, fmt.Errorf("get configurations: %w", err)
+	}
+
+	if r.Combine {
+		configurations = parser.CombineConfigurations(configurations)
+	}
+
+	var fileNames []string
+	for fileName := range configurations {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	var results []EvalResult
+	for _, fileName := range fileNames {
+		resultSet, err := engine.Eval(ctx, configurations[fileName], query)
+		if err != nil {
+			return nil, fmt.Errorf("eval %s: %w", fileName, err)
+		}
+
+		results = append(results, EvalResult{FileName: fileName, Result: resultSet})
+	}
+
+	return results, nil
+}
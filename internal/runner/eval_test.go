@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	ctx := context.Background()
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	files := map[string]string{
+		"policy/kubernetes.rego": "package kubernetes\nis_deployment { input.kind == \"Deployment\" }\n",
+		"data/services.yaml":     "services:\n  ports:\n  - 22\n",
+		"deployment.yaml":        "kind: Deployment\n",
+		"service.yaml":           "kind: Service\n",
+	}
+	for path, contents := range files {
+		path = filepath.Join(directory, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	runner := EvalRunner{
+		Policy: []string{filepath.Join(directory, "policy")},
+		Data:   []string{filepath.Join(directory, "data")},
+	}
+
+	configs := []string{filepath.Join(directory, "service.yaml"), filepath.Join(directory, "deployment.yaml")}
+	results, err := runner.Run(ctx, "data.kubernetes.is_deployment", configs)
+	if err != nil {
+		t.Fatalf("run eval: %v", err)
+	}
+
+	// The results are sorted by file name, and an undefined query has an empty result set.
+	if len(results) != 2 || results[0].FileName != configs[1] || results[1].FileName != configs[0] {
+		t.Fatalf("unexpected results: %v", results)
+	}
+
+	if len(results[0].Result) != 1 || results[0].Result[0].Expressions[0].Value != true {
+		t.Errorf("expected the deployment to be a deployment, got %v", results[0].Result)
+	}
+
+	if len(results[1].Result) != 0 {
+		t.Errorf("expected the query to be undefined for the service, got %v", results[1].Result)
+	}
+
+	results, err = runner.Run(ctx, "data.services.ports[0]", nil)
+	if err != nil {
+		t.Fatalf("run eval without input: %v", err)
+	}
+
+	if len(results) != 1 || len(results[0].Result) != 1 || results[0].Result[0].Expressions[0].Value.(json.Number).String() != "22" {
+		t.Errorf("expected the data to be evaluated, got %v", results)
+	}
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v2"
+	"github.com/open-policy-agent/conftest/parser"
+)
+
+// isGlob returns true if the given file should be expanded as a glob pattern.
+// Files that exist are always used as is, even if their names contain glob
+// metacharacters.
+func isGlob(file string) bool {
+	if !strings.ContainsAny(file, "*?[{") {
+		return false
+	}
+
+	_, err := os.Stat(file)
+	return os.IsNotExist(err)
+}
+
+// expandGlob returns the files that match the given glob pattern, in lexical
+// order. In addition to the standard glob syntax, ** matches any number of
+// directories. The files are found by walking the directory that the pattern
+// is based in, so the same files are skipped as when the directory is given,
+// such as the files that cannot be parsed and the ignored files, and the
+// directories that match the pattern are not walked again. An error is
+// returned when the pattern does not match any of the files.
+func expandGlob(pattern string, ignoreRegex string, ignoreDirs []string, options parser.Options) ([]string, error) {
+	pattern = filepath.Clean(pattern)
+	if _, err := doublestar.PathMatch(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+
+	files, err := getFilesFromDirectory(globBase(pattern), ignoreRegex, ignoreDirs, options)
+	if err != nil {
+		return nil, fmt.Errorf("get files from directory: %w", err)
+	}
+
+	var matches []string
+	for _, file := range files {
+		if matched, _ := doublestar.PathMatch(pattern, file); matched {
+			matches = append(matches, file)
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match the pattern %q", pattern)
+	}
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+// globBase returns the leading directories of the given glob pattern that
+// do not contain any glob metacharacters, which is the directory that
+// contains every path matching the pattern.
+func globBase(pattern string) string {
+	if !isGlob(pattern) {
+		return pattern
+	}
+
+	var base []string
+	for _, element := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if strings.ContainsAny(element, "*?[{") {
+			break
+		}
+
+		base = append(base, element)
+	}
+
+	if len(base) == 0 {
+		return "."
+	}
+
+	if len(base) == 1 && base[0] == "" {
+		return "/"
+	}
+
+	return filepath.FromSlash(strings.Join(base, "/"))
+}
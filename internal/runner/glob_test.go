@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/parser"
+)
+
+func TestParseFileListWithGlobs(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftestglob")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	for _, path := range []string{"a.yaml", "b.json", "nested/c.yaml", "nested/deep/d.yaml", "[literal].yaml"} {
+		path = filepath.Join(directory, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(""), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	t.Run("expands double star patterns", func(t *testing.T) {
+		actual, err := parseFileList([]string{filepath.Join(directory, "**", "*.yaml")}, "", nil, parser.Options{})
+		if err != nil {
+			t.Fatalf("parse file list: %v", err)
+		}
+
+		expected := []string{
+			filepath.Join(directory, "[literal].yaml"),
+			filepath.Join(directory, "a.yaml"),
+			filepath.Join(directory, "nested", "c.yaml"),
+			filepath.Join(directory, "nested", "deep", "d.yaml"),
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Unexpected files. expected %v actual %v", expected, actual)
+		}
+	})
+
+	t.Run("keeps literal paths that contain metacharacters", func(t *testing.T) {
+		literal := filepath.Join(directory, "[literal].yaml")
+		actual, err := parseFileList([]string{literal}, "", nil, parser.Options{})
+		if err != nil {
+			t.Fatalf("parse file list: %v", err)
+		}
+
+		if !reflect.DeepEqual([]string{literal}, actual) {
+			t.Errorf("Unexpected files. expected %v actual %v", []string{literal}, actual)
+		}
+	})
+
+	t.Run("keeps urls as they are", func(t *testing.T) {
+		url := "https://example.com/manifests/*.yaml?ref=main"
+		actual, err := parseFileList([]string{url}, "", nil, parser.Options{})
+		if err != nil {
+			t.Fatalf("parse file list: %v", err)
+		}
+
+		if !reflect.DeepEqual([]string{url}, actual) {
+			t.Errorf("Unexpected files. expected %v actual %v", []string{url}, actual)
+		}
+	})
+
+	t.Run("errors when a pattern does not match", func(t *testing.T) {
+		pattern := filepath.Join(directory, "**", "*.toml")
+		_, err := parseFileList([]string{pattern}, "", nil, parser.Options{})
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+
+		if !strings.Contains(err.Error(), pattern) {
+			t.Errorf("Expected error to name the pattern %q, got %v", pattern, err)
+		}
+	})
+}
+
+func TestParseFileListWithGlobsFiltersFiles(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftestglob")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	files := map[string]string{
+		"deployment.yaml":       "",
+		"policy/deny.rego":      "package main",
+		"README.md":             "# manifests",
+		"ignored.yaml":          "",
+		"testdata/service.yaml": "",
+		"nested/service.yaml":   "",
+		IgnoreFileName:          "ignored.yaml\n",
+	}
+	for path, contents := range files {
+		path = filepath.Join(directory, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	fileList := []string{
+		filepath.Join(directory, "**"),
+		filepath.Join(directory, "*.yaml"),
+		filepath.Join(directory, "nested"),
+	}
+	actual, err := parseFileList(fileList, "", []string{"testdata"}, parser.Options{NoSniff: true})
+	if err != nil {
+		t.Fatalf("parse file list: %v", err)
+	}
+
+	// The files that cannot be parsed, the ignored files and directories,
+	// and the directories themselves are not matched, and the files that
+	// are matched more than once are only listed once.
+	expected := []string{
+		filepath.Join(directory, "deployment.yaml"),
+		filepath.Join(directory, "nested", "service.yaml"),
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected files. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestGlobBase(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{pattern: "**/*.yaml", expected: "."},
+		{pattern: "deploy/**/*.yaml", expected: "deploy"},
+		{pattern: "deploy/nested/*.yaml", expected: filepath.Join("deploy", "nested")},
+		{pattern: "deploy/config.yaml", expected: "deploy/config.yaml"},
+	}
+
+	for _, tt := range tests {
+		if actual := globBase(tt.pattern); actual != tt.expected {
+			t.Errorf("globBase(%q) = %q, expected %q", tt.pattern, actual, tt.expected)
+		}
+	}
+}
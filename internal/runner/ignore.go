@@ -0,0 +1,207 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the name of the file that contains the patterns, written
+// using gitignore syntax, of the paths to skip when walking a directory.
+const IgnoreFileName = ".conftestignore"
+
+// ignorePattern is a single pattern found in an ignore file.
+type ignorePattern struct {
+	regexp   *regexp.Regexp
+	negate   bool
+	dirsOnly bool
+}
+
+// ignoreRules holds the patterns of all of the ignore files found while walking
+// a directory, keyed by the directory that contains the ignore file. Patterns
+// only apply to the subtree of the directory that they were found in.
+type ignoreRules struct {
+	root     string
+	patterns map[string][]ignorePattern
+}
+
+func newIgnoreRules(root string) *ignoreRules {
+	return &ignoreRules{
+		root:     filepath.Clean(root),
+		patterns: make(map[string][]ignorePattern),
+	}
+}
+
+// load reads the ignore file in the given directory, if one exists.
+func (r *ignoreRules) load(directory string) error {
+	contents, err := ioutil.ReadFile(filepath.Join(directory, IgnoreFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read ignore file: %w", err)
+	}
+
+	patterns, err := parseIgnorePatterns(contents)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", filepath.Join(directory, IgnoreFileName), err)
+	}
+
+	r.patterns[filepath.Clean(directory)] = patterns
+	return nil
+}
+
+// matches returns true if the given path is ignored by any of the ignore
+// files that have been loaded. Ignore files that are closer to the path take
+// precedence, and within a single file the last matching pattern wins.
+func (r *ignoreRules) matches(path string, isDir bool) bool {
+	path = filepath.Clean(path)
+
+	var directories []string
+	for directory := filepath.Dir(path); ; directory = filepath.Dir(directory) {
+		directories = append([]string{directory}, directories...)
+		if directory == r.root || directory == filepath.Dir(directory) {
+			break
+		}
+	}
+
+	var ignored bool
+	for _, directory := range directories {
+		relativePath, err := filepath.Rel(directory, path)
+		if err != nil {
+			continue
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		for _, pattern := range r.patterns[directory] {
+			if pattern.dirsOnly && !isDir {
+				continue
+			}
+
+			if pattern.regexp.MatchString(relativePath) {
+				ignored = !pattern.negate
+			}
+		}
+	}
+
+	return ignored
+}
+
+func parseIgnorePatterns(contents []byte) ([]ignorePattern, error) {
+	var patterns []ignorePattern
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasSuffix(line, "\\ ") {
+			line = strings.TrimRight(strings.TrimSuffix(line, "\\ "), " ") + " "
+		} else {
+			line = strings.TrimRight(line, " ")
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var pattern ignorePattern
+		if strings.HasPrefix(line, "!") {
+			pattern.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			pattern.dirsOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		if line == "" {
+			continue
+		}
+
+		// A pattern that contains a separator is relative to the directory of
+		// the ignore file. Otherwise the pattern can match at any depth.
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		expression := globToRegexp(line)
+		if anchored {
+			expression = "^" + expression + "$"
+		} else {
+			expression = "^(?:.*/)?" + expression + "$"
+		}
+
+		compiled, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", scanner.Text(), err)
+		}
+		pattern.regexp = compiled
+
+		patterns = append(patterns, pattern)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// globToRegexp converts a gitignore style glob into a regular expression.
+func globToRegexp(glob string) string {
+	var expression strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				atStart := i == 0 || glob[i-1] == '/'
+				atEnd := i+2 == len(glob)
+				if atStart && atEnd {
+					expression.WriteString(".*")
+					i++
+					continue
+				}
+
+				if atStart && glob[i+2] == '/' {
+					expression.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+
+			expression.WriteString("[^/]*")
+		case '?':
+			expression.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expression.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			expression.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+
+			expression.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return expression.String()
+}
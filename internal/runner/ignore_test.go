@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/parser"
+)
+
+func TestIgnorePatterns(t *testing.T) {
+	contents := []byte(`# comment
+*.json
+!keep.json
+build/
+/root.yaml
+docs/**/*.md
+`)
+
+	patterns, err := parseIgnorePatterns(contents)
+	if err != nil {
+		t.Fatalf("parse patterns: %v", err)
+	}
+
+	rules := newIgnoreRules("project")
+	rules.patterns["project"] = patterns
+
+	testCases := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"project/a.json", false, true},
+		{"project/nested/a.json", false, true},
+		{"project/keep.json", false, false},
+		{"project/build", true, true},
+		{"project/build", false, false},
+		{"project/root.yaml", false, true},
+		{"project/nested/root.yaml", false, false},
+		{"project/docs/a/b/c.md", false, true},
+		{"project/docs/c.md", false, true},
+		{"project/c.md", false, false},
+		{"project/a.yaml", false, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.path, func(t *testing.T) {
+			actual := rules.matches(testCase.path, testCase.isDir)
+			if actual != testCase.expected {
+				t.Errorf("Unexpected match for %v. expected %v actual %v", testCase.path, testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGetFilesFromDirectoryWithIgnoreFiles(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftestignore")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	files := map[string]string{
+		IgnoreFileName:                          "*.json\nvendor/\n",
+		"a.yaml":                                "",
+		"a.json":                                "",
+		"vendor/b.yaml":                         "",
+		"nested/b.yaml":                         "",
+		"nested/keep.json":                      "",
+		filepath.Join("nested", IgnoreFileName): "!keep.json\nb.yaml\n",
+	}
+
+	for path, contents := range files {
+		path = filepath.Join(directory, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	actual, err := getFilesFromDirectory(directory, "", nil, parser.Options{})
+	if err != nil {
+		t.Fatalf("get files: %v", err)
+	}
+	sort.Strings(actual)
+
+	expected := []string{
+		filepath.Join(directory, "a.yaml"),
+		filepath.Join(directory, "nested", "keep.json"),
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected files. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestGetFilesFromDirectoryWithIgnoredDirectories(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftestignore")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	for _, path := range []string{"a.yaml", "testdata/b.yaml", "nested/testdata/c.yaml", "nested/d.yaml", "e_test/f.yaml", "testdata.yaml"} {
+		path = filepath.Join(directory, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(""), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	actual, err := getFilesFromDirectory(directory, "d.yaml", []string{"testdata", "*_test"}, parser.Options{})
+	if err != nil {
+		t.Fatalf("get files: %v", err)
+	}
+	sort.Strings(actual)
+
+	expected := []string{
+		filepath.Join(directory, "a.yaml"),
+		filepath.Join(directory, "testdata.yaml"),
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected files. expected %v actual %v", expected, actual)
+	}
+
+	// The directory that is given is not skipped, even when its name matches.
+	actual, err = getFilesFromDirectory(filepath.Join(directory, "testdata"), "", []string{"testdata"}, parser.Options{})
+	if err != nil {
+		t.Fatalf("get files: %v", err)
+	}
+
+	if expected := []string{filepath.Join(directory, "testdata", "b.yaml")}; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected files. expected %v actual %v", expected, actual)
+	}
+
+	if _, err := parseFileList([]string{directory}, "", []string{"[testdata"}, parser.Options{}); err == nil {
+		t.Error("expected an error for an invalid pattern of a directory")
+	}
+}
@@ -0,0 +1,128 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser"
+)
+
+// fileMetadataKey is the key of the input that contains the metadata of the
+// file that the input was parsed from, when the metadata is enabled.
+const fileMetadataKey = "__file__"
+
+// inputMetadataKey is the key of the input that contains the metadata that is
+// given when running the test, such as the environment that is tested.
+const inputMetadataKey = "__meta__"
+
+// fileMetadata returns the metadata of the file at the given path, where the
+// path is relative to the working directory when the file is within it.
+func fileMetadata(path string) map[string]interface{} {
+	relativePath := path
+	if workingDirectory, err := os.Getwd(); err == nil {
+		if absolutePath, err := filepath.Abs(path); err == nil {
+			if relative, err := filepath.Rel(workingDirectory, absolutePath); err == nil && !strings.HasPrefix(relative, "..") {
+				relativePath = relative
+			}
+		}
+	}
+
+	relativePath = filepath.ToSlash(relativePath)
+	return map[string]interface{}{
+		"path":      relativePath,
+		"name":      filepath.Base(relativePath),
+		"directory": filepath.ToSlash(filepath.Dir(relativePath)),
+		"extension": strings.TrimPrefix(filepath.Ext(relativePath), "."),
+	}
+}
+
+// addFileMetadata adds the metadata of the files to the configurations that
+// were parsed from them. When a file contains several documents, the metadata
+// is added to each of the documents. Documents that are not objects, and
+// configurations that were read from stdin or fetched from a URL, are left
+// as is.
+func addFileMetadata(configurations map[string]interface{}) {
+	for path, configuration := range configurations {
+		if path == "-" || parser.IsURL(path) {
+			continue
+		}
+
+		addToDocuments(configuration, fileMetadataKey, fileMetadata(path))
+	}
+}
+
+// parseInputMetadata parses the given metadata, in the form of key=value
+// (e.g. environment=production), into a map of the keys to their values.
+func parseInputMetadata(pairs []string) (map[string]interface{}, error) {
+	metadata := make(map[string]interface{})
+	for _, pair := range pairs {
+		keyValue := strings.SplitN(pair, "=", 2)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+			return nil, fmt.Errorf("input metadata %q must be in the form of key=value", pair)
+		}
+
+		metadata[strings.TrimSpace(keyValue[0])] = keyValue[1]
+	}
+
+	return metadata, nil
+}
+
+// addInputMetadata adds the given metadata to each of the configurations, even
+// those that were read from stdin or fetched from a URL. The same as with the
+// metadata of the files, the metadata is added to each document of a file
+// that contains several documents, and documents that are not objects are
+// left as is.
+func addInputMetadata(configurations map[string]interface{}, metadata map[string]interface{}) {
+	for _, configuration := range configurations {
+		addToDocuments(configuration, inputMetadataKey, metadata)
+	}
+}
+
+// addToDocuments sets the given key of the documents of the configuration to
+// the given value, when the documents are objects.
+func addToDocuments(configuration interface{}, key string, value interface{}) {
+	switch configuration := configuration.(type) {
+	case map[string]interface{}:
+		configuration[key] = value
+	case []interface{}:
+		for _, document := range configuration {
+			if document, ok := document.(map[string]interface{}); ok {
+				document[key] = value
+			}
+		}
+	}
+}
+
+// combinedMetadata returns the metadata that is added once to the combined
+// input of the given configurations, which is the list of the paths of the
+// files when filePaths is true, and the given input metadata. When there
+// is no metadata, nil is returned, so that the combined input is left as is.
+func combinedMetadata(configurations map[string]interface{}, filePaths bool, inputMetadata map[string]interface{}) map[string]interface{} {
+	metadata := make(map[string]interface{})
+	if filePaths {
+		paths := []string{}
+		for path := range configurations {
+			if path == "-" || path == envInputName || parser.IsURL(path) {
+				continue
+			}
+
+			paths = append(paths, fileMetadata(path)["path"].(string))
+		}
+		sort.Strings(paths)
+
+		metadata[fileMetadataKey] = paths
+	}
+
+	if inputMetadata != nil {
+		metadata[inputMetadataKey] = inputMetadata
+	}
+
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	return metadata
+}
@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAddFileMetadata(t *testing.T) {
+	configurations := map[string]interface{}{
+		filepath.Join("k8s", "deployment.yaml"): map[string]interface{}{"kind": "Deployment"},
+		filepath.Join("k8s", "services.yaml"): []interface{}{
+			map[string]interface{}{"kind": "Service"},
+			"not an object",
+		},
+		"-": map[string]interface{}{"kind": "Pod"},
+	}
+
+	addFileMetadata(configurations)
+
+	expected := map[string]interface{}{
+		filepath.Join("k8s", "deployment.yaml"): map[string]interface{}{
+			"kind": "Deployment",
+			"__file__": map[string]interface{}{
+				"path":      "k8s/deployment.yaml",
+				"name":      "deployment.yaml",
+				"directory": "k8s",
+				"extension": "yaml",
+			},
+		},
+		filepath.Join("k8s", "services.yaml"): []interface{}{
+			map[string]interface{}{
+				"kind": "Service",
+				"__file__": map[string]interface{}{
+					"path":      "k8s/services.yaml",
+					"name":      "services.yaml",
+					"directory": "k8s",
+					"extension": "yaml",
+				},
+			},
+			"not an object",
+		},
+		"-": map[string]interface{}{"kind": "Pod"},
+	}
+
+	if !reflect.DeepEqual(expected, configurations) {
+		t.Errorf("Unexpected configurations. expected %v actual %v", expected, configurations)
+	}
+}
+
+func TestFileMetadataRelativePath(t *testing.T) {
+	absolutePath, err := filepath.Abs(filepath.Join("testdata", "config.yaml"))
+	if err != nil {
+		t.Fatalf("get absolute path: %v", err)
+	}
+
+	metadata := fileMetadata(absolutePath)
+	if metadata["path"] != "testdata/config.yaml" {
+		t.Errorf("expected the path to be relative to the working directory, got %v", metadata["path"])
+	}
+}
+
+func TestAddInputMetadata(t *testing.T) {
+	metadata, err := parseInputMetadata([]string{"environment=production", "region=eu=west"})
+	if err != nil {
+		t.Fatalf("parse input metadata: %v", err)
+	}
+
+	configurations := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+		"services.yaml": []interface{}{
+			map[string]interface{}{"kind": "Service"},
+			"not an object",
+		},
+		"-": map[string]interface{}{"kind": "Pod"},
+	}
+
+	addInputMetadata(configurations, metadata)
+
+	expectedMetadata := map[string]interface{}{"environment": "production", "region": "eu=west"}
+	expected := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment", "__meta__": expectedMetadata},
+		"services.yaml": []interface{}{
+			map[string]interface{}{"kind": "Service", "__meta__": expectedMetadata},
+			"not an object",
+		},
+		"-": map[string]interface{}{"kind": "Pod", "__meta__": expectedMetadata},
+	}
+
+	if !reflect.DeepEqual(expected, configurations) {
+		t.Errorf("Unexpected configurations. expected %v actual %v", expected, configurations)
+	}
+
+	if _, err := parseInputMetadata([]string{"environment"}); err == nil {
+		t.Error("expected an error for metadata without a value")
+	}
+}
+
+func TestCombinedMetadata(t *testing.T) {
+	configurations := map[string]interface{}{
+		filepath.Join("k8s", "services.yaml"):   []interface{}{map[string]interface{}{"kind": "Service"}},
+		filepath.Join("k8s", "deployment.yaml"): map[string]interface{}{"kind": "Deployment"},
+		"-":                                     map[string]interface{}{"kind": "Pod"},
+		envInputName:                            map[string]interface{}{"HOME": "/root"},
+	}
+
+	if metadata := combinedMetadata(configurations, false, nil); metadata != nil {
+		t.Errorf("expected no metadata, got %v", metadata)
+	}
+
+	inputMetadata := map[string]interface{}{"environment": "production"}
+	expected := map[string]interface{}{
+		"__file__": []string{"k8s/deployment.yaml", "k8s/services.yaml"},
+		"__meta__": inputMetadata,
+	}
+
+	metadata := combinedMetadata(configurations, true, inputMetadata)
+	if !reflect.DeepEqual(expected, metadata) {
+		t.Errorf("Unexpected metadata. expected %v actual %v", expected, metadata)
+	}
+}
@@ -0,0 +1,128 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// progressInterval is the minimum interval between the updates of the
+// progress, so that checking many small files does not flood the terminal.
+const progressInterval = 100 * time.Millisecond
+
+// progress reports the number of files that have been parsed and checked on
+// a single line of a terminal, which is updated in place and cleared once the
+// files have been checked. All of the methods of a nil progress do nothing,
+// so that the progress only needs to be created when it is reported.
+type progress struct {
+	writer io.Writer
+
+	mu      sync.Mutex
+	parsed  int
+	checked int
+	total   int
+	drawn   time.Time
+}
+
+// newProgress returns the progress of the TestRunner, which is written to
+// stderr when stderr is a terminal, unless the progress is disabled. When the
+// progress is not reported, the returned progress is nil.
+func (t *TestRunner) newProgress() *progress {
+	if t.NoProgress || !isTerminal(os.Stderr) {
+		return nil
+	}
+
+	return &progress{writer: os.Stderr}
+}
+
+// isTerminal reports whether the given file is a terminal, rather than a
+// file or a pipe that the output is redirected to.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// parsing reports that the given number of files are being parsed.
+func (p *progress) parsing(files int) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintf(p.writer, "\r\033[KParsing %d files", files)
+	p.drawn = time.Now()
+}
+
+// checking reports that the given number of files were parsed, and that the
+// given number of checks, one for each file and namespace, are to be done.
+func (p *progress) checking(parsed int, total int) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.parsed = parsed
+	p.total = total
+	p.draw(true)
+}
+
+// check reports that one of the checks is done.
+func (p *progress) check() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.checked++
+	p.draw(p.checked == p.total)
+}
+
+// clear removes the progress from the terminal, so that the results that are
+// written afterwards are not mixed with it.
+func (p *progress) clear() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprint(p.writer, "\r\033[K")
+}
+
+func (p *progress) draw(force bool) {
+	if !force && time.Since(p.drawn) < progressInterval {
+		return
+	}
+
+	fmt.Fprintf(p.writer, "\r\033[KParsed %d files, checked %d/%d", p.parsed, p.checked, p.total)
+	p.drawn = time.Now()
+}
+
+type progressKey struct{}
+
+// withProgress returns a context that carries the given progress, which is
+// reported by the checks that are done with the context.
+func withProgress(ctx context.Context, p *progress) context.Context {
+	if p == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// progressFromContext returns the progress of the given context, or nil when
+// the context does not carry a progress.
+func progressFromContext(ctx context.Context) *progress {
+	p, _ := ctx.Value(progressKey{}).(*progress)
+	return p
+}
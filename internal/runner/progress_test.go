@@ -0,0 +1,34 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	buf := new(bytes.Buffer)
+	p := &progress{writer: buf}
+
+	ctx := withProgress(context.Background(), p)
+	p.parsing(2)
+	p.checking(2, 4)
+	for i := 0; i < 4; i++ {
+		progressFromContext(ctx).check()
+	}
+	p.clear()
+
+	lines := strings.Split(buf.String(), "\r\033[K")
+	expected := []string{"", "Parsing 2 files", "Parsed 2 files, checked 0/4", "Parsed 2 files, checked 4/4", ""}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("Unexpected progress. expected %q, got %q", expected, lines)
+	}
+
+	// A nil progress, which is not reported, does nothing.
+	var disabled *progress
+	ctx = withProgress(context.Background(), disabled)
+	disabled.parsing(1)
+	progressFromContext(ctx).check()
+	disabled.clear()
+}
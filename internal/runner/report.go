@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+// ReportRunner is the runner for the Report command, reporting the
+// results of previous runs that were written in the JSON format.
+type ReportRunner struct {
+	Output     string
+	NoColor    bool `mapstructure:"no-color"`
+	FailOnWarn bool `mapstructure:"fail-on-warn"`
+
+	// Merge merges the results of the same file and namespace across all
+	// of the given files into one, e.g. when a file was tested in more
+	// than one step. Otherwise, the results of each file are reported
+	// as they are.
+	Merge bool
+}
+
+// Run reads the results of the given files.
+func (r *ReportRunner) Run(files []string) ([]output.CheckResult, error) {
+	var results []output.CheckResult
+	for _, file := range files {
+		fileResults, err := readResults(file)
+		if err != nil {
+			return nil, fmt.Errorf("read results of %s: %w", file, err)
+		}
+
+		results = append(results, fileResults...)
+	}
+
+	if r.Merge {
+		results = output.MergeResults(results)
+	}
+
+	return results, nil
+}
+
+func readResults(file string) ([]output.CheckResult, error) {
+	if file == "-" {
+		return output.ReadJSON(os.Stdin)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer f.Close()
+
+	return output.ReadJSON(f)
+}
@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser"
+)
+
+// changedFiles returns the absolute paths of the files that have changed
+// since the given git revision, or in the given revision range, e.g.
+// origin/main...HEAD. Deleted files are not included, and renamed files
+// are included by their new path.
+func changedFiles(since string) (map[string]bool, error) {
+	if since == "" || strings.HasPrefix(since, "-") {
+		return nil, fmt.Errorf("invalid revision %q", since)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=d", "--relative", "-z", since, "--")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git diff %s: %s", since, message)
+		}
+
+		return nil, fmt.Errorf("git diff %s: %w", since, err)
+	}
+
+	changed := make(map[string]bool)
+	for _, name := range strings.Split(stdout.String(), "\x00") {
+		if name == "" {
+			continue
+		}
+
+		path, err := filepath.Abs(name)
+		if err != nil {
+			return nil, fmt.Errorf("get absolute path: %w", err)
+		}
+
+		changed[path] = true
+	}
+
+	return changed, nil
+}
+
+// filterChangedFiles returns the files of the given list that have changed,
+// along with the directories of Helm charts that contain a changed file.
+// Standard input and the configurations at URLs are always included.
+func filterChangedFiles(files []string, changed map[string]bool) ([]string, error) {
+	var changedFiles []string
+	for _, file := range files {
+		if file == "-" || parser.IsURL(file) {
+			changedFiles = append(changedFiles, file)
+			continue
+		}
+
+		path, err := filepath.Abs(file)
+		if err != nil {
+			return nil, fmt.Errorf("get absolute path: %w", err)
+		}
+
+		if changed[path] || containsChangedFile(path, changed) {
+			changedFiles = append(changedFiles, file)
+		}
+	}
+
+	return changedFiles, nil
+}
+
+func containsChangedFile(directory string, changed map[string]bool) bool {
+	for path := range changed {
+		if strings.HasPrefix(path, directory+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
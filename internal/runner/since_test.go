@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	directory, err := ioutil.TempDir("", "conftestsince")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("get working directory: %v", err)
+	}
+	defer os.Chdir(workingDirectory)
+
+	if err := os.Chdir(directory); err != nil {
+		t.Fatalf("change directory: %v", err)
+	}
+
+	git := func(args ...string) {
+		args = append([]string{"-c", "user.name=conftest", "-c", "user.email=conftest@example.com"}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	writeFile := func(name string, contents string) {
+		if err := ioutil.WriteFile(name, []byte(contents), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	git("init", "-q")
+	for _, name := range []string{"changed.yaml", "deleted.yaml", "renamed.yaml", "unchanged.yaml"} {
+		writeFile(name, "name: "+name)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+
+	writeFile("changed.yaml", "name: changed")
+	git("rm", "-q", "deleted.yaml")
+	git("mv", "renamed.yaml", "new.yaml")
+	git("add", "-A")
+	git("commit", "-q", "-m", "change")
+
+	changed, err := changedFiles("HEAD~1...HEAD")
+	if err != nil {
+		t.Fatalf("get changed files: %v", err)
+	}
+
+	files, err := filterChangedFiles([]string{"changed.yaml", "new.yaml", "unchanged.yaml", "-"}, changed)
+	if err != nil {
+		t.Fatalf("filter changed files: %v", err)
+	}
+
+	expected := []string{"changed.yaml", "new.yaml", "-"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("unexpected changed files. expected %v, actual %v", expected, files)
+	}
+
+	if _, err := changedFiles("does-not-exist"); err == nil {
+		t.Error("expected an error for a revision that does not exist")
+	}
+}
+
+func TestFilterChangedFilesOfCharts(t *testing.T) {
+	chart, err := filepath.Abs(filepath.Join("testdata", "chart"))
+	if err != nil {
+		t.Fatalf("get absolute path: %v", err)
+	}
+
+	changed := map[string]bool{filepath.Join(chart, "templates", "deployment.yaml"): true}
+	files, err := filterChangedFiles([]string{chart, chart + "-other"}, changed)
+	if err != nil {
+		t.Fatalf("filter changed files: %v", err)
+	}
+
+	expected := []string{chart}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("unexpected changed files. expected %v, actual %v", expected, files)
+	}
+}
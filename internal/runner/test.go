@@ -2,15 +2,23 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/open-policy-agent/conftest/downloader"
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/parser/helm"
 	"github.com/open-policy-agent/conftest/policy"
+	"golang.org/x/sync/errgroup"
 )
 
 // TestRunner is the runner for the Test command, executing
@@ -19,66 +27,713 @@ type TestRunner struct {
 	Trace         bool
 	Policy        []string
 	Data          []string
+	DataAs        string `mapstructure:"data-as"`
 	Update        []string
+	CosignKey     string `mapstructure:"cosign-key"`
+	GitDepth      int    `mapstructure:"git-depth"`
 	Ignore        string
+	IgnoreDir     []string `mapstructure:"ignore-dir"`
 	Parser        string
+	ParserMap     []string `mapstructure:"parser-map"`
 	Namespace     []string
-	AllNamespaces bool `mapstructure:"all-namespaces"`
-	FailOnWarn    bool `mapstructure:"fail-on-warn"`
-	NoColor       bool `mapstructure:"no-color"`
+	Rules         []string `mapstructure:"rule"`
+	AllNamespaces bool     `mapstructure:"all-namespaces"`
+	FailOnWarn    bool     `mapstructure:"fail-on-warn"`
+	NoFail        bool     `mapstructure:"no-fail"`
+	FailFast      bool     `mapstructure:"fail-fast"`
+	NoColor       bool     `mapstructure:"no-color"`
 	Combine       bool
 	Output        string
+
+	// CombineBy groups the configurations by a key before combining them,
+	// and combines the configurations of each of the groups separately.
+	CombineBy string `mapstructure:"combine-by"`
+
+	// ShowAllRules outputs every rule that was evaluated against each file,
+	// including the rules that passed, in the standard output format.
+	ShowAllRules bool `mapstructure:"show-all-rules"`
+
+	// RewritePrintToOutput outputs the output of the print statements of
+	// the policies inline in the standard output, marked as debug lines.
+	RewritePrintToOutput bool `mapstructure:"rewrite-print-to-output"`
+
+	// ReportPasses reports the rules that passed for each file in the
+	// results, e.g. in the passes of the JSON output.
+	ReportPasses bool `mapstructure:"report-passes"`
+
+	// OutputFile is the path to the file that the results are written to in
+	// the Output format, in which case a summary of the results is printed
+	// to stdout instead, unless NoSummary is set.
+	OutputFile string `mapstructure:"output-file"`
+	NoSummary  bool   `mapstructure:"no-summary"`
+
+	// TraceOutput is the path to the file that the traces of the queries are
+	// written to, which enables tracing without tracing in the output.
+	TraceOutput string `mapstructure:"trace-output"`
+
+	// FileMetadata adds the metadata of each file, such as its path, to the
+	// input under the __file__ key, so that policies can assert on the layout
+	// of the files as well as their contents.
+	FileMetadata bool `mapstructure:"file-metadata"`
+
+	// InputMeta is metadata, in the form of key=value, that is added to the
+	// input under the __meta__ key, so that policies can depend on how they
+	// are run, e.g. on the environment that is tested, without changing the
+	// configurations.
+	InputMeta []string `mapstructure:"input-meta"`
+
+	// Since is a git revision, or a range of revisions such as
+	// origin/main...HEAD, where only the files that have changed since the
+	// revision, or in the range, are tested. Deleted files are skipped.
+	Since string
+
+	// ListFiles lists the files that would be tested, after expanding the
+	// directories and glob patterns and excluding the ignored files, without
+	// parsing the files or evaluating any policies.
+	ListFiles bool `mapstructure:"list-files"`
+
+	// FollowSymlinks follows the symbolic links to directories when
+	// loading the policies.
+	FollowSymlinks bool `mapstructure:"follow-symlinks"`
+
+	// RulePrefixes are the prefixes of additional rules that are evaluated,
+	// in the form of prefix=severity (e.g. critical=critical), whose results
+	// are failures when their severity is at least FailSeverity.
+	RulePrefixes []string `mapstructure:"rule-prefixes"`
+	FailSeverity string   `mapstructure:"fail-severity"`
+
+	// Dedupe collapses the results of the same file that have the same
+	// message across namespaces into one, and is either DedupeMessage, or
+	// DedupeRule to only collapse the results of the same rule. When empty,
+	// the results are not deduplicated.
+	Dedupe string
+
+	// MaxResultsPerFile is the number of failures, and of warnings, of each
+	// file that are output in the standard format. The results themselves
+	// are not limited, so that they still determine the exit code. When
+	// zero, all of the results are output.
+	MaxResultsPerFile int `mapstructure:"max-results-per-file"`
+
+	// OnlyRootNamespaces limits the namespaces that are found with
+	// AllNamespaces to the namespaces of the policies that are in the given
+	// policy directories themselves, excluding their subdirectories, such
+	// as the directories of shared libraries.
+	OnlyRootNamespaces bool `mapstructure:"only-root-namespaces"`
+
+	// NamespaceMap moves the packages of the policies of policy paths under
+	// a namespace, in the form of path=namespace (e.g. bundles/a=team.a), so
+	// that the policies of several paths can use the same packages.
+	NamespaceMap []string `mapstructure:"namespace-map"`
+
+	// ExcludeNamespace are the namespaces that are not evaluated, which are
+	// removed from the given namespaces, or from all of the namespaces.
+	ExcludeNamespace []string `mapstructure:"exclude-namespace"`
+
+	// FailOnWarnNamespace are the namespaces whose warnings count as
+	// failures towards the exit code, while they are still reported as
+	// warnings. A namespace that ends with * matches all of the namespaces
+	// that start with the rest of it.
+	FailOnWarnNamespace []string `mapstructure:"fail-on-warn-namespace"`
+
+	// Helm renders the directories of Helm charts with helm template, using
+	// the values files in HelmValues and the values in HelmSet, in the form
+	// of key=value. The rendered manifests are tested as a file that is
+	// named after the directory of the chart.
+	Helm       bool
+	HelmValues []string `mapstructure:"helm-values"`
+	HelmSet    []string `mapstructure:"helm-set"`
+
+	// NoSniff disables choosing the parser of the files whose parser cannot
+	// be chosen based on their path, such as files without an extension,
+	// based on their contents.
+	NoSniff bool `mapstructure:"no-sniff"`
+
+	// NoProgress disables reporting the progress of the files that are
+	// parsed and checked on stderr, which is reported when stderr is a
+	// terminal.
+	NoProgress bool `mapstructure:"no-progress"`
+
+	// ResolveRefs replaces the $ref references of the configurations with
+	// the values that they refer to.
+	ResolveRefs bool `mapstructure:"resolve-refs"`
+
+	// Env tests the environment variables of the process as an input named
+	// env, in addition to the files.
+	Env bool
+
+	// AllowEmpty allows testing namespaces that do not have any rules,
+	// whose files pass without being checked, and loading no policies
+	// at all. Otherwise, either is an error.
+	AllowEmpty bool `mapstructure:"allow-empty"`
+
+	// Verbose logs the policies that are loaded to stderr.
+	Verbose bool
+
+	// ExpandLists tests the items of Kubernetes Lists as documents of their
+	// own, instead of the Lists that contain them.
+	ExpandLists bool `mapstructure:"expand-lists"`
+
+	// MaxParserErrors is the number of files that fail to be parsed that are
+	// tolerated, which are reported as failures of the files instead. When
+	// zero, the test stops at the first file that fails to be parsed.
+	MaxParserErrors int `mapstructure:"max-parser-errors"`
+
+	// Strict enables the strict mode of the compiler, and reports namespaces
+	// that did not produce any results, e.g. because the names of their rules
+	// are misspelled, as errors.
+	Strict bool
+
+	// RegoVersion is the version of Rego that the policies are written in.
+	RegoVersion string `mapstructure:"rego-version"`
+
+	// Capabilities is the path to an OPA capabilities file that restricts
+	// the builtins that the policies are allowed to use.
+	Capabilities string
+
+	// AbortOnError stops the test at the first runtime error of a policy,
+	// which is otherwise reported as a failure of the file that triggered it.
+	AbortOnError bool `mapstructure:"abort-on-error"`
+
+	// Parallel is the number of files that are evaluated concurrently.
+	// When zero, the number of files is limited by GOMAXPROCS.
+	Parallel int
+
+	// ParallelNamespaces is the number of namespaces that are evaluated
+	// concurrently. When zero, the namespaces are evaluated one at a time.
+	ParallelNamespaces int `mapstructure:"parallel-namespaces"`
+
+	// Timeout is the longest time that the policies of a namespace are
+	// evaluated against a file, or against the combined files, after which
+	// the check is reported as a failure instead. When zero, the checks
+	// are not limited.
+	Timeout time.Duration
+
+	// FailThreshold is the number of failures that are tolerated before
+	// the test is considered to have failed.
+	FailThreshold int `mapstructure:"fail-threshold"`
+
+	// FailOnExceptionRatio is the highest ratio of exceptions to the total
+	// number of tests that is tolerated before the test is considered to
+	// have failed. A large number of exceptions may indicate that policies
+	// are being bypassed.
+	FailOnExceptionRatio float64 `mapstructure:"fail-on-exception-ratio"`
+
+	// Baseline is the path to a file of known failures, which are reported
+	// as exceptions instead of failures. When UpdateBaseline is set, the
+	// baseline is regenerated from the failures of the current run.
+	Baseline       string
+	UpdateBaseline bool `mapstructure:"update-baseline"`
+
+	// BuildArgs are the build arguments, in the form of KEY=VALUE, that are
+	// used to resolve the ARG commands of Dockerfiles.
+	BuildArgs        []string `mapstructure:"build-arg"`
+	DockerfileStages bool     `mapstructure:"dockerfile-stages"`
+
+	// ProtoDescriptorSet is the path to the compiled FileDescriptorSet that
+	// contains the type of the protobuf messages, given by ProtoMessage.
+	ProtoDescriptorSet string `mapstructure:"proto-descriptor-set"`
+	ProtoMessage       string `mapstructure:"proto-message"`
+
+	// Coverage is the format of the report of the coverage of the policies.
+	// When empty, the coverage of the policies is not recorded.
+	Coverage       string
+	coverageReport output.CoverageReport
 }
 
 // Run executes the TestRunner, verifying all Rego policies against the given
 // list of configuration files.
 func (t *TestRunner) Run(ctx context.Context, fileList []string) ([]output.CheckResult, error) {
-	files, err := parseFileList(fileList, t.Ignore)
+	if err := t.update(ctx); err != nil {
+		return nil, err
+	}
+
+	engine, err := t.LoadEngine(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("parse files: %w", err)
+		return nil, err
 	}
 
-	var configurations map[string]interface{}
-	if t.Parser != "" {
-		configurations, err = parser.ParseConfigurationsAs(files, t.Parser)
-	} else {
-		configurations, err = parser.ParseConfigurations(files)
+	return t.evaluate(ctx, engine, fileList)
+}
+
+// RunWithEngine verifies the given list of configuration files against the
+// policies of an engine that has already been loaded, e.g. with LoadEngine,
+// so that the policies do not need to be loaded again for every run. The
+// engine is copied for the run, and is not modified, so that it can be
+// reused by any number of concurrent calls, as long as coverage is not
+// enabled. The policies to update are not downloaded.
+func (t *TestRunner) RunWithEngine(ctx context.Context, engine *policy.Engine, fileList []string) ([]output.CheckResult, error) {
+	return t.evaluate(ctx, engine.Copy(), fileList)
+}
+
+// update downloads the policies to update, which are currently placed in the
+// first directory that appears in the list of policies.
+func (t *TestRunner) update(ctx context.Context) error {
+	if len(t.Update) == 0 {
+		return nil
 	}
+
+	// The commits are logged to stderr so that they are not mixed
+	// with the results.
+	options := downloader.Options{
+		CosignKey: t.CosignKey,
+		GitDepth:  t.GitDepth,
+		Logger:    log.New(os.Stderr, "", 0),
+	}
+
+	if err := downloader.DownloadWithOptions(ctx, t.Policy[0], t.Update, options); err != nil {
+		return fmt.Errorf("update policies: %w", err)
+	}
+
+	return nil
+}
+
+// LoadEngine loads the policies and the data of the TestRunner into an
+// engine, which can be reused across runs with RunWithEngine.
+func (t *TestRunner) LoadEngine(ctx context.Context) (*policy.Engine, error) {
+	rulePrefixes, err := policy.ParseRulePrefixes(t.RulePrefixes)
 	if err != nil {
+		return nil, fmt.Errorf("parse rule prefixes: %w", err)
+	}
+
+	namespaceMap, err := policy.ParseNamespaceMap(t.NamespaceMap)
+	if err != nil {
+		return nil, fmt.Errorf("parse namespace map: %w", err)
+	}
+
+	options := policy.Options{
+		Strict:         t.Strict,
+		DataParser:     t.DataAs,
+		FollowSymlinks: t.FollowSymlinks,
+		RulePrefixes:   rulePrefixes,
+		FailSeverity:   t.FailSeverity,
+		RegoVersion:    t.RegoVersion,
+		Capabilities:   t.Capabilities,
+		AbortOnError:   t.AbortOnError,
+		NamespaceMap:   namespaceMap,
+		AllowEmpty:     t.AllowEmpty,
+
+		// The revisions of the bundles are logged to stderr so that
+		// they are not mixed with the results.
+		Logger: log.New(os.Stderr, "", 0),
+	}
+
+	engine, err := policy.LoadWithOptions(ctx, t.Policy, t.Data, options)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	// The policies are logged to stderr so that they are not mixed
+	// with the results.
+	if t.Verbose {
+		logger := log.New(os.Stderr, "", 0)
+		logger.Printf("Loaded %d policy modules from %v with the namespaces %v", len(engine.Modules()), t.Policy, engine.Namespaces())
+	}
+
+	return engine, nil
+}
+
+// evaluate parses the given list of configuration files and verifies them
+// against the policies of the given engine.
+func (t *TestRunner) evaluate(ctx context.Context, engine *policy.Engine, fileList []string) ([]output.CheckResult, error) {
+	parserMap, err := parser.ParseParserMap(t.ParserMap)
+	if err != nil {
+		return nil, fmt.Errorf("parse parser map: %w", err)
+	}
+
+	// The environment can be tested on its own, without any files.
+	var files []string
+	if len(fileList) > 0 || !t.Env {
+		files, err = t.parseFileList(fileList, parserMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// When none of the files have changed, there is nothing to test.
+	if len(files) == 0 && !t.Env {
+		return nil, nil
+	}
+
+	options := parser.Options{
+		Parser:           t.Parser,
+		BuildArgs:        parser.ParseBuildArgs(t.BuildArgs),
+		DockerfileStages: t.DockerfileStages,
+		ParserMap:        parserMap,
+		MaxErrors:        t.MaxParserErrors,
+
+		ProtoDescriptorSet: t.ProtoDescriptorSet,
+		ProtoMessage:       t.ProtoMessage,
+
+		Helm:        t.Helm,
+		HelmOptions: helm.Options{Values: t.HelmValues, Set: t.HelmSet},
+
+		NoSniff:     t.NoSniff,
+		ExpandLists: t.ExpandLists,
+		ResolveRefs: t.ResolveRefs,
+	}
+
+	// The progress is cleared before the results are returned, so that it
+	// is never mixed with the output of the results.
+	progress := t.newProgress()
+	progress.parsing(len(files))
+	defer progress.clear()
+
+	// Files that could not be parsed, when they are tolerated, are excluded
+	// from the configurations and reported as failures after the evaluation.
+	var fileErrors parser.FileErrors
+	configurations, positions, err := parser.ParseConfigurationsWithPositions(ctx, files, options)
+	if err != nil && !errors.As(err, &fileErrors) {
 		return nil, fmt.Errorf("get configurations: %w", err)
 	}
 
-	// When there are policies to download, they are currently placed in the first
-	// directory that appears in the list of policies.
-	if len(t.Update) > 0 {
-		if err := downloader.Download(ctx, t.Policy[0], t.Update); err != nil {
-			return nil, fmt.Errorf("update policies: %w", err)
+	var inputMetadata map[string]interface{}
+	if len(t.InputMeta) > 0 {
+		inputMetadata, err = parseInputMetadata(t.InputMeta)
+		if err != nil {
+			return nil, fmt.Errorf("parse input metadata: %w", err)
+		}
+	}
+
+	// When combining, the metadata is added once to the combined input
+	// instead of to each of the configurations.
+	combine := t.Combine || t.CombineBy != ""
+	if t.FileMetadata && !combine {
+		addFileMetadata(configurations)
+	}
+
+	if inputMetadata != nil && !combine {
+		addInputMetadata(configurations, inputMetadata)
+	}
+
+	// The environment is added after the metadata, so that the input only
+	// contains the environment variables.
+	if t.Env {
+		if _, ok := configurations[envInputName]; ok {
+			return nil, fmt.Errorf("the file %s conflicts with the input of the environment", envInputName)
+		}
+
+		configurations[envInputName] = environmentInput(os.Environ())
+	}
+
+	// Coverage is enabled for each evaluation so that the report
+	// only covers the configurations that were evaluated last.
+	if t.Coverage != "" {
+		engine.EnableCoverage()
+	}
+
+	if t.Trace || t.TraceOutput != "" {
+		engine.EnableTracing()
+	}
+
+	if t.ReportPasses {
+		engine.EnableReportPasses()
+	}
+
+	engine.SetPositions(positions)
+
+	namespaces := t.selectNamespaces(engine)
+
+	if !t.AllowEmpty {
+		if err := validateNamespaceRules(engine, namespaces); err != nil {
+			return nil, fmt.Errorf("validate namespaces: %w", err)
+		}
+	}
+
+	if len(t.Rules) > 0 {
+		if err := validateRules(engine, namespaces, t.Rules); err != nil {
+			return nil, fmt.Errorf("validate rules: %w", err)
+		}
+
+		engine.SetRules(t.Rules)
+	}
+
+	// When combining, the configurations are combined once and the policies
+	// of every namespace are evaluated against the same combined input.
+	var results []output.CheckResult
+	if combine {
+		results, err = t.checkCombined(ctx, engine, configurations, inputMetadata, namespaces)
+		if err != nil {
+			return nil, fmt.Errorf("check combined: %w", err)
+		}
+	} else {
+		progress.checking(len(configurations), len(configurations)*len(namespaces))
+		results, err = t.checkNamespaces(withProgress(ctx, progress), engine, configurations, namespaces)
+		if err != nil && !errors.Is(err, errFailFast) {
+			return nil, fmt.Errorf("query rule: %w", err)
+		}
+	}
+
+	// The namespaces that were not evaluated after stopping at the first
+	// failure did not produce any results, which is expected.
+	stopped := errors.Is(err, errFailFast)
+
+	if t.Strict && len(configurations) > 0 && !stopped {
+		if err := validateNamespaceResults(namespaces, results); err != nil {
+			return nil, fmt.Errorf("strict: %w", err)
 		}
 	}
 
-	engine, err := policy.LoadWithData(ctx, t.Policy, t.Data)
+	// The report is only set when coverage is enabled, so that runs without
+	// coverage can share the TestRunner concurrently.
+	if t.Coverage != "" {
+		t.coverageReport = engine.Coverage()
+	}
+
+	if t.Baseline != "" {
+		if t.UpdateBaseline {
+			if err := writeBaseline(t.Baseline, results); err != nil {
+				return nil, fmt.Errorf("update baseline: %w", err)
+			}
+		}
+
+		baseline, err := loadBaseline(t.Baseline)
+		if err != nil {
+			return nil, fmt.Errorf("load baseline: %w", err)
+		}
+
+		applyBaseline(results, baseline)
+	}
+
+	if t.Dedupe != "" {
+		results, err = dedupeResults(results, t.Dedupe)
+		if err != nil {
+			return nil, fmt.Errorf("dedupe: %w", err)
+		}
+	}
+
+	if t.MaxResultsPerFile < 0 {
+		return nil, fmt.Errorf("max results per file must not be negative, got %d", t.MaxResultsPerFile)
+	}
+
+	results = append(results, parseErrorResults(fileErrors)...)
+
+	return results, nil
+}
+
+// Files returns the files that are tested for the given list of files,
+// directories and glob patterns, which are the files that would be parsed
+// by Run. Files in directories are only included when they can be parsed,
+// and are excluded when they are ignored.
+func (t *TestRunner) Files(fileList []string) ([]string, error) {
+	parserMap, err := parser.ParseParserMap(t.ParserMap)
 	if err != nil {
-		return nil, fmt.Errorf("load: %w", err)
+		return nil, fmt.Errorf("parse parser map: %w", err)
 	}
 
-	namespaces := t.Namespace
-	if t.AllNamespaces {
-		namespaces = engine.Namespaces()
+	return t.parseFileList(fileList, parserMap)
+}
+
+// parseFileList expands the given list of files, and only keeps the files
+// that have changed when Since is set.
+func (t *TestRunner) parseFileList(fileList []string, parserMap map[string]string) ([]string, error) {
+	files, err := parseFileList(fileList, t.Ignore, t.IgnoreDir, parser.Options{ParserMap: parserMap, Helm: t.Helm, NoSniff: t.NoSniff})
+	if err != nil {
+		return nil, fmt.Errorf("parse files: %w", err)
+	}
+
+	if t.Since == "" {
+		return files, nil
 	}
 
+	changed, err := changedFiles(t.Since)
+	if err != nil {
+		return nil, fmt.Errorf("get changed files: %w", err)
+	}
+
+	files, err = filterChangedFiles(files, changed)
+	if err != nil {
+		return nil, fmt.Errorf("filter changed files: %w", err)
+	}
+
+	return files, nil
+}
+
+// parseErrorResults returns the results that report the files that could
+// not be parsed, as a failure with a severity of error for each file.
+func parseErrorResults(fileErrors parser.FileErrors) []output.CheckResult {
 	var results []output.CheckResult
-	for _, namespace := range namespaces {
-		if t.Combine {
-			result, err := engine.CheckCombined(ctx, configurations, namespace)
+	for _, fileError := range fileErrors {
+		failure := output.Result{
+			Message:  fmt.Sprintf("failed to parse: %v", fileError.Err),
+			Metadata: map[string]interface{}{"severity": "error"},
+		}
+
+		results = append(results, output.CheckResult{
+			FileName:  fileError.Path,
+			Namespace: "-",
+			Failures:  []output.Result{failure},
+		})
+	}
+
+	return results
+}
+
+// CoverageReport returns the coverage of the policies that were evaluated by
+// the last call to Run. The report is empty when coverage is not enabled.
+func (t *TestRunner) CoverageReport() output.CoverageReport {
+	return t.coverageReport
+}
+
+// errFailFast is returned by the checks when the evaluation stopped at the
+// first failure, together with the results that were gathered until then.
+var errFailFast = errors.New("stopped at the first failure")
+
+// checkNamespaces evaluates the policies in each of the given namespaces
+// against the configurations, evaluating up to ParallelNamespaces namespaces
+// concurrently. The results are in the order of the namespaces, and the
+// evaluation stops at the first error. When FailFast is set, the evaluation
+// also stops at the first failure, and the results so far are returned.
+func (t *TestRunner) checkNamespaces(ctx context.Context, engine *policy.Engine, configurations map[string]interface{}, namespaces []string) ([]output.CheckResult, error) {
+	workers := t.ParallelNamespaces
+	if workers <= 0 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	results := make([][]output.CheckResult, len(namespaces))
+	group, groupCtx := errgroup.WithContext(ctx)
+	for w := 0; w < workers; w++ {
+		group.Go(func() error {
+			for i := range jobs {
+				result, err := t.check(groupCtx, engine, configurations, namespaces[i])
+				results[i] = result
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	group.Go(func() error {
+		defer close(jobs)
+		for i := range namespaces {
+			select {
+			case jobs <- i:
+			case <-groupCtx.Done():
+				return nil
+			}
+		}
+
+		return nil
+	})
+
+	err := group.Wait()
+	if err != nil && !errors.Is(err, errFailFast) {
+		return nil, err
+	}
+
+	var checkResults []output.CheckResult
+	for _, result := range results {
+		checkResults = append(checkResults, result...)
+	}
+
+	return checkResults, err
+}
+
+// check evaluates the policies in the given namespace against each of the
+// configurations using a pool of workers. The results are ordered by the
+// file name of the configuration they were produced from.
+func (t *TestRunner) check(ctx context.Context, engine *policy.Engine, configurations map[string]interface{}, namespace string) ([]output.CheckResult, error) {
+	var paths []string
+	for path := range configurations {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	workers := t.Parallel
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan int)
+	results := make([][]output.CheckResult, len(paths))
+	group, groupCtx := errgroup.WithContext(ctx)
+	for w := 0; w < workers; w++ {
+		group.Go(func() error {
+			for i := range jobs {
+				config := map[string]interface{}{paths[i]: configurations[paths[i]]}
+				result, err := t.withTimeout(groupCtx, paths[i], namespace, func(ctx context.Context) ([]output.CheckResult, error) {
+					return engine.Check(ctx, config, namespace)
+				})
+				if err != nil {
+					return fmt.Errorf("check %s: %w", paths[i], err)
+				}
+
+				results[i] = result
+				progressFromContext(ctx).check()
+
+				if t.FailFast && hasFailures(result) {
+					return errFailFast
+				}
+			}
+
+			return nil
+		})
+	}
+
+	group.Go(func() error {
+		defer close(jobs)
+		for i := range paths {
+			select {
+			case jobs <- i:
+			case <-groupCtx.Done():
+				return nil
+			}
+		}
+
+		return nil
+	})
+
+	err := group.Wait()
+	if err != nil && !errors.Is(err, errFailFast) {
+		return nil, err
+	}
+
+	var checkResults []output.CheckResult
+	for _, result := range results {
+		checkResults = append(checkResults, result...)
+	}
+
+	return checkResults, err
+}
+
+// checkCombined evaluates the policies of each of the given namespaces against
+// the combined configurations. When the configurations are combined by a key,
+// the configurations of each group are combined and evaluated separately. The
+// configurations are only combined once when the checks are not limited by a
+// timeout.
+func (t *TestRunner) checkCombined(ctx context.Context, engine *policy.Engine, configurations map[string]interface{}, inputMetadata map[string]interface{}, namespaces []string) ([]output.CheckResult, error) {
+	groups := []configurationGroup{{Name: "Combined", Configurations: configurations}}
+	if t.CombineBy != "" {
+		key, err := parseCombineBy(t.CombineBy)
+		if err != nil {
+			return nil, fmt.Errorf("parse combine by: %w", err)
+		}
+
+		groups = groupConfigurations(configurations, key)
+	}
+
+	var results []output.CheckResult
+	for _, group := range groups {
+		metadata := combinedMetadata(group.Configurations, t.FileMetadata, inputMetadata)
+		if t.Timeout <= 0 {
+			result, err := engine.CheckCombinedWithMetadata(ctx, group.Name, group.Configurations, metadata, namespaces)
 			if err != nil {
-				return nil, fmt.Errorf("check combined: %w", err)
+				return nil, err
 			}
 
-			results = append(results, result)
-		} else {
-			result, err := engine.Check(ctx, configurations, namespace)
+			results = append(results, result...)
+			continue
+		}
+
+		for _, namespace := range namespaces {
+			result, err := t.withTimeout(ctx, group.Name, namespace, func(ctx context.Context) ([]output.CheckResult, error) {
+				return engine.CheckCombinedWithMetadata(ctx, group.Name, group.Configurations, metadata, []string{namespace})
+			})
 			if err != nil {
-				return nil, fmt.Errorf("query rule: %w", err)
+				return nil, err
 			}
 
 			results = append(results, result...)
@@ -88,15 +743,214 @@ func (t *TestRunner) Run(ctx context.Context, fileList []string) ([]output.Check
 	return results, nil
 }
 
-func parseFileList(fileList []string, ignoreRegex string) ([]string, error) {
-	var files []string
+// withTimeout runs the given check with a deadline of Timeout. When the check
+// does not finish before the deadline, the check is reported as a failure of
+// the file in the namespace, with a severity of error, rather than an error
+// that would stop the evaluation of the other files and namespaces.
+func (t *TestRunner) withTimeout(ctx context.Context, path string, namespace string, check func(ctx context.Context) ([]output.CheckResult, error)) ([]output.CheckResult, error) {
+	if t.Timeout <= 0 {
+		return check(ctx)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+
+	results, err := check(checkCtx)
+	if err == nil || ctx.Err() != nil || !errors.Is(checkCtx.Err(), context.DeadlineExceeded) {
+		return results, err
+	}
+
+	failure := output.Result{
+		Message:  fmt.Sprintf("timed out after %v", t.Timeout),
+		Metadata: map[string]interface{}{"severity": "error"},
+	}
+
+	return []output.CheckResult{{FileName: path, Namespace: namespace, Failures: []output.Result{failure}}}, nil
+}
+
+func hasFailures(results []output.CheckResult) bool {
+	for _, result := range results {
+		if len(result.Failures) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectNamespaces returns the namespaces to evaluate, which are either the
+// given namespaces or all of the namespaces, without the excluded namespaces.
+// An excluded namespace that ends with a * excludes all of the namespaces
+// that start with the rest of it, e.g. legacy.* excludes legacy.kubernetes.
+func (t *TestRunner) selectNamespaces(engine *policy.Engine) []string {
+	namespaces := t.Namespace
+	if t.AllNamespaces && t.OnlyRootNamespaces {
+		namespaces = engine.RootNamespaces()
+	} else if t.AllNamespaces {
+		namespaces = engine.Namespaces()
+	}
+
+	if len(t.ExcludeNamespace) == 0 {
+		return namespaces
+	}
+
+	var selected []string
+	for _, namespace := range namespaces {
+		if !matchNamespace(namespace, t.ExcludeNamespace) {
+			selected = append(selected, namespace)
+		}
+	}
+
+	return selected
+}
+
+// matchNamespace reports whether the namespace is one of the given
+// namespaces, where a namespace that ends with * matches all of the
+// namespaces that start with the rest of it.
+func matchNamespace(namespace string, namespaces []string) bool {
+	for _, pattern := range namespaces {
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(namespace, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+
+		if namespace == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WarningsAsFailures returns the given results where the warnings of the
+// namespaces in FailOnWarnNamespace are also failures. The results are only
+// used to compute the exit code, so that the warnings are still reported
+// as warnings.
+func (t *TestRunner) WarningsAsFailures(results []output.CheckResult) []output.CheckResult {
+	if len(t.FailOnWarnNamespace) == 0 {
+		return results
+	}
+
+	escalated := make([]output.CheckResult, len(results))
+	for i, result := range results {
+		escalated[i] = result
+		if len(result.Warnings) > 0 && matchNamespace(result.Namespace, t.FailOnWarnNamespace) {
+			escalated[i].Failures = append(append([]output.Result{}, result.Failures...), result.Warnings...)
+			escalated[i].Warnings = nil
+		}
+	}
+
+	return escalated
+}
+
+// validateRules returns an error when any of the given rules
+// do not exist in the given namespaces.
+func validateRules(engine *policy.Engine, namespaces []string, rules []string) error {
+	var availableRules []string
+	for _, namespace := range namespaces {
+		for _, rule := range engine.Rules(namespace) {
+			if !contains(availableRules, rule) {
+				availableRules = append(availableRules, rule)
+			}
+		}
+	}
+	sort.Strings(availableRules)
+
+	for _, rule := range rules {
+		if !contains(availableRules, rule) {
+			return fmt.Errorf("unknown rule %q, available rules: %v", rule, availableRules)
+		}
+	}
+
+	return nil
+}
+
+// validateNamespaceRules returns an error when none of the given namespaces
+// have any rules, e.g. because of a typo in the name of a namespace, as the
+// files would pass without being checked against any rules.
+func validateNamespaceRules(engine *policy.Engine, namespaces []string) error {
+	for _, namespace := range namespaces {
+		if len(engine.Rules(namespace)) > 0 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no rules found in the namespaces %v, the namespaces of the policies are %v", namespaces, engine.Namespaces())
+}
+
+// validateNamespaceResults returns an error when any of the given namespaces
+// did not produce any results for all of the configurations.
+func validateNamespaceResults(namespaces []string, results []output.CheckResult) error {
+	tests := make(map[string]int)
+	for _, result := range results {
+		tests[result.Namespace] += result.Successes + len(result.Failures) + len(result.Warnings) + len(result.Exceptions)
+	}
+
+	for _, namespace := range namespaces {
+		if tests[namespace] == 0 {
+			return fmt.Errorf("namespace %q did not produce any results", namespace)
+		}
+	}
+
+	return nil
+}
+
+func contains(collection []string, item string) bool {
+	for _, value := range collection {
+		if value == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseFileList expands the globs and directories of the given list of files
+// into the files that can be parsed. When charts is true, the directories of
+// Helm charts are kept as they are, as the charts are rendered by the parser.
+func parseFileList(fileList []string, ignoreRegex string, ignoreDirs []string, options parser.Options) ([]string, error) {
+	for _, pattern := range ignoreDirs {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid ignored directory %q: %w", pattern, err)
+		}
+	}
+
+	var expandedFileList []string
 	for _, file := range fileList {
+		if file == "" || file == "-" || parser.IsURL(file) || !isGlob(file) {
+			expandedFileList = append(expandedFileList, file)
+			continue
+		}
+
+		matches, err := expandGlob(file, ignoreRegex, ignoreDirs, options)
+		if err != nil {
+			return nil, fmt.Errorf("expand glob: %w", err)
+		}
+
+		expandedFileList = append(expandedFileList, matches...)
+	}
+
+	// The same file can be found more than once, e.g. when it matches
+	// several of the globs, but it is only tested once.
+	var files []string
+	seen := make(map[string]bool)
+	addFiles := func(paths ...string) {
+		for _, path := range paths {
+			if !seen[path] {
+				seen[path] = true
+				files = append(files, path)
+			}
+		}
+	}
+
+	for _, file := range expandedFileList {
 		if file == "" {
 			continue
 		}
 
-		if file == "-" {
-			files = append(files, "-")
+		// Standard input and the configurations at URLs are read by the
+		// parsers, as they are not files on the file system.
+		if file == "-" || parser.IsURL(file) {
+			addFiles(file)
 			continue
 		}
 
@@ -105,15 +959,17 @@ func parseFileList(fileList []string, ignoreRegex string) ([]string, error) {
 			return nil, fmt.Errorf("get file info: %w", err)
 		}
 
-		if fileInfo.IsDir() {
-			directoryFiles, err := getFilesFromDirectory(file, ignoreRegex)
+		if fileInfo.IsDir() && options.Helm && helm.IsChart(file) {
+			addFiles(file)
+		} else if fileInfo.IsDir() {
+			directoryFiles, err := getFilesFromDirectory(file, ignoreRegex, ignoreDirs, options)
 			if err != nil {
 				return nil, fmt.Errorf("get files from directory: %w", err)
 			}
 
-			files = append(files, directoryFiles...)
+			addFiles(directoryFiles...)
 		} else {
-			files = append(files, file)
+			addFiles(file)
 		}
 	}
 
@@ -124,19 +980,39 @@ func parseFileList(fileList []string, ignoreRegex string) ([]string, error) {
 	return files, nil
 }
 
-func getFilesFromDirectory(directory string, ignoreRegex string) ([]string, error) {
+// getFilesFromDirectory returns the files in the given directory that can be
+// parsed, skipping the files that are ignored, and the subdirectories whose
+// name matches one of the ignored directories, e.g. testdata or *_test.
+func getFilesFromDirectory(directory string, ignoreRegex string, ignoreDirs []string, options parser.Options) ([]string, error) {
 	regexp, err := regexp.Compile(ignoreRegex)
 	if err != nil {
 		return nil, fmt.Errorf("given regexp couldn't be parsed :%w", err)
 	}
 
 	var files []string
+	ignoreRules := newIgnoreRules(directory)
 	walk := func(currentPath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return fmt.Errorf("walk path: %w", err)
 		}
 
 		if info.IsDir() {
+			if ignoreRules.matches(currentPath, true) {
+				return filepath.SkipDir
+			}
+
+			if currentPath != directory && matchDirectory(info.Name(), ignoreDirs) {
+				return filepath.SkipDir
+			}
+
+			if err := ignoreRules.load(currentPath); err != nil {
+				return fmt.Errorf("load ignore file: %w", err)
+			}
+
+			return nil
+		}
+
+		if ignoreRules.matches(currentPath, false) {
 			return nil
 		}
 
@@ -144,7 +1020,7 @@ func getFilesFromDirectory(directory string, ignoreRegex string) ([]string, erro
 			return nil
 		}
 
-		if parser.FileSupported(currentPath) {
+		if parser.FileSupportedWithOptions(currentPath, parser.Options{ParserMap: options.ParserMap, NoSniff: options.NoSniff}) {
 			files = append(files, currentPath)
 		}
 
@@ -158,3 +1034,15 @@ func getFilesFromDirectory(directory string, ignoreRegex string) ([]string, erro
 
 	return files, nil
 }
+
+// matchDirectory reports whether the name of a directory matches any of the
+// given patterns. The patterns have been validated by parseFileList.
+func matchDirectory(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,586 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/policy"
+)
+
+func TestValidateNamespaceResults(t *testing.T) {
+	results := []output.CheckResult{
+		{FileName: "deployment.yaml", Namespace: "main", Successes: 1},
+		{FileName: "service.yaml", Namespace: "main"},
+		{FileName: "deployment.yaml", Namespace: "labels", Exceptions: []output.Result{{}}},
+		{FileName: "deployment.yaml", Namespace: "typo"},
+		{FileName: "service.yaml", Namespace: "typo"},
+	}
+
+	if err := validateNamespaceResults([]string{"main", "labels"}, results); err != nil {
+		t.Errorf("namespaces with results should be valid: %v", err)
+	}
+
+	if err := validateNamespaceResults([]string{"main", "typo"}, results); err == nil {
+		t.Error("namespace without results should be invalid")
+	}
+}
+
+func TestCheckNamespaces(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(policyDir)
+
+	var namespaces []string
+	for i := 0; i < 8; i++ {
+		namespace := fmt.Sprintf("namespace%d", i)
+		namespaces = append(namespaces, namespace)
+
+		policy := fmt.Sprintf("package %s\ndeny[msg] { msg := \"%s\" }\n", namespace, namespace)
+		if err := ioutil.WriteFile(filepath.Join(policyDir, namespace+".rego"), []byte(policy), os.ModePerm); err != nil {
+			t.Fatalf("write policy: %v", err)
+		}
+	}
+
+	invalid := "package invalid\ndeny[msg] { msg := {\"reason\": \"missing message\"} }\n"
+	if err := ioutil.WriteFile(filepath.Join(policyDir, "invalid.rego"), []byte(invalid), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	engine, err := policy.Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("load policies: %v", err)
+	}
+
+	configurations := map[string]interface{}{
+		"a.yaml": map[string]interface{}{},
+		"b.yaml": map[string]interface{}{},
+	}
+
+	runner := TestRunner{ParallelNamespaces: 4}
+	results, err := runner.checkNamespaces(ctx, engine, configurations, namespaces)
+	if err != nil {
+		t.Fatalf("check namespaces: %v", err)
+	}
+
+	if len(results) != 2*len(namespaces) {
+		t.Fatalf("expected %v results, got %v", 2*len(namespaces), len(results))
+	}
+
+	for i, result := range results {
+		if expected := namespaces[i/2]; result.Namespace != expected || result.Failures[0].Message != expected {
+			t.Errorf("result %v is for namespace %v, expected %v", i, result.Namespace, expected)
+		}
+	}
+
+	if _, err := runner.checkNamespaces(ctx, engine, configurations, append(namespaces, "invalid")); err == nil {
+		t.Error("expected the error of the invalid namespace to be returned")
+	}
+}
+
+func TestCheckNamespacesFailFast(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(policyDir)
+
+	for _, namespace := range []string{"main", "other"} {
+		policy := fmt.Sprintf("package %s\ndeny[msg] { input.fail; msg := \"failed\" }\n", namespace)
+		if err := ioutil.WriteFile(filepath.Join(policyDir, namespace+".rego"), []byte(policy), os.ModePerm); err != nil {
+			t.Fatalf("write policy: %v", err)
+		}
+	}
+
+	engine, err := policy.Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("load policies: %v", err)
+	}
+
+	configurations := map[string]interface{}{
+		"a.yaml": map[string]interface{}{},
+		"b.yaml": map[string]interface{}{"fail": true},
+		"c.yaml": map[string]interface{}{"fail": true},
+	}
+
+	runner := TestRunner{Parallel: 1, FailFast: true}
+	results, err := runner.checkNamespaces(ctx, engine, configurations, []string{"main", "other"})
+	if !errors.Is(err, errFailFast) {
+		t.Fatalf("expected the evaluation to stop at the first failure, got %v", err)
+	}
+
+	// The files are evaluated in order, so the evaluation stops at the
+	// first failing file, and the other namespace is not evaluated.
+	if len(results) != 2 || results[0].FileName != "a.yaml" || results[1].FileName != "b.yaml" || len(results[1].Failures) != 1 {
+		t.Errorf("expected the results up to the first failure, got %v", results)
+	}
+
+	runner.FailFast = false
+	results, err = runner.checkNamespaces(ctx, engine, configurations, []string{"main", "other"})
+	if err != nil {
+		t.Fatalf("check namespaces: %v", err)
+	}
+
+	if len(results) != 6 {
+		t.Errorf("expected all of the results without fail fast, got %v", len(results))
+	}
+}
+
+func TestSelectNamespaces(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(policyDir)
+
+	for i, namespace := range []string{"main", "kubernetes", "legacy.kubernetes", "legacy.terraform"} {
+		policy := fmt.Sprintf("package %s\ndeny[msg] { msg := \"failed\" }\n", namespace)
+		if err := ioutil.WriteFile(filepath.Join(policyDir, fmt.Sprintf("policy%d.rego", i)), []byte(policy), os.ModePerm); err != nil {
+			t.Fatalf("write policy: %v", err)
+		}
+	}
+
+	engine, err := policy.Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("load policies: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		runner   TestRunner
+		expected []string
+	}{
+		{"given namespaces", TestRunner{Namespace: []string{"main", "kubernetes"}}, []string{"main", "kubernetes"}},
+		{"exact exclusion", TestRunner{Namespace: []string{"main", "kubernetes"}, ExcludeNamespace: []string{"kubernetes"}}, []string{"main"}},
+		{"all namespaces", TestRunner{AllNamespaces: true, ExcludeNamespace: []string{"main"}}, []string{"kubernetes", "legacy.kubernetes", "legacy.terraform"}},
+		{"prefix exclusion", TestRunner{AllNamespaces: true, ExcludeNamespace: []string{"legacy.*"}}, []string{"kubernetes", "main"}},
+		{"exclusion is not a prefix", TestRunner{AllNamespaces: true, ExcludeNamespace: []string{"legacy"}}, []string{"kubernetes", "legacy.kubernetes", "legacy.terraform", "main"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := tt.runner.selectNamespaces(engine)
+			sort.Strings(actual)
+
+			expected := append([]string{}, tt.expected...)
+			sort.Strings(expected)
+
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("selectNamespaces() = %v, want %v", actual, expected)
+			}
+		})
+	}
+}
+
+func TestWarningsAsFailures(t *testing.T) {
+	warning := output.Result{Message: "warning"}
+	failure := output.Result{Message: "failure"}
+	results := []output.CheckResult{
+		{FileName: "deployment.yaml", Namespace: "main", Warnings: []output.Result{warning}},
+		{FileName: "deployment.yaml", Namespace: "security", Warnings: []output.Result{warning}, Failures: []output.Result{failure}},
+		{FileName: "deployment.yaml", Namespace: "security.images", Warnings: []output.Result{warning}},
+	}
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		expected   []output.CheckResult
+	}{
+		{"no namespaces", nil, results},
+		{
+			"exact namespace",
+			[]string{"security"},
+			[]output.CheckResult{
+				results[0],
+				{FileName: "deployment.yaml", Namespace: "security", Failures: []output.Result{failure, warning}},
+				results[2],
+			},
+		},
+		{
+			"prefix namespace",
+			[]string{"security*"},
+			[]output.CheckResult{
+				results[0],
+				{FileName: "deployment.yaml", Namespace: "security", Failures: []output.Result{failure, warning}},
+				{FileName: "deployment.yaml", Namespace: "security.images", Failures: []output.Result{warning}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := TestRunner{FailOnWarnNamespace: tt.namespaces}
+			actual := runner.WarningsAsFailures(results)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("WarningsAsFailures() = %v, want %v", actual, tt.expected)
+			}
+
+			if len(results[1].Warnings) != 1 || len(results[1].Failures) != 1 {
+				t.Errorf("expected the results to be left as is, got %v", results[1])
+			}
+		})
+	}
+}
+
+func TestRunMaxParserErrors(t *testing.T) {
+	ctx := context.Background()
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	policy := "package main\ndeny[msg] { input.fail; msg := \"failed\" }\n"
+	if err := ioutil.WriteFile(filepath.Join(directory, "policy.rego"), []byte(policy), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	valid := filepath.Join(directory, "valid.json")
+	invalid := filepath.Join(directory, "invalid.json")
+	for path, contents := range map[string]string{valid: `{"fail": false}`, invalid: `{"fail":`} {
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	runner := TestRunner{Policy: []string{directory}, Namespace: []string{"main"}}
+	if _, err := runner.Run(ctx, []string{valid, invalid}); err == nil {
+		t.Error("expected the test to stop at the file that could not be parsed")
+	}
+
+	runner.MaxParserErrors = 1
+	results, err := runner.Run(ctx, []string{valid, invalid})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(results) != 2 || results[0].FileName != valid || results[0].Successes != 1 {
+		t.Fatalf("expected the valid file to be evaluated, got %v", results)
+	}
+
+	if results[1].FileName != invalid || len(results[1].Failures) != 1 || results[1].Failures[0].Metadata["severity"] != "error" {
+		t.Errorf("expected the file that could not be parsed to be reported as a failure, got %v", results[1])
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	policy := `package main
+slow { input.slow }
+slow { input[_].contents.slow }
+deny[msg] {
+	slow
+	x := numbers.range(1, 100000)[_]
+	y := numbers.range(1, 100000)[_]
+	x + y == 0
+	msg := "unreachable"
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(directory, "policy.rego"), []byte(policy), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	fast := filepath.Join(directory, "fast.json")
+	slow := filepath.Join(directory, "slow.json")
+	for path, contents := range map[string]string{fast: `{"slow": false}`, slow: `{"slow": true}`} {
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	runner := TestRunner{Policy: []string{directory}, Namespace: []string{"main"}, Timeout: 100 * time.Millisecond}
+	results, err := runner.Run(ctx, []string{fast, slow})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(results) != 2 || results[0].FileName != fast || results[0].Successes != 1 {
+		t.Fatalf("expected the fast file to be evaluated, got %v", results)
+	}
+
+	if results[1].FileName != slow || len(results[1].Failures) != 1 || results[1].Failures[0].Metadata["severity"] != "error" {
+		t.Errorf("expected the slow file to be reported as timed out, got %v", results[1])
+	}
+
+	runner.Combine = true
+	results, err = runner.Run(ctx, []string{fast, slow})
+	if err != nil {
+		t.Fatalf("run combined: %v", err)
+	}
+
+	if len(results) != 1 || results[0].FileName != "Combined" || len(results[0].Failures) != 1 {
+		t.Errorf("expected the combined files to be reported as timed out, got %v", results)
+	}
+}
+
+func TestRunCombinedInputMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	policy := `package main
+documents_have_metadata { input.files[_].contents.__meta__ }
+deny[msg] {
+	input.__meta__.environment == "production"
+	count(input.files) == 2
+	not documents_have_metadata
+	msg := "production"
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(directory, "policy.rego"), []byte(policy), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	deployment := filepath.Join(directory, "deployment.json")
+	service := filepath.Join(directory, "service.json")
+	for path, contents := range map[string]string{deployment: `{"kind": "Deployment"}`, service: `{"kind": "Service"}`} {
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	runner := TestRunner{Policy: []string{directory}, Namespace: []string{"main"}, Combine: true, InputMeta: []string{"environment=production"}}
+	results, err := runner.Run(ctx, []string{deployment, service})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(results) != 1 || results[0].FileName != "Combined" || len(results[0].Failures) != 1 {
+		t.Errorf("expected the metadata to be added once to the combined input, got %v", results)
+	}
+}
+
+func TestRunCombinedFileMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	policy := `package main
+deny[msg] {
+	path := input.__file__[_]
+	not endswith(path, "deployment.json")
+	msg := sprintf("%s is not a deployment", [path])
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(directory, "policy.rego"), []byte(policy), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	deployment := filepath.Join(directory, "deployment.json")
+	service := filepath.Join(directory, "service.json")
+	for path, contents := range map[string]string{deployment: `{"kind": "Deployment"}`, service: `{"kind": "Service"}`} {
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	runner := TestRunner{Policy: []string{directory}, Namespace: []string{"main"}, Combine: true, FileMetadata: true}
+	results, err := runner.Run(ctx, []string{deployment, service})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	expected := []output.Result{{Message: fmt.Sprintf("%s is not a deployment", fileMetadata(service)["path"]), Rule: "deny"}}
+	if len(results) != 1 || !reflect.DeepEqual(results[0].Failures, expected) {
+		t.Errorf("expected the paths of the files to be listed in the combined input, got %v", results)
+	}
+}
+
+func TestRunMaxResultsPerFile(t *testing.T) {
+	ctx := context.Background()
+
+	runner := TestRunner{
+		Policy:            []string{"../../examples/kubernetes/policy"},
+		Namespace:         []string{"main"},
+		MaxResultsPerFile: 1,
+		FailThreshold:     2,
+	}
+	results, err := runner.Run(ctx, []string{"../../examples/kubernetes/deployment.yaml"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	// The limit only applies to the output, so the failures that are not
+	// output still count towards the threshold.
+	if len(results) != 1 || len(results[0].Failures) != 4 {
+		t.Fatalf("expected all of the failures to be returned, got %v", results)
+	}
+
+	if exitCode := output.ExitCodeWithThreshold(results, runner.FailThreshold); exitCode != output.ExitCodeFailures {
+		t.Errorf("expected the failures to exceed the threshold, got exit code %d", exitCode)
+	}
+}
+
+func TestRunAllowEmpty(t *testing.T) {
+	ctx := context.Background()
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	policy := "package main\ndeny[msg] { input.fail; msg := \"failed\" }\n"
+	if err := ioutil.WriteFile(filepath.Join(directory, "policy.rego"), []byte(policy), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	file := filepath.Join(directory, "config.json")
+	if err := ioutil.WriteFile(file, []byte(`{"fail": true}`), os.ModePerm); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	runner := TestRunner{Policy: []string{directory}, Namespace: []string{"mian"}}
+	if _, err := runner.Run(ctx, []string{file}); err == nil {
+		t.Error("expected an error for a namespace without any rules")
+	}
+
+	runner.AllowEmpty = true
+	results, err := runner.Run(ctx, []string{file})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(results) != 1 || len(results[0].Failures) != 0 || results[0].Successes != 0 {
+		t.Errorf("expected the file to not be checked, got %v", results)
+	}
+
+	empty := filepath.Join(directory, "empty")
+	if err := os.Mkdir(empty, os.ModePerm); err != nil {
+		t.Fatalf("create dir: %v", err)
+	}
+
+	runner = TestRunner{Policy: []string{empty}, Namespace: []string{"main"}}
+	if _, err := runner.Run(ctx, []string{file}); err == nil {
+		t.Error("expected an error for a policy path without any policies")
+	}
+
+	runner.AllowEmpty = true
+	if _, err := runner.Run(ctx, []string{file}); err != nil {
+		t.Errorf("run without policies: %v", err)
+	}
+}
+
+func TestRunWithEngine(t *testing.T) {
+	ctx := context.Background()
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	policy := "package main\ndeny[msg] { input.fail; msg := \"failed\" }\nwarn_labels[msg] { not input.labels; msg := \"no labels\" }\n"
+	if err := ioutil.WriteFile(filepath.Join(directory, "policy.rego"), []byte(policy), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	pass := filepath.Join(directory, "pass.json")
+	fail := filepath.Join(directory, "fail.json")
+	for path, contents := range map[string]string{pass: `{"fail": false, "labels": {}}`, fail: `{"fail": true}`} {
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	runner := TestRunner{Policy: []string{directory}, Namespace: []string{"main"}}
+	engine, err := runner.LoadEngine(ctx)
+	if err != nil {
+		t.Fatalf("load engine: %v", err)
+	}
+
+	// The runs select different rules of the same engine, which must
+	// not affect each other.
+	runners := []TestRunner{
+		{Namespace: []string{"main"}},
+		{Namespace: []string{"main"}, Rules: []string{"deny"}},
+	}
+
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		go func(i int) {
+			runner := runners[i%2]
+			results, err := runner.RunWithEngine(ctx, engine, []string{pass, fail})
+			if err != nil {
+				errs <- fmt.Errorf("run: %w", err)
+				return
+			}
+
+			warnings := 1
+			if len(runner.Rules) > 0 {
+				warnings = 0
+			}
+
+			if len(results) != 2 || results[0].FileName != fail || len(results[0].Failures) != 1 || len(results[0].Warnings) != warnings || len(results[1].Failures) != 0 {
+				errs <- fmt.Errorf("unexpected results with rules %v: %v", runner.Rules, results)
+				return
+			}
+
+			errs <- nil
+		}(i)
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	files := map[string]string{"deployment.yaml": "{}", "service.yaml": "{}", "notes.txt": "Some notes"}
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(directory, name), []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	runner := TestRunner{Ignore: "service"}
+	actual, err := runner.Files([]string{directory})
+	if err != nil {
+		t.Fatalf("files: %v", err)
+	}
+
+	expected := []string{filepath.Join(directory, "deployment.yaml")}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected files %v, got %v", expected, actual)
+	}
+}
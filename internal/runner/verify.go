@@ -1,7 +1,6 @@
 package runner
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"strings"
@@ -9,7 +8,6 @@ import (
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/policy"
 	"github.com/open-policy-agent/opa/tester"
-	"github.com/open-policy-agent/opa/topdown"
 )
 
 // VerifyRunner is the runner for the Verify command, executing
@@ -20,58 +18,96 @@ type VerifyRunner struct {
 	Output  string
 	NoColor bool `mapstructure:"no-color"`
 	Trace   bool
+
+	// FollowSymlinks follows the symbolic links to directories when
+	// loading the policies.
+	FollowSymlinks bool `mapstructure:"follow-symlinks"`
+
+	// RegoVersion is the version of Rego that the policies are written in.
+	RegoVersion string `mapstructure:"rego-version"`
+
+	// Capabilities is the path to an OPA capabilities file that restricts
+	// the builtins that the policies are allowed to use.
+	Capabilities string
+
+	// RunFilter is a regular expression that the names of the tests are matched
+	// against, in the form of package.name, e.g. data.main.test_deny. Only
+	// the tests that match it are run.
+	RunFilter string `mapstructure:"run"`
+
+	// Verbose outputs whether each test passed or failed, along with how
+	// long it took to run, in the standard output format.
+	Verbose bool
+
+	// FailFast stops running the tests at the first test that failed.
+	FailFast bool `mapstructure:"fail-fast"`
 }
 
 // Run executes the Rego tests for the given policies.
 func (r *VerifyRunner) Run(ctx context.Context) ([]output.CheckResult, error) {
-	engine, err := policy.LoadWithData(ctx, r.Policy, r.Data)
+	engine, err := policy.LoadWithOptions(ctx, r.Policy, r.Data, policy.Options{FollowSymlinks: r.FollowSymlinks, RegoVersion: r.RegoVersion, Capabilities: r.Capabilities})
 	if err != nil {
 		return nil, fmt.Errorf("load: %w", err)
 	}
 
-	runner := tester.NewRunner().SetCompiler(engine.Compiler()).SetStore(engine.Store()).SetModules(engine.Modules()).EnableTracing(true).SetRuntime(engine.Runtime())
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runner := tester.NewRunner().SetCompiler(engine.Compiler()).SetStore(engine.Store()).SetModules(engine.Modules()).EnableTracing(r.Trace).SetRuntime(engine.Runtime()).Filter(r.RunFilter)
 	ch, err := runner.RunTests(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("running tests: %w", err)
 	}
 
 	var results []output.CheckResult
+	var stopped bool
 	for result := range ch {
-		if result.Error != nil {
-			return nil, fmt.Errorf("run test: %w", result.Error)
+
+		// The channel is drained after the first failure when failing fast,
+		// so that the tests that were still running are not blocked.
+		if stopped {
+			continue
 		}
 
-		buf := new(bytes.Buffer)
-		topdown.PrettyTrace(buf, result.Trace)
-		var traces []string
-		for _, line := range strings.Split(buf.String(), "\n") {
-			if len(line) > 0 {
-				traces = append(traces, line)
-			}
+		if result.Error != nil {
+			return nil, fmt.Errorf("run test: %w", result.Error)
 		}
 
 		var outputResult output.Result
 		if result.Fail {
 			outputResult.Message = result.Package + "." + result.Name
+			outputResult.Rule = result.Name
 		}
 
 		queryResult := output.QueryResult{
-			Query:   result.Name,
+			Query:   result.Package + "." + result.Name,
 			Results: []output.Result{outputResult},
-			Traces:  traces,
+		}
+		if r.Trace {
+			queryResult.Traces = policy.PrettyTrace(result.Trace)
+			queryResult.TraceEvents = policy.TraceEvents(result.Trace)
 		}
 
 		checkResult := output.CheckResult{
-			FileName: result.Location.File,
-			Queries:  []output.QueryResult{queryResult},
+			FileName:  result.Location.File,
+			Namespace: strings.TrimPrefix(result.Package, "data."),
+			Queries:   []output.QueryResult{queryResult},
+			Duration:  result.Duration,
 		}
 		if result.Fail {
 			checkResult.Failures = []output.Result{outputResult}
+			checkResult.Rules = []output.EvaluatedRule{{Name: result.Name, Outcome: output.OutcomeFail}}
 		} else {
 			checkResult.Successes++
+			checkResult.Rules = []output.EvaluatedRule{{Name: result.Name, Outcome: output.OutcomePass}}
 		}
 
 		results = append(results, checkResult)
+
+		if result.Fail && r.FailFast {
+			stopped = true
+			cancel()
+		}
 	}
 
 	return results, nil
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyRunner(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(policyDir)
+
+	tests := "package main\ntest_allow_a { true }\ntest_allow_b { true }\ntest_deny { false }\n"
+	if err := ioutil.WriteFile(filepath.Join(policyDir, "main_test.rego"), []byte(tests), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	runner := VerifyRunner{Policy: []string{policyDir}}
+	results, err := runner.Run(ctx)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		if result.Namespace != "main" {
+			t.Errorf("expected the namespace of the package, got %q", result.Namespace)
+		}
+
+		if result.Duration <= 0 {
+			t.Errorf("expected the duration of the test to be recorded")
+		}
+	}
+
+	runner.RunFilter = "allow"
+	results, err = runner.Run(ctx)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results of the tests that match the filter, got %d", len(results))
+	}
+
+	for _, result := range results {
+		if len(result.Failures) > 0 {
+			t.Errorf("unexpected failure of %s", result.Queries[0].Query)
+		}
+	}
+
+	runner.RunFilter = "deny"
+	results, err = runner.Run(ctx)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(results) != 1 || len(results[0].Failures) != 1 {
+		t.Fatalf("expected the failure of the test that matches the filter, got %v", results)
+	}
+
+	if results[0].Failures[0].Rule != "test_deny" {
+		t.Errorf("expected the failure of test_deny, got %q", results[0].Failures[0].Rule)
+	}
+}
+
+func TestVerifyRunnerFailFast(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(policyDir)
+
+	tests := "package main\ntest_a { false }\ntest_b { false }\ntest_c { true }\n"
+	if err := ioutil.WriteFile(filepath.Join(policyDir, "main_test.rego"), []byte(tests), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	runner := VerifyRunner{Policy: []string{policyDir}, FailFast: true}
+	results, err := runner.Run(ctx)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(results) != 1 || len(results[0].Failures) != 1 {
+		t.Errorf("expected to stop at the first failure, got %v", results)
+	}
+}
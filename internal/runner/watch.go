@@ -0,0 +1,181 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/policy"
+)
+
+// watchDebounce is the duration to wait for further changes before the
+// policies are evaluated again, as saving a single file often results in
+// several events.
+const watchDebounce = 250 * time.Millisecond
+
+// Watch evaluates the policies against the given list of configuration files,
+// and evaluates them again every time the policies, the data or the
+// configuration files change, until the context is cancelled. The results of
+// every evaluation, or the error that prevented it, are passed to report.
+//
+// The policies and data are only loaded again when they have changed.
+func (t *TestRunner) Watch(ctx context.Context, fileList []string, report func([]output.CheckResult, error)) error {
+	for _, file := range fileList {
+		if file == "-" {
+			return fmt.Errorf("standard input can not be watched")
+		}
+	}
+
+	if err := t.update(ctx); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Bundles and documents that are fetched over HTTP are only fetched
+	// again when the policies are loaded again, as they can not be watched.
+	var policyPaths []string
+	for _, policyPath := range t.Policy {
+		if !parser.IsURL(policyPath) {
+			policyPaths = append(policyPaths, policyPath)
+		}
+	}
+
+	for _, dataPath := range t.Data {
+		dataPath = policy.DataPath(dataPath)
+		if !parser.IsURL(dataPath) {
+			policyPaths = append(policyPaths, dataPath)
+		}
+	}
+
+	// Likewise, configurations that are fetched over HTTP are fetched again
+	// whenever the other files change.
+	var inputPaths []string
+	for _, file := range fileList {
+		if !parser.IsURL(file) {
+			inputPaths = append(inputPaths, globBase(file))
+		}
+	}
+
+	for _, path := range append(policyPaths, inputPaths...) {
+		if err := watchPath(watcher, path); err != nil {
+			return fmt.Errorf("watch %s: %w", path, err)
+		}
+	}
+
+	engine, err := t.LoadEngine(ctx)
+	if err != nil {
+		report(nil, err)
+	} else {
+		report(t.evaluate(ctx, engine, fileList))
+	}
+
+	var pending <-chan time.Time
+	var reload bool
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err := <-watcher.Errors:
+			return fmt.Errorf("watch: %w", err)
+
+		case event := <-watcher.Events:
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			policyChanged := within(policyPaths, event.Name)
+			if !policyChanged && !within(inputPaths, event.Name) {
+				continue
+			}
+
+			// Directories that are created after the watch started are not
+			// watched yet. The path may already be gone, which is not an error.
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					_ = watchPath(watcher, event.Name)
+				}
+			}
+
+			reload = reload || policyChanged
+			pending = time.After(watchDebounce)
+
+		case <-pending:
+			pending = nil
+
+			// When the policies could not be loaded previously, they are loaded
+			// again on any change, as there is no engine to evaluate them with.
+			if reload || engine == nil {
+				reload = false
+
+				engine, err = t.LoadEngine(ctx)
+				if err != nil {
+					report(nil, err)
+					continue
+				}
+			}
+
+			report(t.evaluate(ctx, engine, fileList))
+		}
+	}
+}
+
+// watchPath adds the given path to the watcher. Directories are watched
+// recursively. Files are watched through the directory that contains them,
+// as editors commonly save files by replacing them.
+func watchPath(watcher *fsnotify.Watcher, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("get file info: %w", err)
+	}
+
+	if !info.IsDir() {
+		return watcher.Add(filepath.Dir(path))
+	}
+
+	walk := func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("walk path: %w", err)
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		return watcher.Add(currentPath)
+	}
+
+	return filepath.Walk(path, walk)
+}
+
+// within returns true if the given path is one of the given paths,
+// or is inside one of them.
+func within(paths []string, path string) bool {
+	for _, root := range paths {
+		relativePath, err := filepath.Rel(filepath.Clean(root), filepath.Clean(path))
+		if err != nil {
+			continue
+		}
+
+		if relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+func TestWatch(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftestwatch")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	policyDir := filepath.Join(directory, "policy")
+	if err := os.MkdirAll(policyDir, os.ModePerm); err != nil {
+		t.Fatalf("create dir: %v", err)
+	}
+
+	writeFile := func(path string, contents string) {
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	policyFile := filepath.Join(policyDir, "policy.rego")
+	writeFile(policyFile, `package main
+deny[msg] { input.replicas < 2; msg := "too few replicas" }`)
+
+	configFile := filepath.Join(directory, "config.json")
+	writeFile(configFile, `{"replicas": 1}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type report struct {
+		results []output.CheckResult
+		err     error
+	}
+	reports := make(chan report)
+
+	runner := TestRunner{Policy: []string{policyDir}, Namespace: []string{"main"}}
+	done := make(chan error)
+	go func() {
+		done <- runner.Watch(ctx, []string{configFile}, func(results []output.CheckResult, err error) {
+			select {
+			case reports <- report{results: results, err: err}:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	next := func() []output.CheckResult {
+		select {
+		case r := <-reports:
+			if r.err != nil {
+				t.Fatalf("unexpected error: %v", r.err)
+			}
+
+			return r.results
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for results")
+		}
+
+		return nil
+	}
+
+	if failures := len(next()[0].Failures); failures != 1 {
+		t.Fatalf("expected 1 failure on the first run, got %v", failures)
+	}
+
+	writeFile(configFile, `{"replicas": 3}`)
+	if failures := len(next()[0].Failures); failures != 0 {
+		t.Fatalf("expected no failures after the configuration changed, got %v", failures)
+	}
+
+	writeFile(policyFile, `package main
+deny[msg] { input.replicas < 5; msg := "too few replicas" }`)
+	if failures := len(next()[0].Failures); failures != 1 {
+		t.Fatalf("expected 1 failure after the policy changed, got %v", failures)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error when stopping the watch: %v", err)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		paths    []string
+		path     string
+		expected bool
+	}{
+		{paths: []string{"policy"}, path: "policy/deny.rego", expected: true},
+		{paths: []string{"policy"}, path: "./policy", expected: true},
+		{paths: []string{"policy"}, path: "policyfile.rego", expected: false},
+		{paths: []string{"config.yaml"}, path: "./config.yaml", expected: true},
+		{paths: []string{"config.yaml"}, path: "other.yaml", expected: false},
+		{paths: []string{"nested/config.yaml"}, path: "nested/other.yaml", expected: false},
+	}
+
+	for _, tt := range tests {
+		if actual := within(tt.paths, tt.path); actual != tt.expected {
+			t.Errorf("within(%v, %q) = %v, expected %v", tt.paths, tt.path, actual, tt.expected)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/open-policy-agent/conftest/internal/commands"
@@ -8,6 +9,11 @@ import (
 
 func main() {
 	if err := commands.NewDefaultCommand().Execute(); err != nil {
+		var exitErr *commands.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,80 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// The defined coverage formats are the formats that
+// a coverage report can be written in.
+const (
+	CoverageText = "text"
+	CoverageJSON = "json"
+)
+
+// CoverageReport reports which lines and rules of the
+// policies were evaluated.
+type CoverageReport struct {
+	CoveredLines int            `json:"covered_lines"`
+	TotalLines   int            `json:"total_lines"`
+	CoveredRules int            `json:"covered_rules"`
+	TotalRules   int            `json:"total_rules"`
+	Rules        []RuleCoverage `json:"rules"`
+}
+
+// RuleCoverage reports whether a single definition of a
+// rule produced a result for any of the inputs.
+type RuleCoverage struct {
+	Namespace string `json:"namespace"`
+	Rule      string `json:"rule"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Covered   bool   `json:"covered"`
+}
+
+// WriteCoverage writes the coverage report to the writer in the given format.
+func WriteCoverage(w io.Writer, report CoverageReport, format string) error {
+	switch format {
+	case CoverageJSON:
+		if report.Rules == nil {
+			report.Rules = []RuleCoverage{}
+		}
+
+		b, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			return fmt.Errorf("marshal coverage: %w", err)
+		}
+
+		fmt.Fprintln(w, string(b))
+	case CoverageText:
+		fmt.Fprintf(w, "Rules: %d/%d covered (%s)\n", report.CoveredRules, report.TotalRules, percentage(report.CoveredRules, report.TotalRules))
+		fmt.Fprintf(w, "Lines: %d/%d covered (%s)\n", report.CoveredLines, report.TotalLines, percentage(report.CoveredLines, report.TotalLines))
+
+		var printedHeader bool
+		for _, rule := range report.Rules {
+			if rule.Covered {
+				continue
+			}
+
+			if !printedHeader {
+				fmt.Fprintln(w, "\nRules that were not covered:")
+				printedHeader = true
+			}
+
+			fmt.Fprintf(w, "%s:%d: %s\n", rule.File, rule.Line, getRuleID(rule.Namespace, rule.Rule))
+		}
+	default:
+		return fmt.Errorf("unknown coverage format %q, valid formats are: %v", format, []string{CoverageText, CoverageJSON})
+	}
+
+	return nil
+}
+
+func percentage(covered int, total int) string {
+	if total == 0 {
+		return "100.00%"
+	}
+
+	return fmt.Sprintf("%.2f%%", float64(covered)/float64(total)*100)
+}
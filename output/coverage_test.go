@@ -0,0 +1,84 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteCoverage(t *testing.T) {
+	report := CoverageReport{
+		CoveredLines: 3,
+		TotalLines:   4,
+		CoveredRules: 1,
+		TotalRules:   2,
+		Rules: []RuleCoverage{
+			{Namespace: "main", Rule: "deny", File: "policy/deny.rego", Line: 3, Covered: true},
+			{Namespace: "main", Rule: "warn", File: "policy/deny.rego", Line: 8, Covered: false},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		expected []string
+	}{
+		{
+			name:   "text",
+			format: CoverageText,
+			expected: []string{
+				"Rules: 1/2 covered (50.00%)",
+				"Lines: 3/4 covered (75.00%)",
+				"",
+				"Rules that were not covered:",
+				"policy/deny.rego:8: main.warn",
+				"",
+			},
+		},
+		{
+			name:   "json",
+			format: CoverageJSON,
+			expected: []string{
+				`{`,
+				`	"covered_lines": 3,`,
+				`	"total_lines": 4,`,
+				`	"covered_rules": 1,`,
+				`	"total_rules": 2,`,
+				`	"rules": [`,
+				`		{`,
+				`			"namespace": "main",`,
+				`			"rule": "deny",`,
+				`			"file": "policy/deny.rego",`,
+				`			"line": 3,`,
+				`			"covered": true`,
+				`		},`,
+				`		{`,
+				`			"namespace": "main",`,
+				`			"rule": "warn",`,
+				`			"file": "policy/deny.rego",`,
+				`			"line": 8,`,
+				`			"covered": false`,
+				`		}`,
+				`	]`,
+				`}`,
+				``,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := strings.Join(tt.expected, "\n")
+
+			buf := new(bytes.Buffer)
+			if err := WriteCoverage(buf, report, tt.format); err != nil {
+				t.Fatal("write coverage:", err)
+			}
+			actual := buf.String()
+
+			if expected != actual {
+				t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// CSV represents an Outputter that outputs
+// results in CSV format.
+type CSV struct {
+	Writer io.Writer
+}
+
+// NewCSV creates a new CSV with the given writer.
+func NewCSV(w io.Writer) *CSV {
+	csv := CSV{
+		Writer: w,
+	}
+
+	return &csv
+}
+
+// Output outputs the results. Each result is written as a single row,
+// and the rows of each check result are written as soon as they are formatted.
+func (c *CSV) Output(checkResults []CheckResult) error {
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.Write([]string{"filename", "namespace", "rule", "severity", "message"}); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	for _, checkResult := range checkResults {
+		var rows [][]string
+		for _, failure := range checkResult.Failures {
+			rows = append(rows, []string{checkResult.FileName, checkResult.Namespace, failure.Rule, failure.label("failure"), failure.Message})
+		}
+
+		for _, warning := range checkResult.Warnings {
+			rows = append(rows, []string{checkResult.FileName, checkResult.Namespace, warning.Rule, warning.label("warning"), warning.Message})
+		}
+
+		for _, exception := range checkResult.Exceptions {
+			rows = append(rows, []string{checkResult.FileName, checkResult.Namespace, exception.Rule, "exception", exception.Message})
+		}
+
+		if err := writer.WriteAll(rows); err != nil {
+			return fmt.Errorf("write rows: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+
+	return nil
+}
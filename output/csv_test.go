@@ -0,0 +1,80 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []CheckResult
+		expected []string
+	}{
+		{
+			name: "no warnings or errors",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+				},
+			},
+			expected: []string{
+				"filename,namespace,rule,severity,message",
+				"",
+			},
+		},
+		{
+			name: "records failures, warnings and exceptions",
+			input: []CheckResult{
+				{
+					FileName:   "examples/kubernetes/service.yaml",
+					Namespace:  "namespace",
+					Warnings:   []Result{{Message: "first warning", Rule: "warn"}},
+					Failures:   []Result{{Message: "first failure", Rule: "deny"}},
+					Exceptions: []Result{{Message: "first exception", Rule: "deny_foo"}},
+				},
+			},
+			expected: []string{
+				"filename,namespace,rule,severity,message",
+				"examples/kubernetes/service.yaml,namespace,deny,failure,first failure",
+				"examples/kubernetes/service.yaml,namespace,warn,warning,first warning",
+				"examples/kubernetes/service.yaml,namespace,deny_foo,exception,first exception",
+				"",
+			},
+		},
+		{
+			name: "quotes multiline messages",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+					Failures:  []Result{{Message: "first line\nsecond, \"quoted\" line", Rule: "deny"}},
+				},
+			},
+			expected: []string{
+				"filename,namespace,rule,severity,message",
+				"examples/kubernetes/service.yaml,namespace,deny,failure,\"first line",
+				"second, \"\"quoted\"\" line\"",
+				"",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := strings.Join(tt.expected, "\n")
+
+			buf := new(bytes.Buffer)
+			if err := NewCSV(buf).Output(tt.input); err != nil {
+				t.Fatal("output csv:", err)
+			}
+			actual := buf.String()
+
+			if expected != actual {
+				t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// isUnifiedDiff reports whether the given message looks like a unified diff,
+// e.g. the diff between the expected and actual configurations that a policy
+// returns, which has a hunk header or a pair of file headers.
+func isUnifiedDiff(message string) bool {
+	lines := strings.Split(message, "\n")
+	if len(lines) < 2 {
+		return false
+	}
+
+	var oldFile bool
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "@@ ") && strings.Contains(line[3:], " @@"):
+			return true
+		case strings.HasPrefix(line, "--- "):
+			oldFile = true
+		case strings.HasPrefix(line, "+++ ") && oldFile:
+			return true
+		}
+	}
+
+	return false
+}
+
+// colorizeMessage colors the lines of the given message when it is a unified
+// diff, where the additions are green, the removals are red and the hunk
+// headers are cyan. Other messages are returned as is.
+func colorizeMessage(message string, colorizer aurora.Aurora) string {
+	if !isUnifiedDiff(message) {
+		return message
+	}
+
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- "):
+			lines[i] = colorizer.Bold(line).String()
+		case strings.HasPrefix(line, "+"):
+			lines[i] = colorizer.Colorize(line, aurora.GreenFg).String()
+		case strings.HasPrefix(line, "-"):
+			lines[i] = colorizer.Colorize(line, aurora.RedFg).String()
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = colorizer.Colorize(line, aurora.CyanFg).String()
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/logrusorgru/aurora"
+)
+
+func TestIsUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		expected bool
+	}{
+		{
+			name:     "hunk",
+			message:  "@@ -1,2 +1,2 @@\n replicas:\n-  2\n+  3",
+			expected: true,
+		},
+		{
+			name:     "file headers",
+			message:  "--- expected\n+++ actual\n-replicas: 2\n+replicas: 3",
+			expected: true,
+		},
+		{
+			name:     "single line",
+			message:  "@@ -1 +1 @@",
+			expected: false,
+		},
+		{
+			name:     "list",
+			message:  "missing labels:\n- app\n- release",
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := isUnifiedDiff(testCase.message); actual != testCase.expected {
+				t.Errorf("Unexpected result. expected %v, got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestColorizeMessage(t *testing.T) {
+	message := "--- expected\n+++ actual\n@@ -1 +1 @@\n-replicas: 2\n+replicas: 3"
+
+	if actual := colorizeMessage(message, aurora.NewAurora(false)); actual != message {
+		t.Errorf("Expected the message to not be colored, got %q", actual)
+	}
+
+	colored := colorizeMessage(message, aurora.NewAurora(true))
+	lines := strings.Split(colored, "\n")
+	expected := []string{
+		aurora.Bold("--- expected").String(),
+		aurora.Bold("+++ actual").String(),
+		aurora.Cyan("@@ -1 +1 @@").String(),
+		aurora.Red("-replicas: 2").String(),
+		aurora.Green("+replicas: 3").String(),
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Unexpected line %d. expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+
+	if actual := colorizeMessage("missing labels:\n- app", aurora.NewAurora(true)); actual != "missing labels:\n- app" {
+		t.Errorf("Expected a message that is not a diff to be returned as is, got %q", actual)
+	}
+}
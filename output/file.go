@@ -0,0 +1,77 @@
+package output
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// File represents an Outputter that writes the results in
+// a given format to a file, instead of writing them to stdout.
+type File struct {
+	Path    string
+	Format  string
+	Options Options
+
+	// Echo is an Outputter that the results are also output to
+	// after they are written to the file, e.g. a Summary on stdout.
+	Echo Outputter
+}
+
+// NewFile creates a new File that writes the results in the given format to
+// the file at the given path. An error is returned when the format is a
+// template that cannot be parsed.
+func NewFile(path string, format string, options Options) (*File, error) {
+	validateOptions := options
+	validateOptions.Writer = ioutil.Discard
+	if _, err := New(format, validateOptions); err != nil {
+		return nil, fmt.Errorf("new outputter: %w", err)
+	}
+
+	file := File{
+		Path:    path,
+		Format:  format,
+		Options: options,
+	}
+
+	return &file, nil
+}
+
+// Output outputs the results. The file is created, along with its parent
+// directories, every time the results are output, so that the file only ever
+// contains the results of the last output.
+func (f *File) Output(results []CheckResult) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), os.ModePerm); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	file, err := os.Create(f.Path)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer file.Close()
+
+	options := f.Options
+	options.Writer = file
+	outputter, err := New(f.Format, options)
+	if err != nil {
+		return fmt.Errorf("new outputter: %w", err)
+	}
+
+	if err := outputter.Output(results); err != nil {
+		return fmt.Errorf("output to file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
+
+	if f.Echo != nil {
+		if err := f.Echo.Output(results); err != nil {
+			return fmt.Errorf("echo: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatal("create temp dir:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "reports", "results.json")
+	file, err := NewFile(path, OutputJSON, Options{NoColor: true})
+	if err != nil {
+		t.Fatal("new file:", err)
+	}
+
+	summary := new(bytes.Buffer)
+	file.Echo = &Summary{Writer: summary, NoColor: true}
+
+	// The results of each output replace the results of the previous
+	// output, rather than being appended to the file.
+	for _, failures := range [][]Result{{{Message: "first failure"}}, {{Message: "second failure"}}} {
+		results := []CheckResult{{FileName: "deployment.yaml", Namespace: "main", Successes: 1, Failures: failures}}
+		if err := file.Output(results); err != nil {
+			t.Fatal("output:", err)
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal("read file:", err)
+		}
+
+		var written []CheckResult
+		if err := json.Unmarshal(contents, &written); err != nil {
+			t.Fatal("unmarshal results:", err)
+		}
+
+		if !reflect.DeepEqual(written, results) {
+			t.Errorf("Unexpected results in file. expected %v actual %v", results, written)
+		}
+	}
+
+	expected := "2 tests, 1 passed, 0 warnings, 1 failure, 0 exceptions\n2 tests, 1 passed, 0 warnings, 1 failure, 0 exceptions\n"
+	if summary.String() != expected {
+		t.Errorf("Unexpected summary. expected %q actual %q", expected, summary.String())
+	}
+}
+
+func TestNewFileInvalidTemplate(t *testing.T) {
+	if _, err := NewFile("results.txt", OutputTemplate+"={{.Unclosed", Options{}); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GitHub represents an Outputter that outputs results as GitHub Actions
+// workflow commands, which GitHub shows as annotations on the files.
+type GitHub struct {
+	Writer io.Writer
+}
+
+// NewGitHub creates a new GitHub with the given writer.
+func NewGitHub(w io.Writer) *GitHub {
+	github := GitHub{
+		Writer: w,
+	}
+
+	return &github
+}
+
+// Output outputs the results. Failures are reported as errors and warnings
+// as warnings, on the line of the file that produced them when it is known.
+func (g *GitHub) Output(checkResults []CheckResult) error {
+	for _, checkResult := range checkResults {
+		for _, failure := range checkResult.Failures {
+			fmt.Fprintln(g.Writer, githubCommand("error", checkResult, failure))
+		}
+
+		// Informational results are reported as notices rather than warnings.
+		for _, warning := range checkResult.Warnings {
+			command := "warning"
+			if warning.Severity == "info" {
+				command = "notice"
+			}
+
+			fmt.Fprintln(g.Writer, githubCommand(command, checkResult, warning))
+		}
+	}
+
+	return nil
+}
+
+func githubCommand(command string, checkResult CheckResult, result Result) string {
+	var properties []string
+
+	// Results that originate from standard input do not have a file
+	// that can be annotated.
+	if checkResult.FileName != "" && checkResult.FileName != "-" {
+		properties = append(properties, "file="+escapeGitHubProperty(checkResult.FileName))
+
+		if result.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", result.Line))
+		}
+
+		if result.Column > 0 {
+			properties = append(properties, fmt.Sprintf("col=%d", result.Column))
+		}
+	}
+
+	title := getRuleID(checkResult.Namespace, result.Rule)
+	if result.Annotations != nil && result.Annotations.Title != "" {
+		title = result.Annotations.Title
+	}
+
+	if title != "" {
+		properties = append(properties, "title="+escapeGitHubProperty(title))
+	}
+
+	if len(properties) == 0 {
+		return fmt.Sprintf("::%s::%s", command, escapeGitHubData(result.Message))
+	}
+
+	return fmt.Sprintf("::%s %s::%s", command, strings.Join(properties, ","), escapeGitHubData(result.Message))
+}
+
+// escapeGitHubData escapes the message of a workflow command,
+// so that messages can span multiple lines.
+func escapeGitHubData(data string) string {
+	data = strings.ReplaceAll(data, "%", "%25")
+	data = strings.ReplaceAll(data, "\r", "%0D")
+	return strings.ReplaceAll(data, "\n", "%0A")
+}
+
+// escapeGitHubProperty escapes the value of a property of a workflow
+// command, which additionally must not contain colons and commas.
+func escapeGitHubProperty(property string) string {
+	property = escapeGitHubData(property)
+	property = strings.ReplaceAll(property, ":", "%3A")
+	return strings.ReplaceAll(property, ",", "%2C")
+}
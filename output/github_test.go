@@ -0,0 +1,79 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGitHub(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []CheckResult
+		expected []string
+	}{
+		{
+			name: "no warnings or errors",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+				},
+			},
+			expected: []string{""},
+		},
+		{
+			name: "records failures and warnings",
+			input: []CheckResult{
+				{
+					FileName:   "examples/kubernetes/service.yaml",
+					Namespace:  "namespace",
+					Warnings:   []Result{{Message: "first warning", Rule: "warn"}},
+					Failures:   []Result{{Message: "first failure", Rule: "deny", Line: 4, Column: 3}},
+					Exceptions: []Result{{Message: "first exception", Rule: "deny_foo"}},
+				},
+			},
+			expected: []string{
+				"::error file=examples/kubernetes/service.yaml,line=4,col=3,title=namespace.deny::first failure",
+				"::warning file=examples/kubernetes/service.yaml,title=namespace.warn::first warning",
+				"",
+			},
+		},
+		{
+			name: "escapes messages and uses the title of annotated rules",
+			input: []CheckResult{
+				{
+					FileName:  "-",
+					Namespace: "namespace",
+					Failures: []Result{
+						{
+							Message:     "100% of\nthe replicas",
+							Rule:        "deny",
+							Annotations: &Annotations{Title: "Replicas: minimum, maximum"},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"::error title=Replicas%3A minimum%2C maximum::100%25 of%0Athe replicas",
+				"",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := strings.Join(tt.expected, "\n")
+
+			buf := new(bytes.Buffer)
+			if err := NewGitHub(buf).Output(tt.input); err != nil {
+				t.Fatal("output github:", err)
+			}
+			actual := buf.String()
+
+			if expected != actual {
+				t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+			}
+		})
+	}
+}
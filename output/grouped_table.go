@@ -0,0 +1,97 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/olekukonko/tablewriter"
+)
+
+// GroupedTable represents an Outputter that outputs results in a
+// tabular format, with a table and a summary for each file, followed
+// by the summary of all of the files.
+type GroupedTable struct {
+	Writer io.Writer
+
+	// NoColor will disable all coloring when
+	// set to true.
+	NoColor bool
+}
+
+// NewGroupedTable creates a new GroupedTable with the given writer.
+func NewGroupedTable(w io.Writer) *GroupedTable {
+	groupedTable := GroupedTable{
+		Writer: w,
+	}
+
+	return &groupedTable
+}
+
+// Output outputs the results.
+func (t *GroupedTable) Output(checkResults []CheckResult) error {
+	colorizer := aurora.NewAurora(!t.NoColor)
+
+	// The results of every namespace of a file are grouped together,
+	// in the order that the files first appear in the results.
+	var fileNames []string
+	files := make(map[string][]CheckResult)
+	for _, checkResult := range checkResults {
+		if _, ok := files[checkResult.FileName]; !ok {
+			fileNames = append(fileNames, checkResult.FileName)
+		}
+
+		files[checkResult.FileName] = append(files[checkResult.FileName], checkResult)
+	}
+
+	var totalSuccesses, totalWarnings, totalFailures, totalExceptions int
+	for _, fileName := range fileNames {
+		fmt.Fprintln(t.Writer, colorizer.Bold(fileName))
+
+		table := tablewriter.NewWriter(t.Writer)
+		table.SetHeader([]string{"result", "namespace", "message"})
+
+		var successes, warnings, failures, exceptions int
+		for _, checkResult := range files[fileName] {
+			for _, result := range checkResult.Exceptions {
+				table.Append([]string{colorizer.Colorize("exception", aurora.CyanFg).String(), checkResult.Namespace, result.Message})
+			}
+
+			for _, result := range checkResult.Warnings {
+				table.Append([]string{colorizer.Colorize(result.label("warning"), aurora.YellowFg).String(), checkResult.Namespace, result.Message})
+			}
+
+			for _, result := range checkResult.Failures {
+				table.Append([]string{colorizer.Colorize(result.label("failure"), aurora.RedFg).String(), checkResult.Namespace, result.Message})
+			}
+
+			successes += checkResult.Successes
+			warnings += len(checkResult.Warnings)
+			failures += len(checkResult.Failures)
+			exceptions += len(checkResult.Exceptions)
+		}
+
+		if table.NumLines() > 0 {
+			table.Render()
+		}
+
+		summary, color := summarize(successes, warnings, failures, exceptions)
+		fmt.Fprintln(t.Writer, colorizer.Colorize(summary, color))
+		fmt.Fprintln(t.Writer)
+
+		totalSuccesses += successes
+		totalWarnings += warnings
+		totalFailures += failures
+		totalExceptions += exceptions
+	}
+
+	var pluralSuffixFiles string
+	if len(fileNames) != 1 {
+		pluralSuffixFiles = "s"
+	}
+
+	summary, color := summarize(totalSuccesses, totalWarnings, totalFailures, totalExceptions)
+	fmt.Fprintln(t.Writer, colorizer.Colorize(fmt.Sprintf("%v file%s, %s", len(fileNames), pluralSuffixFiles, summary), color))
+
+	return nil
+}
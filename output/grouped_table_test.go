@@ -0,0 +1,85 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGroupedTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []CheckResult
+		expected []string
+	}{
+		{
+			name: "No warnings or errors",
+			input: []CheckResult{
+				{FileName: "examples/kubernetes/service.yaml", Namespace: "namespace", Successes: 1},
+			},
+			expected: []string{
+				`examples/kubernetes/service.yaml`,
+				`1 test, 1 passed, 0 warnings, 0 failures, 0 exceptions`,
+				``,
+				`1 file, 1 test, 1 passed, 0 warnings, 0 failures, 0 exceptions`,
+				``,
+			},
+		},
+		{
+			name: "Multiple files and namespaces",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "main",
+					Warnings:  []Result{{Message: "first warning"}},
+					Failures:  []Result{{Message: "first failure"}},
+				},
+				{
+					FileName:  "examples/kubernetes/deployment.yaml",
+					Namespace: "main",
+					Successes: 2,
+				},
+				{
+					FileName:   "examples/kubernetes/service.yaml",
+					Namespace:  "labels",
+					Successes:  1,
+					Exceptions: []Result{{Message: "first exception"}},
+				},
+			},
+			expected: []string{
+				`examples/kubernetes/service.yaml`,
+				`+-----------+-----------+-----------------+`,
+				`|  RESULT   | NAMESPACE |     MESSAGE     |`,
+				`+-----------+-----------+-----------------+`,
+				`| warning   | main      | first warning   |`,
+				`| failure   | main      | first failure   |`,
+				`| exception | labels    | first exception |`,
+				`+-----------+-----------+-----------------+`,
+				`4 tests, 1 passed, 1 warning, 1 failure, 1 exception`,
+				``,
+				`examples/kubernetes/deployment.yaml`,
+				`2 tests, 2 passed, 0 warnings, 0 failures, 0 exceptions`,
+				``,
+				`2 files, 6 tests, 3 passed, 1 warning, 1 failure, 1 exception`,
+				``,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := strings.Join(tt.expected, "\n")
+
+			buf := new(bytes.Buffer)
+			table := &GroupedTable{Writer: buf, NoColor: true}
+			if err := table.Output(tt.input); err != nil {
+				t.Fatal("output grouped table:", err)
+			}
+			actual := buf.String()
+
+			if expected != actual {
+				t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+			}
+		})
+	}
+}
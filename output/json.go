@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 )
 
 // JSON represents an Outputter that outputs
 // results in JSON format.
 type JSON struct {
 	Writer io.Writer
+
+	// Tracing includes the traces of the queries of each
+	// file and namespace in the output.
+	Tracing bool
 }
 
 // NewJSON creates a new JSON with the given writer. 
@@ -29,9 +34,15 @@ func (j *JSON) Output(results []CheckResult) error {
 			results[r].FileName = ""
 		}
 
+		if j.Tracing {
+			results[r].Traces = queryTraces(results[r].Queries)
+		}
+
 		results[r].Queries = nil
 	}
 
+	sortCheckResults(results)
+
 	b, err := json.Marshal(results)
 	if err != nil {
 		return fmt.Errorf("marshal json: %w", err)
@@ -45,3 +56,47 @@ func (j *JSON) Output(results []CheckResult) error {
 	fmt.Fprintln(j.Writer, out.String())
 	return nil
 }
+
+// queryTraces returns the traces of the given queries that have been traced.
+func queryTraces(queries []QueryResult) []QueryTrace {
+	var traces []QueryTrace
+	for _, query := range queries {
+		if len(query.TraceEvents) == 0 {
+			continue
+		}
+
+		traces = append(traces, QueryTrace{Query: query.Query, Events: query.TraceEvents})
+	}
+
+	return traces
+}
+
+// sortCheckResults sorts the results by file name and namespace, and the
+// results of each file by rule and message, so that the output is the same
+// for every run regardless of the order in which the files were evaluated.
+// The keys of maps, such as the metadata, are always sorted when marshaled.
+func sortCheckResults(results []CheckResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].FileName != results[j].FileName {
+			return results[i].FileName < results[j].FileName
+		}
+
+		return results[i].Namespace < results[j].Namespace
+	})
+
+	for r := range results {
+		sortResults(results[r].Warnings)
+		sortResults(results[r].Failures)
+		sortResults(results[r].Exceptions)
+	}
+}
+
+func sortResults(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rule != results[j].Rule {
+			return results[i].Rule < results[j].Rule
+		}
+
+		return results[i].Message < results[j].Message
+	})
+}
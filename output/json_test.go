@@ -113,7 +113,7 @@ func TestJSON(t *testing.T) {
 			},
 		},
 		{
-			name: "Multiple files",
+			name: "Multiple files are sorted by file name",
 			input: []CheckResult{
 				{FileName: "examples/kubernetes/service.yaml", Namespace: "namespace"},
 				{FileName: "examples/kubernetes/deployment.yaml", Namespace: "namespace"},
@@ -121,12 +121,12 @@ func TestJSON(t *testing.T) {
 			expected: []string{
 				`[`,
 				`	{`,
-				`		"filename": "examples/kubernetes/service.yaml",`,
+				`		"filename": "examples/kubernetes/deployment.yaml",`,
 				`		"namespace": "namespace",`,
 				`		"successes": 0`,
 				`	},`,
 				`	{`,
-				`		"filename": "examples/kubernetes/deployment.yaml",`,
+				`		"filename": "examples/kubernetes/service.yaml",`,
 				`		"namespace": "namespace",`,
 				`		"successes": 0`,
 				`	}`,
@@ -152,3 +152,138 @@ func TestJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONIsDeterministic(t *testing.T) {
+	newResults := func() []CheckResult {
+		return []CheckResult{
+			{
+				FileName:  "service.yaml",
+				Namespace: "main",
+				Failures: []Result{
+					{Message: "second", Rule: "deny_ports"},
+					{Message: "first", Rule: "deny_ports", Metadata: map[string]interface{}{"b": 2, "a": 1, "c": 3}},
+					{Message: "labels", Rule: "deny_labels"},
+				},
+			},
+			{FileName: "deployment.yaml", Namespace: "main", Warnings: []Result{{Message: "b"}, {Message: "a"}}},
+			{FileName: "deployment.yaml", Namespace: "kubernetes", Successes: 1},
+		}
+	}
+
+	expected := `[
+	{
+		"filename": "deployment.yaml",
+		"namespace": "kubernetes",
+		"successes": 1
+	},
+	{
+		"filename": "deployment.yaml",
+		"namespace": "main",
+		"successes": 0,
+		"warnings": [
+			{
+				"msg": "a"
+			},
+			{
+				"msg": "b"
+			}
+		]
+	},
+	{
+		"filename": "service.yaml",
+		"namespace": "main",
+		"successes": 0,
+		"failures": [
+			{
+				"msg": "labels",
+				"rule": "deny_labels"
+			},
+			{
+				"msg": "first",
+				"rule": "deny_ports",
+				"metadata": {
+					"a": 1,
+					"b": 2,
+					"c": 3
+				}
+			},
+			{
+				"msg": "second",
+				"rule": "deny_ports"
+			}
+		]
+	}
+]
+`
+
+	for i := 0; i < 10; i++ {
+		results := newResults()
+
+		// The results are reversed on every other run, as the order in
+		// which the results are produced must not affect the output.
+		if i%2 == 1 {
+			for left, right := 0, len(results)-1; left < right; left, right = left+1, right-1 {
+				results[left], results[right] = results[right], results[left]
+			}
+		}
+
+		buf := new(bytes.Buffer)
+		if err := NewJSON(buf).Output(results); err != nil {
+			t.Fatal("output json:", err)
+		}
+
+		if actual := buf.String(); actual != expected {
+			t.Fatalf("Unexpected output. expected %v actual %v", expected, actual)
+		}
+	}
+}
+
+func TestJSONWithTracing(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "examples/kubernetes/service.yaml",
+			Namespace: "main",
+			Successes: 1,
+			Queries: []QueryResult{
+				{
+					Query:       "data.main.deny",
+					Traces:      []string{"Enter data.main.deny = _"},
+					TraceEvents: []TraceEvent{{Op: "Enter", Node: "data.main.deny = _"}},
+				},
+				{Query: "data.main.warn"},
+			},
+		},
+	}
+
+	expected := `[
+	{
+		"filename": "examples/kubernetes/service.yaml",
+		"namespace": "main",
+		"successes": 1,
+		"traces": [
+			{
+				"query": "data.main.deny",
+				"events": [
+					{
+						"op": "Enter",
+						"query_id": 0,
+						"parent_id": 0,
+						"node": "data.main.deny = _"
+					}
+				]
+			}
+		]
+	}
+]
+`
+
+	buf := new(bytes.Buffer)
+	jsonOutput := &JSON{Writer: buf, Tracing: true}
+	if err := jsonOutput.Output(results); err != nil {
+		t.Fatal("output json:", err)
+	}
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+	}
+}
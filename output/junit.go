@@ -31,9 +31,10 @@ func (j *JUnit) Output(results []CheckResult) error {
 	for _, result := range results {
 		for _, warning := range result.Warnings {
 			warningTest := parser.Test{
-				Name:   getTestName(result.FileName, result.Namespace, warning.Message),
-				Result: parser.FAIL,
-				Output: []string{warning.Message},
+				Name:     getTestName(result.FileName, result.Namespace, warning.Message),
+				Duration: result.Duration,
+				Result:   parser.FAIL,
+				Output:   []string{warning.Message},
 			}
 
 			tests = append(tests, &warningTest)
@@ -41,19 +42,47 @@ func (j *JUnit) Output(results []CheckResult) error {
 
 		for _, failure := range result.Failures {
 			failingTest := parser.Test{
-				Name:   getTestName(result.FileName, result.Namespace, failure.Message),
-				Result: parser.FAIL,
-				Output: []string{failure.Message},
+				Name:     getTestName(result.FileName, result.Namespace, failure.Message),
+				Duration: result.Duration,
+				Result:   parser.FAIL,
+				Output:   []string{failure.Message},
 			}
 
 			tests = append(tests, &failingTest)
 		}
 
-		for s := 0; s < result.Successes; s++ {
+		// Exceptions are policy violations that were explicitly allowed,
+		// which are reported as skipped tests.
+		for _, exception := range result.Exceptions {
+			skippedTest := parser.Test{
+				Name:     getTestName(result.FileName, result.Namespace, exception.Message),
+				Duration: result.Duration,
+				Result:   parser.SKIP,
+				Output:   []string{exception.Message},
+			}
+
+			tests = append(tests, &skippedTest)
+		}
+
+		// The rules that passed are named after the rules when they are
+		// reported, and the other successes are added as unnamed tests.
+		for _, pass := range result.Passes {
+			passedTest := parser.Test{
+				Name:     getTestName(result.FileName, result.Namespace, pass.Rule),
+				Duration: result.Duration,
+				Result:   parser.PASS,
+				Output:   []string{},
+			}
+
+			tests = append(tests, &passedTest)
+		}
+
+		for s := len(result.Passes); s < result.Successes; s++ {
 			successfulTest := parser.Test{
-				Name:   getTestName(result.FileName, result.Namespace, ""),
-				Result: parser.PASS,
-				Output: []string{},
+				Name:     getTestName(result.FileName, result.Namespace, ""),
+				Duration: result.Duration,
+				Result:   parser.PASS,
+				Output:   []string{},
 			}
 
 			tests = append(tests, &successfulTest)
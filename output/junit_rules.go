@@ -0,0 +1,130 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/formatter"
+	"github.com/jstemmer/go-junit-report/parser"
+)
+
+// JUnitRules represents an Outputter that outputs results in JUnit format,
+// with a test case for each rule of each file, where the class name of the
+// test cases is the namespace of the rules.
+type JUnitRules struct {
+	Writer io.Writer
+}
+
+// NewJUnitRules creates a new JUnitRules with the given writer.
+func NewJUnitRules(w io.Writer) *JUnitRules {
+	jUnitRules := JUnitRules{
+		Writer: w,
+	}
+
+	return &jUnitRules
+}
+
+// Output outputs the results.
+func (j *JUnitRules) Output(results []CheckResult) error {
+	var namespaces []string
+	packages := make(map[string]*parser.Package)
+	for _, result := range results {
+		pkg, ok := packages[result.Namespace]
+		if !ok {
+			pkg = &parser.Package{Name: result.Namespace}
+			packages[result.Namespace] = pkg
+			namespaces = append(namespaces, result.Namespace)
+		}
+
+		pkg.Tests = append(pkg.Tests, ruleTests(result)...)
+	}
+
+	var report parser.Report
+	for _, namespace := range namespaces {
+		report.Packages = append(report.Packages, *packages[namespace])
+	}
+
+	if err := formatter.JUnitReportXML(&report, false, runtime.Version(), j.Writer); err != nil {
+		return fmt.Errorf("format junit: %w", err)
+	}
+
+	return nil
+}
+
+// ruleTests returns a test for each rule of the given result. The failures
+// and warnings of a rule are a failed test with all of their messages, the
+// exceptions are skipped tests, and the other rules that were evaluated are
+// passed tests. The tests are sorted by their names.
+func ruleTests(result CheckResult) []*parser.Test {
+	var tests []*parser.Test
+	testsByName := make(map[string]*parser.Test)
+	addTest := func(name string, testResult parser.Result, message string) {
+		if test, ok := testsByName[name]; ok {
+			test.Output = append(test.Output, message)
+			return
+		}
+
+		test := parser.Test{Name: name, Result: testResult, Output: []string{message}}
+		testsByName[name] = &test
+		tests = append(tests, &test)
+	}
+
+	for _, failure := range result.Failures {
+		addTest(ruleTestName(result.FileName, failure), parser.FAIL, failure.Message)
+	}
+
+	for _, warning := range result.Warnings {
+		addTest(ruleTestName(result.FileName, warning), parser.FAIL, warning.Message)
+	}
+
+	for _, exception := range result.Exceptions {
+		addTest(ruleTestName(result.FileName, exception), parser.SKIP, exception.Message)
+	}
+
+	// The rules that passed are the rules that were evaluated, without any
+	// results for them. When the rules are not known, a passed test is
+	// added for each success of the file instead.
+	var passed int
+	for _, rule := range result.ruleNames() {
+		name := fmt.Sprintf("%s - %s", result.FileName, rule)
+		if _, ok := testsByName[name]; ok {
+			continue
+		}
+
+		test := parser.Test{Name: name, Result: parser.PASS, Output: []string{}}
+		testsByName[name] = &test
+		tests = append(tests, &test)
+		passed++
+	}
+
+	if passed == 0 {
+		for s := 0; s < result.Successes; s++ {
+			tests = append(tests, &parser.Test{Name: result.FileName, Result: parser.PASS, Output: []string{}})
+		}
+	}
+
+	// Only the unit tests of the verify command record their duration, in
+	// which case the result has the test as its only rule.
+	for _, test := range tests {
+		test.Duration = result.Duration
+	}
+
+	// The rules are evaluated in no particular order, so the tests are
+	// sorted for the report to be the same for the same results.
+	sort.SliceStable(tests, func(i, k int) bool {
+		return tests[i].Name < tests[k].Name
+	})
+
+	return tests
+}
+
+func ruleTestName(fileName string, result Result) string {
+	if result.Rule == "" {
+		return fmt.Sprintf("%s - %s", fileName, strings.Split(result.Message, "\n")[0])
+	}
+
+	return fmt.Sprintf("%s - %s", fileName, result.Rule)
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestJUnitRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []CheckResult
+		expected []string
+	}{
+		{
+			name: "Failures of the same rule",
+			input: []CheckResult{
+				{
+					FileName:  "deployment.yaml",
+					Namespace: "main",
+					Failures: []Result{
+						{Message: "first failure", Rule: "deny"},
+						{Message: "second failure", Rule: "deny"},
+					},
+					Warnings: []Result{{Message: "first warning", Rule: "warn"}},
+					Rules: []EvaluatedRule{
+						{Name: "deny", Outcome: OutcomeFail},
+						{Name: "deny_labels", Outcome: OutcomePass},
+						{Name: "warn", Outcome: OutcomeWarn},
+					},
+				},
+			},
+			expected: []string{
+				`<?xml version="1.0" encoding="UTF-8"?>`,
+				`<testsuites>`,
+				`	<testsuite tests="3" failures="2" time="0.000" name="main">`,
+				`		<properties>`,
+				`			<property name="go.version" value="%s"></property>`,
+				`		</properties>`,
+				`		<testcase classname="main" name="deployment.yaml - deny" time="0.000">`,
+				`			<failure message="Failed" type="">first failure&#xA;second failure</failure>`,
+				`		</testcase>`,
+				`		<testcase classname="main" name="deployment.yaml - deny_labels" time="0.000"></testcase>`,
+				`		<testcase classname="main" name="deployment.yaml - warn" time="0.000">`,
+				`			<failure message="Failed" type="">first warning</failure>`,
+				`		</testcase>`,
+				`	</testsuite>`,
+				`</testsuites>`,
+				``,
+			},
+		},
+		{
+			name: "Namespaces and passing files",
+			input: []CheckResult{
+				{
+					FileName:   "deployment.yaml",
+					Namespace:  "main",
+					Exceptions: []Result{{Message: "exception", Rule: "deny_labels"}},
+				},
+				{
+					FileName:  "service.yaml",
+					Namespace: "labels",
+					Successes: 1,
+				},
+			},
+			expected: []string{
+				`<?xml version="1.0" encoding="UTF-8"?>`,
+				`<testsuites>`,
+				`	<testsuite tests="1" failures="0" time="0.000" name="main">`,
+				`		<properties>`,
+				`			<property name="go.version" value="%[1]s"></property>`,
+				`		</properties>`,
+				`		<testcase classname="main" name="deployment.yaml - deny_labels" time="0.000">`,
+				`			<skipped message="exception"></skipped>`,
+				`		</testcase>`,
+				`	</testsuite>`,
+				`	<testsuite tests="1" failures="0" time="0.000" name="labels">`,
+				`		<properties>`,
+				`			<property name="go.version" value="%[1]s"></property>`,
+				`		</properties>`,
+				`		<testcase classname="labels" name="service.yaml" time="0.000"></testcase>`,
+				`	</testsuite>`,
+				`</testsuites>`,
+				``,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := fmt.Sprintf(strings.Join(tt.expected, "\n"), runtime.Version())
+
+			buf := new(bytes.Buffer)
+			if err := NewJUnitRules(buf).Output(tt.input); err != nil {
+				t.Fatal("output junit:", err)
+			}
+			actual := buf.String()
+
+			if expected != actual {
+				t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+			}
+		})
+	}
+}
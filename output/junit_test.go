@@ -62,6 +62,54 @@ func TestJUnit(t *testing.T) {
 				``,
 			},
 		},
+		{
+			name: "An exception",
+			input: []CheckResult{
+				{
+					FileName:   "examples/kubernetes/service.yaml",
+					Namespace:  "namespace",
+					Exceptions: []Result{{Message: "first exception"}},
+				},
+			},
+			expected: []string{
+				`<?xml version="1.0" encoding="UTF-8"?>`,
+				`<testsuites>`,
+				`	<testsuite tests="1" failures="0" time="0.000" name="conftest">`,
+				`		<properties>`,
+				`			<property name="go.version" value="%s"></property>`,
+				`		</properties>`,
+				`		<testcase classname="conftest" name="examples/kubernetes/service.yaml - namespace - first exception" time="0.000">`,
+				`			<skipped message="first exception"></skipped>`,
+				`		</testcase>`,
+				`	</testsuite>`,
+				`</testsuites>`,
+				``,
+			},
+		},
+		{
+			name: "Passes",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+					Successes: 2,
+					Passes:    []Result{{Rule: "deny"}},
+				},
+			},
+			expected: []string{
+				`<?xml version="1.0" encoding="UTF-8"?>`,
+				`<testsuites>`,
+				`	<testsuite tests="2" failures="0" time="0.000" name="conftest">`,
+				`		<properties>`,
+				`			<property name="go.version" value="%s"></property>`,
+				`		</properties>`,
+				`		<testcase classname="conftest" name="examples/kubernetes/service.yaml - namespace - deny" time="0.000"></testcase>`,
+				`		<testcase classname="conftest" name="examples/kubernetes/service.yaml - namespace" time="0.000"></testcase>`,
+				`	</testsuite>`,
+				`</testsuites>`,
+				``,
+			},
+		},
 		{
 			name: "Failure with a long description",
 			input: []CheckResult{
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+)
+
+// limitResults keeps the first max failures and the first max warnings of
+// each file, across all of the namespaces that the file was evaluated
+// against, in the order of the results. The results of each namespace are
+// sorted by rule and message before they are limited, so that the same
+// results are kept on every run. The message of the last result of the file
+// that is kept notes how many of the results were left out, e.g. (+3 more).
+// The given results are not modified.
+func limitResults(results []CheckResult, max int) []CheckResult {
+	limited := make([]CheckResult, len(results))
+	copy(limited, results)
+
+	limit := func(collection func(*CheckResult) *[]Result) {
+		kept := make(map[string]int)
+		omitted := make(map[string]int)
+		last := make(map[string]*Result)
+		for r := range limited {
+			if len(*collection(&limited[r])) == 0 {
+				continue
+			}
+
+			fileName := limited[r].FileName
+			results := make([]Result, len(*collection(&limited[r])))
+			copy(results, *collection(&limited[r]))
+
+			available := max - kept[fileName]
+			if len(results) > available {
+				sort.SliceStable(results, func(i, j int) bool {
+					if results[i].Rule != results[j].Rule {
+						return results[i].Rule < results[j].Rule
+					}
+
+					return results[i].Message < results[j].Message
+				})
+
+				omitted[fileName] += len(results) - available
+				results = results[:available]
+			}
+
+			if len(results) > 0 {
+				kept[fileName] += len(results)
+				last[fileName] = &results[len(results)-1]
+			}
+
+			*collection(&limited[r]) = results
+		}
+
+		for fileName, count := range omitted {
+			if result, ok := last[fileName]; ok {
+				result.Message = fmt.Sprintf("%s (+%d more)", result.Message, count)
+			}
+		}
+	}
+
+	limit(func(result *CheckResult) *[]Result { return &result.Failures })
+	limit(func(result *CheckResult) *[]Result { return &result.Warnings })
+
+	return limited
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLimitResults(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "image must be pinned", Rule: "deny"},
+				{Message: "containers must not run as root", Rule: "deny"},
+				{Message: "labels are required", Rule: "deny_labels"},
+			},
+			Warnings: []Result{{Message: "replicas should be set", Rule: "warn"}},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "type must be ClusterIP", Rule: "deny"},
+				{Message: "ports must be named", Rule: "deny"},
+			},
+		},
+	}
+
+	expected := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "containers must not run as root", Rule: "deny"},
+				{Message: "image must be pinned (+1 more)", Rule: "deny"},
+			},
+			Warnings: []Result{{Message: "replicas should be set", Rule: "warn"}},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "type must be ClusterIP", Rule: "deny"},
+				{Message: "ports must be named", Rule: "deny"},
+			},
+		},
+	}
+
+	actual := limitResults(results, 2)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected results. expected %v, got %v", expected, actual)
+	}
+
+	if results[0].Failures[0].Message != "image must be pinned" {
+		t.Errorf("The given results must not be modified, got %v", results[0].Failures)
+	}
+}
+
+func TestLimitResultsAcrossNamespaces(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "labels",
+			Failures:  []Result{{Message: "labels are required", Rule: "deny"}},
+		},
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "image must be pinned", Rule: "deny"},
+				{Message: "containers must not run as root", Rule: "deny"},
+			},
+		},
+	}
+
+	expected := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "labels",
+			Failures:  []Result{{Message: "labels are required", Rule: "deny"}},
+		},
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures:  []Result{{Message: "containers must not run as root (+1 more)", Rule: "deny"}},
+		},
+	}
+
+	actual := limitResults(results, 2)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected results. expected %v, got %v", expected, actual)
+	}
+
+	expected = []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "labels",
+			Failures:  []Result{{Message: "labels are required (+2 more)", Rule: "deny"}},
+		},
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures:  []Result{},
+		},
+	}
+
+	actual = limitResults(results, 1)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected results. expected %v, got %v", expected, actual)
+	}
+}
@@ -1,6 +1,11 @@
 package output
 
-import "os"
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
 
 // Outputter controls how results of an evaluation will
 // be recorded and reported to the end user.
@@ -13,6 +18,22 @@ type Outputter interface {
 type Options struct {
 	Tracing bool
 	NoColor bool
+
+	// ShowAllRules outputs the rules that passed along with the rules
+	// that produced results, in the standard format.
+	ShowAllRules bool
+
+	// ShowPrints outputs the output of the print statements of the
+	// policies as debug lines, in the standard format.
+	ShowPrints bool
+
+	// MaxResultsPerFile limits the failures, and the warnings, of each
+	// file that are output in the standard format.
+	MaxResultsPerFile int
+
+	// Writer is where the results are written to.
+	// When nil, the results are written to stdout.
+	Writer io.Writer
 }
 
 // The defined output formats represent all of the supported formats
@@ -23,23 +44,85 @@ const (
 	OutputTAP      = "tap"
 	OutputTable    = "table"
 	OutputJUnit    = "junit"
+	OutputSARIF    = "sarif"
+	OutputCSV      = "csv"
+	OutputGitHub   = "github"
+	OutputTOML     = "toml"
+
+	// OutputGroupedTable is a table of the results of each file,
+	// followed by the summary of the file.
+	OutputGroupedTable = "grouped-table"
+
+	// OutputJUnitRules is the JUnit format with a test case for each rule
+	// of each file, whose class name is the namespace of the rule.
+	OutputJUnitRules = "junit-rules"
+
+	// OutputTemplate is used as template=<template>, where the template is
+	// either the path to a file that contains the template or the template itself.
+	OutputTemplate = "template"
 )
 
 // Get returns a type that can render output in the given format.
+// When the format is a template that cannot be parsed, the
+// standard format is used instead.
 func Get(format string, options Options) Outputter {
+	outputter, err := New(format, options)
+	if err != nil {
+		if options.Writer != nil {
+			return NewStandard(options.Writer)
+		}
+
+		return NewStandard(os.Stdout)
+	}
+
+	return outputter
+}
+
+// New returns a type that can render output in the given format.
+// An error is returned when the format is a template that cannot be parsed.
+func New(format string, options Options) (Outputter, error) {
+	if options.Writer == nil {
+		options.Writer = os.Stdout
+	}
+
+	if strings.HasPrefix(format, OutputTemplate+"=") {
+		template, err := NewTemplate(options.Writer, strings.TrimPrefix(format, OutputTemplate+"="), options.NoColor)
+		if err != nil {
+			return nil, fmt.Errorf("new template: %w", err)
+		}
+
+		return template, nil
+	}
+
+	return get(format, options), nil
+}
+
+func get(format string, options Options) Outputter {
 	switch format {
 	case OutputStandard:
-		return &Standard{Writer: os.Stdout, NoColor: options.NoColor, Tracing: options.Tracing}
+		return &Standard{Writer: options.Writer, NoColor: options.NoColor, Tracing: options.Tracing, ShowAllRules: options.ShowAllRules, ShowPrints: options.ShowPrints, MaxResultsPerFile: options.MaxResultsPerFile}
 	case OutputJSON:
-		return NewJSON(os.Stdout)
+		return &JSON{Writer: options.Writer, Tracing: options.Tracing}
 	case OutputTAP:
-		return NewTAP(os.Stdout)
+		return NewTAP(options.Writer)
 	case OutputTable:
-		return NewTable(os.Stdout)
+		return NewTable(options.Writer)
+	case OutputGroupedTable:
+		return &GroupedTable{Writer: options.Writer, NoColor: options.NoColor}
 	case OutputJUnit:
-		return NewJUnit(os.Stdout)
+		return NewJUnit(options.Writer)
+	case OutputJUnitRules:
+		return NewJUnitRules(options.Writer)
+	case OutputSARIF:
+		return NewSARIF(options.Writer)
+	case OutputCSV:
+		return NewCSV(options.Writer)
+	case OutputGitHub:
+		return NewGitHub(options.Writer)
+	case OutputTOML:
+		return NewTOML(options.Writer)
 	default:
-		return NewStandard(os.Stdout)
+		return NewStandard(options.Writer)
 	}
 }
 
@@ -50,6 +133,13 @@ func Outputs() []string {
 		OutputJSON,
 		OutputTAP,
 		OutputTable,
+		OutputGroupedTable,
 		OutputJUnit,
+		OutputJUnitRules,
+		OutputSARIF,
+		OutputCSV,
+		OutputGitHub,
+		OutputTOML,
+		OutputTemplate,
 	}
 }
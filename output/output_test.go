@@ -27,10 +27,38 @@ func TestGetOutputter(t *testing.T) {
 			input:    OutputTable,
 			expected: NewTable(os.Stdout),
 		},
+		{
+			input:    OutputGroupedTable,
+			expected: NewGroupedTable(os.Stdout),
+		},
 		{
 			input:    OutputJUnit,
 			expected: NewJUnit(os.Stdout),
 		},
+		{
+			input:    OutputJUnitRules,
+			expected: NewJUnitRules(os.Stdout),
+		},
+		{
+			input:    OutputSARIF,
+			expected: NewSARIF(os.Stdout),
+		},
+		{
+			input:    OutputCSV,
+			expected: NewCSV(os.Stdout),
+		},
+		{
+			input:    OutputGitHub,
+			expected: NewGitHub(os.Stdout),
+		},
+		{
+			input:    OutputTOML,
+			expected: NewTOML(os.Stdout),
+		},
+		{
+			input:    OutputTemplate + "={{len .}}",
+			expected: &Template{},
+		},
 		{
 			input:    "unknown_format",
 			expected: NewStandard(os.Stdout),
@@ -50,3 +78,9 @@ func TestGetOutputter(t *testing.T) {
 		})
 	}
 }
+
+func TestNewOutputterInvalidTemplate(t *testing.T) {
+	if _, err := New(OutputTemplate+"={{.Unclosed", Options{}); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
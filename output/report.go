@@ -0,0 +1,53 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ReadJSON reads the results that were written in the JSON format.
+func ReadJSON(r io.Reader) ([]CheckResult, error) {
+	var results []CheckResult
+	if err := json.NewDecoder(r).Decode(&results); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	return results, nil
+}
+
+// MergeResults merges the results of the same file and namespace into one,
+// adding up their successes and combining their warnings, failures,
+// exceptions and outputs. The merged results are in the order that each
+// file and namespace first appears in.
+func MergeResults(results []CheckResult) []CheckResult {
+	type key struct {
+		fileName  string
+		namespace string
+	}
+
+	var merged []CheckResult
+	indexes := make(map[key]int)
+	for _, result := range results {
+		k := key{fileName: result.FileName, namespace: result.Namespace}
+		index, ok := indexes[k]
+		if !ok {
+			indexes[k] = len(merged)
+			merged = append(merged, result)
+			continue
+		}
+
+		mergedResult := &merged[index]
+		mergedResult.Successes += result.Successes
+		mergedResult.Warnings = append(mergedResult.Warnings, result.Warnings...)
+		mergedResult.Failures = append(mergedResult.Failures, result.Failures...)
+		mergedResult.Exceptions = append(mergedResult.Exceptions, result.Exceptions...)
+		mergedResult.Passes = append(mergedResult.Passes, result.Passes...)
+		mergedResult.Rules = append(mergedResult.Rules, result.Rules...)
+		mergedResult.Queries = append(mergedResult.Queries, result.Queries...)
+		mergedResult.Outputs = append(mergedResult.Outputs, result.Outputs...)
+		mergedResult.Traces = append(mergedResult.Traces, result.Traces...)
+	}
+
+	return merged
+}
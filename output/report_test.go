@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReadJSON(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Successes: 2,
+			Failures: []Result{
+				{
+					Message:     "Containers must not run as root",
+					Rule:        "deny_root",
+					Metadata:    map[string]interface{}{"details": map[string]interface{}{"container": "nginx"}, "priority": float64(1)},
+					Severity:    "high",
+					Line:        12,
+					Column:      7,
+					Annotations: &Annotations{Title: "Root", RelatedResources: []string{"https://example.com"}},
+				},
+			},
+			Warnings:   []Result{{Message: "Deployments should have at least 2 replicas", Namespaces: []string{"main", "kubernetes"}}},
+			Exceptions: []Result{{Message: "data.main.exception[_][_] == \"latest\""}},
+			Outputs:    []PrintOutput{{Rule: "deny", Message: "policy/deny.rego:5: nginx"}},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewJSON(buf).Output(results); err != nil {
+		t.Fatal("output json:", err)
+	}
+
+	actual, err := ReadJSON(buf)
+	if err != nil {
+		t.Fatal("read json:", err)
+	}
+
+	if !reflect.DeepEqual(results, actual) {
+		t.Errorf("Unexpected results. expected %v actual %v", results, actual)
+	}
+}
+
+func TestMergeResults(t *testing.T) {
+	results := []CheckResult{
+		{FileName: "deployment.yaml", Namespace: "main", Successes: 1, Failures: []Result{{Message: "first"}}},
+		{FileName: "service.yaml", Namespace: "main", Successes: 1},
+		{FileName: "deployment.yaml", Namespace: "main", Successes: 2, Failures: []Result{{Message: "second"}}, Warnings: []Result{{Message: "warning"}}},
+		{FileName: "deployment.yaml", Namespace: "kubernetes", Successes: 1},
+	}
+
+	expected := []CheckResult{
+		{FileName: "deployment.yaml", Namespace: "main", Successes: 3, Failures: []Result{{Message: "first"}, {Message: "second"}}, Warnings: []Result{{Message: "warning"}}},
+		{FileName: "service.yaml", Namespace: "main", Successes: 1},
+		{FileName: "deployment.yaml", Namespace: "kubernetes", Successes: 1},
+	}
+
+	actual := MergeResults(results)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected results. expected %v actual %v", expected, actual)
+	}
+}
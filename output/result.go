@@ -1,11 +1,63 @@
 package output
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Result describes the result of a single rule evaluation.
 type Result struct {
 	Message  string                 `json:"msg"`
+	Rule     string                 `json:"rule,omitempty"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Severity is the severity of the rule that produced the result, e.g.
+	// high, when the rule has one of the additional rule prefixes.
+	Severity string `json:"severity,omitempty"`
+
+	// Namespaces are the namespaces that produced the result, when the
+	// same result of several namespaces was collapsed into the result.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Line and Column are the position of the value that produced the
+	// result, when the position is known.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+
+	// Annotations are the METADATA annotations of the rule
+	// that produced the result, when the rule is annotated.
+	Annotations *Annotations `json:"annotations,omitempty"`
+
+	// Baseline is true when the result is a failure that is in the
+	// baseline, which is reported as an exception instead.
+	Baseline bool `json:"baseline,omitempty"`
+}
+
+// Annotations are the METADATA annotations of a rule.
+type Annotations struct {
+	Title            string                 `json:"title,omitempty"`
+	Description      string                 `json:"description,omitempty"`
+	RelatedResources []string               `json:"related_resources,omitempty"`
+	Custom           map[string]interface{} `json:"custom,omitempty"`
+}
+
+// URL returns a URL with more information about the rule. The URL is
+// taken from the url custom annotation, or otherwise from the first
+// related resource. When the rule does not have a URL, it is empty.
+func (a *Annotations) URL() string {
+	if a == nil {
+		return ""
+	}
+
+	if url, ok := a.Custom["url"].(string); ok {
+		return url
+	}
+
+	if len(a.RelatedResources) > 0 {
+		return a.RelatedResources[0]
+	}
+
+	return ""
 }
 
 // NewResult creates a new result. An error is returned if the
@@ -32,6 +84,16 @@ func NewResult(metadata map[string]interface{}) (Result, error) {
 	return result, nil
 }
 
+// label returns the severity of the result, or the given label of the kind of
+// the result, e.g. failure, when the result does not have a severity.
+func (r Result) label(kind string) string {
+	if r.Severity != "" {
+		return r.Severity
+	}
+
+	return kind
+}
+
 // Passed returns true if the result did not fail a policy.
 func (r Result) Passed() bool {
 	return r.Message == ""
@@ -52,6 +114,21 @@ type QueryResult struct {
 	// Traces represents a single trace of how the query was
 	// evaluated. Each trace value is a trace line.
 	Traces []string `json:"traces"`
+
+	// TraceEvents are the events of the trace of the query, which
+	// are only recorded when tracing is enabled.
+	TraceEvents []TraceEvent `json:"trace_events,omitempty"`
+
+	// Outputs are the outputs of the print statements that were
+	// called while evaluating the query.
+	Outputs []string `json:"outputs,omitempty"`
+}
+
+// PrintOutput describes the output of a print statement that was
+// called while evaluating a rule.
+type PrintOutput struct {
+	Rule    string `json:"rule"`
+	Message string `json:"msg"`
 }
 
 // Passed returns true if all of the results in the query
@@ -77,48 +154,201 @@ type CheckResult struct {
 	Failures   []Result      `json:"failures,omitempty"`
 	Exceptions []Result      `json:"exceptions,omitempty"`
 	Queries    []QueryResult `json:"queries,omitempty"`
+	Outputs    []PrintOutput `json:"outputs,omitempty"`
+	Traces     []QueryTrace  `json:"traces,omitempty"`
+
+	// Passes are the rules that were evaluated without any failures,
+	// warnings or exceptions, which are only reported when requested.
+	// The results of the passes do not have a message.
+	Passes []Result `json:"passes,omitempty"`
+
+	// Rules are the rules that were evaluated to produce the result, in
+	// the order in which they were evaluated, with the outcome of each.
+	Rules []EvaluatedRule `json:"rules,omitempty"`
+
+	// Duration is how long it took to run the unit test of the result,
+	// which is only recorded by the verify command, in nanoseconds.
+	Duration time.Duration `json:"duration,omitempty"`
 }
 
-// ExitCode returns the exit code that should be returned
-// given all of the returned results.
-func ExitCode(results []CheckResult) int {
-	var hasFailure bool
-	for _, result := range results {
-		if len(result.Failures) > 0 {
-			hasFailure = true
+// ruleNames returns the names of the rules that were evaluated to produce
+// the result, in the order in which they were evaluated.
+func (c CheckResult) ruleNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, rule := range c.Rules {
+		if seen[rule.Name] {
+			continue
 		}
-	}
 
-	if hasFailure {
-		return 1
+		seen[rule.Name] = true
+		names = append(names, rule.Name)
 	}
 
-	return 0
+	return names
+}
+
+// The outcomes of the rules that were evaluated.
+const (
+	OutcomePass      = "pass"
+	OutcomeFail      = "fail"
+	OutcomeWarn      = "warn"
+	OutcomeException = "exception"
+)
+
+// EvaluatedRule describes a rule that was evaluated, such as deny, and
+// its outcome, e.g. fail when the rule produced any failures.
+type EvaluatedRule struct {
+	Name    string `json:"name"`
+	Outcome string `json:"outcome"`
+}
+
+// QueryTrace describes the trace of how a query was evaluated.
+type QueryTrace struct {
+	Query  string       `json:"query"`
+	Events []TraceEvent `json:"events"`
+}
+
+// TraceEvent describes a single event of the trace of a query, such as
+// the evaluation of an expression (eval) or a rule that failed (fail).
+type TraceEvent struct {
+	Op       string `json:"op"`
+	QueryID  uint64 `json:"query_id"`
+	ParentID uint64 `json:"parent_id"`
+	Node     string `json:"node,omitempty"`
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// ExitCode returns the exit code that should be returned
+// given all of the returned results.
+func ExitCode(results []CheckResult) int {
+	return ExitCodeWithThreshold(results, 0)
 }
 
 // ExitCodeFailOnWarn returns the exit code that should be returned
 // given all of the returned results, and will consider warnings
 // as failures.
 func ExitCodeFailOnWarn(results []CheckResult) int {
-	var hasFailure bool
-	var hasWarning bool
-	for _, result := range results {
-		if len(result.Failures) > 0 {
-			hasFailure = true
+	return ExitCodeFailOnWarnWithThreshold(results, 0)
+}
+
+// ExitCodeWithThreshold returns the exit code that should be returned
+// given all of the returned results, where failures are only considered
+// when the total number of failures exceeds the threshold.
+func ExitCodeWithThreshold(results []CheckResult, threshold int) int {
+	failures, _ := countResults(results)
+	if failures > threshold {
+		return 1
+	}
+
+	return 0
+}
+
+// ExitCodeFailOnWarnWithThreshold returns the exit code that should be
+// returned given all of the returned results, where failures are only
+// considered when the total number of failures exceeds the threshold.
+// Warnings are considered as failures regardless of the threshold.
+func ExitCodeFailOnWarnWithThreshold(results []CheckResult, threshold int) int {
+	failures, warnings := countResults(results)
+	if failures > threshold {
+		return ExitCodeFailOnWarnFailures
+	}
+
+	if warnings > 0 {
+		return ExitCodeFailOnWarnWarnings
+	}
+
+	return ExitCodeSuccess
+}
+
+// The exit codes when warnings are considered as failures, which tell
+// apart failures from warnings without the detailed exit codes.
+const (
+	ExitCodeFailOnWarnWarnings = 1
+	ExitCodeFailOnWarnFailures = 2
+)
+
+// The detailed exit codes, which tell apart failures, warnings and errors.
+const (
+	ExitCodeSuccess  = 0
+	ExitCodeFailures = 1
+	ExitCodeWarnings = 2
+	ExitCodeError    = 3
+)
+
+// ExitCodeDetailed returns the detailed exit code that should be returned
+// given all of the returned results, where failures are only considered
+// when the total number of failures exceeds the threshold. Warnings are
+// considered as failures when failOnWarn is true.
+func ExitCodeDetailed(results []CheckResult, threshold int, failOnWarn bool) int {
+	failures, warnings := countResults(results)
+	if failures > threshold || (failOnWarn && warnings > 0) {
+		return ExitCodeFailures
+	}
+
+	if warnings > 0 {
+		return ExitCodeWarnings
+	}
+
+	return ExitCodeSuccess
+}
+
+// ExitCodeExceptionRatio returns the exit code that should be returned
+// when the ratio of exceptions exceeds the tolerated ratio, given the exit
+// code of the results. Too many exceptions are considered to be a failure,
+// but do not hide an error, nor failures that were already found.
+func ExitCodeExceptionRatio(exitCode int, failOnWarn bool, detailed bool) int {
+	if detailed {
+		if exitCode == ExitCodeError {
+			return exitCode
 		}
 
-		if len(result.Warnings) > 0 {
-			hasWarning = true
+		return ExitCodeFailures
+	}
+
+	failure := ExitCodeFailures
+	if failOnWarn {
+		failure = ExitCodeFailOnWarnFailures
+	}
+
+	if exitCode > failure {
+		return exitCode
+	}
+
+	return failure
+}
+
+// ExceptionRatio returns the ratio of the number of exceptions to the
+// total number of tests in the given results. When there are no tests,
+// the ratio is zero. Failures that are in the baseline are not counted
+// as exceptions, as they are not exempted by the policies.
+func ExceptionRatio(results []CheckResult) float64 {
+	var tests int
+	var exceptions int
+	for _, result := range results {
+		tests += result.Successes + len(result.Failures) + len(result.Warnings) + len(result.Exceptions)
+		for _, exception := range result.Exceptions {
+			if !exception.Baseline {
+				exceptions++
+			}
 		}
 	}
 
-	if hasFailure {
-		return 2
+	if tests == 0 {
+		return 0
 	}
 
-	if hasWarning {
-		return 1
+	return float64(exceptions) / float64(tests)
+}
+
+func countResults(results []CheckResult) (int, int) {
+	var failures int
+	var warnings int
+	for _, result := range results {
+		failures += len(result.Failures)
+		warnings += len(result.Warnings)
 	}
 
-	return 0
+	return failures, warnings
 }
@@ -59,3 +59,120 @@ func TestExitCodeFailOnWarn(t *testing.T) {
 		}
 	}
 }
+
+func TestExitCodeWithThreshold(t *testing.T) {
+	warning := CheckResult{
+		Warnings: []Result{{}},
+	}
+
+	failures := CheckResult{
+		Failures: []Result{{}, {}},
+	}
+
+	testCases := []struct {
+		results    []CheckResult
+		threshold  int
+		failOnWarn bool
+		expected   int
+	}{
+		{results: []CheckResult{failures}, threshold: 0, expected: 1},
+		{results: []CheckResult{failures}, threshold: 1, expected: 1},
+		{results: []CheckResult{failures}, threshold: 2, expected: 0},
+		{results: []CheckResult{failures, failures}, threshold: 3, expected: 1},
+		{results: []CheckResult{warning, failures}, threshold: 2, expected: 0},
+		{results: []CheckResult{failures}, threshold: 2, failOnWarn: true, expected: 0},
+		{results: []CheckResult{warning, failures}, threshold: 2, failOnWarn: true, expected: 1},
+		{results: []CheckResult{warning, failures}, threshold: 1, failOnWarn: true, expected: 2},
+	}
+
+	for _, testCase := range testCases {
+		var actual int
+		if testCase.failOnWarn {
+			actual = ExitCodeFailOnWarnWithThreshold(testCase.results, testCase.threshold)
+		} else {
+			actual = ExitCodeWithThreshold(testCase.results, testCase.threshold)
+		}
+
+		if actual != testCase.expected {
+			t.Errorf("Unexpected error code. expected %v, actual %v", testCase.expected, actual)
+		}
+	}
+}
+
+func TestExitCodeDetailed(t *testing.T) {
+	warning := CheckResult{
+		Warnings: []Result{{}},
+	}
+
+	failures := CheckResult{
+		Failures: []Result{{}, {}},
+	}
+
+	testCases := []struct {
+		results    []CheckResult
+		threshold  int
+		failOnWarn bool
+		expected   int
+	}{
+		{results: []CheckResult{}, expected: ExitCodeSuccess},
+		{results: []CheckResult{failures}, expected: ExitCodeFailures},
+		{results: []CheckResult{warning}, expected: ExitCodeWarnings},
+		{results: []CheckResult{warning, failures}, expected: ExitCodeFailures},
+		{results: []CheckResult{warning, failures}, threshold: 2, expected: ExitCodeWarnings},
+		{results: []CheckResult{warning}, failOnWarn: true, expected: ExitCodeFailures},
+		{results: []CheckResult{failures}, threshold: 2, failOnWarn: true, expected: ExitCodeSuccess},
+	}
+
+	for _, testCase := range testCases {
+		actual := ExitCodeDetailed(testCase.results, testCase.threshold, testCase.failOnWarn)
+		if actual != testCase.expected {
+			t.Errorf("Unexpected error code. expected %v, actual %v", testCase.expected, actual)
+		}
+	}
+}
+
+func TestExceptionRatio(t *testing.T) {
+	testCases := []struct {
+		results  []CheckResult
+		expected float64
+	}{
+		{results: []CheckResult{}, expected: 0},
+		{results: []CheckResult{{Successes: 2}}, expected: 0},
+		{results: []CheckResult{{Successes: 1, Exceptions: []Result{{}}}}, expected: 0.5},
+		{results: []CheckResult{{Failures: []Result{{}}, Warnings: []Result{{}}}, {Exceptions: []Result{{}, {}}}}, expected: 0.5},
+		{results: []CheckResult{{Exceptions: []Result{{}}}}, expected: 1},
+		{results: []CheckResult{{Successes: 2, Exceptions: []Result{{}, {Baseline: true}}}}, expected: 0.25},
+	}
+
+	for _, testCase := range testCases {
+		actual := ExceptionRatio(testCase.results)
+		if actual != testCase.expected {
+			t.Errorf("Unexpected exception ratio. expected %v, actual %v", testCase.expected, actual)
+		}
+	}
+}
+
+func TestExitCodeExceptionRatio(t *testing.T) {
+	testCases := []struct {
+		exitCode   int
+		failOnWarn bool
+		detailed   bool
+		expected   int
+	}{
+		{exitCode: ExitCodeSuccess, expected: ExitCodeFailures},
+		{exitCode: ExitCodeFailures, expected: ExitCodeFailures},
+		{exitCode: ExitCodeSuccess, failOnWarn: true, expected: ExitCodeFailOnWarnFailures},
+		{exitCode: ExitCodeFailOnWarnWarnings, failOnWarn: true, expected: ExitCodeFailOnWarnFailures},
+		{exitCode: ExitCodeSuccess, detailed: true, expected: ExitCodeFailures},
+		{exitCode: ExitCodeWarnings, detailed: true, expected: ExitCodeFailures},
+		{exitCode: ExitCodeError, detailed: true, expected: ExitCodeError},
+		{exitCode: ExitCodeError, failOnWarn: true, detailed: true, expected: ExitCodeError},
+	}
+
+	for _, testCase := range testCases {
+		actual := ExitCodeExceptionRatio(testCase.exitCode, testCase.failOnWarn, testCase.detailed)
+		if actual != testCase.expected {
+			t.Errorf("Unexpected exit code. expected %v, actual %v", testCase.expected, actual)
+		}
+	}
+}
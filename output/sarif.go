@@ -0,0 +1,224 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIF represents an Outputter that outputs
+// results in SARIF 2.1.0 format.
+type SARIF struct {
+	Writer io.Writer
+}
+
+// NewSARIF creates a new SARIF with the given writer.
+func NewSARIF(w io.Writer) *SARIF {
+	sarif := SARIF{
+		Writer: w,
+	}
+
+	return &sarif
+}
+
+type sarifReport struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string            `json:"id"`
+	ShortDescription *sarifDescription `json:"shortDescription,omitempty"`
+	FullDescription  *sarifDescription `json:"fullDescription,omitempty"`
+	HelpURI          string            `json:"helpUri,omitempty"`
+}
+
+type sarifDescription struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+
+	// Properties are the properties of the result, e.g. its severity.
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+// Output outputs the results.
+func (s *SARIF) Output(results []CheckResult) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "conftest",
+				InformationURI: "https://www.conftest.dev",
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	annotations := make(map[string]*Annotations)
+	for _, result := range results {
+		for _, failure := range result.Failures {
+			run.Results = append(run.Results, newSARIFResult(result, failure, "error"))
+			addAnnotations(annotations, result, failure)
+		}
+
+		for _, warning := range result.Warnings {
+			run.Results = append(run.Results, newSARIFResult(result, warning, "warning"))
+			addAnnotations(annotations, result, warning)
+		}
+
+		// Exceptions are policy violations that were explicitly allowed, which
+		// SARIF represents as a result that has been suppressed in source.
+		for _, exception := range result.Exceptions {
+			sarifResult := newSARIFResult(result, exception, "note")
+			sarifResult.Suppressions = []sarifSuppression{{Kind: "inSource"}}
+			run.Results = append(run.Results, sarifResult)
+		}
+	}
+
+	for _, result := range run.Results {
+		if rules[result.RuleID] {
+			continue
+		}
+
+		rules[result.RuleID] = true
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSARIFRule(result.RuleID, annotations[result.RuleID]))
+	}
+
+	// For consistency when printing the results, sort the rules by their id.
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+
+	report := sarifReport{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	}
+
+	b, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return fmt.Errorf("marshal sarif: %w", err)
+	}
+
+	fmt.Fprintln(s.Writer, string(b))
+	return nil
+}
+
+func newSARIFResult(checkResult CheckResult, result Result, level string) sarifResult {
+	sarifResult := sarifResult{
+		RuleID:  getRuleID(checkResult.Namespace, result.Rule),
+		Level:   level,
+		Message: sarifMessage{Text: result.Message},
+	}
+
+	if result.Severity != "" {
+		sarifResult.Properties = map[string]interface{}{"severity": result.Severity}
+	}
+
+	// Results that originate from standard input do not have a file
+	// that can be pointed to.
+	if checkResult.FileName != "" && checkResult.FileName != "-" {
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: checkResult.FileName},
+			},
+		}
+
+		sarifResult.Locations = []sarifLocation{location}
+	}
+
+	return sarifResult
+}
+
+// newSARIFRule creates a rule that is described by the
+// annotations of the rule, when the rule is annotated.
+func newSARIFRule(id string, annotations *Annotations) sarifRule {
+	rule := sarifRule{ID: id}
+	if annotations == nil {
+		return rule
+	}
+
+	if annotations.Title != "" {
+		rule.ShortDescription = &sarifDescription{Text: annotations.Title}
+	}
+
+	if annotations.Description != "" {
+		rule.FullDescription = &sarifDescription{Text: annotations.Description}
+	}
+
+	rule.HelpURI = annotations.URL()
+
+	return rule
+}
+
+func addAnnotations(annotations map[string]*Annotations, checkResult CheckResult, result Result) {
+	if result.Annotations == nil {
+		return
+	}
+
+	ruleID := getRuleID(checkResult.Namespace, result.Rule)
+	if _, ok := annotations[ruleID]; !ok {
+		annotations[ruleID] = result.Annotations
+	}
+}
+
+func getRuleID(namespace string, rule string) string {
+	if rule == "" {
+		return namespace
+	}
+
+	if namespace == "" {
+		return rule
+	}
+
+	return namespace + "." + rule
+}
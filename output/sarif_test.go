@@ -0,0 +1,253 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSARIF(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []CheckResult
+		expected []string
+	}{
+		{
+			name: "No warnings or errors",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+				},
+			},
+			expected: []string{
+				`{`,
+				`	"version": "2.1.0",`,
+				`	"$schema": "https://json.schemastore.org/sarif-2.1.0.json",`,
+				`	"runs": [`,
+				`		{`,
+				`			"tool": {`,
+				`				"driver": {`,
+				`					"name": "conftest",`,
+				`					"informationUri": "https://www.conftest.dev"`,
+				`				}`,
+				`			},`,
+				`			"results": []`,
+				`		}`,
+				`	]`,
+				`}`,
+				``,
+			},
+		},
+		{
+			name: "A warning, a failure and an exception",
+			input: []CheckResult{
+				{
+					FileName:   "examples/kubernetes/service.yaml",
+					Namespace:  "namespace",
+					Warnings:   []Result{{Message: "first warning", Rule: "warn"}},
+					Failures:   []Result{{Message: "first failure", Rule: "deny"}},
+					Exceptions: []Result{{Message: "first exception", Rule: "deny_foo"}},
+				},
+			},
+			expected: []string{
+				`{`,
+				`	"version": "2.1.0",`,
+				`	"$schema": "https://json.schemastore.org/sarif-2.1.0.json",`,
+				`	"runs": [`,
+				`		{`,
+				`			"tool": {`,
+				`				"driver": {`,
+				`					"name": "conftest",`,
+				`					"informationUri": "https://www.conftest.dev",`,
+				`					"rules": [`,
+				`						{`,
+				`							"id": "namespace.deny"`,
+				`						},`,
+				`						{`,
+				`							"id": "namespace.deny_foo"`,
+				`						},`,
+				`						{`,
+				`							"id": "namespace.warn"`,
+				`						}`,
+				`					]`,
+				`				}`,
+				`			},`,
+				`			"results": [`,
+				`				{`,
+				`					"ruleId": "namespace.deny",`,
+				`					"level": "error",`,
+				`					"message": {`,
+				`						"text": "first failure"`,
+				`					},`,
+				`					"locations": [`,
+				`						{`,
+				`							"physicalLocation": {`,
+				`								"artifactLocation": {`,
+				`									"uri": "examples/kubernetes/service.yaml"`,
+				`								}`,
+				`							}`,
+				`						}`,
+				`					]`,
+				`				},`,
+				`				{`,
+				`					"ruleId": "namespace.warn",`,
+				`					"level": "warning",`,
+				`					"message": {`,
+				`						"text": "first warning"`,
+				`					},`,
+				`					"locations": [`,
+				`						{`,
+				`							"physicalLocation": {`,
+				`								"artifactLocation": {`,
+				`									"uri": "examples/kubernetes/service.yaml"`,
+				`								}`,
+				`							}`,
+				`						}`,
+				`					]`,
+				`				},`,
+				`				{`,
+				`					"ruleId": "namespace.deny_foo",`,
+				`					"level": "note",`,
+				`					"message": {`,
+				`						"text": "first exception"`,
+				`					},`,
+				`					"locations": [`,
+				`						{`,
+				`							"physicalLocation": {`,
+				`								"artifactLocation": {`,
+				`									"uri": "examples/kubernetes/service.yaml"`,
+				`								}`,
+				`							}`,
+				`						}`,
+				`					],`,
+				`					"suppressions": [`,
+				`						{`,
+				`							"kind": "inSource"`,
+				`						}`,
+				`					]`,
+				`				}`,
+				`			]`,
+				`		}`,
+				`	]`,
+				`}`,
+				``,
+			},
+		},
+		{
+			name: "Describes annotated rules",
+			input: []CheckResult{
+				{
+					FileName:  "-",
+					Namespace: "namespace",
+					Failures: []Result{
+						{
+							Message: "first failure",
+							Rule:    "deny",
+							Annotations: &Annotations{
+								Title:            "Replicas",
+								Description:      "Deployments must have at least two replicas.",
+								RelatedResources: []string{"https://example.com/replicas"},
+							},
+						},
+					},
+				},
+			},
+			expected: []string{
+				`{`,
+				`	"version": "2.1.0",`,
+				`	"$schema": "https://json.schemastore.org/sarif-2.1.0.json",`,
+				`	"runs": [`,
+				`		{`,
+				`			"tool": {`,
+				`				"driver": {`,
+				`					"name": "conftest",`,
+				`					"informationUri": "https://www.conftest.dev",`,
+				`					"rules": [`,
+				`						{`,
+				`							"id": "namespace.deny",`,
+				`							"shortDescription": {`,
+				`								"text": "Replicas"`,
+				`							},`,
+				`							"fullDescription": {`,
+				`								"text": "Deployments must have at least two replicas."`,
+				`							},`,
+				`							"helpUri": "https://example.com/replicas"`,
+				`						}`,
+				`					]`,
+				`				}`,
+				`			},`,
+				`			"results": [`,
+				`				{`,
+				`					"ruleId": "namespace.deny",`,
+				`					"level": "error",`,
+				`					"message": {`,
+				`						"text": "first failure"`,
+				`					}`,
+				`				}`,
+				`			]`,
+				`		}`,
+				`	]`,
+				`}`,
+				``,
+			},
+		},
+		{
+			name: "Omits locations for standard input",
+			input: []CheckResult{
+				{
+					FileName:  "-",
+					Namespace: "namespace",
+					Failures:  []Result{{Message: "first failure", Rule: "deny"}},
+				},
+			},
+			expected: []string{
+				`{`,
+				`	"version": "2.1.0",`,
+				`	"$schema": "https://json.schemastore.org/sarif-2.1.0.json",`,
+				`	"runs": [`,
+				`		{`,
+				`			"tool": {`,
+				`				"driver": {`,
+				`					"name": "conftest",`,
+				`					"informationUri": "https://www.conftest.dev",`,
+				`					"rules": [`,
+				`						{`,
+				`							"id": "namespace.deny"`,
+				`						}`,
+				`					]`,
+				`				}`,
+				`			},`,
+				`			"results": [`,
+				`				{`,
+				`					"ruleId": "namespace.deny",`,
+				`					"level": "error",`,
+				`					"message": {`,
+				`						"text": "first failure"`,
+				`					}`,
+				`				}`,
+				`			]`,
+				`		}`,
+				`	]`,
+				`}`,
+				``,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := strings.Join(tt.expected, "\n")
+
+			buf := new(bytes.Buffer)
+			if err := NewSARIF(buf).Output(tt.input); err != nil {
+				t.Fatal("output sarif:", err)
+			}
+			actual := buf.String()
+
+			if expected != actual {
+				t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package output
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	"github.com/logrusorgru/aurora"
 )
@@ -19,6 +21,21 @@ type Standard struct {
 	// NoColor will disable all coloring when
 	// set to true.
 	NoColor bool
+
+	// ShowAllRules outputs a line for each rule that was evaluated against
+	// each file, including the rules that passed, instead of only the rules
+	// that produced results.
+	ShowAllRules bool
+
+	// ShowPrints outputs the output of the print statements of the policies
+	// as debug lines in the section of the file and namespace that was being
+	// evaluated, and under each of the queries when tracing.
+	ShowPrints bool
+
+	// MaxResultsPerFile limits the failures, and the warnings, that are
+	// output for each file across all of its namespaces. The summary still
+	// counts all of the results. When zero, all of the results are output.
+	MaxResultsPerFile int
 }
 
 // NewStandard creates a new Standard with the given writer.
@@ -46,7 +63,15 @@ func (s *Standard) Output(results []CheckResult) error {
 	var totalExceptions int
 	var totalWarnings int
 	var totalSuccesses int
-	for _, result := range results {
+
+	// Only the lines of the results are limited, so that the summary
+	// counts all of the results.
+	displayed := results
+	if s.MaxResultsPerFile > 0 {
+		displayed = limitResults(results, s.MaxResultsPerFile)
+	}
+
+	for r, result := range results {
 		var indicator string
 		var namespace string
 		if result.FileName == "-" {
@@ -68,16 +93,27 @@ func (s *Standard) Output(results []CheckResult) error {
 			continue
 		}
 
-		for _, warning := range result.Warnings {
-			fmt.Fprintln(s.Writer, colorizer.Colorize("WARN", aurora.YellowFg), indicator, namespace, warning.Message)
+		if s.ShowPrints {
+			for _, printed := range result.Outputs {
+				fmt.Fprintln(s.Writer, colorizer.Colorize("DEBG", aurora.BlueFg), indicator, namespace, printed.Rule+":", printed.Message)
+			}
 		}
 
-		for _, failure := range result.Failures {
-			fmt.Fprintln(s.Writer, colorizer.Colorize("FAIL", aurora.RedFg), indicator, namespace, failure.Message)
-		}
+		if s.ShowAllRules {
+			s.outputRules(displayed[r], indicator, namespace, colorizer)
+		} else {
+			// Results with a severity are labeled with their severity instead.
+			for _, warning := range displayed[r].Warnings {
+				fmt.Fprintln(s.Writer, colorizer.Colorize(strings.ToUpper(warning.label("warn")), aurora.YellowFg), indicator, namespace, colorizeMessage(warning.Message, colorizer))
+			}
 
-		for _, exception := range result.Exceptions {
-			fmt.Fprintln(s.Writer, colorizer.Colorize("EXCP", aurora.CyanFg), indicator, namespace, exception.Message)
+			for _, failure := range displayed[r].Failures {
+				fmt.Fprintln(s.Writer, colorizer.Colorize(strings.ToUpper(failure.label("fail")), aurora.RedFg), indicator, namespace, colorizeMessage(failure.Message, colorizer))
+			}
+
+			for _, exception := range result.Exceptions {
+				fmt.Fprintln(s.Writer, colorizer.Colorize("EXCP", aurora.CyanFg), indicator, namespace, colorizeMessage(exception.Message, colorizer))
+			}
 		}
 
 		totalFailures += len(result.Failures)
@@ -86,6 +122,105 @@ func (s *Standard) Output(results []CheckResult) error {
 		totalSuccesses += result.Successes
 	}
 
+	outputText, outputColor := summarize(totalSuccesses, totalWarnings, totalFailures, totalExceptions)
+
+	fmt.Fprintln(s.Writer)
+	fmt.Fprintln(s.Writer, colorizer.Colorize(outputText, outputColor))
+	return nil
+}
+
+// outputRules outputs the results of the given result grouped by the rules
+// that produced them, sorted by the names of the rules, followed by a PASS
+// line for each of the other rules that were evaluated. The results that were
+// not produced by a rule, such as timeouts, are output first.
+func (s *Standard) outputRules(result CheckResult, indicator string, namespace string, colorizer aurora.Aurora) {
+	type line struct {
+		label   aurora.Value
+		message string
+	}
+
+	lines := make(map[string][]line)
+	addLines := func(results []Result, label func(Result) string, color aurora.Color) {
+		for _, r := range results {
+			lines[r.Rule] = append(lines[r.Rule], line{label: colorizer.Colorize(label(r), color), message: colorizeMessage(r.Message, colorizer)})
+		}
+	}
+
+	addLines(result.Warnings, func(r Result) string { return strings.ToUpper(r.label("warn")) }, aurora.YellowFg)
+	addLines(result.Failures, func(r Result) string { return strings.ToUpper(r.label("fail")) }, aurora.RedFg)
+	addLines(result.Exceptions, func(Result) string { return "EXCP" }, aurora.CyanFg)
+
+	rules := result.ruleNames()
+	for rule := range lines {
+		if !containsRule(rules, rule) {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Strings(rules)
+
+	for _, rule := range rules {
+
+		// The unit tests of the verify command are shown with how long
+		// they took to run.
+		name := rule
+		if result.Duration > 0 {
+			name = fmt.Sprintf("%s (%v)", rule, result.Duration)
+		}
+
+		if len(lines[rule]) == 0 {
+			fmt.Fprintln(s.Writer, colorizer.Colorize("PASS", aurora.GreenFg), indicator, namespace, name)
+			continue
+		}
+
+		for _, l := range lines[rule] {
+			if rule == "" {
+				fmt.Fprintln(s.Writer, l.label, indicator, namespace, l.message)
+				continue
+			}
+
+			fmt.Fprintln(s.Writer, l.label, indicator, namespace, name+":", l.message)
+		}
+	}
+}
+
+func containsRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Standard) outputTrace(results []CheckResult, colorizer aurora.Aurora) {
+	for _, result := range results {
+		for _, query := range result.Queries {
+			var color aurora.Color
+			if query.Passed() {
+				color = aurora.GreenFg
+			} else {
+				color = aurora.RedFg
+			}
+
+			fmt.Fprintln(s.Writer, colorizer.Colorize("file: "+result.FileName+" | query: "+query.Query, color))
+
+			for _, t := range query.Traces {
+				fmt.Fprintln(s.Writer, colorizer.Colorize("TRAC ", aurora.BlueFg), "", t)
+			}
+
+			if s.ShowPrints {
+				for _, printed := range query.Outputs {
+					fmt.Fprintln(s.Writer, colorizer.Colorize("DEBG ", aurora.BlueFg), "", printed)
+				}
+			}
+		}
+	}
+}
+
+// summarize returns the summary of the totals of the results, and the
+// color that the summary is shown in based on the most severe result.
+func summarize(totalSuccesses int, totalWarnings int, totalFailures int, totalExceptions int) (string, aurora.Color) {
 	totalTests := totalFailures + totalExceptions + totalWarnings + totalSuccesses
 
 	var pluralSuffixTests string
@@ -127,26 +262,5 @@ func (s *Standard) Output(results []CheckResult) error {
 		outputColor = aurora.GreenFg
 	}
 
-	fmt.Fprintln(s.Writer)
-	fmt.Fprintln(s.Writer, colorizer.Colorize(outputText, outputColor))
-	return nil
-}
-
-func (s *Standard) outputTrace(results []CheckResult, colorizer aurora.Aurora) {
-	for _, result := range results {
-		for _, query := range result.Queries {
-			var color aurora.Color
-			if query.Passed() {
-				color = aurora.GreenFg
-			} else {
-				color = aurora.RedFg
-			}
-
-			fmt.Fprintln(s.Writer, colorizer.Colorize("file: "+result.FileName+" | query: "+query.Query, color))
-
-			for _, t := range query.Traces {
-				fmt.Fprintln(s.Writer, colorizer.Colorize("TRAC ", aurora.BlueFg), "", t)
-			}
-		}
-	}
+	return outputText, outputColor
 }
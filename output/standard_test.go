@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestStandard(t *testing.T) {
@@ -31,6 +32,41 @@ func TestStandard(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name: "labels results with their severity",
+			input: []CheckResult{
+				{
+					FileName:  "foo.yaml",
+					Namespace: "namespace",
+					Warnings:  []Result{{Message: "first warning", Severity: "info"}},
+					Failures:  []Result{{Message: "first failure", Severity: "critical"}},
+				},
+			},
+			expected: []string{
+				"INFO - foo.yaml - namespace - first warning",
+				"CRITICAL - foo.yaml - namespace - first failure",
+				"",
+				"2 tests, 0 passed, 1 warning, 1 failure, 0 exceptions",
+				"",
+			},
+		},
+		{
+			name: "omits print outputs by default",
+			input: []CheckResult{
+				{
+					FileName: "foo.yaml",
+					Namespace: "namespace",
+					Failures: []Result{{Message: "first failure"}},
+					Outputs: []PrintOutput{{Rule: "deny", Message: "policy.rego:4: hello"}},
+				},
+			},
+			expected: []string{
+				"FAIL - foo.yaml - namespace - first failure",
+				"",
+				"1 test, 0 passed, 0 warnings, 1 failure, 0 exceptions",
+				"",
+			},
+		},
 		{
 			name: "skips filenames for stdin",
 			input: []CheckResult{
@@ -69,3 +105,167 @@ func TestStandard(t *testing.T) {
 		})
 	}
 }
+
+func TestStandardShowPrints(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "foo.yaml",
+			Namespace: "namespace",
+			Failures:  []Result{{Message: "first failure"}},
+			Queries: []QueryResult{
+				{Query: "data.namespace.deny", Traces: []string{"Enter data.namespace.deny = _"}, Outputs: []string{"policy.rego:4: hello"}},
+			},
+			Outputs: []PrintOutput{{Rule: "deny", Message: "policy.rego:4: hello"}},
+		},
+	}
+
+	t.Run("inline", func(t *testing.T) {
+		expected := strings.Join([]string{
+			"DEBG - foo.yaml - namespace - deny: policy.rego:4: hello",
+			"FAIL - foo.yaml - namespace - first failure",
+			"",
+			"1 test, 0 passed, 0 warnings, 1 failure, 0 exceptions",
+			"",
+		}, "\n")
+
+		buf := new(bytes.Buffer)
+		standard := Standard{Writer: buf, NoColor: true, ShowPrints: true}
+		if err := standard.Output(results); err != nil {
+			t.Fatal("output standard:", err)
+		}
+
+		if actual := buf.String(); actual != expected {
+			t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+		}
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		expected := strings.Join([]string{
+			"file: foo.yaml | query: data.namespace.deny",
+			"TRAC   Enter data.namespace.deny = _",
+			"DEBG   policy.rego:4: hello",
+			"",
+		}, "\n")
+
+		buf := new(bytes.Buffer)
+		standard := Standard{Writer: buf, NoColor: true, Tracing: true, ShowPrints: true}
+		if err := standard.Output(results); err != nil {
+			t.Fatal("output standard:", err)
+		}
+
+		if actual := buf.String(); actual != expected {
+			t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+		}
+	})
+}
+
+func TestStandardShowAllRules(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "foo.yaml",
+			Namespace: "main",
+			Successes: 2,
+			Warnings:  []Result{{Message: "first warning", Rule: "warn"}},
+			Failures:  []Result{{Message: "first failure", Rule: "deny_labels"}, {Message: "timed out after 1s"}},
+			Rules: []EvaluatedRule{
+				{Name: "deny", Outcome: OutcomePass},
+				{Name: "deny_labels", Outcome: OutcomeFail},
+				{Name: "violation", Outcome: OutcomePass},
+				{Name: "warn", Outcome: OutcomeWarn},
+			},
+		},
+	}
+
+	expected := strings.Join([]string{
+		"FAIL - foo.yaml - main - timed out after 1s",
+		"PASS - foo.yaml - main - deny",
+		"FAIL - foo.yaml - main - deny_labels: first failure",
+		"PASS - foo.yaml - main - violation",
+		"WARN - foo.yaml - main - warn: first warning",
+		"",
+		"5 tests, 2 passed, 1 warning, 2 failures, 0 exceptions",
+		"",
+	}, "\n")
+
+	buf := new(bytes.Buffer)
+	standard := Standard{Writer: buf, NoColor: true, ShowAllRules: true}
+	if err := standard.Output(results); err != nil {
+		t.Fatal("output standard:", err)
+	}
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestStandardShowAllRulesDurations(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "policy/main_test.rego",
+			Namespace: "main",
+			Successes: 1,
+			Rules:     []EvaluatedRule{{Name: "test_allow", Outcome: OutcomePass}},
+			Duration:  2 * time.Millisecond,
+		},
+		{
+			FileName:  "policy/main_test.rego",
+			Namespace: "main",
+			Failures:  []Result{{Message: "data.main.test_deny", Rule: "test_deny"}},
+			Rules:     []EvaluatedRule{{Name: "test_deny", Outcome: OutcomeFail}},
+			Duration:  time.Millisecond,
+		},
+	}
+
+	expected := strings.Join([]string{
+		"PASS - policy/main_test.rego - main - test_allow (2ms)",
+		"FAIL - policy/main_test.rego - main - test_deny (1ms): data.main.test_deny",
+		"",
+		"2 tests, 1 passed, 0 warnings, 1 failure, 0 exceptions",
+		"",
+	}, "\n")
+
+	buf := new(bytes.Buffer)
+	standard := Standard{Writer: buf, NoColor: true, ShowAllRules: true}
+	if err := standard.Output(results); err != nil {
+		t.Fatal("output standard:", err)
+	}
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestStandardMaxResultsPerFile(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "image must be pinned", Rule: "deny"},
+				{Message: "containers must not run as root", Rule: "deny"},
+			},
+		},
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "labels",
+			Failures:  []Result{{Message: "labels are required", Rule: "deny"}},
+		},
+	}
+
+	expected := strings.Join([]string{
+		"FAIL - deployment.yaml - main - containers must not run as root (+2 more)",
+		"",
+		"3 tests, 0 passed, 0 warnings, 3 failures, 0 exceptions",
+		"",
+	}, "\n")
+
+	buf := new(bytes.Buffer)
+	standard := Standard{Writer: buf, NoColor: true, MaxResultsPerFile: 1}
+	if err := standard.Output(results); err != nil {
+		t.Fatal("output standard:", err)
+	}
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+	}
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// Summary represents an Outputter that only outputs the summary
+// of the results, i.e. the number of tests, warnings and failures.
+type Summary struct {
+	Writer io.Writer
+
+	// NoColor will disable all coloring when
+	// set to true.
+	NoColor bool
+}
+
+// NewSummary creates a new Summary with the given writer.
+func NewSummary(w io.Writer) *Summary {
+	summary := Summary{
+		Writer: w,
+	}
+
+	return &summary
+}
+
+// Output outputs the results.
+func (s *Summary) Output(results []CheckResult) error {
+	colorizer := aurora.NewAurora(!s.NoColor)
+
+	var successes, warnings, failures, exceptions int
+	for _, result := range results {
+		successes += result.Successes
+		warnings += len(result.Warnings)
+		failures += len(result.Failures)
+		exceptions += len(result.Exceptions)
+	}
+
+	summary, color := summarize(successes, warnings, failures, exceptions)
+	fmt.Fprintln(s.Writer, colorizer.Colorize(summary, color))
+
+	return nil
+}
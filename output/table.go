@@ -37,11 +37,11 @@ func (t *Table) Output(checkResults []CheckResult) error {
 		}
 
 		for _, result := range checkResult.Warnings {
-			table.Append([]string{"warning", checkResult.FileName, checkResult.Namespace, result.Message})
+			table.Append([]string{result.label("warning"), checkResult.FileName, checkResult.Namespace, result.Message})
 		}
 
 		for _, result := range checkResult.Failures {
-			table.Append([]string{"failure", checkResult.FileName, checkResult.Namespace, result.Message})
+			table.Append([]string{result.label("failure"), checkResult.FileName, checkResult.Namespace, result.Message})
 		}
 	}
 
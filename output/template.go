@@ -0,0 +1,106 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// Template represents an Outputter that outputs
+// results using a user supplied Go template.
+type Template struct {
+	Writer   io.Writer
+	Template *template.Template
+}
+
+// templateResult is the representation of a CheckResult
+// that is made available to the template.
+type templateResult struct {
+	Filename   string
+	Namespace  string
+	Successes  int
+	Failures   []Result
+	Warnings   []Result
+	Exceptions []Result
+	Success    bool
+}
+
+var templateColors = map[string]aurora.Color{
+	"red":     aurora.RedFg,
+	"green":   aurora.GreenFg,
+	"yellow":  aurora.YellowFg,
+	"blue":    aurora.BlueFg,
+	"magenta": aurora.MagentaFg,
+	"cyan":    aurora.CyanFg,
+	"white":   aurora.WhiteFg,
+}
+
+// NewTemplate creates a new Template with the given writer. The source is
+// either the path to a file that contains the template, or the template itself.
+// An error is returned if the template could not be parsed.
+func NewTemplate(w io.Writer, source string, noColor bool) (*Template, error) {
+	text := source
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		contents, err := ioutil.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("read template: %w", err)
+		}
+
+		text = string(contents)
+	}
+
+	colorizer := aurora.NewAurora(!noColor)
+	functions := template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"color": func(name string, value interface{}) (string, error) {
+			color, ok := templateColors[name]
+			if !ok {
+				return "", fmt.Errorf("unknown color: %v", name)
+			}
+
+			return colorizer.Colorize(value, color).String(), nil
+		},
+	}
+
+	parsed, err := template.New("output").Funcs(functions).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	templateOutput := Template{
+		Writer:   w,
+		Template: parsed,
+	}
+
+	return &templateOutput, nil
+}
+
+// Output outputs the results.
+func (t *Template) Output(checkResults []CheckResult) error {
+	results := []templateResult{}
+	for _, checkResult := range checkResults {
+		result := templateResult{
+			Filename:   checkResult.FileName,
+			Namespace:  checkResult.Namespace,
+			Successes:  checkResult.Successes,
+			Failures:   checkResult.Failures,
+			Warnings:   checkResult.Warnings,
+			Exceptions: checkResult.Exceptions,
+			Success:    len(checkResult.Failures) == 0,
+		}
+
+		results = append(results, result)
+	}
+
+	if err := t.Template.Execute(t.Writer, results); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	input := []CheckResult{
+		{
+			FileName:  "examples/kubernetes/service.yaml",
+			Namespace: "namespace",
+			Successes: 1,
+			Warnings:  []Result{{Message: "first warning"}},
+			Failures:  []Result{{Message: "first failure"}},
+		},
+		{
+			FileName:  "examples/kubernetes/deployment.yaml",
+			Namespace: "namespace",
+			Successes: 2,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		noColor  bool
+		expected string
+	}{
+		{
+			name:     "exposes the fields of the results",
+			template: `{{range .}}{{.Filename}} {{.Success}} {{len .Failures}} {{len .Warnings}} {{.Successes}}{{"\n"}}{{end}}`,
+			expected: "examples/kubernetes/service.yaml false 1 1 1\nexamples/kubernetes/deployment.yaml true 0 0 2\n",
+		},
+		{
+			name:     "provides helper functions",
+			template: `{{range .}}{{range .Failures}}{{upper .Message}} {{color "red" "FAIL"}}{{end}}{{end}}`,
+			expected: "FIRST FAILURE \x1b[31mFAIL\x1b[0m",
+		},
+		{
+			name:     "does not color when color is disabled",
+			template: `{{range .}}{{range .Failures}}{{color "red" "FAIL"}}{{end}}{{end}}`,
+			noColor:  true,
+			expected: "FAIL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			template, err := NewTemplate(buf, tt.template, tt.noColor)
+			if err != nil {
+				t.Fatal("new template:", err)
+			}
+
+			if err := template.Output(input); err != nil {
+				t.Fatal("output template:", err)
+			}
+			actual := buf.String()
+
+			if tt.expected != actual {
+				t.Errorf("Unexpected output. expected %q actual %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTemplateFromFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatal("create temp dir:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "output.tmpl")
+	if err := ioutil.WriteFile(path, []byte(`{{len .}} results`), 0644); err != nil {
+		t.Fatal("write template:", err)
+	}
+
+	buf := new(bytes.Buffer)
+	template, err := NewTemplate(buf, path, true)
+	if err != nil {
+		t.Fatal("new template:", err)
+	}
+
+	if err := template.Output([]CheckResult{{}, {}}); err != nil {
+		t.Fatal("output template:", err)
+	}
+
+	if expected, actual := "2 results", buf.String(); expected != actual {
+		t.Errorf("Unexpected output. expected %q actual %q", expected, actual)
+	}
+}
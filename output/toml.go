@@ -0,0 +1,112 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TOML represents an Outputter that outputs
+// results in TOML format.
+type TOML struct {
+	Writer io.Writer
+}
+
+// NewTOML creates a new TOML with the given writer.
+func NewTOML(w io.Writer) *TOML {
+	tomlOutput := TOML{
+		Writer: w,
+	}
+
+	return &tomlOutput
+}
+
+// Output outputs the results.
+//
+// The results have the same structure as the results of the JSON format,
+// but as a TOML document cannot be a list, the results are in a list of
+// tables named results. Values that are null, which TOML does not support,
+// are left out.
+func (t *TOML) Output(results []CheckResult) error {
+	for r := range results {
+		if results[r].FileName == "-" {
+			results[r].FileName = ""
+		}
+
+		results[r].Queries = nil
+	}
+
+	sortCheckResults(results)
+
+	// The results are converted through JSON so that the keys are the same
+	// as the keys of the JSON format, and the numbers keep their types.
+	b, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var documents []interface{}
+	if err := decoder.Decode(&documents); err != nil {
+		return fmt.Errorf("unmarshal json: %w", err)
+	}
+
+	for d := range documents {
+		documents[d] = tomlValue(documents[d])
+	}
+
+	var out bytes.Buffer
+	encoder := toml.NewEncoder(&out)
+	encoder.Indent = ""
+	if err := encoder.Encode(map[string]interface{}{"results": documents}); err != nil {
+		return fmt.Errorf("marshal toml: %w", err)
+	}
+
+	fmt.Fprint(t.Writer, out.String())
+	return nil
+}
+
+// tomlValue returns the given value that was decoded from JSON without any
+// of the null values that it contains, and with its numbers converted to
+// integers and floats.
+func tomlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if item == nil {
+				delete(v, key)
+				continue
+			}
+
+			v[key] = tomlValue(item)
+		}
+
+		return v
+
+	case []interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if item != nil {
+				items = append(items, tomlValue(item))
+			}
+		}
+
+		return items
+
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := v.Float64()
+		return f
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTOML(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Successes: 1,
+		},
+		{
+			FileName:  "-",
+			Namespace: "main",
+			Warnings:  []Result{{Message: "first warning", Rule: "warn"}},
+			Failures: []Result{
+				{Message: "second failure", Rule: "deny"},
+				{Message: "first failure", Rule: "deny", Metadata: map[string]interface{}{"replicas": 2, "ratio": 0.5, "owner": nil}},
+			},
+		},
+	}
+
+	expected := []string{
+		`[[results]]`,
+		`filename = ""`,
+		`namespace = "main"`,
+		`successes = 0`,
+		``,
+		`[[results.failures]]`,
+		`msg = "first failure"`,
+		`rule = "deny"`,
+		`[results.failures.metadata]`,
+		`ratio = 0.5`,
+		`replicas = 2`,
+		``,
+		`[[results.failures]]`,
+		`msg = "second failure"`,
+		`rule = "deny"`,
+		``,
+		`[[results.warnings]]`,
+		`msg = "first warning"`,
+		`rule = "warn"`,
+		``,
+		`[[results]]`,
+		`filename = "service.yaml"`,
+		`namespace = "main"`,
+		`successes = 1`,
+		``,
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewTOML(buf).Output(results); err != nil {
+		t.Fatal("output toml:", err)
+	}
+
+	actual := buf.String()
+	if actual != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected output. expected %v actual %v", strings.Join(expected, "\n"), actual)
+	}
+}
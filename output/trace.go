@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Trace represents an Outputter that only outputs the traces of the
+// queries of the results, in a section for each file and namespace,
+// with a subsection for each query.
+type Trace struct {
+	Writer io.Writer
+}
+
+// NewTrace creates a new Trace with the given writer.
+func NewTrace(w io.Writer) *Trace {
+	trace := Trace{
+		Writer: w,
+	}
+
+	return &trace
+}
+
+// Output outputs the results. The sections are sorted by file name and
+// namespace, and the queries of each section are in the order in which
+// they were evaluated.
+func (t *Trace) Output(results []CheckResult) error {
+	sorted := make([]CheckResult, len(results))
+	copy(sorted, results)
+	sortCheckResults(sorted)
+
+	for _, result := range sorted {
+		fmt.Fprintf(t.Writer, "## %s - %s\n\n", result.FileName, result.Namespace)
+
+		for _, query := range result.Queries {
+			fmt.Fprintf(t.Writer, "### %s\n\n", query.Query)
+
+			for _, line := range query.Traces {
+				fmt.Fprintln(t.Writer, line)
+			}
+
+			fmt.Fprintln(t.Writer)
+		}
+	}
+
+	return nil
+}
+
+// TraceFile represents an Outputter that writes the traces of the results to
+// a file, and then outputs the results to another Outputter, so that the
+// traces do not flood the output of the results.
+type TraceFile struct {
+	Path string
+
+	// Echo is the Outputter that the results are output to after the
+	// traces are written to the file.
+	Echo Outputter
+}
+
+// Output outputs the results. The file is created, along with its parent
+// directories, every time the results are output, so that the file only ever
+// contains the traces of the last output.
+func (f *TraceFile) Output(results []CheckResult) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), os.ModePerm); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	file, err := os.Create(f.Path)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer file.Close()
+
+	if err := NewTrace(file).Output(results); err != nil {
+		return fmt.Errorf("output traces: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
+
+	if f.Echo != nil {
+		if err := f.Echo.Output(results); err != nil {
+			return fmt.Errorf("echo: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	results := []CheckResult{
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Queries:   []QueryResult{{Query: "data.main.deny", Traces: []string{"Enter data.main.deny = _"}}},
+		},
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Queries: []QueryResult{
+				{Query: "data.main.deny", Traces: []string{"Enter data.main.deny = _", "| Eval data.main.deny = _"}},
+				{Query: "data.main.warn"},
+			},
+		},
+	}
+
+	expected := strings.Join([]string{
+		"## deployment.yaml - main",
+		"",
+		"### data.main.deny",
+		"",
+		"Enter data.main.deny = _",
+		"| Eval data.main.deny = _",
+		"",
+		"### data.main.warn",
+		"",
+		"",
+		"## service.yaml - main",
+		"",
+		"### data.main.deny",
+		"",
+		"Enter data.main.deny = _",
+		"",
+		"",
+	}, "\n")
+
+	buf := new(bytes.Buffer)
+	if err := NewTrace(buf).Output(results); err != nil {
+		t.Fatal("output trace:", err)
+	}
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Unexpected output. expected %q actual %q", expected, actual)
+	}
+
+	if results[0].FileName != "service.yaml" {
+		t.Error("The order of the given results must not be changed")
+	}
+}
+
+func TestTraceFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	results := []CheckResult{{FileName: "deployment.yaml", Namespace: "main", Successes: 1}}
+
+	echo := new(bytes.Buffer)
+	path := filepath.Join(dir, "traces", "trace.txt")
+	traceFile := TraceFile{Path: path, Echo: &Summary{Writer: echo, NoColor: true}}
+	if err := traceFile.Output(results); err != nil {
+		t.Fatal("output trace file:", err)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read trace file: %v", err)
+	}
+
+	if !strings.HasPrefix(string(contents), "## deployment.yaml - main") {
+		t.Errorf("Unexpected contents of the trace file: %q", contents)
+	}
+
+	if !strings.Contains(echo.String(), "1 test, 1 passed") {
+		t.Errorf("Expected the results to be echoed, got %q", echo.String())
+	}
+}
@@ -0,0 +1,207 @@
+package cloudformation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parser is a parser for AWS CloudFormation templates, in either YAML or JSON.
+//
+// The intrinsic functions of the templates are always in their long form,
+// so that policies can inspect them regardless of how they are written. The
+// short form tags of YAML templates are converted to the long form, e.g.
+// !Ref Bucket becomes {"Ref": "Bucket"}, !Sub becomes {"Fn::Sub": ...}, and
+// !GetAtt Bucket.Arn becomes {"Fn::GetAtt": ["Bucket", "Arn"]}.
+type Parser struct{}
+
+// templateRegexp matches the top-level keys of YAML templates that
+// only CloudFormation templates have.
+var templateRegexp = regexp.MustCompile(`(?m)^["']?(AWSTemplateFormatVersion|Resources)["']?\s*:`)
+
+// IsTemplate reports whether the given YAML or JSON contents are the
+// contents of a CloudFormation template, which have a top-level
+// AWSTemplateFormatVersion or Resources key.
+func IsTemplate(contents []byte) bool {
+	trimmed := bytes.TrimSpace(contents)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return templateRegexp.Match(contents)
+	}
+
+	if !bytes.Contains(trimmed, []byte(`"Resources"`)) && !bytes.Contains(trimmed, []byte(`"AWSTemplateFormatVersion"`)) {
+		return false
+	}
+
+	var template map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &template); err != nil {
+		return false
+	}
+
+	_, hasVersion := template["AWSTemplateFormatVersion"]
+	_, hasResources := template["Resources"]
+	return hasVersion || hasResources
+}
+
+// Unmarshal unmarshals CloudFormation templates. When the file contains
+// more than one document, the documents are unmarshaled as a list.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var documents []interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unmarshal template: %w", err)
+		}
+
+		document, err := convert(&node)
+		if err != nil {
+			return fmt.Errorf("convert template: %w", err)
+		}
+
+		documents = append(documents, document)
+	}
+
+	var result interface{}
+	if len(documents) == 1 {
+		result = documents[0]
+	} else if len(documents) > 1 {
+		result = documents
+	}
+
+	j, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal template: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal template json: %w", err)
+	}
+
+	return nil
+}
+
+// convert converts the given YAML node into the values that the JSON
+// decoder produces, converting the intrinsic functions to their long form.
+func convert(node *yaml.Node) (interface{}, error) {
+	if isIntrinsic(node.Tag) {
+		return convertIntrinsic(node)
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+
+		return convert(node.Content[0])
+
+	case yaml.AliasNode:
+		return convert(node.Alias)
+
+	case yaml.MappingNode:
+		mapping := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := convert(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			mapping[node.Content[i].Value] = value
+		}
+
+		return mapping, nil
+
+	case yaml.SequenceNode:
+		sequence := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := convert(item)
+			if err != nil {
+				return nil, err
+			}
+
+			sequence = append(sequence, value)
+		}
+
+		return sequence, nil
+	}
+
+	return convertScalar(node)
+}
+
+// convertScalar converts the given scalar, where numbers are float64s
+// as they are when decoding JSON.
+func convertScalar(node *yaml.Node) (interface{}, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var value bool
+		if err := node.Decode(&value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+
+		return value, nil
+	case "!!int", "!!float":
+		var value float64
+		if err := node.Decode(&value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+
+		return value, nil
+	}
+
+	return node.Value, nil
+}
+
+// isIntrinsic reports whether the tag is the short form of an intrinsic
+// function, e.g. !Ref, rather than one of the standard tags of YAML.
+func isIntrinsic(tag string) bool {
+	return strings.HasPrefix(tag, "!") && !strings.HasPrefix(tag, "!!")
+}
+
+// convertIntrinsic converts the short form of an intrinsic function into its
+// long form, which is an object with the name of the function as its only key.
+func convertIntrinsic(node *yaml.Node) (interface{}, error) {
+	name := strings.TrimPrefix(node.Tag, "!")
+
+	untagged := *node
+	untagged.Tag = ""
+	if node.Kind == yaml.ScalarNode {
+		untagged.Tag = "!!str"
+	}
+
+	value, err := convert(&untagged)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "Ref", "Condition":
+		return map[string]interface{}{name: value}, nil
+
+	// The short form of GetAtt is the logical name of the resource and
+	// the name of the attribute, separated by the first dot.
+	case "GetAtt":
+		if attribute, ok := value.(string); ok {
+			parts := strings.SplitN(attribute, ".", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("line %d: !GetAtt %s must be in the form of resource.attribute", node.Line, strconv.Quote(attribute))
+			}
+
+			value = []interface{}{parts[0], parts[1]}
+		}
+	}
+
+	return map[string]interface{}{"Fn::" + name: value}, nil
+}
@@ -0,0 +1,127 @@
+package cloudformation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloudFormationParser(t *testing.T) {
+	testTable := []struct {
+		name     string
+		template string
+		expected interface{}
+	}{
+		{
+			name:     "ref",
+			template: `Value: !Ref Bucket`,
+			expected: map[string]interface{}{"Value": map[string]interface{}{"Ref": "Bucket"}},
+		},
+		{
+			name:     "get attribute",
+			template: `Value: !GetAtt Bucket.Arn`,
+			expected: map[string]interface{}{"Value": map[string]interface{}{"Fn::GetAtt": []interface{}{"Bucket", "Arn"}}},
+		},
+		{
+			name:     "get attribute of a nested attribute",
+			template: `Value: !GetAtt Database.Endpoint.Address`,
+			expected: map[string]interface{}{"Value": map[string]interface{}{"Fn::GetAtt": []interface{}{"Database", "Endpoint.Address"}}},
+		},
+		{
+			name:     "sub",
+			template: `Value: !Sub "arn:aws:s3:::${Bucket}/*"`,
+			expected: map[string]interface{}{"Value": map[string]interface{}{"Fn::Sub": "arn:aws:s3:::${Bucket}/*"}},
+		},
+		{
+			name: "nested functions",
+			template: `Value: !Join
+  - ":"
+  - - !Ref AWS::Region
+    - 8080`,
+			expected: map[string]interface{}{"Value": map[string]interface{}{"Fn::Join": []interface{}{
+				":",
+				[]interface{}{map[string]interface{}{"Ref": "AWS::Region"}, float64(8080)},
+			}}},
+		},
+		{
+			name:     "json template",
+			template: `{"Resources": {"Bucket": {"Type": "AWS::S3::Bucket"}}}`,
+			expected: map[string]interface{}{"Resources": map[string]interface{}{"Bucket": map[string]interface{}{"Type": "AWS::S3::Bucket"}}},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			var actual interface{}
+			parser := &Parser{}
+			if err := parser.Unmarshal([]byte(test.template), &actual); err != nil {
+				t.Fatalf("parser should not have thrown an error: %v", err)
+			}
+
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Errorf("Unexpected template. expected %v, actual %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestShortAndLongFormsAreEqual(t *testing.T) {
+	short := `Resources:
+  Policy:
+    Properties:
+      Bucket: !Ref Bucket
+      Arn: !GetAtt Bucket.Arn
+      Name: !Sub "${AWS::StackName}-policy"`
+
+	long := `{"Resources": {"Policy": {"Properties": {
+  "Bucket": {"Ref": "Bucket"},
+  "Arn": {"Fn::GetAtt": ["Bucket", "Arn"]},
+  "Name": {"Fn::Sub": "${AWS::StackName}-policy"}
+}}}}`
+
+	parser := &Parser{}
+
+	var shortTemplate interface{}
+	if err := parser.Unmarshal([]byte(short), &shortTemplate); err != nil {
+		t.Fatalf("unmarshal short form: %v", err)
+	}
+
+	var longTemplate interface{}
+	if err := parser.Unmarshal([]byte(long), &longTemplate); err != nil {
+		t.Fatalf("unmarshal long form: %v", err)
+	}
+
+	if !reflect.DeepEqual(shortTemplate, longTemplate) {
+		t.Errorf("Unexpected template. expected %v, actual %v", longTemplate, shortTemplate)
+	}
+}
+
+func TestInvalidGetAttribute(t *testing.T) {
+	var actual interface{}
+	parser := &Parser{}
+	if err := parser.Unmarshal([]byte(`Value: !GetAtt Bucket`), &actual); err == nil {
+		t.Error("expected an error for a GetAtt without an attribute")
+	}
+}
+
+func TestIsTemplate(t *testing.T) {
+	testTable := []struct {
+		name     string
+		contents string
+		expected bool
+	}{
+		{name: "yaml template", contents: "AWSTemplateFormatVersion: 2010-09-09\nResources: {}", expected: true},
+		{name: "yaml resources", contents: "Resources:\n  Bucket:\n    Type: AWS::S3::Bucket", expected: true},
+		{name: "nested resources", contents: "spec:\n  Resources: {}", expected: false},
+		{name: "kubernetes manifest", contents: "apiVersion: v1\nkind: Pod", expected: false},
+		{name: "json template", contents: `{"Resources": {}}`, expected: true},
+		{name: "nested json resources", contents: `{"spec": {"Resources": {}}}`, expected: false},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := IsTemplate([]byte(test.contents)); actual != test.expected {
+				t.Errorf("Unexpected result. expected %v, actual %v", test.expected, actual)
+			}
+		})
+	}
+}
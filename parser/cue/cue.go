@@ -3,25 +3,40 @@ package cue
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"cuelang.org/go/cue"
-	cformat "cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/errors"
 )
 
 // Parser is a CUE parser.
-type Parser struct{}
+type Parser struct {
+	path string
+}
 
-// Unmarshal unmarshals CUE files.
-func (*Parser) Unmarshal(p []byte, v interface{}) error {
-	out, err := cformat.Source(p)
-	if err != nil {
-		return fmt.Errorf("format cue: %w", err)
+// SetPath sets the path of the file being parsed so that
+// errors refer to the file that they were found in.
+func (p *Parser) SetPath(path string) {
+	p.path = path
+}
+
+// Unmarshal unmarshals CUE files. The configuration is evaluated to concrete
+// values, and values that are incomplete or conflicting are reported as errors.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	fileName := "-"
+	if p.path != "" {
+		fileName = filepath.Base(p.path)
 	}
 
 	var r cue.Runtime
-	instance, err := r.Compile("name", out)
+	instance, err := r.Compile(fileName, data)
 	if err != nil {
-		return fmt.Errorf("compile cue: %w", err)
+		return fmt.Errorf("compile cue: %s", formatErrors(err))
+	}
+
+	if err := instance.Value().Validate(cue.Concrete(true)); err != nil {
+		return fmt.Errorf("evaluate cue: %s", formatErrors(err))
 	}
 
 	j, err := instance.Value().MarshalJSON()
@@ -35,3 +50,48 @@ func (*Parser) Unmarshal(p []byte, v interface{}) error {
 
 	return nil
 }
+
+// formatErrors formats each of the errors with the path of the
+// value and the positions in the file that caused the error,
+// e.g. spec.replicas: incomplete value (int) at config.cue:3:12.
+func formatErrors(err error) string {
+	var messages []string
+	for _, cueErr := range errors.Errors(err) {
+		message := cueErr.Error()
+		if path := cueErr.Path(); len(path) > 0 {
+			message = strings.Join(path, ".") + ": " + message
+		}
+
+		var positions []string
+		for _, position := range cueErr.InputPositions() {
+			if position.IsValid() && !containsString(positions, position.String()) {
+				positions = append(positions, position.String())
+			}
+		}
+		if len(positions) == 0 && cueErr.Position().IsValid() {
+			positions = append(positions, cueErr.Position().String())
+		}
+
+		if len(positions) > 0 {
+			message += " at " + strings.Join(positions, ", ")
+		}
+
+		messages = append(messages, message)
+	}
+
+	if len(messages) == 0 {
+		return err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func containsString(collection []string, item string) bool {
+	for _, value := range collection {
+		if value == item {
+			return true
+		}
+	}
+
+	return false
+}
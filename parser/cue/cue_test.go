@@ -37,3 +37,39 @@ func TestCueParser(t *testing.T) {
 		t.Error("There should be at least one item defined in the parsed file, but none found")
 	}
 }
+
+func TestCueParserErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "incomplete value",
+			input:    "spec: {\n\treplicas: int\n}",
+			expected: "evaluate cue: spec.replicas: incomplete value (int) at deployment.cue:2:12",
+		},
+		{
+			name:     "conflicting values",
+			input:    "replicas: 1\nreplicas: 2",
+			expected: "evaluate cue: replicas: conflicting values 1 and 2 at deployment.cue:1:11, deployment.cue:2:11",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := &Parser{}
+			parser.SetPath("examples/cue/deployment.cue")
+
+			var input interface{}
+			err := parser.Unmarshal([]byte(testCase.input), &input)
+			if err == nil {
+				t.Fatal("parser should have thrown an error")
+			}
+
+			if err.Error() != testCase.expected {
+				t.Errorf("Unexpected error. expected %v actual %v", testCase.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package cyclonedx
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Parser is a parser for CycloneDX software bills of materials in JSON.
+//
+// The result has the document itself under document, and the components of
+// the document, including the components that are nested within other
+// components, under packages. Each of the packages has the same fields
+// regardless of the format of the SBOM, i.e. id, name, version, license
+// and purl, where the license is the licenses of the component joined
+// with AND, e.g. MIT AND Apache-2.0.
+type Parser struct{}
+
+type bom struct {
+	BOMFormat  string      `json:"bomFormat"`
+	Components []component `json:"components"`
+}
+
+type component struct {
+	BOMRef     string      `json:"bom-ref"`
+	Name       string      `json:"name"`
+	Version    string      `json:"version"`
+	PURL       string      `json:"purl"`
+	Licenses   []license   `json:"licenses"`
+	Components []component `json:"components"`
+}
+
+// license is either a license with an SPDX id or a name,
+// or an SPDX license expression.
+type license struct {
+	License struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"license"`
+	Expression string `json:"expression"`
+}
+
+// Unmarshal unmarshals CycloneDX SBOMs.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var b bom
+	if err := json.Unmarshal(data, &b); err != nil {
+		return fmt.Errorf("unmarshal cyclonedx: %w", err)
+	}
+
+	if b.BOMFormat != "CycloneDX" {
+		return fmt.Errorf("bomFormat must be CycloneDX, got %q", b.BOMFormat)
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("unmarshal cyclonedx document: %w", err)
+	}
+
+	packages := make([]map[string]string, 0, len(b.Components))
+	packages = appendPackages(packages, b.Components)
+
+	result := map[string]interface{}{
+		"document": document,
+		"packages": packages,
+	}
+
+	j, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal cyclonedx to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal cyclonedx json: %w", err)
+	}
+
+	return nil
+}
+
+// appendPackages appends the packages of the given components to the
+// packages, where each component is followed by its nested components.
+func appendPackages(packages []map[string]string, components []component) []map[string]string {
+	for _, c := range components {
+		packages = append(packages, map[string]string{
+			"id":      c.BOMRef,
+			"name":    c.Name,
+			"version": c.Version,
+			"license": licenseExpression(c.Licenses),
+			"purl":    c.PURL,
+		})
+
+		packages = appendPackages(packages, c.Components)
+	}
+
+	return packages
+}
+
+func licenseExpression(licenses []license) string {
+	var expressions []string
+	for _, l := range licenses {
+		switch {
+		case l.Expression != "":
+			expressions = append(expressions, l.Expression)
+		case l.License.ID != "":
+			expressions = append(expressions, l.License.ID)
+		case l.License.Name != "":
+			expressions = append(expressions, l.License.Name)
+		}
+	}
+
+	// Expressions are parenthesized when they are combined with other
+	// licenses, so that their operators keep their precedence.
+	if len(expressions) > 1 {
+		for i, expression := range expressions {
+			if strings.Contains(expression, " ") {
+				expressions[i] = "(" + expression + ")"
+			}
+		}
+	}
+
+	return strings.Join(expressions, " AND ")
+}
@@ -0,0 +1,76 @@
+package cyclonedx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCycloneDXParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "components": [
+    {
+      "type": "library",
+      "bom-ref": "pkg:npm/express@4.18.2",
+      "name": "express",
+      "version": "4.18.2",
+      "purl": "pkg:npm/express@4.18.2",
+      "licenses": [{"license": {"id": "MIT"}}],
+      "components": [
+        {
+          "type": "library",
+          "name": "debug",
+          "version": "2.6.9",
+          "purl": "pkg:npm/debug@2.6.9",
+          "licenses": [{"license": {"name": "Custom"}}, {"expression": "MIT OR Apache-2.0"}]
+        }
+      ]
+    }
+  ]
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"id":      "pkg:npm/express@4.18.2",
+			"name":    "express",
+			"version": "4.18.2",
+			"license": "MIT",
+			"purl":    "pkg:npm/express@4.18.2",
+		},
+		map[string]interface{}{
+			"id":      "",
+			"name":    "debug",
+			"version": "2.6.9",
+			"license": "Custom AND (MIT OR Apache-2.0)",
+			"purl":    "pkg:npm/debug@2.6.9",
+		},
+	}
+
+	result := input.(map[string]interface{})
+	if !reflect.DeepEqual(expected, result["packages"]) {
+		t.Errorf("Unexpected packages. expected %v actual %v", expected, result["packages"])
+	}
+
+	document := result["document"].(map[string]interface{})
+	if document["specVersion"] != "1.4" {
+		t.Errorf("expected the document to be kept, got %v", document)
+	}
+}
+
+func TestCycloneDXParserInvalid(t *testing.T) {
+	parser := &Parser{}
+
+	for _, sample := range []string{`{"components": []}`, `{"bomFormat": "CycloneDX"`} {
+		var input interface{}
+		if err := parser.Unmarshal([]byte(sample), &input); err == nil {
+			t.Errorf("expected an error for %q", sample)
+		}
+	}
+}
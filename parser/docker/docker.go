@@ -4,20 +4,57 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/moby/buildkit/frontend/dockerfile/shell"
 )
 
 // Parser is a Dockerfile parser.
-type Parser struct{}
+type Parser struct {
+	// BuildArgs are the values of the build arguments, as passed to
+	// docker build with --build-arg. They take precedence over the
+	// defaults declared with ARG.
+	BuildArgs map[string]string
+
+	// Stages represents the Dockerfile as a list of its build stages,
+	// rather than a flat list of commands.
+	Stages bool
+}
 
 // Command represents a command in a Dockerfile.
 type Command struct {
-	Cmd    string   // lowercased command name (ex: `from`)
-	SubCmd string   // for ONBUILD only this holds the sub-command
-	JSON   bool     // whether the value is written in json form
-	Flags  []string // Any flags such as `--from=...` for `COPY`.
-	Value  []string // The contents of the command (ex: `ubuntu:xenial`)
+	Cmd      string   // lowercased command name (ex: `from`)
+	SubCmd   string   // for ONBUILD only this holds the sub-command
+	JSON     bool     // whether the value is written in json form
+	Flags    []string // Any flags such as `--from=...` for `COPY`.
+	Value    []string // The contents of the command (ex: `ubuntu:${TAG}`)
+	Resolved []string // The contents with ARG and ENV substituted (ex: `ubuntu:xenial`)
+	Stage    int      // The index of the build stage, or -1 for commands before the first FROM
+}
+
+// Stage represents a build stage of a multi-stage Dockerfile.
+type Stage struct {
+	Name     string    // The name of the stage (ex: `builder` for `FROM golang AS builder`)
+	From     string    // The resolved base image of the stage
+	Commands []Command // The commands of the stage, including the FROM command
+}
+
+// Commands whose values are expanded by the builder. The values of
+// commands such as RUN are expanded by the shell at build time instead.
+var expandedCommands = map[string]bool{
+	"add":        true,
+	"arg":        true,
+	"copy":       true,
+	"env":        true,
+	"expose":     true,
+	"from":       true,
+	"label":      true,
+	"stopsignal": true,
+	"user":       true,
+	"volume":     true,
+	"workdir":    true,
+	"onbuild":    true,
 }
 
 // Unmarshal unmarshals Dockerfiles
@@ -28,9 +65,17 @@ func (dp *Parser) Unmarshal(p []byte, v interface{}) error {
 		return fmt.Errorf("parse dockerfile: %w", err)
 	}
 
+	lex := shell.NewLex(res.EscapeToken)
+
+	// Build arguments declared before the first FROM are only in scope of
+	// the FROM commands, unless they are declared again within a stage.
+	globalArgs := make(map[string]string)
+	var env map[string]string
+
 	// Code attributed to https://github.com/asottile/dockerfile
 	// TODO: Just import the package
 	var commands []Command
+	stage := -1
 	for _, child := range res.AST.Children {
 		cmd := Command{
 			Cmd:   child.Value,
@@ -47,11 +92,43 @@ func (dp *Parser) Unmarshal(p []byte, v interface{}) error {
 			cmd.Value = append(cmd.Value, n.Value)
 		}
 
+		if cmd.Cmd == "from" {
+			stage++
+			env = make(map[string]string)
+		}
+		cmd.Stage = stage
+
+		scope := env
+		if stage < 0 || cmd.Cmd == "from" {
+			scope = globalArgs
+		}
+
+		cmd.Resolved = cmd.Value
+		if expandedCommands[cmd.Cmd] {
+			cmd.Resolved, err = resolve(lex, cmd.Value, scope)
+			if err != nil {
+				return fmt.Errorf("resolve %s: %w", cmd.Cmd, err)
+			}
+		}
+
+		switch cmd.Cmd {
+		case "arg":
+			dp.declareArgs(cmd.Resolved, scope, globalArgs)
+		case "env":
+			for i := 0; i+1 < len(cmd.Resolved); i += 2 {
+				env[cmd.Resolved[i]] = cmd.Resolved[i+1]
+			}
+		}
+
 		commands = append(commands, cmd)
 	}
 
-	var dockerFile [][]Command
-	dockerFile = append(dockerFile, commands)
+	var dockerFile []interface{}
+	if dp.Stages {
+		dockerFile = append(dockerFile, stages(commands))
+	} else {
+		dockerFile = append(dockerFile, commands)
+	}
 
 	j, err := json.Marshal(dockerFile)
 	if err != nil {
@@ -64,3 +141,66 @@ func (dp *Parser) Unmarshal(p []byte, v interface{}) error {
 
 	return nil
 }
+
+// declareArgs adds the build arguments declared by an ARG command to the
+// given scope. An argument without a default inherits the value of the
+// global argument with the same name.
+func (dp *Parser) declareArgs(values []string, scope map[string]string, globalArgs map[string]string) {
+	for _, value := range values {
+		name := value
+		defaultValue, hasDefault := "", false
+		if index := strings.Index(value, "="); index >= 0 {
+			name, defaultValue, hasDefault = value[:index], value[index+1:], true
+		}
+
+		switch buildArg, ok := dp.BuildArgs[name]; {
+		case ok:
+			scope[name] = buildArg
+		case hasDefault:
+			scope[name] = defaultValue
+		default:
+			if globalValue, ok := globalArgs[name]; ok {
+				scope[name] = globalValue
+			}
+		}
+	}
+}
+
+func resolve(lex *shell.Lex, values []string, scope map[string]string) ([]string, error) {
+	resolved := make([]string, 0, len(values))
+	for _, value := range values {
+		word, err := lex.ProcessWordWithMap(value, scope)
+		if err != nil {
+			return nil, fmt.Errorf("process word %q: %w", value, err)
+		}
+
+		resolved = append(resolved, word)
+	}
+
+	return resolved, nil
+}
+
+func stages(commands []Command) []Stage {
+	var stages []Stage
+	for _, cmd := range commands {
+		if cmd.Stage < 0 {
+			continue
+		}
+
+		if cmd.Cmd == "from" {
+			stage := Stage{}
+			if len(cmd.Resolved) > 0 {
+				stage.From = cmd.Resolved[0]
+			}
+			if len(cmd.Resolved) > 2 && strings.EqualFold(cmd.Resolved[1], "as") {
+				stage.Name = cmd.Resolved[2]
+			}
+
+			stages = append(stages, stage)
+		}
+
+		stages[cmd.Stage].Commands = append(stages[cmd.Stage].Commands, cmd)
+	}
+
+	return stages
+}
@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -31,3 +32,77 @@ RUN echo hello`
 		t.Errorf("first Docker command should be '%v', was '%v'", expected, actual)
 	}
 }
+
+func TestParser_UnmarshalResolved(t *testing.T) {
+	parser := Parser{BuildArgs: map[string]string{"BASE": "alpine:3.12"}}
+
+	sample := `ARG VERSION=1.16
+ARG BASE=scratch
+FROM golang:${VERSION} AS build
+ARG VERSION
+ENV APP=/src/app
+COPY . $APP
+RUN go build -o /bin/app $APP
+FROM $BASE
+COPY --from=build /bin/app /bin/app`
+
+	var commands []Command
+	if err := parser.Unmarshal([]byte(sample), &[]interface{}{&commands}); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	tests := []struct {
+		index    int
+		value    []string
+		resolved []string
+		stage    int
+	}{
+		{0, []string{"VERSION=1.16"}, []string{"VERSION=1.16"}, -1},
+		{2, []string{"golang:${VERSION}", "AS", "build"}, []string{"golang:1.16", "AS", "build"}, 0},
+		{5, []string{".", "$APP"}, []string{".", "/src/app"}, 0},
+		{6, []string{"go build -o /bin/app $APP"}, []string{"go build -o /bin/app $APP"}, 0},
+		{7, []string{"$BASE"}, []string{"alpine:3.12"}, 1},
+	}
+
+	for _, tt := range tests {
+		cmd := commands[tt.index]
+		if !reflect.DeepEqual(cmd.Value, tt.value) {
+			t.Errorf("command %d: unexpected value %v, want %v", tt.index, cmd.Value, tt.value)
+		}
+
+		if !reflect.DeepEqual(cmd.Resolved, tt.resolved) {
+			t.Errorf("command %d: unexpected resolved value %v, want %v", tt.index, cmd.Resolved, tt.resolved)
+		}
+
+		if cmd.Stage != tt.stage {
+			t.Errorf("command %d: unexpected stage %d, want %d", tt.index, cmd.Stage, tt.stage)
+		}
+	}
+}
+
+func TestParser_UnmarshalStages(t *testing.T) {
+	parser := Parser{Stages: true}
+
+	sample := `ARG VERSION=1.16
+FROM golang:${VERSION} AS build
+RUN go build -o /bin/app .
+FROM alpine
+COPY --from=build /bin/app /bin/app`
+
+	var stages []Stage
+	if err := parser.Unmarshal([]byte(sample), &[]interface{}{&stages}); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if len(stages) != 2 {
+		t.Fatalf("expected 2 stages, got %d", len(stages))
+	}
+
+	if stages[0].Name != "build" || stages[0].From != "golang:1.16" || len(stages[0].Commands) != 2 {
+		t.Errorf("unexpected first stage: %+v", stages[0])
+	}
+
+	if stages[1].Name != "" || stages[1].From != "alpine" || len(stages[1].Commands) != 2 {
+		t.Errorf("unexpected second stage: %+v", stages[1])
+	}
+}
@@ -1,7 +1,11 @@
 package edn
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"sort"
+	"time"
 
 	"olympos.io/encoding/edn"
 )
@@ -9,7 +13,10 @@ import (
 // Parser is an EDN parser.
 type Parser struct{}
 
-// Unmarshal unmarshals EDN encoded files.
+// Unmarshal unmarshals EDN encoded files. Keywords are strings that start
+// with a colon, e.g. :db/name, both when they are used as keys and as values,
+// so that they can be compared with the keywords in the file. Booleans, numbers
+// and nil keep their type, so policies can inspect them the same as JSON.
 func (tp *Parser) Unmarshal(p []byte, v interface{}) error {
 	var res interface{}
 
@@ -17,7 +24,14 @@ func (tp *Parser) Unmarshal(p []byte, v interface{}) error {
 		return fmt.Errorf("unmarshal EDN: %w", err)
 	}
 
-	*v.(*interface{}) = cleanupMapValue(res)
+	j, err := json.Marshal(cleanupMapValue(res))
+	if err != nil {
+		return fmt.Errorf("marshal EDN to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal EDN json: %w", err)
+	}
 
 	return nil
 }
@@ -38,14 +52,47 @@ func cleanupInterfaceMap(in map[interface{}]interface{}) map[string]interface{}
 	return res
 }
 
+// cleanupInterfaceSet converts a set into a list, which is sorted
+// so that the order of the elements is the same for every run.
+func cleanupInterfaceSet(in map[interface{}]bool) []interface{} {
+	var keys []string
+	elements := make(map[string]interface{})
+	for k := range in {
+		key := fmt.Sprintf("%v", k)
+		keys = append(keys, key)
+		elements[key] = cleanupMapValue(k)
+	}
+	sort.Strings(keys)
+
+	res := make([]interface{}, len(keys))
+	for i, key := range keys {
+		res[i] = elements[key]
+	}
+	return res
+}
+
 func cleanupMapValue(v interface{}) interface{} {
 	switch v := v.(type) {
 	case []interface{}:
 		return cleanupInterfaceArray(v)
 	case map[interface{}]interface{}:
 		return cleanupInterfaceMap(v)
-	case string:
+	case map[interface{}]bool:
+		return cleanupInterfaceSet(v)
+	case nil, string, bool, int64, float64:
 		return v
+	case rune:
+		return string(v)
+	case edn.Rune:
+		return string(rune(v))
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *big.Int:
+		return v.String()
+	case *big.Float:
+		return v.Text('g', -1)
+	case edn.Tag:
+		return cleanupMapValue(v.Value)
 	default:
 		return fmt.Sprintf("%v", v)
 	}
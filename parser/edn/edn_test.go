@@ -17,7 +17,7 @@ func TestEDNParser(t *testing.T) {
 			name:           "a single config",
 			controlConfigs: []byte(`{:sample true}`),
 			expectedResult: map[string]interface{}{
-				":sample": "true",
+				":sample": true,
 			},
 		},
 		{
@@ -28,8 +28,34 @@ func TestEDNParser(t *testing.T) {
 :sample3 5432}`),
 			expectedResult: map[string]interface{}{
 				":sample1": "my-username",
-				":sample2": "false",
-				":sample3": "5432",
+				":sample2": false,
+				":sample3": float64(5432),
+			},
+		},
+		{
+			name:           "namespaced keywords",
+			controlConfigs: []byte(`{:db/name "conftest" :db/user :admin}`),
+			expectedResult: map[string]interface{}{
+				":db/name": "conftest",
+				":db/user": ":admin",
+			},
+		},
+		{
+			name:           "nested collections",
+			controlConfigs: []byte(`{:ports [80 [443 8443]] :args ("-v" nil) :tags #{:b :a} :initial \x}`),
+			expectedResult: map[string]interface{}{
+				":ports":   []interface{}{float64(80), []interface{}{float64(443), float64(8443)}},
+				":args":    []interface{}{"-v", nil},
+				":tags":    []interface{}{":a", ":b"},
+				":initial": "x",
+			},
+		},
+		{
+			name:           "tagged values",
+			controlConfigs: []byte(`{:created #inst "2021-01-02T03:04:05Z" :id #uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"}`),
+			expectedResult: map[string]interface{}{
+				":created": "2021-01-02T03:04:05Z",
+				":id":      "f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
 			},
 		},
 	}
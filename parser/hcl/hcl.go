@@ -0,0 +1,67 @@
+package hcl
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/open-policy-agent/conftest/parser/hcl1"
+	"github.com/open-policy-agent/conftest/parser/hcl2"
+)
+
+// hcl1Errors are the summaries of the syntax errors of HCL2 that are
+// caused by syntax that is only valid in HCL1:
+//
+//	"name" = "value"  # quoted argument names
+//	tags.name = "x"   # dotted argument names
+//	port = 0x1F       # hexadecimal numbers
+var hcl1Errors = map[string]bool{
+	"Invalid argument name":                 true,
+	"Argument or block definition required": true,
+	"Missing newline after argument":        true,
+}
+
+// Parser is an HCL parser, which detects the version of the HCL language
+// that the files are written in. Files are parsed as HCL2, unless they are
+// only valid HCL1 files, which are parsed as HCL1.
+type Parser struct {
+	hcl2.Parser
+}
+
+// Unmarshal unmarshals HCL files that are written using either version
+// of the HCL language.
+func (p *Parser) Unmarshal(b []byte, v interface{}) error {
+	if IsHCL1(b) {
+		var parser hcl1.Parser
+		if err := parser.Unmarshal(b, v); err != nil {
+			return fmt.Errorf("unmarshal hcl1: %w", err)
+		}
+
+		return nil
+	}
+
+	return p.Parser.Unmarshal(b, v)
+}
+
+// IsHCL1 reports whether the given file is written in version 1 of the HCL
+// language. A file is HCL1 when it cannot be parsed as HCL2 because of syntax
+// that is only valid in HCL1, such as quoted or dotted argument names, and it
+// can be parsed as HCL1. Other syntax errors are errors of HCL2 files, as HCL1
+// is lenient enough to accept some of the files that are not valid in either
+// version, e.g. an argument without a value.
+func IsHCL1(b []byte) bool {
+	_, diags := hclsyntax.ParseConfig(b, "", hcl.Pos{Line: 1, Column: 1})
+	if !diags.HasErrors() {
+		return false
+	}
+
+	for _, diag := range diags {
+		if diag.Severity == hcl.DiagError && !hcl1Errors[diag.Summary] {
+			return false
+		}
+	}
+
+	var parser hcl1.Parser
+	var parsed interface{}
+	return parser.Unmarshal(b, &parsed) == nil
+}
@@ -0,0 +1,95 @@
+package hcl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsHCL1(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "hcl2",
+			input:    "resource \"aws_instance\" \"web\" {\n  tags = { for k, v in var.tags : k => upper(v) }\n}\n",
+			expected: false,
+		},
+		{
+			name:     "valid in both versions",
+			input:    "provider \"google\" {\n  region = \"europe-west2\"\n}\n",
+			expected: false,
+		},
+		{
+			name:     "quoted argument names",
+			input:    "provider \"aws\" {\n  \"region\" = \"us-east-1\"\n}\n",
+			expected: true,
+		},
+		{
+			name:     "dotted argument names",
+			input:    "variable \"images\" {\n  default.nginx = \"nginx:1.19\"\n}\n",
+			expected: true,
+		},
+		{
+			name:     "hexadecimal numbers",
+			input:    "port = 0x1F90\n",
+			expected: true,
+		},
+		{
+			name:     "missing value",
+			input:    "region = \n",
+			expected: false,
+		},
+		{
+			name:     "invalid in both versions",
+			input:    "provider \"aws\" {\n  \"region\" = \"us-east-1\"\n",
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := IsHCL1([]byte(testCase.input)); actual != testCase.expected {
+				t.Errorf("expected IsHCL1 to be %v, got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestHCLParser(t *testing.T) {
+	parser := &Parser{}
+
+	var hcl1Input interface{}
+	if err := parser.Unmarshal([]byte("provider \"aws\" {\n  \"region\" = \"us-east-1\"\n}\n"), &hcl1Input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"provider": []map[string]interface{}{
+			{"aws": []map[string]interface{}{{"region": "us-east-1"}}},
+		},
+	}
+	if !reflect.DeepEqual(expected, hcl1Input) {
+		t.Errorf("expected the file to be parsed as HCL1 %v, got %v", expected, hcl1Input)
+	}
+
+	var hcl2Input interface{}
+	if err := parser.Unmarshal([]byte("provider \"aws\" {\n  region = upper(\"us-east-1\")\n}\n"), &hcl2Input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected = map[string]interface{}{
+		"provider": map[string]interface{}{
+			"aws": map[string]interface{}{"region": "US-EAST-1"},
+		},
+	}
+	if !reflect.DeepEqual(expected, hcl2Input) {
+		t.Errorf("expected the file to be parsed as HCL2 %v, got %v", expected, hcl2Input)
+	}
+
+	var invalid interface{}
+	if err := parser.Unmarshal([]byte("region = \n"), &invalid); err == nil {
+		t.Error("parser should have thrown an error")
+	}
+}
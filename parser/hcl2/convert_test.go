@@ -1,9 +1,12 @@
 package hcl2
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/open-policy-agent/conftest/parser/position"
 	"github.com/tmccombs/hcl2json/convert"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // This file is mostly attributed to https://github.com/tmccombs/hcl2json
@@ -139,3 +142,164 @@ func TestConversion(t *testing.T) {
 		}
 	}
 }
+
+func TestPositions(t *testing.T) {
+	input := `resource "aws_s3_bucket" "example" {
+  bucket = "example"
+
+  tag {
+    key = "a"
+  }
+
+  tag {
+    key = "b"
+  }
+}`
+
+	positions, err := Parser{}.Positions([]byte(input))
+	if err != nil {
+		t.Fatal("get positions:", err)
+	}
+
+	expected := map[string]position.Position{
+		"resource":                                 {Line: 1, Column: 1},
+		"resource.aws_s3_bucket":                   {Line: 1, Column: 1},
+		"resource.aws_s3_bucket.example":           {Line: 1, Column: 1},
+		"resource.aws_s3_bucket.example.bucket":    {Line: 2, Column: 3},
+		"resource.aws_s3_bucket.example.tag":       {Line: 4, Column: 3},
+		"resource.aws_s3_bucket.example.tag.0":     {Line: 4, Column: 3},
+		"resource.aws_s3_bucket.example.tag.0.key": {Line: 5, Column: 5},
+		"resource.aws_s3_bucket.example.tag.1":     {Line: 8, Column: 3},
+		"resource.aws_s3_bucket.example.tag.1.key": {Line: 9, Column: 5},
+	}
+
+	if !reflect.DeepEqual(expected, positions) {
+		t.Errorf("Expected:\n%v\n\nGot:\n%v", expected, positions)
+	}
+}
+
+func TestUnmarshalEvaluatesStaticExpressions(t *testing.T) {
+	input := `
+variable "env" {
+  default = "dev"
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+
+locals {
+  name   = "${local.prefix}-bucket"
+  prefix = "app-${var.env}"
+  tags   = { env = var.env, owner = var.owner }
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = local.name
+  region = upper(var.region)
+  arn    = "${var.env}-${aws_iam_role.example.arn}"
+  policy = aws_iam_policy.example.json
+  tags   = local.tags
+  count  = "${4 - 2}"
+  list   = [var.env, aws_iam_role.example.id]
+}`
+
+	module := `
+variable "owner" {
+  default = "team"
+}`
+
+	testCases := []struct {
+		name      string
+		parser    Parser
+		variables map[string]interface{}
+		output    map[string]interface{}
+	}{
+		{
+			name:   "defaults",
+			parser: Parser{},
+			output: map[string]interface{}{
+				"bucket": "app-dev-bucket",
+				"region": "US-EAST-1",
+				"arn":    "dev-${aws_iam_role.example.arn}",
+				"policy": "${aws_iam_policy.example.json}",
+				"tags":   "${local.tags}",
+				"count":  float64(2),
+				"list":   []interface{}{"dev", "${aws_iam_role.example.id}"},
+			},
+		},
+		{
+			name:   "variables",
+			parser: Parser{Variables: map[string]cty.Value{"env": cty.StringVal("prod"), "owner": cty.StringVal("ops")}},
+			output: map[string]interface{}{
+				"bucket": "app-prod-bucket",
+				"region": "US-EAST-1",
+				"arn":    "prod-${aws_iam_role.example.arn}",
+				"policy": "${aws_iam_policy.example.json}",
+				"tags":   map[string]interface{}{"env": "prod", "owner": "ops"},
+				"count":  float64(2),
+				"list":   []interface{}{"prod", "${aws_iam_role.example.id}"},
+			},
+		},
+		{
+			name:   "module files",
+			parser: Parser{ModuleFiles: [][]byte{[]byte(module)}},
+			output: map[string]interface{}{
+				"bucket": "app-dev-bucket",
+				"region": "US-EAST-1",
+				"arn":    "dev-${aws_iam_role.example.arn}",
+				"policy": "${aws_iam_policy.example.json}",
+				"tags":   map[string]interface{}{"env": "dev", "owner": "team"},
+				"count":  float64(2),
+				"list":   []interface{}{"dev", "${aws_iam_role.example.id}"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var config map[string]interface{}
+			if err := testCase.parser.Unmarshal([]byte(input), &config); err != nil {
+				t.Fatal("unmarshal:", err)
+			}
+
+			resource := config["resource"].(map[string]interface{})["aws_s3_bucket"].(map[string]interface{})["example"]
+			if !reflect.DeepEqual(testCase.output, resource) {
+				t.Errorf("Expected:\n%v\n\nGot:\n%v", testCase.output, resource)
+			}
+		})
+	}
+}
+
+func TestParseVariables(t *testing.T) {
+	input := `
+env     = "prod"
+regions = ["us-east-1", "eu-west-1"]
+size    = 2 * 3
+`
+
+	variables, err := ParseVariables([]byte(input))
+	if err != nil {
+		t.Fatal("parse variables:", err)
+	}
+
+	expected := map[string]cty.Value{
+		"env":     cty.StringVal("prod"),
+		"regions": cty.TupleVal([]cty.Value{cty.StringVal("us-east-1"), cty.StringVal("eu-west-1")}),
+		"size":    cty.NumberIntVal(6),
+	}
+
+	if len(variables) != len(expected) {
+		t.Fatalf("Expected %d variables, got %d", len(expected), len(variables))
+	}
+
+	for name, value := range expected {
+		if !value.RawEquals(variables[name]) {
+			t.Errorf("Expected variable %s to be %#v, got %#v", name, value, variables[name])
+		}
+	}
+
+	if _, err := ParseVariables([]byte(`env = aws_iam_role.example.arn`)); err == nil {
+		t.Error("expected an error for a variable that cannot be evaluated")
+	}
+}
@@ -0,0 +1,230 @@
+package hcl2
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// functions are the functions that can be called by the expressions that are
+// evaluated, which are the functions of Terraform that do not depend on the
+// file system or the state of the infrastructure.
+var functions = map[string]function.Function{
+	"abs":        stdlib.AbsoluteFunc,
+	"ceil":       stdlib.CeilFunc,
+	"chomp":      stdlib.ChompFunc,
+	"chunklist":  stdlib.ChunklistFunc,
+	"coalesce":   stdlib.CoalesceFunc,
+	"concat":     stdlib.ConcatFunc,
+	"contains":   stdlib.ContainsFunc,
+	"distinct":   stdlib.DistinctFunc,
+	"element":    stdlib.ElementFunc,
+	"flatten":    stdlib.FlattenFunc,
+	"floor":      stdlib.FloorFunc,
+	"format":     stdlib.FormatFunc,
+	"formatlist": stdlib.FormatListFunc,
+	"indent":     stdlib.IndentFunc,
+	"join":       stdlib.JoinFunc,
+	"jsondecode": stdlib.JSONDecodeFunc,
+	"jsonencode": stdlib.JSONEncodeFunc,
+	"keys":       stdlib.KeysFunc,
+	"length":     stdlib.LengthFunc,
+	"lookup":     stdlib.LookupFunc,
+	"lower":      stdlib.LowerFunc,
+	"max":        stdlib.MaxFunc,
+	"merge":      stdlib.MergeFunc,
+	"min":        stdlib.MinFunc,
+	"reverse":    stdlib.ReverseListFunc,
+	"sort":       stdlib.SortFunc,
+	"split":      stdlib.SplitFunc,
+	"strrev":     stdlib.ReverseFunc,
+	"substr":     stdlib.SubstrFunc,
+	"title":      stdlib.TitleFunc,
+	"trim":       stdlib.TrimFunc,
+	"trimprefix": stdlib.TrimPrefixFunc,
+	"trimspace":  stdlib.TrimSpaceFunc,
+	"trimsuffix": stdlib.TrimSuffixFunc,
+	"upper":      stdlib.UpperFunc,
+	"values":     stdlib.ValuesFunc,
+	"zipmap":     stdlib.ZipmapFunc,
+}
+
+// maxLocalPasses limits the number of times the locals are evaluated, where
+// each pass resolves the locals that refer to locals of the previous pass.
+const maxLocalPasses = 10
+
+// ParseVariables parses the values of the input variables in the given
+// .tfvars file, which must be values that can be evaluated statically.
+func ParseVariables(p []byte) (map[string]cty.Value, error) {
+	file, diags := hclsyntax.ParseConfig(p, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parse config: %v", diags.Errs())
+	}
+
+	attributes, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("get attributes: %v", diags.Errs())
+	}
+
+	variables := make(map[string]cty.Value)
+	for name, attribute := range attributes {
+		value, diags := attribute.Expr.Value(&hcl.EvalContext{Functions: functions})
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("evaluate variable %s: %v", name, diags.Errs())
+		}
+
+		variables[name] = value
+	}
+
+	return variables, nil
+}
+
+// newEvalContext returns the context to evaluate expressions with, which
+// contains the input variables and the locals of the given bodies of the files
+// of a module. The values of the variables are the given variables, or otherwise
+// their defaults, and locals that cannot be evaluated statically are omitted.
+func newEvalContext(bodies []*hclsyntax.Body, variables map[string]cty.Value) *hcl.EvalContext {
+	ctx := &hcl.EvalContext{Functions: functions}
+
+	vars := make(map[string]cty.Value)
+	var locals []*hclsyntax.Attribute
+	for _, body := range bodies {
+		for _, block := range body.Blocks {
+			switch {
+			case block.Type == "variable" && len(block.Labels) == 1:
+				if attribute, ok := block.Body.Attributes["default"]; ok {
+					if value, ok := evaluate(attribute.Expr, ctx); ok {
+						vars[block.Labels[0]] = value
+					}
+				}
+
+			case block.Type == "locals":
+				for _, attribute := range block.Body.Attributes {
+					locals = append(locals, attribute)
+				}
+			}
+		}
+	}
+
+	for name, value := range variables {
+		vars[name] = value
+	}
+
+	ctx.Variables = map[string]cty.Value{
+		"var":   cty.ObjectVal(vars),
+		"local": cty.EmptyObjectVal,
+	}
+
+	// Locals can refer to each other, so they are evaluated until none of
+	// the remaining locals can be evaluated with the locals that are known.
+	values := make(map[string]cty.Value)
+	for pass := 0; pass < maxLocalPasses && len(locals) > 0; pass++ {
+		var remaining []*hclsyntax.Attribute
+		for _, attribute := range locals {
+			value, ok := evaluate(attribute.Expr, ctx)
+			if !ok {
+				remaining = append(remaining, attribute)
+				continue
+			}
+
+			values[attribute.Name] = value
+		}
+
+		if len(remaining) == len(locals) {
+			break
+		}
+
+		locals = remaining
+		ctx.Variables["local"] = cty.ObjectVal(values)
+	}
+
+	return ctx
+}
+
+// evaluate returns the value of the given expression when it can be evaluated
+// statically using the given context, i.e. it only refers to known values.
+func evaluate(expr hcl.Expression, ctx *hcl.EvalContext) (cty.Value, bool) {
+	value, diags := expr.Value(ctx)
+	if diags.HasErrors() || !value.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+
+	return value, true
+}
+
+// resolveBody replaces the expressions of the attributes of the given body,
+// including the attributes of nested blocks, with their values when they can
+// be evaluated statically. Expressions that cannot be evaluated are kept, and
+// expressions that are partially static, such as objects, lists and templates,
+// have the parts that can be evaluated replaced instead.
+func resolveBody(body *hclsyntax.Body, ctx *hcl.EvalContext) {
+	for _, attribute := range body.Attributes {
+		attribute.Expr = resolveExpression(attribute.Expr, ctx)
+	}
+
+	for _, block := range body.Blocks {
+		resolveBody(block.Body, ctx)
+	}
+}
+
+func resolveExpression(expr hclsyntax.Expression, ctx *hcl.EvalContext) hclsyntax.Expression {
+	switch expr := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		return expr
+
+	// Template strings are converted from their parts, so only
+	// the parts are replaced to keep the string representation.
+	case *hclsyntax.TemplateExpr:
+		if value, ok := evaluate(expr, ctx); ok && value.Type() == cty.String {
+			return literal(value, expr)
+		}
+
+		for i, part := range expr.Parts {
+			expr.Parts[i] = resolveTemplatePart(part, ctx)
+		}
+
+		return expr
+	}
+
+	if value, ok := evaluate(expr, ctx); ok {
+		return literal(value, expr)
+	}
+
+	switch expr := expr.(type) {
+	case *hclsyntax.TemplateWrapExpr:
+		expr.Wrapped = resolveExpression(expr.Wrapped, ctx)
+	case *hclsyntax.TupleConsExpr:
+		for i, item := range expr.Exprs {
+			expr.Exprs[i] = resolveExpression(item, ctx)
+		}
+	case *hclsyntax.ObjectConsExpr:
+		for i, item := range expr.Items {
+			expr.Items[i].ValueExpr = resolveExpression(item.ValueExpr, ctx)
+		}
+	}
+
+	return expr
+}
+
+// resolveTemplatePart replaces a part of a template with its value when it can
+// be evaluated statically, and its value can be converted into a string.
+func resolveTemplatePart(part hclsyntax.Expression, ctx *hcl.EvalContext) hclsyntax.Expression {
+	if _, ok := part.(*hclsyntax.LiteralValueExpr); ok {
+		return part
+	}
+
+	value, ok := evaluate(part, ctx)
+	if !ok || !value.Type().IsPrimitiveType() || value.IsNull() {
+		return part
+	}
+
+	return literal(value, part)
+}
+
+func literal(value cty.Value, expr hclsyntax.Expression) *hclsyntax.LiteralValueExpr {
+	return &hclsyntax.LiteralValueExpr{Val: value, SrcRange: expr.Range()}
+}
@@ -4,16 +4,59 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/tmccombs/hcl2json/convert"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // Parser is an HCL2 parser.
-type Parser struct{}
+type Parser struct {
+	// Variables are the values of the input variables, e.g. from .tfvars
+	// files, which take precedence over the defaults of the variables.
+	Variables map[string]cty.Value
+
+	// ModuleFiles are the contents of the other files of the module that the
+	// parsed file belongs to, whose variables and locals can be referenced.
+	ModuleFiles [][]byte
+}
 
 // Unmarshal unmarshals HCL files that are written using
 // version 2 of the HCL language.
-func (Parser) Unmarshal(p []byte, v interface{}) error {
-	hclBytes, err := convert.Bytes(p, "", convert.Options{})
+//
+// Expressions that can be evaluated statically, such as literals, function
+// calls on literals, and references to variables and locals with known values,
+// are replaced with their values. Expressions that depend on values that are
+// only known when the configuration is applied, such as the attributes of
+// resources, are kept as is, wrapped in ${}.
+func (hp Parser) Unmarshal(p []byte, v interface{}) error {
+	file, diags := hclsyntax.ParseConfig(p, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return fmt.Errorf("convert to bytes: parse config: %v", diags.Errs())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return fmt.Errorf("unexpected body type %T", file.Body)
+	}
+
+	// Files of the module that cannot be parsed are ignored here, as they
+	// report their own errors when they are parsed.
+	bodies := []*hclsyntax.Body{body}
+	for _, moduleFile := range hp.ModuleFiles {
+		parsed, diags := hclsyntax.ParseConfig(moduleFile, "", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+
+		if moduleBody, ok := parsed.Body.(*hclsyntax.Body); ok {
+			bodies = append(bodies, moduleBody)
+		}
+	}
+
+	resolveBody(body, newEvalContext(bodies, hp.Variables))
+
+	hclBytes, err := convert.File(file, convert.Options{})
 	if err != nil {
 		return fmt.Errorf("convert to bytes: %w", err)
 	}
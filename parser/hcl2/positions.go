@@ -0,0 +1,77 @@
+package hcl2
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/open-policy-agent/conftest/parser/position"
+)
+
+// Positions returns the positions of the attributes and blocks in the given
+// HCL file. The paths match the structure produced by Unmarshal, where the type
+// and labels of a block are nested keys and repeated blocks become a list.
+func (Parser) Positions(p []byte) (map[string]position.Position, error) {
+	file, diags := hclsyntax.ParseConfig(p, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parse config: %v", diags.Errs())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", file.Body)
+	}
+
+	positions := make(map[string]position.Position)
+	addBodyPositions(positions, "", body)
+
+	return positions, nil
+}
+
+func addBodyPositions(positions map[string]position.Position, path string, body *hclsyntax.Body) {
+	for name, attribute := range body.Attributes {
+		positions[position.Join(path, name)] = newPosition(attribute.NameRange)
+	}
+
+	// Blocks that share the same type and labels are converted into a list,
+	// so the blocks need to be grouped before their paths can be determined.
+	var blockPaths []string
+	blocks := make(map[string][]*hclsyntax.Block)
+	for _, block := range body.Blocks {
+		blockPath := position.Join(path, block.Type)
+		for _, label := range block.Labels {
+			if _, ok := positions[blockPath]; !ok {
+				positions[blockPath] = newPosition(block.TypeRange)
+			}
+
+			blockPath = position.Join(blockPath, label)
+		}
+
+		if _, ok := blocks[blockPath]; !ok {
+			blockPaths = append(blockPaths, blockPath)
+		}
+
+		blocks[blockPath] = append(blocks[blockPath], block)
+	}
+
+	for _, blockPath := range blockPaths {
+		group := blocks[blockPath]
+		if len(group) == 1 {
+			positions[blockPath] = newPosition(group[0].TypeRange)
+			addBodyPositions(positions, blockPath, group[0].Body)
+			continue
+		}
+
+		positions[blockPath] = newPosition(group[0].TypeRange)
+		for i, block := range group {
+			indexPath := position.Join(blockPath, strconv.Itoa(i))
+			positions[indexPath] = newPosition(block.TypeRange)
+			addBodyPositions(positions, indexPath, block.Body)
+		}
+	}
+}
+
+func newPosition(r hcl.Range) position.Position {
+	return position.Position{Line: r.Start.Line, Column: r.Start.Column}
+}
@@ -0,0 +1,60 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Options are the options for rendering Helm charts.
+type Options struct {
+	// Values are the paths of the values files that are passed to Helm with
+	// --values, where the values of later files take precedence.
+	Values []string
+
+	// Set are the values that are passed to Helm with --set, in the form of
+	// key=value, which take precedence over the values files.
+	Set []string
+}
+
+// IsChart reports whether the given path is the directory of a Helm chart,
+// which is a directory that contains a Chart.yaml file.
+func IsChart(path string) bool {
+	info, err := os.Stat(filepath.Join(path, "Chart.yaml"))
+	return err == nil && !info.IsDir()
+}
+
+// Render renders the templates of the chart in the given directory with
+// helm template, and returns the rendered manifests as a multi-document YAML
+// file. The helm binary must be available on the PATH.
+func Render(chart string, options Options) ([]byte, error) {
+	if _, err := exec.LookPath("helm"); err != nil {
+		return nil, fmt.Errorf("helm must be available on the PATH to render charts")
+	}
+
+	args := []string{"template", chart}
+	for _, values := range options.Values {
+		args = append(args, "--values", values)
+	}
+
+	for _, set := range options.Set {
+		args = append(args, "--set", set)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("helm", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("render chart: %s", message)
+		}
+
+		return nil, fmt.Errorf("render chart: %w", err)
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,87 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeHelm puts a helm script on the PATH that prints its arguments as a
+// YAML document, or fails when the chart is named broken. The returned
+// function restores the PATH.
+func fakeHelm(t *testing.T, directory string) func() {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake helm is a shell script")
+	}
+
+	script := `#!/bin/sh
+if [ "$2" = "broken" ]; then
+  echo "Error: parse error in templates/deployment.yaml" >&2
+  exit 1
+fi
+echo "---"
+echo "args: $*"
+echo "---"
+echo "kind: Service"
+`
+	if err := ioutil.WriteFile(filepath.Join(directory, "helm"), []byte(script), 0755); err != nil {
+		t.Fatalf("write fake helm: %v", err)
+	}
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", directory+string(os.PathListSeparator)+path)
+	return func() { os.Setenv("PATH", path) }
+}
+
+func TestRender(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftesthelm")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	defer fakeHelm(t, directory)()
+
+	rendered, err := Render("chart", Options{Values: []string{"prod.yaml"}, Set: []string{"replicas=3"}})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	expected := "args: template chart --values prod.yaml --set replicas=3"
+	if !strings.Contains(string(rendered), expected) {
+		t.Errorf("expected the rendered chart to contain %q, got %q", expected, rendered)
+	}
+
+	_, err = Render("broken", Options{})
+	if err == nil || !strings.Contains(err.Error(), "parse error in templates/deployment.yaml") {
+		t.Errorf("expected the error of helm to be returned, got %v", err)
+	}
+}
+
+func TestIsChart(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftesthelm")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	chart := filepath.Join(directory, "chart")
+	if err := os.MkdirAll(chart, os.ModePerm); err != nil {
+		t.Fatalf("create chart: %v", err)
+	}
+
+	if IsChart(chart) {
+		t.Errorf("expected %s without a Chart.yaml not to be a chart", chart)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(chart, "Chart.yaml"), []byte("name: chart"), os.ModePerm); err != nil {
+		t.Fatalf("write Chart.yaml: %v", err)
+	}
+
+	if !IsChart(chart) {
+		t.Errorf("expected %s to be a chart", chart)
+	}
+}
@@ -9,6 +9,10 @@ import (
 )
 
 // Parser is an INI parser.
+//
+// The result is a map of section names to the keys of the section. Keys that
+// are defined before the first section header are in the section named "".
+// When a key is defined more than once within a section, the last definition wins.
 type Parser struct{}
 
 // Unmarshal unmarshals INI files.
@@ -21,8 +25,12 @@ func (i *Parser) Unmarshal(p []byte, v interface{}) error {
 	result := make(map[string]map[string]interface{})
 	for _, s := range cfg.Sections() {
 		sectionName := s.Name()
-		if sectionName == "DEFAULT" {
-			continue
+		if sectionName == ini.DefaultSection {
+			if len(s.Keys()) == 0 {
+				continue
+			}
+
+			sectionName = ""
 		}
 
 		result[sectionName] = map[string]interface{}{}
@@ -1,6 +1,7 @@
 package ini
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -35,6 +36,39 @@ func TestIniParser(t *testing.T) {
 	}
 }
 
+func TestIniParserSections(t *testing.T) {
+	parser := &Parser{}
+	sample := `global=true
+
+[database]
+host=localhost
+port=5432
+host=db.example.com
+
+[database]
+user=admin`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"": map[string]interface{}{
+			"global": true,
+		},
+		"database": map[string]interface{}{
+			"host": "db.example.com",
+			"port": 5432.0,
+			"user": "admin",
+		},
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected sections. expected %v actual %v", expected, input)
+	}
+}
+
 func TestConvertTypes(t *testing.T) {
 	testTable := []struct {
 		name           string
@@ -3,19 +3,41 @@ package jsonnet
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/google/go-jsonnet"
 )
 
+// TLAEnvironmentPrefix is the prefix of the environment variables that are
+// passed to the evaluated Jsonnet as top-level arguments. For example, the
+// environment variable CONFTEST_JSONNET_TLA_env=prod is passed as the
+// top-level argument env with the value prod.
+const TLAEnvironmentPrefix = "CONFTEST_JSONNET_TLA_"
+
 // Parser is a Jsonnet parser.
-type Parser struct{}
+type Parser struct {
+	path string
+}
+
+// SetPath sets the path of the file being parsed so that imports
+// are resolved relative to the directory of the file.
+func (p *Parser) SetPath(path string) {
+	p.path = path
+}
 
 // Unmarshal unmarshals Jsonnet files.
 func (p *Parser) Unmarshal(data []byte, v interface{}) error {
 	vm := jsonnet.MakeVM()
-	snippetStream, err := vm.EvaluateSnippet("", string(data))
+	for name, value := range topLevelArguments(os.Environ()) {
+		vm.TLAVar(name, value)
+	}
+
+	// The file name given to the snippet is used by the importer as the
+	// base location when resolving relative import and importstr paths.
+	snippetStream, err := vm.EvaluateSnippet(p.path, string(data))
 	if err != nil {
-		return err
+		return fmt.Errorf("evaluate jsonnet: %w", err)
 	}
 
 	if err := json.Unmarshal([]byte(snippetStream), v); err != nil {
@@ -24,3 +46,21 @@ func (p *Parser) Unmarshal(data []byte, v interface{}) error {
 
 	return nil
 }
+
+func topLevelArguments(environment []string) map[string]string {
+	arguments := make(map[string]string)
+	for _, pair := range environment {
+		if !strings.HasPrefix(pair, TLAEnvironmentPrefix) {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(pair, TLAEnvironmentPrefix), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			continue
+		}
+
+		arguments[parts[0]] = parts[1]
+	}
+
+	return arguments
+}
@@ -1,6 +1,9 @@
 package jsonnet
 
 import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -32,3 +35,59 @@ func TestJsonnetParser(t *testing.T) {
 		t.Error("there should be at least one item defined in the parsed file, but none found")
 	}
 }
+
+func TestJsonnetParserImports(t *testing.T) {
+	path := filepath.Join("testdata", "main.jsonnet")
+	sample, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read sample: %v", err)
+	}
+
+	parser := &Parser{}
+	parser.SetPath(path)
+
+	var input interface{}
+	if err := parser.Unmarshal(sample, &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	item := input.(map[string]interface{})
+	if item["name"] != "conftest" {
+		t.Errorf("expected imported name to be conftest, got %v", item["name"])
+	}
+
+	if item["greeting"] != "hello" {
+		t.Errorf("expected imported greeting to be hello, got %v", item["greeting"])
+	}
+}
+
+func TestJsonnetParserEvaluationError(t *testing.T) {
+	sample := `{ value: error "failed on purpose" }`
+
+	var input interface{}
+	err := (&Parser{}).Unmarshal([]byte(sample), &input)
+	if err == nil {
+		t.Fatal("expected an evaluation error")
+	}
+
+	if !strings.Contains(err.Error(), "failed on purpose") {
+		t.Errorf("expected error to contain the jsonnet error, got %v", err)
+	}
+}
+
+func TestTopLevelArguments(t *testing.T) {
+	environment := []string{
+		"HOME=/home/conftest",
+		"CONFTEST_JSONNET_TLA_env=prod",
+		"CONFTEST_JSONNET_TLA_=ignored",
+	}
+
+	arguments := topLevelArguments(environment)
+	if len(arguments) != 1 {
+		t.Fatalf("expected 1 top-level argument, got %v", arguments)
+	}
+
+	if arguments["env"] != "prod" {
+		t.Errorf("expected env to be prod, got %v", arguments["env"])
+	}
+}
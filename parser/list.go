@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser/position"
+)
+
+// isList reports whether the given document is a Kubernetes List, such as the
+// output of kubectl get -o yaml, whose kind is List, or the kind of the items
+// followed by List, e.g. DeploymentList, and whose items are a list.
+func isList(document interface{}) ([]interface{}, bool) {
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	kind, ok := object["kind"].(string)
+	if !ok || !strings.HasSuffix(kind, "List") {
+		return nil, false
+	}
+
+	if _, ok := object["apiVersion"].(string); !ok {
+		return nil, false
+	}
+
+	items, ok := object["items"].([]interface{})
+	return items, ok
+}
+
+// expandLists returns the documents of the given configuration, where the
+// items of each Kubernetes List are documents in place of the List, and the
+// paths of the documents within the configuration, e.g. items.0 of a single
+// List or 1.items.0 of the second document of a file. The configuration is
+// returned as is when it does not contain any Lists.
+func expandLists(configuration interface{}) (interface{}, []string) {
+	documents, multiple := configuration.([]interface{})
+	if !multiple {
+		documents = []interface{}{configuration}
+	}
+
+	var expanded []interface{}
+	var paths []string
+	var found bool
+	for i, document := range documents {
+		documentPath := ""
+		if multiple {
+			documentPath = strconv.Itoa(i)
+		}
+
+		items, ok := isList(document)
+		if !ok {
+			expanded = append(expanded, document)
+			paths = append(paths, documentPath)
+			continue
+		}
+
+		found = true
+		for j, item := range items {
+			expanded = append(expanded, item)
+			paths = append(paths, position.Join(documentPath, "items."+strconv.Itoa(j)))
+		}
+	}
+
+	if !found {
+		return configuration, nil
+	}
+
+	if expanded == nil {
+		expanded = []interface{}{}
+	}
+
+	return expanded, paths
+}
+
+// expandListPositions moves the given positions of the values of a
+// configuration to the documents that the configuration is expanded into,
+// given the paths of the documents within the configuration.
+func expandListPositions(positions map[string]position.Position, paths []string) map[string]position.Position {
+	expanded := make(map[string]position.Position)
+	for i, documentPath := range paths {
+		document := strconv.Itoa(i)
+		for key, pos := range positions {
+			switch {
+			case key == documentPath:
+				expanded[document] = pos
+			case strings.HasPrefix(key, documentPath+"."):
+				expanded[position.Join(document, strings.TrimPrefix(key, documentPath+"."))] = pos
+			}
+		}
+	}
+
+	return expanded
+}
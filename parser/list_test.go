@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const kubernetesList = `apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: hello
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: hello
+`
+
+func TestExpandLists(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "list.yaml")
+	if err := ioutil.WriteFile(path, []byte(kubernetesList), os.ModePerm); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+
+	configurations, err := ParseConfigurationsWithOptions([]string{path}, Options{})
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	if _, ok := configurations[path].(map[string]interface{}); !ok {
+		t.Errorf("Expected the List to be parsed as is by default, got %v", configurations[path])
+	}
+
+	options := Options{ExpandLists: true}
+	configurations, err = ParseConfigurationsWithOptions([]string{path}, options)
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	documents, ok := configurations[path].([]interface{})
+	if !ok || len(documents) != 2 {
+		t.Fatalf("Expected the items of the List to be documents, got %v", configurations[path])
+	}
+
+	var kinds []interface{}
+	for _, document := range documents {
+		kinds = append(kinds, document.(map[string]interface{})["kind"])
+	}
+
+	if !reflect.DeepEqual(kinds, []interface{}{"Deployment", "Service"}) {
+		t.Errorf("Unexpected kinds of the documents: %v", kinds)
+	}
+
+	positions, err := ParsePositionsWithOptions([]string{path}, options)
+	if err != nil {
+		t.Fatalf("parse positions: %v", err)
+	}
+
+	if line := positions[path]["1.metadata.name"].Line; line != 11 {
+		t.Errorf("Unexpected line of the name of the Service. Got %v, expected 11", line)
+	}
+}
+
+func TestExpandListsOfDocuments(t *testing.T) {
+	configuration := []interface{}{
+		map[string]interface{}{"kind": "ConfigMap"},
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "DeploymentList",
+			"items":      []interface{}{map[string]interface{}{"kind": "Deployment"}},
+		},
+		map[string]interface{}{"kind": "List", "items": []interface{}{}},
+	}
+
+	expanded, paths := expandLists(configuration)
+
+	expected := []interface{}{
+		map[string]interface{}{"kind": "ConfigMap"},
+		map[string]interface{}{"kind": "Deployment"},
+		map[string]interface{}{"kind": "List", "items": []interface{}{}},
+	}
+	if !reflect.DeepEqual(expanded, expected) {
+		t.Errorf("Unexpected documents. expected %v, got %v", expected, expanded)
+	}
+
+	if !reflect.DeepEqual(paths, []string{"0", "1.items.0", "2"}) {
+		t.Errorf("Unexpected paths of the documents: %v", paths)
+	}
+}
@@ -0,0 +1,56 @@
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// maxLineSize is the maximum size of a single line.
+const maxLineSize = 64 * 1024 * 1024
+
+// Parser is a parser for newline delimited JSON, also known as JSON Lines.
+type Parser struct{}
+
+// Unmarshal unmarshals newline delimited JSON files. Each line is a separate
+// JSON value, and the values are returned as a single list so that policies
+// can iterate over all of the records. Blank lines are skipped.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	records := []interface{}{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+
+	var lineNumber int
+	for scanner.Scan() {
+		lineNumber++
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record interface{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("unmarshal json on line %d: %w", lineNumber, err)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan line %d: %w", lineNumber+1, err)
+	}
+
+	j, err := json.Marshal([]interface{}{records})
+	if err != nil {
+		return fmt.Errorf("marshal records to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal records json: %w", err)
+	}
+
+	return nil
+}
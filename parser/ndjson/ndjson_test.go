@@ -0,0 +1,49 @@
+package ndjson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `{"level": "info", "message": "started"}
+
+{"level": "error", "message": "failed", "code": 1}
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		[]interface{}{
+			map[string]interface{}{"level": "info", "message": "started"},
+			map[string]interface{}{"level": "error", "message": "failed", "code": float64(1)},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected records. expected %v actual %v", expected, input)
+	}
+}
+
+func TestNDJSONParserMalformedLine(t *testing.T) {
+	parser := &Parser{}
+	sample := `{"level": "info"}
+{"level": "error"}
+
+{"level": }`
+
+	var input interface{}
+	err := parser.Unmarshal([]byte(sample), &input)
+	if err == nil {
+		t.Fatal("parser should have thrown an error")
+	}
+
+	if !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("error should include the line number, got: %v", err)
+	}
+}
@@ -2,35 +2,54 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/open-policy-agent/conftest/parser/cloudformation"
 	"github.com/open-policy-agent/conftest/parser/cue"
+	"github.com/open-policy-agent/conftest/parser/cyclonedx"
 	"github.com/open-policy-agent/conftest/parser/docker"
 	"github.com/open-policy-agent/conftest/parser/edn"
+	"github.com/open-policy-agent/conftest/parser/hcl"
 	"github.com/open-policy-agent/conftest/parser/hcl1"
 	"github.com/open-policy-agent/conftest/parser/hcl2"
+	"github.com/open-policy-agent/conftest/parser/helm"
 	"github.com/open-policy-agent/conftest/parser/hocon"
 	"github.com/open-policy-agent/conftest/parser/ignore"
 	"github.com/open-policy-agent/conftest/parser/ini"
 	"github.com/open-policy-agent/conftest/parser/json"
 	"github.com/open-policy-agent/conftest/parser/jsonnet"
+	"github.com/open-policy-agent/conftest/parser/ndjson"
+	"github.com/open-policy-agent/conftest/parser/position"
+	"github.com/open-policy-agent/conftest/parser/properties"
+	"github.com/open-policy-agent/conftest/parser/proto"
+	"github.com/open-policy-agent/conftest/parser/spdx"
+	"github.com/open-policy-agent/conftest/parser/systemd"
+	"github.com/open-policy-agent/conftest/parser/tfplan"
 	"github.com/open-policy-agent/conftest/parser/toml"
 	"github.com/open-policy-agent/conftest/parser/vcl"
 	"github.com/open-policy-agent/conftest/parser/xml"
 	"github.com/open-policy-agent/conftest/parser/yaml"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // The defined parsers are the parsers that are valid for
 // parsing files.
 const (
 	TOML       = "toml"
+	HCL        = "hcl"
 	HCL1       = "hcl1"
 	HCL2       = "hcl2"
+	TFPLAN     = "tfplan"
 	CUE        = "cue"
 	INI        = "ini"
 	HOCON      = "hocon"
@@ -42,14 +61,44 @@ const (
 	VCL        = "vcl"
 	XML        = "xml"
 	IGNORE     = "ignore"
+	PROPERTIES = "properties"
+	NDJSON     = "ndjson"
+	PROTO      = "proto"
+	SYSTEMD    = "systemd"
+	CYCLONEDX  = "cyclonedx"
+	SPDX       = "spdx"
+
+	CLOUDFORMATION = "cloudformation"
 )
 
 // Parser defines all of the methods that every parser
 // definition must implement.
+//
+// Unmarshal parses the contents of a file into v, which is a pointer to an
+// empty interface. The parsed value must only consist of the types that
+// encoding/json decodes into, i.e. maps with string keys, slices, strings,
+// float64s, bools and nil. Files that contain several documents are parsed
+// into a slice that has an element for each document.
+//
+// Custom parsers can be made available with Register, and can additionally
+// implement PathSetter and PositionParser.
 type Parser interface {
 	Unmarshal(p []byte, v interface{}) error
 }
 
+// PathSetter is implemented by parsers that need to know the path of
+// the file they are parsing, e.g. to resolve relative imports.
+type PathSetter interface {
+	SetPath(path string)
+}
+
+// PositionParser is implemented by parsers that are able to locate the values
+// within the files they parse. The positions are keyed by the dot separated path
+// of the value, e.g. spec.containers.0.image.
+type PositionParser interface {
+	Positions(p []byte) (map[string]position.Position, error)
+}
+
 // New returns a new Parser.
 func New(parser string) (Parser, error) {
 	switch parser {
@@ -61,10 +110,14 @@ func New(parser string) (Parser, error) {
 		return &ini.Parser{}, nil
 	case HOCON:
 		return &hocon.Parser{}, nil
+	case HCL:
+		return &hcl.Parser{}, nil
 	case HCL1:
 		return &hcl1.Parser{}, nil
 	case HCL2:
 		return &hcl2.Parser{}, nil
+	case TFPLAN:
+		return &tfplan.Parser{}, nil
 	case Dockerfile:
 		return &docker.Parser{}, nil
 	case YAML:
@@ -81,7 +134,25 @@ func New(parser string) (Parser, error) {
 		return &xml.Parser{}, nil
 	case IGNORE:
 		return &ignore.Parser{}, nil
+	case PROPERTIES:
+		return &properties.Parser{}, nil
+	case NDJSON:
+		return &ndjson.Parser{}, nil
+	case PROTO:
+		return &proto.Parser{}, nil
+	case SYSTEMD:
+		return &systemd.Parser{}, nil
+	case CYCLONEDX:
+		return &cyclonedx.Parser{}, nil
+	case SPDX:
+		return &spdx.Parser{}, nil
+	case CLOUDFORMATION:
+		return &cloudformation.Parser{}, nil
 	default:
+		if p, ok := registered(parser); ok {
+			return p, nil
+		}
+
 		return nil, fmt.Errorf("unknown parser: %v", parser)
 	}
 }
@@ -97,21 +168,78 @@ func NewFromPath(path string) (Parser, error) {
 		return New(Dockerfile)
 	}
 
-	fileExtension := filepath.Ext(path)[1:]
+	// Compressed files are parsed based on the extension of the
+	// file that was compressed, e.g. config.json.gz is parsed as JSON.
+	if strings.EqualFold(filepath.Ext(path), ".gz") {
+		uncompressedPath := path[:len(path)-len(".gz")]
+		if filepath.Ext(uncompressedPath) == "" && !strings.EqualFold(filepath.Base(uncompressedPath), "dockerfile") {
+			return nil, fmt.Errorf("unknown parser for compressed file: %v", path)
+		}
+
+		return NewFromPath(uncompressedPath)
+	}
+
+	// CloudFormation templates are detected by their suffix, or by their
+	// contents when they are parsed as YAML or JSON.
+	lowerPath := strings.ToLower(path)
+	for _, suffix := range []string{".template", ".cfn.yaml", ".cfn.yml", ".cfn.json"} {
+		if strings.HasSuffix(lowerPath, suffix) {
+			return New(CLOUDFORMATION)
+		}
+	}
+
+	// SBOMs are detected by their suffix, since they are otherwise
+	// parsed as plain JSON documents.
+	if strings.HasSuffix(lowerPath, ".cdx.json") {
+		return New(CYCLONEDX)
+	}
+
+	if strings.HasSuffix(lowerPath, ".spdx.json") || strings.HasSuffix(lowerPath, ".spdx") {
+		return New(SPDX)
+	}
+
+	fileExtension := strings.TrimPrefix(filepath.Ext(path), ".")
 	if fileExtension == "yml" || fileExtension == "yaml" {
 		return New(YAML)
 	}
 
-	// When parsing Terraform files, the default parser to use
-	// should be the latest HCL parser.
-	if fileExtension == "tf" {
+	// Terraform and other HCL files are parsed as HCL2, unless they are
+	// only valid HCL1 files, e.g. the files of older Terraform versions.
+	if fileExtension == "tf" || fileExtension == "hcl" {
+		return New(HCL)
+	}
+
+	// Variable definition files of Terraform are also HCL2 files.
+	if fileExtension == "tfvars" {
 		return New(HCL2)
 	}
 
+	if fileExtension == "cfg" {
+		return New(INI)
+	}
+
+	if fileExtension == "jsonl" {
+		return New(NDJSON)
+	}
+
 	if fileExtension == "gitignore" || fileExtension == "dockerignore" {
 		return New(IGNORE)
 	}
 
+	// Protobuf messages are either in the text format or in the binary wire
+	// format, while .proto files are the schemas of the messages themselves.
+	if fileExtension == "textproto" || fileExtension == "pb" {
+		return New(PROTO)
+	}
+
+	if fileExtension == "service" || fileExtension == "timer" || fileExtension == "socket" {
+		return New(SYSTEMD)
+	}
+
+	if fileExtension == "proto" {
+		return nil, fmt.Errorf("unknown parser: %v", fileExtension)
+	}
+
 	parser, err := New(fileExtension)
 	if err != nil {
 		return nil, fmt.Errorf("new: %w", err)
@@ -120,43 +248,219 @@ func NewFromPath(path string) (Parser, error) {
 	return parser, nil
 }
 
-// Parsers returns a list of the supported Parsers.
+// NewFromOptions returns a file parser for the file at the given path. When the
+// options specify a parser, that parser is used for every file. Otherwise, the
+// parser map is consulted before choosing the parser based on the file type.
+// When the parser cannot be chosen based on the file type, the parser is chosen
+// based on the contents of the file, unless NoSniff is set.
+func NewFromOptions(path string, options Options) (Parser, error) {
+	if options.Parser != "" {
+		return New(options.Parser)
+	}
+
+	if path != "-" && len(options.ParserMap) > 0 {
+		if parser, ok := options.ParserMap[parserMapExtension(path)]; ok {
+			return New(parser)
+		}
+	}
+
+	parser, err := NewFromPath(path)
+	if err != nil && path != "-" && !IsURL(path) && !options.NoSniff {
+		if name, ok := sniffFile(path); ok {
+			return New(name)
+		}
+	}
+
+	return parser, err
+}
+
+// parserMapExtension returns the extension of the given path that is looked
+// up in the parser map, which is the extension of the compressed file for
+// compressed files.
+func parserMapExtension(path string) string {
+	uncompressedPath := path
+	if strings.EqualFold(filepath.Ext(path), ".gz") {
+		uncompressedPath = path[:len(path)-len(".gz")]
+	}
+
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(uncompressedPath), "."))
+}
+
+// ParseParserMap parses a list of mappings from file extensions to parsers,
+// in the form of .ext=parser, e.g. .tfvars=hcl2, into a parser map.
+func ParseParserMap(mappings []string) (map[string]string, error) {
+	parserMap := make(map[string]string)
+	for _, mapping := range mappings {
+		index := strings.Index(mapping, "=")
+		if index < 0 {
+			return nil, fmt.Errorf("invalid parser mapping %q, expected the form .ext=parser", mapping)
+		}
+
+		extension := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(mapping[:index]), "."))
+		parser := strings.TrimSpace(mapping[index+1:])
+		if extension == "" {
+			return nil, fmt.Errorf("invalid parser mapping %q, the extension must not be empty", mapping)
+		}
+
+		if _, err := New(parser); err != nil {
+			return nil, fmt.Errorf("invalid parser mapping %q, valid parsers are: %v", mapping, Parsers())
+		}
+
+		parserMap[extension] = parser
+	}
+
+	return parserMap, nil
+}
+
+// builtinParsers are the parsers that are defined by conftest itself.
+var builtinParsers = []string{
+	TOML,
+	HCL,
+	HCL1,
+	HCL2,
+	TFPLAN,
+	CUE,
+	INI,
+	HOCON,
+	Dockerfile,
+	YAML,
+	JSON,
+	JSONNET,
+	EDN,
+	VCL,
+	XML,
+	IGNORE,
+	PROPERTIES,
+	NDJSON,
+	PROTO,
+	SYSTEMD,
+	CYCLONEDX,
+	SPDX,
+	CLOUDFORMATION,
+}
+
+// Parsers returns a list of the supported Parsers, which are the built in
+// parsers followed by the parsers that are registered with Register.
 func Parsers() []string {
-	parsers := []string{
-		TOML,
-		HCL1,
-		HCL2,
-		CUE,
-		INI,
-		HOCON,
-		Dockerfile,
-		YAML,
-		JSON,
-		JSONNET,
-		EDN,
-		VCL,
-		XML,
-		IGNORE,
-	}
-
-	return parsers
+	parsers := append([]string{}, builtinParsers...)
+	return append(parsers, registeredNames()...)
 }
 
 // FileSupported returns true if the file at the given path is
 // a file that can be parsed.
 func FileSupported(path string) bool {
-	if _, err := NewFromPath(path); err != nil {
+	return FileSupportedWithOptions(path, Options{})
+}
+
+// FileSupportedWithOptions returns true if the file at the given
+// path is a file that can be parsed using the given options.
+func FileSupportedWithOptions(path string, options Options) bool {
+	if _, err := NewFromOptions(path, options); err != nil {
 		return false
 	}
 
 	return true
 }
 
+// Options are the options for parsing configurations.
+type Options struct {
+	// Parser is the parser to use for every file. When empty, the parser
+	// is chosen based on the path of each file.
+	Parser string
+
+	// BuildArgs are the build arguments used to resolve the ARG
+	// commands of Dockerfiles.
+	BuildArgs map[string]string
+
+	// DockerfileStages represents Dockerfiles as a list of build stages.
+	DockerfileStages bool
+
+	// ParserMap maps file extensions, without the leading dot, to the parser
+	// to use for the files with that extension. It takes precedence over the
+	// parser that would be chosen based on the extension.
+	ParserMap map[string]string
+
+	// ProtoDescriptorSet is the path to the compiled FileDescriptorSet that
+	// contains the type of protobuf messages, given by ProtoMessage.
+	ProtoDescriptorSet string
+	ProtoMessage       string
+
+	// MaxErrors is the number of files that fail to be parsed that are
+	// tolerated. The files are skipped, and their errors are returned as
+	// FileErrors along with the configurations of the other files. When
+	// zero, parsing stops at the first file that fails to be parsed.
+	MaxErrors int
+
+	// Helm renders the directories of Helm charts with helm template, and
+	// parses the rendered manifests as the configuration of the chart.
+	Helm        bool
+	HelmOptions helm.Options
+
+	// NoSniff disables choosing the parser of the files whose parser cannot
+	// be chosen based on their path, e.g. files without an extension, based
+	// on their contents, in which case the files are not supported.
+	NoSniff bool
+
+	// ExpandLists parses the items of Kubernetes Lists, e.g. the output of
+	// kubectl get -o yaml, as the documents of the file in place of the
+	// List, so that the items are checked as resources of their own.
+	ExpandLists bool
+
+	// ResolveRefs replaces the $ref references of the configurations, as used
+	// by JSON Schema and OpenAPI, with the values that they refer to, so that
+	// the policies do not have to follow the references.
+	ResolveRefs bool
+}
+
+// FileError is the error of a file that could not be parsed.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// FileErrors are the errors of the files that could not be parsed when
+// parsing continues past the files that fail to be parsed.
+type FileErrors []*FileError
+
+func (e FileErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	return fmt.Sprintf("%d files could not be parsed, the first being %v", len(e), e[0])
+}
+
+// ParseBuildArgs parses build arguments in the form of KEY=VALUE. As with
+// docker build, an argument without a value takes its value from the
+// environment variable with the same name.
+func ParseBuildArgs(args []string) map[string]string {
+	buildArgs := make(map[string]string)
+	for _, arg := range args {
+		name := arg
+		value := os.Getenv(arg)
+		if index := strings.Index(arg, "="); index >= 0 {
+			name, value = arg[:index], arg[index+1:]
+		}
+
+		buildArgs[name] = value
+	}
+
+	return buildArgs
+}
+
 // ParseConfigurations parses and returns the configurations from the given
 // list of files. The result will be a map where the key is the file name of
 // the configuration.
 func ParseConfigurations(files []string) (map[string]interface{}, error) {
-	configurations, err := parseConfigurations(files, "")
+	configurations, _, err := parseConfigurations(context.Background(), files, Options{})
 	if err != nil {
 		return nil, fmt.Errorf("get configurations: %w", err)
 	}
@@ -168,7 +472,7 @@ func ParseConfigurations(files []string) (map[string]interface{}, error) {
 // configurations given in the file list. The result will be a map where the key
 // is the file name of the configuration.
 func ParseConfigurationsAs(files []string, parser string) (map[string]interface{}, error) {
-	configurations, err := parseConfigurations(files, parser)
+	configurations, _, err := parseConfigurations(context.Background(), files, Options{Parser: parser})
 	if err != nil {
 		return nil, fmt.Errorf("parse configurations: %w", err)
 	}
@@ -176,6 +480,58 @@ func ParseConfigurationsAs(files []string, parser string) (map[string]interface{
 	return configurations, nil
 }
 
+// ParseConfigurationsWithOptions parses the files using the given options and
+// returns the configurations given in the file list. The result will be a map
+// where the key is the file name of the configuration.
+//
+// When MaxErrors is set and some of the files could not be parsed, the
+// configurations of the other files are returned with an error that wraps
+// the FileErrors of the files that could not be parsed.
+func ParseConfigurationsWithOptions(files []string, options Options) (map[string]interface{}, error) {
+	configurations, _, err := parseConfigurations(context.Background(), files, options)
+	if err != nil {
+		return configurations, fmt.Errorf("parse configurations: %w", err)
+	}
+
+	return configurations, nil
+}
+
+// ParseConfigurationsWithPositions is the same as ParseConfigurationsWithOptions,
+// but also returns the positions of the values in the files, keyed by the file
+// name, which are located while the files are parsed. Files whose parser is
+// unable to locate their values, and rendered Helm charts, do not have any
+// positions. The context is used to fetch the configurations at URLs.
+func ParseConfigurationsWithPositions(ctx context.Context, files []string, options Options) (map[string]interface{}, map[string]map[string]position.Position, error) {
+	configurations, positions, err := parseConfigurations(ctx, files, options)
+	if err != nil {
+		return configurations, positions, fmt.Errorf("parse configurations: %w", err)
+	}
+
+	return configurations, positions, nil
+}
+
+// ParsePositions returns the positions of the values in the given list of files,
+// keyed by the file name. Files whose parser is unable to locate their values do
+// not have any positions.
+func ParsePositions(files []string, parser string) (map[string]map[string]position.Position, error) {
+	return ParsePositionsWithOptions(files, Options{Parser: parser})
+}
+
+// ParsePositionsWithOptions returns the positions of the values in the given list
+// of files, keyed by the file name, choosing the parser of each file using the
+// given options. The files are parsed to locate their values, so when the
+// configurations are needed as well, ParseConfigurationsWithPositions should be
+// used instead to parse the files only once.
+func ParsePositionsWithOptions(files []string, options Options) (map[string]map[string]position.Position, error) {
+	var fileErrors FileErrors
+	_, positions, err := parseConfigurations(context.Background(), files, options)
+	if err != nil && !errors.As(err, &fileErrors) {
+		return nil, fmt.Errorf("parse configurations: %w", err)
+	}
+
+	return positions, nil
+}
+
 // CombineConfigurations takes the given configurations and combines them into a single
 // configuration. The result will be a map that contains a single key with a value of
 // Combined.
@@ -208,8 +564,8 @@ func CombineConfigurations(configs map[string]interface{}) map[string]interface{
 	}
 
 	// For consistency when printing the results, sort the configurations by
-	// their file paths.
-	sort.Slice(allConfigurations, func(i, j int) bool {
+	// their file paths. The documents of a file keep the order of the file.
+	sort.SliceStable(allConfigurations, func(i, j int) bool {
 		return allConfigurations[i].Path < allConfigurations[j].Path
 	})
 
@@ -219,39 +575,258 @@ func CombineConfigurations(configs map[string]interface{}) map[string]interface{
 	return combinedConfigurations
 }
 
-func parseConfigurations(paths []string, parser string) (map[string]interface{}, error) {
+func parseConfigurations(ctx context.Context, paths []string, options Options) (map[string]interface{}, map[string]map[string]position.Position, error) {
+	modules, variables, moduleErrors := readTerraformModules(paths, options)
+
+	var fileErrors FileErrors
 	parsedConfigurations := make(map[string]interface{})
+	positions := make(map[string]map[string]position.Position)
+	refs := newRefResolver(options)
+
+	// addFileError records the error of the file at the given path, which
+	// stops the parsing when the errors are not tolerated, or when there
+	// are more errors than are tolerated.
+	addFileError := func(path string, err error) error {
+		if options.MaxErrors <= 0 {
+			return &FileError{Path: path, Err: err}
+		}
+
+		fileErrors = append(fileErrors, &FileError{Path: path, Err: err})
+		if len(fileErrors) > options.MaxErrors {
+			return fmt.Errorf("more than %d files could not be parsed: %w", options.MaxErrors, fileErrors[len(fileErrors)-1])
+		}
+
+		return nil
+	}
+
 	for _, path := range paths {
+		if err, ok := moduleErrors[path]; ok {
+			if err := addFileError(path, fmt.Errorf("read terraform module: %w", err)); err != nil {
+				return nil, nil, err
+			}
+
+			continue
+		}
+
+		// Charts are rendered into YAML, and configurations at URLs are
+		// fetched before choosing their parser, as the parser depends on
+		// the content type of the response.
 		var fileParser Parser
+		var contents []byte
 		var err error
-		if parser == "" {
-			fileParser, err = NewFromPath(path)
+		if options.Helm && helm.IsChart(path) {
+			fileParser = &yaml.Parser{}
+			contents, err = helm.Render(path, options.HelmOptions)
+			if err != nil {
+				if err := addFileError(path, err); err != nil {
+					return nil, nil, err
+				}
+
+				continue
+			}
+		} else if IsURL(path) {
+			fileParser, contents, err = fetchConfiguration(ctx, path, options)
+			if err != nil {
+				if err := addFileError(path, fmt.Errorf("fetch: %w", err)); err != nil {
+					return nil, nil, err
+				}
+
+				continue
+			}
 		} else {
-			fileParser, err = New(parser)
+			fileParser, err = NewFromOptions(path, options)
+			if err != nil {
+				if err := addFileError(path, fmt.Errorf("new parser: %w", err)); err != nil {
+					return nil, nil, err
+				}
+
+				continue
+			}
+
+			contents, err = getConfigurationContent(path)
+			if err != nil {
+				if err := addFileError(path, fmt.Errorf("get configuration content: %w", err)); err != nil {
+					return nil, nil, err
+				}
+
+				continue
+			}
 		}
-		if err != nil {
-			return nil, fmt.Errorf("new parser: %w", err)
+
+		if isCloudFormationTemplate(path, fileParser, contents, options) {
+			fileParser = &cloudformation.Parser{}
 		}
 
-		contents, err := getConfigurationContent(path)
-		if err != nil {
-			return nil, fmt.Errorf("get configuration content: %w", err)
+		if dockerParser, ok := fileParser.(*docker.Parser); ok {
+			dockerParser.BuildArgs = options.BuildArgs
+			dockerParser.Stages = options.DockerfileStages
+		}
+
+		if protoParser, ok := fileParser.(*proto.Parser); ok {
+			protoParser.DescriptorSet = options.ProtoDescriptorSet
+			protoParser.Message = options.ProtoMessage
+		}
+
+		if hcl2Parser, ok := hcl2ParserOf(fileParser); ok && path != "-" && !IsURL(path) {
+			hcl2Parser.Variables = variables
+			for modulePath, contents := range modules[filepath.Dir(path)] {
+				if modulePath != path {
+					hcl2Parser.ModuleFiles = append(hcl2Parser.ModuleFiles, contents)
+				}
+			}
+		}
+
+		if pathSetter, ok := fileParser.(PathSetter); ok && path != "-" && !IsURL(path) {
+			pathSetter.SetPath(path)
 		}
 
 		var parsed interface{}
 		if err := fileParser.Unmarshal(contents, &parsed); err != nil {
-			return nil, fmt.Errorf("parser unmarshal: %w", err)
+			if err := addFileError(path, err); err != nil {
+				return nil, nil, err
+			}
+
+			continue
+		}
+
+		if options.ResolveRefs {
+			parsed, err = refs.resolveRefs(path, parsed)
+			if err != nil {
+				if err := addFileError(path, err); err != nil {
+					return nil, nil, err
+				}
+
+				continue
+			}
+		}
+
+		// Positions are only used to enrich the results, so a file that
+		// cannot be located should not prevent its policies from being run.
+		var filePositions map[string]position.Position
+		if positionParser, ok := fileParser.(PositionParser); ok && !(options.Helm && helm.IsChart(path)) {
+			filePositions, err = positionParser.Positions(contents)
+			if err != nil {
+				filePositions = nil
+			}
+		}
+
+		if options.ExpandLists {
+			var listPaths []string
+			parsed, listPaths = expandLists(parsed)
+
+			// The positions of the items of Lists are moved to the
+			// documents that the items are parsed as.
+			if filePositions != nil && listPaths != nil {
+				filePositions = expandListPositions(filePositions, listPaths)
+			}
+		}
+
+		if filePositions != nil {
+			positions[path] = filePositions
 		}
 
 		parsedConfigurations[path] = parsed
 	}
 
-	return parsedConfigurations, nil
+	if len(fileErrors) > 0 {
+		return parsedConfigurations, positions, fileErrors
+	}
+
+	return parsedConfigurations, positions, nil
+}
+
+// readTerraformModules reads the HCL2 files in the given paths, which are
+// grouped by their directory, as the files in a directory are a module whose
+// variables and locals can be referenced by all of its files. The values of the
+// variables in .tfvars files are returned as well, and apply to every module.
+// The errors of the files that cannot be read, or whose variables cannot be
+// parsed, are returned by their paths, so that they are reported the same as
+// the other files that cannot be parsed.
+func readTerraformModules(paths []string, options Options) (map[string]map[string][]byte, map[string]cty.Value, map[string]error) {
+	modules := make(map[string]map[string][]byte)
+	variables := make(map[string]cty.Value)
+	errs := make(map[string]error)
+	for _, path := range paths {
+		if path == "-" || IsURL(path) {
+			continue
+		}
+
+		fileParser, err := NewFromOptions(path, options)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := hcl2ParserOf(fileParser); !ok {
+			continue
+		}
+
+		contents, err := getConfigurationContent(path)
+		if err != nil {
+			errs[path] = fmt.Errorf("get configuration content: %w", err)
+			continue
+		}
+
+		if !strings.EqualFold(filepath.Ext(path), ".tfvars") {
+			directory := filepath.Dir(path)
+			if modules[directory] == nil {
+				modules[directory] = make(map[string][]byte)
+			}
+
+			modules[directory][path] = contents
+			continue
+		}
+
+		fileVariables, err := hcl2.ParseVariables(contents)
+		if err != nil {
+			errs[path] = fmt.Errorf("parse variables: %w", err)
+			continue
+		}
+
+		for name, value := range fileVariables {
+			variables[name] = value
+		}
+	}
+
+	return modules, variables, errs
+}
+
+// isCloudFormationTemplate reports whether the contents that would be parsed
+// with the given YAML or JSON parser are a CloudFormation template, unless the
+// parser was chosen with the options.
+func isCloudFormationTemplate(path string, fileParser Parser, contents []byte, options Options) bool {
+	switch fileParser.(type) {
+	case *yaml.Parser, *json.Parser:
+	default:
+		return false
+	}
+
+	if options.Parser != "" {
+		return false
+	}
+
+	if _, ok := options.ParserMap[parserMapExtension(path)]; ok && path != "-" {
+		return false
+	}
+
+	return cloudformation.IsTemplate(contents)
+}
+
+// hcl2ParserOf returns the HCL2 parser of the given parser, which is either
+// the HCL2 parser itself or the parser that detects the version of HCL.
+func hcl2ParserOf(fileParser Parser) (*hcl2.Parser, bool) {
+	switch p := fileParser.(type) {
+	case *hcl2.Parser:
+		return p, true
+	case *hcl.Parser:
+		return &p.Parser, true
+	}
+
+	return nil, false
 }
 
 func getConfigurationContent(path string) ([]byte, error) {
 	if path == "-" {
-		contents, err := ioutil.ReadAll(bufio.NewReader(os.Stdin))
+		contents, err := readContent(os.Stdin)
 		if err != nil {
 			return nil, fmt.Errorf("read standard in: %w", err)
 		}
@@ -264,10 +839,47 @@ func getConfigurationContent(path string) ([]byte, error) {
 		return nil, fmt.Errorf("get abs: %w", err)
 	}
 
-	contents, err := ioutil.ReadFile(filePath)
+	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("open file: %w", err)
 	}
+	defer file.Close()
+
+	contents, err := readContent(file)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return contents, nil
+}
+
+// gzipMagic are the bytes that every gzip compressed file starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readContent reads all of the content from the given reader. Content that
+// is compressed with gzip is decompressed while it is being read.
+func readContent(r io.Reader) ([]byte, error) {
+	reader := bufio.NewReader(r)
+
+	magic, err := reader.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("peek: %w", err)
+	}
+
+	if !bytes.Equal(magic, gzipMagic) {
+		return ioutil.ReadAll(reader)
+	}
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("new gzip reader: %w", err)
+	}
+	defer gzipReader.Close()
+
+	contents, err := ioutil.ReadAll(gzipReader)
+	if err != nil {
+		return nil, fmt.Errorf("decompress: %w", err)
+	}
 
 	return contents, nil
 }
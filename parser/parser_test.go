@@ -1,11 +1,33 @@
 package parser
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	encodingjson "encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/open-policy-agent/conftest/parser/cloudformation"
+	"github.com/open-policy-agent/conftest/parser/cue"
+	"github.com/open-policy-agent/conftest/parser/cyclonedx"
 	"github.com/open-policy-agent/conftest/parser/docker"
+	"github.com/open-policy-agent/conftest/parser/hcl"
 	"github.com/open-policy-agent/conftest/parser/hcl2"
+	"github.com/open-policy-agent/conftest/parser/ini"
+	"github.com/open-policy-agent/conftest/parser/json"
+	"github.com/open-policy-agent/conftest/parser/ndjson"
+	"github.com/open-policy-agent/conftest/parser/properties"
+	"github.com/open-policy-agent/conftest/parser/proto"
+	"github.com/open-policy-agent/conftest/parser/spdx"
+	"github.com/open-policy-agent/conftest/parser/systemd"
 	"github.com/open-policy-agent/conftest/parser/yaml"
 )
 
@@ -26,6 +48,14 @@ func TestNewFromPath(t *testing.T) {
 			"test.yml",
 			&yaml.Parser{},
 		},
+		{
+			"stack.cfn.yaml",
+			&cloudformation.Parser{},
+		},
+		{
+			"stack.template",
+			&cloudformation.Parser{},
+		},
 		{
 			"dockerfile",
 			&docker.Parser{},
@@ -36,7 +66,75 @@ func TestNewFromPath(t *testing.T) {
 		},
 		{
 			"test.tf",
-			&hcl2.Parser{},
+			&hcl.Parser{},
+		},
+		{
+			"config.hcl",
+			&hcl.Parser{},
+		},
+		{
+			"tox.ini",
+			&ini.Parser{},
+		},
+		{
+			"nginx.service",
+			&systemd.Parser{},
+		},
+		{
+			"backup.timer",
+			&systemd.Parser{},
+		},
+		{
+			"docker.socket",
+			&systemd.Parser{},
+		},
+		{
+			"bom.cdx.json",
+			&cyclonedx.Parser{},
+		},
+		{
+			"sbom.spdx.json",
+			&spdx.Parser{},
+		},
+		{
+			"sbom.spdx",
+			&spdx.Parser{},
+		},
+		{
+			"setup.cfg",
+			&ini.Parser{},
+		},
+		{
+			"application.properties",
+			&properties.Parser{},
+		},
+		{
+			"events.ndjson",
+			&ndjson.Parser{},
+		},
+		{
+			"events.jsonl",
+			&ndjson.Parser{},
+		},
+		{
+			"deployment.cue",
+			&cue.Parser{},
+		},
+		{
+			"envoy.textproto",
+			&proto.Parser{},
+		},
+		{
+			"envoy.pb",
+			&proto.Parser{},
+		},
+		{
+			"config.json.gz",
+			&json.Parser{},
+		},
+		{
+			"deployment.yaml.gz",
+			&yaml.Parser{},
 		},
 	}
 
@@ -56,3 +154,402 @@ func TestNewFromPath(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBuildArgs(t *testing.T) {
+	os.Setenv("CONFTEST_TEST_BUILD_ARG", "from-env")
+	defer os.Unsetenv("CONFTEST_TEST_BUILD_ARG")
+
+	actual := ParseBuildArgs([]string{"VERSION=1.16", "EMPTY=", "OPTIONS=a=b", "CONFTEST_TEST_BUILD_ARG"})
+	expected := map[string]string{
+		"VERSION":                 "1.16",
+		"EMPTY":                   "",
+		"OPTIONS":                 "a=b",
+		"CONFTEST_TEST_BUILD_ARG": "from-env",
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected build args. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestParseConfigurationsCompressed(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatal("create temp dir:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write([]byte(`{"name": "compressed"}`)); err != nil {
+		t.Fatal("compress:", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal("close gzip writer:", err)
+	}
+
+	path := filepath.Join(directory, "config.json.gz")
+	if err := ioutil.WriteFile(path, compressed.Bytes(), os.ModePerm); err != nil {
+		t.Fatal("write file:", err)
+	}
+
+	configurations, err := ParseConfigurations([]string{path})
+	if err != nil {
+		t.Fatal("parse configurations:", err)
+	}
+
+	expected := map[string]interface{}{"name": "compressed"}
+	if !reflect.DeepEqual(configurations[path], expected) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations[path])
+	}
+}
+
+func TestParseConfigurationsTerraformModule(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatal("create temp dir:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	files := map[string]string{
+		"variables.tf":     "variable \"env\" {\n  default = \"dev\"\n}\n",
+		"main.tf":          "resource \"aws_s3_bucket\" \"example\" {\n  bucket = \"app-${var.env}\"\n}\n",
+		"terraform.tfvars": "env = \"prod\"\n",
+	}
+
+	var paths []string
+	for name, contents := range files {
+		path := filepath.Join(directory, name)
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatal("write file:", err)
+		}
+
+		paths = append(paths, path)
+	}
+
+	configurations, err := ParseConfigurations(paths)
+	if err != nil {
+		t.Fatal("parse configurations:", err)
+	}
+
+	expected := map[string]interface{}{
+		"resource": map[string]interface{}{
+			"aws_s3_bucket": map[string]interface{}{
+				"example": map[string]interface{}{"bucket": "app-prod"},
+			},
+		},
+	}
+
+	path := filepath.Join(directory, "main.tf")
+	if !reflect.DeepEqual(configurations[path], expected) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations[path])
+	}
+}
+
+func TestParseConfigurationsMaxErrors(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatal("create temp dir:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	valid := filepath.Join(directory, "valid.json")
+	invalid := filepath.Join(directory, "invalid.json")
+	malformed := filepath.Join(directory, "malformed.json")
+	for path, contents := range map[string]string{valid: `{"name": "valid"}`, invalid: `{"name":`, malformed: `}`} {
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatal("write file:", err)
+		}
+	}
+
+	if _, err := ParseConfigurationsWithOptions([]string{valid, invalid}, Options{}); err == nil {
+		t.Error("expected an error without tolerating parse errors")
+	}
+
+	configurations, err := ParseConfigurationsWithOptions([]string{valid, invalid}, Options{MaxErrors: 1})
+	var fileErrors FileErrors
+	if !errors.As(err, &fileErrors) {
+		t.Fatalf("expected the file errors to be returned, got %v", err)
+	}
+
+	if len(fileErrors) != 1 || fileErrors[0].Path != invalid {
+		t.Errorf("unexpected file errors: %v", fileErrors)
+	}
+
+	expected := map[string]interface{}{valid: map[string]interface{}{"name": "valid"}}
+	if !reflect.DeepEqual(configurations, expected) {
+		t.Errorf("Unexpected configurations. expected %v actual %v", expected, configurations)
+	}
+
+	_, err = ParseConfigurationsWithOptions([]string{valid, invalid, malformed}, Options{MaxErrors: 1})
+	if err == nil || errors.As(err, &fileErrors) {
+		t.Errorf("expected an error when exceeding the number of tolerated errors, got %v", err)
+	}
+
+	// Files that do not have a parser, or that cannot be read, are
+	// tolerated the same as the files that cannot be parsed.
+	unknown := filepath.Join(directory, "unknown.xyz")
+	if err := ioutil.WriteFile(unknown, []byte("\x00"), os.ModePerm); err != nil {
+		t.Fatal("write file:", err)
+	}
+	missing := filepath.Join(directory, "missing.json")
+
+	configurations, err = ParseConfigurationsWithOptions([]string{valid, unknown, missing}, Options{MaxErrors: 2, NoSniff: true})
+	if !errors.As(err, &fileErrors) {
+		t.Fatalf("expected the file errors to be returned, got %v", err)
+	}
+
+	var paths []string
+	for _, fileError := range fileErrors {
+		paths = append(paths, fileError.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{unknown, missing}) {
+		t.Errorf("unexpected file errors: %v", fileErrors)
+	}
+
+	if !reflect.DeepEqual(configurations, expected) {
+		t.Errorf("Unexpected configurations. expected %v actual %v", expected, configurations)
+	}
+
+	// The variables of the Terraform modules are read before the files are
+	// parsed, and their errors are tolerated all the same.
+	module := filepath.Join(directory, "main.tf")
+	variables := filepath.Join(directory, "bad.tfvars")
+	for path, contents := range map[string]string{module: `resource "aws_s3_bucket" "b" {}`, variables: `region = `} {
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatal("write file:", err)
+		}
+	}
+
+	configurations, err = ParseConfigurationsWithOptions([]string{module, variables}, Options{MaxErrors: 5})
+	if !errors.As(err, &fileErrors) {
+		t.Fatalf("expected the file errors to be returned, got %v", err)
+	}
+
+	if len(fileErrors) != 1 || fileErrors[0].Path != variables {
+		t.Errorf("unexpected file errors: %v", fileErrors)
+	}
+
+	if _, ok := configurations[module]; !ok {
+		t.Errorf("expected the module to be parsed, got %v", configurations)
+	}
+
+	if _, err := ParseConfigurationsWithOptions([]string{module, variables}, Options{}); err == nil || !strings.Contains(err.Error(), variables) {
+		t.Errorf("expected an error that names the variables file without tolerating errors, got %v", err)
+	}
+}
+
+func TestParseConfigurationsStdin(t *testing.T) {
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+
+	parse := func(contents string, options Options) interface{} {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			t.Fatal("create pipe:", err)
+		}
+		defer reader.Close()
+
+		if _, err := writer.Write([]byte(contents)); err != nil {
+			t.Fatal("write stdin:", err)
+		}
+		writer.Close()
+		os.Stdin = reader
+
+		configurations, err := ParseConfigurationsWithOptions([]string{"-"}, options)
+		if err != nil {
+			t.Fatal("parse configurations:", err)
+		}
+
+		return configurations["-"]
+	}
+
+	documents := parse("---\nkind: Service\n---\n---\nkind: Deployment\n", Options{})
+	expected := []interface{}{
+		map[string]interface{}{"kind": "Service"},
+		map[string]interface{}{"kind": "Deployment"},
+	}
+	if !reflect.DeepEqual(documents, expected) {
+		t.Errorf("Unexpected documents. expected %v actual %v", expected, documents)
+	}
+
+	configuration := parse(`{"kind": "Service"}`, Options{Parser: JSON})
+	if !reflect.DeepEqual(configuration, map[string]interface{}{"kind": "Service"}) {
+		t.Errorf("Unexpected configuration parsed with the given parser: %v", configuration)
+	}
+}
+
+func TestParseConfigurationsWithPositions(t *testing.T) {
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal("create pipe:", err)
+	}
+	defer reader.Close()
+
+	if _, err := writer.Write([]byte("kind: Service\nmetadata:\n  name: web\n")); err != nil {
+		t.Fatal("write stdin:", err)
+	}
+	writer.Close()
+	os.Stdin = reader
+
+	// Standard input can only be read once, so the positions have to be
+	// located while the configuration is parsed.
+	configurations, positions, err := ParseConfigurationsWithPositions(context.Background(), []string{"-"}, Options{})
+	if err != nil {
+		t.Fatal("parse configurations:", err)
+	}
+
+	if !reflect.DeepEqual(configurations["-"], map[string]interface{}{"kind": "Service", "metadata": map[string]interface{}{"name": "web"}}) {
+		t.Errorf("Unexpected configuration: %v", configurations["-"])
+	}
+
+	if line := positions["-"]["metadata.name"].Line; line != 3 {
+		t.Errorf("Unexpected line of the name. Got %v, expected 3", line)
+	}
+}
+
+func TestParseConfigurationsURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/service":
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write([]byte("kind: Service\n"))
+		case "/deployment.json":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(`{"kind": "Deployment"}`))
+		case "/config":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("name = \"config\"\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service := server.URL + "/service"
+	deployment := server.URL + "/deployment.json"
+	configurations, err := ParseConfigurationsWithOptions([]string{service, deployment}, Options{})
+	if err != nil {
+		t.Fatal("parse configurations:", err)
+	}
+
+	expected := map[string]interface{}{
+		service:    map[string]interface{}{"kind": "Service"},
+		deployment: map[string]interface{}{"kind": "Deployment"},
+	}
+	if !reflect.DeepEqual(configurations, expected) {
+		t.Errorf("Unexpected configurations. expected %v actual %v", expected, configurations)
+	}
+
+	config := server.URL + "/config"
+	if _, err := ParseConfigurationsWithOptions([]string{config}, Options{}); err == nil || !strings.Contains(err.Error(), "--parser") {
+		t.Errorf("expected an error for an unknown content type, got %v", err)
+	}
+
+	configurations, err = ParseConfigurationsWithOptions([]string{config}, Options{Parser: TOML})
+	if err != nil {
+		t.Fatal("parse configurations:", err)
+	}
+
+	if !reflect.DeepEqual(configurations[config], map[string]interface{}{"name": "config"}) {
+		t.Errorf("Unexpected configuration parsed with the given parser: %v", configurations[config])
+	}
+
+	missing := server.URL + "/missing.yaml"
+	configurations, err = ParseConfigurationsWithOptions([]string{service, missing}, Options{MaxErrors: 1})
+	var fileErrors FileErrors
+	if !errors.As(err, &fileErrors) || len(fileErrors) != 1 || fileErrors[0].Path != missing {
+		t.Fatalf("expected a file error for the missing configuration, got %v", err)
+	}
+
+	if _, ok := configurations[service]; !ok {
+		t.Errorf("expected the other configurations to be parsed, got %v", configurations)
+	}
+}
+
+func TestNewFromOptions(t *testing.T) {
+	options := Options{ParserMap: map[string]string{"tfvars": HCL2, "config": JSON}}
+
+	testCases := []struct {
+		path     string
+		options  Options
+		expected Parser
+	}{
+		{"terraform.tfvars", options, &hcl2.Parser{}},
+		{"app.CONFIG", options, &json.Parser{}},
+		{"app.config.gz", options, &json.Parser{}},
+		{"deployment.yaml", options, &yaml.Parser{}},
+		{"terraform.tfvars", Options{Parser: YAML, ParserMap: options.ParserMap}, &yaml.Parser{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.path, func(t *testing.T) {
+			expectedType := reflect.TypeOf(testCase.expected)
+
+			actual, err := NewFromOptions(testCase.path, testCase.options)
+			if err != nil {
+				t.Fatal("from options:", err)
+			}
+			actualType := reflect.TypeOf(actual)
+
+			if !reflect.DeepEqual(actualType, expectedType) {
+				t.Errorf("Unexpected parser. expected %v actual %v", expectedType, actualType)
+			}
+		})
+	}
+}
+
+func TestParseParserMap(t *testing.T) {
+	actual, err := ParseParserMap([]string{".tfvars=hcl2", "Config=json"})
+	if err != nil {
+		t.Fatal("parse parser map:", err)
+	}
+
+	expected := map[string]string{"tfvars": HCL2, "config": JSON}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected parser map. expected %v actual %v", expected, actual)
+	}
+
+	for _, invalid := range []string{".tfvars", "=json", ".tfvars=unknown"} {
+		if _, err := ParseParserMap([]string{invalid}); err == nil {
+			t.Errorf("Parser mapping %q should be invalid", invalid)
+		}
+	}
+}
+
+func TestCombineConfigurations(t *testing.T) {
+	var documents []interface{}
+	for i := 0; i < 20; i++ {
+		documents = append(documents, map[string]interface{}{"index": i})
+	}
+
+	configurations := map[string]interface{}{
+		"b.yaml": documents,
+		"a.yaml": map[string]interface{}{"kind": "Service"},
+	}
+
+	combined, err := encodingjson.Marshal(CombineConfigurations(configurations)["Combined"])
+	if err != nil {
+		t.Fatalf("marshal combined configurations: %v", err)
+	}
+
+	var actual []map[string]interface{}
+	if err := encodingjson.Unmarshal(combined, &actual); err != nil {
+		t.Fatalf("unmarshal combined configurations: %v", err)
+	}
+
+	if len(actual) != 21 || actual[0]["path"] != "a.yaml" {
+		t.Fatalf("expected the configurations to be sorted by their paths, got %v", actual)
+	}
+
+	// Each document of a file is attributed to the file, in the order of the file.
+	for i, configuration := range actual[1:] {
+		expected := map[string]interface{}{"path": "b.yaml", "contents": map[string]interface{}{"index": float64(i)}}
+		if !reflect.DeepEqual(expected, configuration) {
+			t.Errorf("expected document %v to be %v, got %v", i, expected, configuration)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// Package position describes where values are located within parsed files.
+package position
+
+// Position is the location of a value within a file.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Join joins the given path segments into a dot separated path, which is
+// the format used to identify values within a parsed configuration,
+// e.g. spec.containers.0.image.
+func Join(path string, segment string) string {
+	if path == "" {
+		return segment
+	}
+
+	if segment == "" {
+		return path
+	}
+
+	return path + "." + segment
+}
@@ -0,0 +1,172 @@
+package properties
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parser is a Java properties parser.
+//
+// Keys are kept flat, so a key such as server.port is available as
+// input["server.port"] rather than as a nested map. All values are strings,
+// and when a key is defined more than once, the last definition wins.
+type Parser struct{}
+
+// Unmarshal unmarshals Java properties files.
+func (pp *Parser) Unmarshal(p []byte, v interface{}) error {
+	lines, err := logicalLines(p)
+	if err != nil {
+		return fmt.Errorf("read lines: %w", err)
+	}
+
+	result := make(map[string]string)
+	for _, line := range lines {
+		key, value := splitKeyValue(line)
+
+		unescapedKey, err := unescape(key)
+		if err != nil {
+			return fmt.Errorf("unescape key %q: %w", key, err)
+		}
+
+		unescapedValue, err := unescape(value)
+		if err != nil {
+			return fmt.Errorf("unescape value of %q: %w", key, err)
+		}
+
+		result[unescapedKey] = unescapedValue
+	}
+
+	j, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal properties to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal properties json: %w", err)
+	}
+
+	return nil
+}
+
+// logicalLines returns the lines that contain properties, without comments
+// and blank lines. Lines that end with a backslash are joined with the line
+// that follows them, ignoring the leading whitespace of the following line.
+func logicalLines(p []byte) ([]string, error) {
+	var lines []string
+	var current strings.Builder
+	var continued bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(p))
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		line = strings.TrimSuffix(line, "\r")
+
+		if !continued && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+
+		continued = hasContinuation(line)
+		if continued {
+			line = line[:len(line)-1]
+		}
+		current.WriteString(line)
+
+		if !continued {
+			lines = append(lines, current.String())
+			current.Reset()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	if current.Len() > 0 {
+		lines = append(lines, current.String())
+	}
+
+	return lines, nil
+}
+
+// hasContinuation returns true if the line ends with an odd number of
+// backslashes, as an even number of backslashes are escaped backslashes.
+func hasContinuation(line string) bool {
+	var backslashes int
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		backslashes++
+	}
+
+	return backslashes%2 == 1
+}
+
+// splitKeyValue splits the line into its key and value. The key ends at the
+// first unescaped '=', ':' or whitespace character, and the separator as well
+// as any whitespace surrounding it is not part of the value.
+func splitKeyValue(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	key := line[:end]
+	value := strings.TrimLeft(line[end:], " \t\f")
+	if strings.HasPrefix(value, "=") || strings.HasPrefix(value, ":") {
+		value = strings.TrimLeft(value[1:], " \t\f")
+	}
+
+	return key, value
+}
+
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			result.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			result.WriteByte('\t')
+		case 'n':
+			result.WriteByte('\n')
+		case 'r':
+			result.WriteByte('\r')
+		case 'f':
+			result.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid unicode escape %q", s[i-1:])
+			}
+
+			codePoint, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape %q", s[i-1:i+5])
+			}
+
+			result.WriteRune(rune(codePoint))
+			i += 4
+		default:
+			result.WriteByte(s[i])
+		}
+	}
+
+	return result.String(), nil
+}
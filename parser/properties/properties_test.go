@@ -0,0 +1,51 @@
+package properties
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPropertiesParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `# Comment
+! Another comment
+server.port=8080
+spring.application.name : demo
+logging.level.root    INFO
+greeting=Hello \
+         World
+path=C:\\temp
+unicode=caf\u00e9
+empty=
+escaped\=key=value
+server.port=9090`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"server.port":             "9090",
+		"spring.application.name": "demo",
+		"logging.level.root":      "INFO",
+		"greeting":                "Hello World",
+		"path":                    `C:\temp`,
+		"unicode":                 "café",
+		"empty":                   "",
+		"escaped=key":             "value",
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected properties. expected %v actual %v", expected, input)
+	}
+}
+
+func TestPropertiesParserInvalidUnicode(t *testing.T) {
+	parser := &Parser{}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(`key=\u12`), &input); err == nil {
+		t.Error("expected an error for an invalid unicode escape")
+	}
+}
@@ -0,0 +1,152 @@
+package proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Parser is a protobuf parser, which decodes messages in either the text
+// format or the binary wire format using the descriptors of their types.
+type Parser struct {
+	// DescriptorSet is the path to a compiled FileDescriptorSet, e.g. as
+	// generated by protoc --include_imports --descriptor_set_out, which
+	// contains the descriptor of the message type and its dependencies.
+	DescriptorSet string
+
+	// Message is the fully qualified name of the type of the message
+	// that is parsed, e.g. envoy.config.bootstrap.v3.Bootstrap.
+	Message string
+
+	path string
+}
+
+// SetPath sets the path of the file being parsed, whose extension determines
+// whether the message is in the binary wire format (.pb) or the text format.
+func (p *Parser) SetPath(path string) {
+	p.path = path
+}
+
+// Unmarshal unmarshals protobuf messages, which are represented the same as
+// in the canonical JSON encoding of protobuf, using the original field names
+// as they are declared in the .proto files.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	if p.DescriptorSet == "" {
+		return fmt.Errorf("parsing protobuf requires a descriptor set of the message types, e.g. --proto-descriptor-set descriptors.pb")
+	}
+
+	if p.Message == "" {
+		return fmt.Errorf("parsing protobuf requires the name of the message type, e.g. --proto-message package.Message")
+	}
+
+	files, types, err := loadDescriptorSet(p.DescriptorSet)
+	if err != nil {
+		return fmt.Errorf("load descriptor set: %w", err)
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(p.Message))
+	if err != nil {
+		return fmt.Errorf("find message %s: %w", p.Message, err)
+	}
+
+	messageDescriptor, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return fmt.Errorf("%s is not a message", p.Message)
+	}
+
+	message := dynamicpb.NewMessage(messageDescriptor)
+	if p.isBinary() {
+		options := proto.UnmarshalOptions{Resolver: types}
+		if err := options.Unmarshal(data, message); err != nil {
+			return fmt.Errorf("unmarshal binary protobuf: %w", err)
+		}
+	} else {
+		options := prototext.UnmarshalOptions{Resolver: types}
+		if err := options.Unmarshal(data, message); err != nil {
+			return fmt.Errorf("unmarshal text protobuf: %w", err)
+		}
+	}
+
+	encoded, err := protojson.MarshalOptions{UseProtoNames: true, Resolver: types}.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	if err := json.Unmarshal(encoded, v); err != nil {
+		return fmt.Errorf("unmarshal json: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Parser) isBinary() bool {
+	path := p.path
+	if strings.EqualFold(filepath.Ext(path), ".gz") {
+		path = path[:len(path)-len(".gz")]
+	}
+
+	extension := strings.ToLower(filepath.Ext(path))
+	return extension == ".pb" || extension == ".binpb"
+}
+
+// loadDescriptorSet loads the files of the descriptor set at the given path,
+// along with the types of all of their messages, which are used to resolve the
+// messages that are embedded in google.protobuf.Any fields.
+func loadDescriptorSet(path string) (*protoregistry.Files, *protoregistry.Types, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var descriptorSet descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(contents, &descriptorSet); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(&descriptorSet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new files: %w", err)
+	}
+
+	types := new(protoregistry.Types)
+	var registerErr error
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		registerErr = registerMessages(types, file.Messages())
+		return registerErr == nil
+	})
+	if registerErr != nil {
+		return nil, nil, fmt.Errorf("register messages: %w", registerErr)
+	}
+
+	return files, types, nil
+}
+
+func registerMessages(types *protoregistry.Types, messages protoreflect.MessageDescriptors) error {
+	for i := 0; i < messages.Len(); i++ {
+		message := messages.Get(i)
+		if message.IsMapEntry() {
+			continue
+		}
+
+		if err := types.RegisterMessage(dynamicpb.NewMessageType(message)); err != nil {
+			return fmt.Errorf("register %s: %w", message.FullName(), err)
+		}
+
+		if err := registerMessages(types, message.Messages()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
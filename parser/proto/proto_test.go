@@ -0,0 +1,169 @@
+package proto
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func writeDescriptorSet(t *testing.T, directory string) string {
+	field := func(name string, number int32, fieldType descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		descriptor := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     fieldType.Enum(),
+			Label:    label.Enum(),
+		}
+		if typeName != "" {
+			descriptor.TypeName = proto.String(typeName)
+		}
+
+		return descriptor
+	}
+
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	descriptorSet := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("listener.proto"),
+			Package: proto.String("test"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{
+				{
+					Name: proto.String("Listener"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("listener_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+						field("port", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional, ""),
+						field("hosts", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, repeated, ""),
+						field("filter", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".test.Filter"),
+					},
+				},
+				{
+					Name: proto.String("Filter"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("type", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					},
+				},
+			},
+		}},
+	}
+
+	contents, err := proto.Marshal(descriptorSet)
+	if err != nil {
+		t.Fatalf("marshal descriptor set: %v", err)
+	}
+
+	path := filepath.Join(directory, "descriptors.pb")
+	if err := ioutil.WriteFile(path, contents, os.ModePerm); err != nil {
+		t.Fatalf("write descriptor set: %v", err)
+	}
+
+	return path
+}
+
+func TestProtoParser(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	descriptorSet := writeDescriptorSet(t, directory)
+	expected := map[string]interface{}{
+		"listener_name": "public",
+		"port":          float64(8080),
+		"hosts":         []interface{}{"example.com", "example.org"},
+		"filter":        map[string]interface{}{"type": "router"},
+	}
+
+	textParser := &Parser{DescriptorSet: descriptorSet, Message: "test.Listener"}
+	textParser.SetPath("listener.textproto")
+	sample := `
+listener_name: "public"
+port: 8080
+hosts: "example.com"
+hosts: "example.org"
+filter { type: "router" }
+`
+
+	var input interface{}
+	if err := textParser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected text message. expected %v actual %v", expected, input)
+	}
+
+	// The binary message is encoded from the message that was decoded from
+	// the text format, which is then decoded again by the parser.
+	files, _, err := loadDescriptorSet(descriptorSet)
+	if err != nil {
+		t.Fatalf("load descriptor set: %v", err)
+	}
+
+	descriptor, err := files.FindDescriptorByName("test.Listener")
+	if err != nil {
+		t.Fatalf("find message: %v", err)
+	}
+
+	message := dynamicpb.NewMessage(descriptor.(protoreflect.MessageDescriptor))
+	if err := prototext.Unmarshal([]byte(sample), message); err != nil {
+		t.Fatalf("unmarshal text message: %v", err)
+	}
+
+	binary, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatalf("marshal binary message: %v", err)
+	}
+
+	binaryParser := &Parser{DescriptorSet: descriptorSet, Message: "test.Listener"}
+	binaryParser.SetPath("listener.pb")
+
+	input = nil
+	if err := binaryParser.Unmarshal(binary, &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected binary message. expected %v actual %v", expected, input)
+	}
+}
+
+func TestProtoParserErrors(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	descriptorSet := writeDescriptorSet(t, directory)
+	testCases := []struct {
+		name   string
+		parser *Parser
+		input  string
+	}{
+		{name: "without descriptor set", parser: &Parser{Message: "test.Listener"}, input: `port: 8080`},
+		{name: "without message", parser: &Parser{DescriptorSet: descriptorSet}, input: `port: 8080`},
+		{name: "unknown message", parser: &Parser{DescriptorSet: descriptorSet, Message: "test.Unknown"}, input: `port: 8080`},
+		{name: "unknown field", parser: &Parser{DescriptorSet: descriptorSet, Message: "test.Listener"}, input: `address: "0.0.0.0"`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var input interface{}
+			if err := testCase.parser.Unmarshal([]byte(testCase.input), &input); err == nil {
+				t.Error("parser should have thrown an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// refKey is the key of the objects that refer to another value, as used by
+// JSON Schema and OpenAPI, e.g. {"$ref": "#/components/schemas/Pet"}.
+const refKey = "$ref"
+
+// refResolver resolves the $ref references of configurations by inlining
+// the values that they refer to.
+type refResolver struct {
+	options Options
+
+	// files are the parsed contents of the files that are referred to.
+	files map[string]interface{}
+
+	// resolving are the references that are being resolved, in the form of
+	// file#pointer, which are used to detect circular references.
+	resolving []string
+}
+
+func newRefResolver(options Options) *refResolver {
+	return &refResolver{
+		options: options,
+		files:   make(map[string]interface{}),
+	}
+}
+
+// resolveRefs returns the given configuration of the file with the given path
+// with each of its $ref references replaced with the value that it refers to.
+// References are either JSON pointers within the same document, e.g.
+// #/definitions/port, or paths of files relative to the directory of the
+// file, optionally followed by a pointer, e.g. common.yaml#/definitions/port.
+// References to URLs are left as is. The documents of a file with several
+// documents are resolved separately, and an error is returned for any
+// reference that cannot be resolved or that refers to itself.
+func (r *refResolver) resolveRefs(path string, configuration interface{}) (interface{}, error) {
+	// The configurations that are not read from files, such as stdin, have
+	// no directory to resolve the references to other files from.
+	if path == "-" || IsURL(path) {
+		path = ""
+	}
+
+	documents, multiple := configuration.([]interface{})
+	if !multiple {
+		return r.resolve(configuration, path, configuration)
+	}
+
+	resolved := make([]interface{}, len(documents))
+	for i, document := range documents {
+		value, err := r.resolve(document, path, document)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved[i] = value
+	}
+
+	return resolved, nil
+}
+
+// resolve resolves the references of the given value, which is part of the
+// given document of the file with the given path.
+func (r *refResolver) resolve(value interface{}, path string, document interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v[refKey].(string); ok && !isRemoteRef(ref) {
+			resolved, err := r.resolveRef(ref, path, document)
+			if err != nil {
+				return nil, err
+			}
+
+			// The other keys of an object with a reference are merged
+			// into the object that it refers to, and take precedence.
+			object, ok := resolved.(map[string]interface{})
+			if !ok || len(v) == 1 {
+				return resolved, nil
+			}
+
+			merged := make(map[string]interface{}, len(object)+len(v)-1)
+			for key, item := range object {
+				merged[key] = item
+			}
+
+			for key, item := range v {
+				if key == refKey {
+					continue
+				}
+
+				resolvedItem, err := r.resolve(item, path, document)
+				if err != nil {
+					return nil, err
+				}
+
+				merged[key] = resolvedItem
+			}
+
+			return merged, nil
+		}
+
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolvedItem, err := r.resolve(item, path, document)
+			if err != nil {
+				return nil, err
+			}
+
+			resolved[key] = resolvedItem
+		}
+
+		return resolved, nil
+
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolvedItem, err := r.resolve(item, path, document)
+			if err != nil {
+				return nil, err
+			}
+
+			resolved[i] = resolvedItem
+		}
+
+		return resolved, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// resolveRef returns the value that the given reference of the given
+// document of the file with the given path refers to, with its own
+// references resolved.
+func (r *refResolver) resolveRef(ref string, path string, document interface{}) (interface{}, error) {
+	target, pointer := ref, ""
+	if i := strings.Index(ref, "#"); i >= 0 {
+		target, pointer = ref[:i], ref[i+1:]
+	}
+
+	if target != "" {
+		if path == "" {
+			return nil, fmt.Errorf("resolve $ref %q: references to files are only supported in files", ref)
+		}
+
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), filepath.FromSlash(target))
+		}
+
+		contents, err := r.load(target)
+		if err != nil {
+			return nil, fmt.Errorf("resolve $ref %q: %w", ref, err)
+		}
+
+		path, document = target, contents
+	}
+
+	key := path + "#" + pointer
+	for i, resolving := range r.resolving {
+		if resolving == key {
+			return nil, fmt.Errorf("circular $ref: %s", strings.Join(append(r.resolving[i:], key), " -> "))
+		}
+	}
+
+	value, err := lookupPointer(document, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("resolve $ref %q: %w", ref, err)
+	}
+
+	r.resolving = append(r.resolving, key)
+	defer func() { r.resolving = r.resolving[:len(r.resolving)-1] }()
+
+	return r.resolve(value, path, document)
+}
+
+// load returns the parsed contents of the file with the given path, which is
+// parsed with the parser of its extension.
+func (r *refResolver) load(path string) (interface{}, error) {
+	if contents, ok := r.files[path]; ok {
+		return contents, nil
+	}
+
+	fileParser, err := NewFromOptions(path, r.options)
+	if err != nil {
+		return nil, fmt.Errorf("new parser: %w", err)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var parsed interface{}
+	if err := fileParser.Unmarshal(contents, &parsed); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	r.files[path] = parsed
+	return parsed, nil
+}
+
+// lookupPointer returns the value at the given JSON pointer, e.g.
+// /definitions/port, of the document. The empty pointer refers to the
+// document itself.
+func lookupPointer(document interface{}, pointer string) (interface{}, error) {
+	pointer, err := url.PathUnescape(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pointer %q: %w", pointer, err)
+	}
+
+	if pointer == "" {
+		return document, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("pointer %q must start with /", pointer)
+	}
+
+	value := document
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			item, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", pointer)
+			}
+
+			value = item
+
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("%s not found", pointer)
+			}
+
+			value = v[index]
+
+		default:
+			return nil, fmt.Errorf("%s not found", pointer)
+		}
+	}
+
+	return value, nil
+}
+
+// isRemoteRef reports whether the given reference refers to a URL, such as
+// the URL of a JSON Schema, which is not resolved.
+func isRemoteRef(ref string) bool {
+	return strings.Contains(ref, "://") || strings.HasPrefix(ref, "urn:")
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveRefs(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	files := map[string]string{
+		"openapi.yaml": `paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          $ref: "#/components/responses/Pets"
+components:
+  responses:
+    Pets:
+      description: A list of pets
+      schema:
+        $ref: schemas/pet.json#/definitions/Pet
+`,
+		filepath.Join("schemas", "pet.json"): `{
+  "definitions": {
+    "Pet": {"type": "object", "properties": {"name": {"$ref": "#/definitions/Name"}, "owner": {"$ref": "https://example.com/owner.json"}}},
+    "Name": {"type": "string"}
+  }
+}`,
+		"circular.yaml": `definitions:
+  Node:
+    properties:
+      next:
+        $ref: "#/definitions/Node"
+`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(directory, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	openAPI := filepath.Join(directory, "openapi.yaml")
+	configurations, err := ParseConfigurationsWithOptions([]string{openAPI}, Options{ResolveRefs: true})
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	pet := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name":  map[string]interface{}{"type": "string"},
+			"owner": map[string]interface{}{"$ref": "https://example.com/owner.json"},
+		},
+	}
+	pets := map[string]interface{}{"description": "A list of pets", "schema": pet}
+
+	responses := configurations[openAPI].(map[string]interface{})["paths"].(map[string]interface{})["/pets"].(map[string]interface{})["get"].(map[string]interface{})["responses"]
+	if expected := map[string]interface{}{"200": pets}; !reflect.DeepEqual(responses, expected) {
+		t.Errorf("Unexpected responses. expected %v, got %v", expected, responses)
+	}
+
+	// The references are left as is by default.
+	configurations, err = ParseConfigurationsWithOptions([]string{openAPI}, Options{})
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	responses = configurations[openAPI].(map[string]interface{})["paths"].(map[string]interface{})["/pets"].(map[string]interface{})["get"].(map[string]interface{})["responses"]
+	if expected := map[string]interface{}{"200": map[string]interface{}{"$ref": "#/components/responses/Pets"}}; !reflect.DeepEqual(responses, expected) {
+		t.Errorf("Unexpected responses. expected %v, got %v", expected, responses)
+	}
+
+	_, err = ParseConfigurationsWithOptions([]string{filepath.Join(directory, "circular.yaml")}, Options{ResolveRefs: true})
+	if err == nil || !strings.Contains(err.Error(), "circular $ref") {
+		t.Errorf("expected a circular reference to be reported, got %v", err)
+	}
+}
+
+func TestLookupPointer(t *testing.T) {
+	document := map[string]interface{}{
+		"paths": map[string]interface{}{
+			"/pets": []interface{}{"get", "post"},
+		},
+		"a~b": "tilde",
+	}
+
+	testCases := []struct {
+		pointer  string
+		expected interface{}
+	}{
+		{pointer: "", expected: document},
+		{pointer: "/paths/~1pets/1", expected: "post"},
+		{pointer: "/a~0b", expected: "tilde"},
+		{pointer: "/paths/%7E1pets/0", expected: "get"},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := lookupPointer(document, testCase.pointer)
+		if err != nil {
+			t.Errorf("lookup %q: %v", testCase.pointer, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("Unexpected value of %q. expected %v, got %v", testCase.pointer, testCase.expected, actual)
+		}
+	}
+
+	for _, pointer := range []string{"/missing", "/paths/~1pets/2", "paths"} {
+		if _, err := lookupPointer(document, pointer); err == nil {
+			t.Errorf("expected an error for %q", pointer)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Parser)
+)
+
+// Register makes a custom parser available under the given name, so that it
+// can be selected with --parser and --parser-map, and is used for the files
+// whose extension is the name, e.g. the files that end with .myconfig for a
+// parser that is registered as myconfig. Parsers are usually registered in
+// the init function of the package that defines them.
+//
+// Register panics when the name is empty, when the parser is nil, or when a
+// parser with the same name is already registered or built in.
+func Register(name string, p Parser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" {
+		panic("parser: register parser with an empty name")
+	}
+
+	if p == nil {
+		panic(fmt.Sprintf("parser: register nil parser %q", name))
+	}
+
+	if _, ok := registry[name]; ok || isBuiltin(name) {
+		panic(fmt.Sprintf("parser: register duplicate parser %q", name))
+	}
+
+	registry[name] = p
+}
+
+// registered returns the custom parser that is registered under the given
+// name. Parsers that are pointers to structs are copied, so that the parsers
+// that keep the state of the file they parse, such as its path, are not
+// shared between files.
+func registered(name string) (Parser, bool) {
+	registryMu.RLock()
+	p, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	value := reflect.ValueOf(p)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return p, true
+	}
+
+	instance := reflect.New(value.Elem().Type())
+	instance.Elem().Set(value.Elem())
+	return instance.Interface().(Parser), true
+}
+
+// registeredNames returns the names of the custom parsers in lexical order.
+func registeredNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func isBuiltin(name string) bool {
+	for _, builtin := range builtinParsers {
+		if name == builtin {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// keyValueParser parses lines of key: value pairs, and records the path of
+// the file it parses.
+type keyValueParser struct {
+	path string
+}
+
+func (p *keyValueParser) SetPath(path string) {
+	p.path = path
+}
+
+func (p *keyValueParser) Unmarshal(contents []byte, v interface{}) error {
+	parsed := map[string]interface{}{"path": p.path}
+	for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+		keyValue := strings.SplitN(line, ":", 2)
+		parsed[strings.TrimSpace(keyValue[0])] = strings.TrimSpace(keyValue[1])
+	}
+
+	*v.(*interface{}) = parsed
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	registered := &keyValueParser{}
+	Register("keyvalue", registered)
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatal("create temp dir:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	custom := filepath.Join(directory, "config.keyvalue")
+	other := filepath.Join(directory, "config.txt")
+	for _, path := range []string{custom, other} {
+		if err := ioutil.WriteFile(path, []byte("name: config"), os.ModePerm); err != nil {
+			t.Fatal("write file:", err)
+		}
+	}
+
+	if !FileSupported(custom) {
+		t.Errorf("expected %s to be supported by the registered parser", custom)
+	}
+
+	configurations, err := ParseConfigurations([]string{custom})
+	if err != nil {
+		t.Fatal("parse configurations:", err)
+	}
+
+	expected := map[string]interface{}{"name": "config", "path": custom}
+	if !reflect.DeepEqual(configurations[custom], expected) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations[custom])
+	}
+
+	configurations, err = ParseConfigurationsAs([]string{other}, "keyvalue")
+	if err != nil {
+		t.Fatal("parse configurations as keyvalue:", err)
+	}
+
+	expected = map[string]interface{}{"name": "config", "path": other}
+	if !reflect.DeepEqual(configurations[other], expected) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations[other])
+	}
+
+	if registered.path != "" {
+		t.Errorf("expected the registered parser to be copied, but its path was set to %s", registered.path)
+	}
+
+	parsers := Parsers()
+	if parsers[len(parsers)-1] != "keyvalue" {
+		t.Errorf("expected the registered parser to be listed, got %v", parsers)
+	}
+
+	for _, name := range []string{"keyvalue", YAML, ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected registering %q to panic", name)
+				}
+			}()
+
+			Register(name, &keyValueParser{})
+		}()
+	}
+}
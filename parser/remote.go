@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/internal/remote"
+)
+
+// contentTypeParsers are the parsers of the documents that are fetched over
+// HTTP, keyed by the media type of the response.
+var contentTypeParsers = map[string]string{
+	"application/json":   JSON,
+	"text/json":          JSON,
+	"application/yaml":   YAML,
+	"application/x-yaml": YAML,
+	"text/yaml":          YAML,
+	"text/x-yaml":        YAML,
+	"application/toml":   TOML,
+}
+
+// IsURL reports whether the given path is the URL of a file that is
+// fetched over HTTP, rather than a path on the file system.
+func IsURL(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// NameFromContentType returns the name of the parser of a document with the
+// given content type, and whether the content type is known. Media types with
+// the +json and +yaml suffixes are parsed as JSON and YAML respectively.
+func NameFromContentType(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	if parserName, ok := contentTypeParsers[mediaType]; ok {
+		return parserName, true
+	}
+
+	if strings.HasSuffix(mediaType, "+json") {
+		return JSON, true
+	}
+
+	if strings.HasSuffix(mediaType, "+yaml") {
+		return YAML, true
+	}
+
+	return "", false
+}
+
+// fetchConfiguration fetches the configuration at the given URL, and returns
+// it with the parser to parse it with. Unless the options specify a parser,
+// the parser is chosen based on the content type of the response, falling
+// back to the parser map and the extension of the path of the URL for servers
+// that do not send a known content type.
+func fetchConfiguration(ctx context.Context, configurationURL string, options Options) (Parser, []byte, error) {
+	response, err := remote.Get(ctx, configurationURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+
+	contents, err := readContent(response.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read body: %w", err)
+	}
+
+	fileParser, err := newFromURL(response.Request.URL, response.Header.Get("Content-Type"), options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fileParser, contents, nil
+}
+
+func newFromURL(configurationURL *url.URL, contentType string, options Options) (Parser, error) {
+	if options.Parser != "" {
+		return New(options.Parser)
+	}
+
+	urlPath := path.Clean("/" + configurationURL.Path)
+	extension := strings.ToLower(strings.TrimPrefix(path.Ext(urlPath), "."))
+	if parser, ok := options.ParserMap[extension]; ok {
+		return New(parser)
+	}
+
+	if parserName, ok := NameFromContentType(contentType); ok {
+		return New(parserName)
+	}
+
+	if extension == "" && !strings.EqualFold(path.Base(urlPath), "dockerfile") {
+		return nil, fmt.Errorf("unknown parser for content type %q, the parser can be set with --parser", contentType)
+	}
+
+	return NewFromPath(urlPath)
+}
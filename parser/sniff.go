@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// sniffLength is the number of bytes at the start of a file that are
+// used to detect its format.
+const sniffLength = 4096
+
+// sniffFile returns the name of the parser for the file at the given path,
+// based on its contents, when the format of the file can be detected.
+func sniffFile(path string) (string, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil || !fileInfo.Mode().IsRegular() {
+		return "", false
+	}
+
+	// Errors are expected when the head of a compressed file is
+	// decompressed, which still leaves the contents that were read.
+	head, _ := readContent(io.LimitReader(file, sniffLength))
+	name, ok := sniff(head)
+	if !ok || name != YAML {
+		return name, ok
+	}
+
+	// The document start is also used by other formats, e.g. as the front
+	// matter of Markdown, so the whole file must be YAML collections.
+	contents, err := getConfigurationContent(path)
+	if err != nil || !isYAMLCollection(contents) {
+		return "", false
+	}
+
+	return name, true
+}
+
+// sniff returns the name of the parser for the given contents from the
+// start of a file, which are either:
+//
+// - a shebang, e.g. #!/usr/bin/env jsonnet, that names one of the parsers
+// - a JSON object or array
+// - a Dockerfile, whose first instruction is FROM, or ARG followed by FROM
+// - a YAML directive (%YAML) or document start (---)
+func sniff(contents []byte) (string, bool) {
+	if name, ok := sniffShebang(contents); ok {
+		return name, true
+	}
+
+	if isJSON(contents) {
+		return JSON, true
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#!")) {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return "", false
+	}
+
+	if strings.HasPrefix(lines[0], "%YAML") || lines[0] == "---" {
+		return YAML, true
+	}
+
+	if isInstruction(lines[0], "FROM") {
+		return Dockerfile, true
+	}
+
+	if isInstruction(lines[0], "ARG") {
+		for _, line := range lines[1:] {
+			if isInstruction(line, "FROM") {
+				return Dockerfile, true
+			}
+
+			if !isInstruction(line, "ARG") {
+				break
+			}
+		}
+	}
+
+	return "", false
+}
+
+// sniffShebang returns the name of the parser that is the interpreter of
+// the shebang on the first line of the given contents, if any.
+func sniffShebang(contents []byte) (string, bool) {
+	if !bytes.HasPrefix(contents, []byte("#!")) {
+		return "", false
+	}
+
+	line := string(contents[2:])
+	if end := strings.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	// The interpreter of env is the first argument that is not a flag,
+	// e.g. jsonnet for #!/usr/bin/env -S jsonnet.
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+
+	if _, err := New(interpreter); err != nil {
+		return "", false
+	}
+
+	return interpreter, true
+}
+
+// isJSON reports whether the given contents start with a JSON object or
+// array, based on their first tokens, as the contents may be truncated.
+func isJSON(contents []byte) bool {
+	trimmed := bytes.TrimSpace(contents)
+	if !bytes.HasPrefix(trimmed, []byte("{")) && !bytes.HasPrefix(trimmed, []byte("[")) {
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	if _, err := decoder.Token(); err != nil {
+		return false
+	}
+
+	// The first token within the object or array must be valid as well,
+	// e.g. [section] is the header of an INI section instead.
+	token, err := decoder.Token()
+	if err != nil {
+		return false
+	}
+
+	if trimmed[0] == '{' {
+		_, isKey := token.(string)
+		return isKey || token == json.Delim('}')
+	}
+
+	return true
+}
+
+// isInstruction reports whether the given line of a Dockerfile is the
+// given instruction. While instructions are case insensitive, only
+// uppercase instructions are detected, e.g. to not mistake a line of
+// text that starts with "from" for an instruction.
+func isInstruction(line string, instruction string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 1 && fields[0] == instruction
+}
+
+// isYAMLCollection reports whether the given contents are YAML whose
+// documents are all mappings or sequences.
+func isYAMLCollection(contents []byte) bool {
+	decoder := yamlv3.NewDecoder(bytes.NewReader(contents))
+	var documents int
+	for {
+		var node yamlv3.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil || len(node.Content) == 0 {
+			return false
+		}
+
+		if kind := node.Content[0].Kind; kind != yamlv3.MappingNode && kind != yamlv3.SequenceNode {
+			return false
+		}
+
+		documents++
+	}
+
+	return documents > 0
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/parser/docker"
+	"github.com/open-policy-agent/conftest/parser/json"
+	"github.com/open-policy-agent/conftest/parser/yaml"
+)
+
+func TestSniff(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{name: "dockerfile", contents: "FROM alpine:3.14\nRUN apk add curl", expected: Dockerfile},
+		{name: "dockerfile with a parser directive", contents: "# syntax=docker/dockerfile:1\nFROM alpine", expected: Dockerfile},
+		{name: "dockerfile with arguments before from", contents: "ARG VERSION=3.14\nFROM alpine:$VERSION", expected: Dockerfile},
+		{name: "arguments without from", contents: "ARG VERSION=3.14\nRUN echo", expected: ""},
+		{name: "text that starts with from", contents: "from now on, use the new config", expected: ""},
+		{name: "json object", contents: `{"name": "app"}`, expected: JSON},
+		{name: "json array", contents: "\n[1, 2, 3]", expected: JSON},
+		{name: "ini section", contents: "[section]\nkey = value", expected: ""},
+		{name: "hcl block", contents: "{ resource }", expected: ""},
+		{name: "yaml document start", contents: "# comment\n---\nname: app", expected: YAML},
+		{name: "yaml directive", contents: "%YAML 1.2\n---\nname: app", expected: YAML},
+		{name: "shebang of a parser", contents: "#!/usr/bin/env jsonnet\n{}", expected: JSONNET},
+		{name: "shebang with flags", contents: "#!/usr/bin/env -S jsonnet --tla-str env=dev\n{}", expected: JSONNET},
+		{name: "shebang of another interpreter", contents: "#!/bin/bash\necho hello", expected: ""},
+		{name: "text", contents: "key: value", expected: ""},
+		{name: "empty", contents: "", expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, _ := sniff([]byte(testCase.contents))
+			if actual != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewFromOptionsSniffing(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	files := map[string]string{
+		"Dockerfile.prod": "FROM alpine",
+		"settings.conf":   `{"debug": true}`,
+		"manifests":       "---\nkind: Pod\n---\nkind: Service",
+		"README.md":       "---\ntitle: Readme\n---\n# Readme\n\nSome text.",
+		"Jenkinsfile":     "pipeline {\n  agent any\n}",
+	}
+
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(directory, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		expected Parser
+	}{
+		{name: "Dockerfile.prod", expected: &docker.Parser{}},
+		{name: "settings.conf", expected: &json.Parser{}},
+		{name: "manifests", expected: &yaml.Parser{}},
+		{name: "README.md"},
+		{name: "Jenkinsfile"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := filepath.Join(directory, testCase.name)
+			actual, err := NewFromOptions(path, Options{})
+			if testCase.expected == nil {
+				if err == nil {
+					t.Errorf("expected an error, got parser %T", actual)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("new parser: %v", err)
+			}
+
+			if !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("expected parser %T, got %T", testCase.expected, actual)
+			}
+
+			if _, err := NewFromOptions(path, Options{NoSniff: true}); err == nil {
+				t.Error("expected an error when sniffing is disabled")
+			}
+		})
+	}
+}
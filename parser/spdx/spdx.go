@@ -0,0 +1,111 @@
+package spdx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Parser is a parser for SPDX software bills of materials, in either the JSON
+// or the tag-value format.
+//
+// The result has the document itself under document, and the packages of the
+// document under packages. Each of the packages has the same fields regardless
+// of the format of the SBOM, i.e. id, name, version, license and purl, where
+// the license is the concluded license of the package, or its declared license
+// when the concluded license is NOASSERTION.
+//
+// Documents in the tag-value format are converted to the structure of the JSON
+// format, e.g. PackageVersion is the versionInfo of the package and each of the
+// Relationship lines is an element of relationships, with its spdxElementId,
+// relationshipType and relatedSpdxElement.
+type Parser struct{}
+
+type document struct {
+	Packages []spdxPackage `json:"packages"`
+}
+
+type spdxPackage struct {
+	SPDXID           string        `json:"SPDXID"`
+	Name             string        `json:"name"`
+	VersionInfo      string        `json:"versionInfo"`
+	LicenseConcluded string        `json:"licenseConcluded"`
+	LicenseDeclared  string        `json:"licenseDeclared"`
+	ExternalRefs     []externalRef `json:"externalRefs"`
+}
+
+type externalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// Unmarshal unmarshals SPDX SBOMs.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var doc map[string]interface{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("unmarshal spdx json: %w", err)
+		}
+	} else {
+		var err error
+		doc, err = parseTagValue(data)
+		if err != nil {
+			return fmt.Errorf("parse spdx tag-value: %w", err)
+		}
+	}
+
+	if _, ok := doc["spdxVersion"]; !ok {
+		return fmt.Errorf("document is missing spdxVersion")
+	}
+
+	j, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("marshal spdx document: %w", err)
+	}
+
+	var d document
+	if err := json.Unmarshal(j, &d); err != nil {
+		return fmt.Errorf("unmarshal spdx packages: %w", err)
+	}
+
+	packages := make([]map[string]string, 0, len(d.Packages))
+	for _, pkg := range d.Packages {
+		license := pkg.LicenseConcluded
+		if license == "" || license == "NOASSERTION" {
+			license = pkg.LicenseDeclared
+		}
+
+		var purl string
+		for _, ref := range pkg.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				purl = ref.ReferenceLocator
+				break
+			}
+		}
+
+		packages = append(packages, map[string]string{
+			"id":      pkg.SPDXID,
+			"name":    pkg.Name,
+			"version": pkg.VersionInfo,
+			"license": license,
+			"purl":    purl,
+		})
+	}
+
+	result := map[string]interface{}{
+		"document": doc,
+		"packages": packages,
+	}
+
+	j, err = json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal spdx to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal spdx json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package spdx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSPDXParserJSON(t *testing.T) {
+	parser := &Parser{}
+	sample := `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "example",
+  "packages": [
+    {
+      "SPDXID": "SPDXRef-Package-express",
+      "name": "express",
+      "versionInfo": "4.18.2",
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "MIT",
+      "externalRefs": [
+        {"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/express@4.18.2"}
+      ]
+    }
+  ]
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"id":      "SPDXRef-Package-express",
+			"name":    "express",
+			"version": "4.18.2",
+			"license": "MIT",
+			"purl":    "pkg:npm/express@4.18.2",
+		},
+	}
+
+	result := input.(map[string]interface{})
+	if !reflect.DeepEqual(expected, result["packages"]) {
+		t.Errorf("Unexpected packages. expected %v actual %v", expected, result["packages"])
+	}
+}
+
+func TestSPDXParserTagValue(t *testing.T) {
+	parser := &Parser{}
+	sample := `SPDXVersion: SPDX-2.3
+DataLicense: CC0-1.0
+SPDXID: SPDXRef-DOCUMENT
+DocumentName: example
+Creator: Tool: syft
+Creator: Organization: Example
+Created: 2022-01-01T00:00:00Z
+
+# Packages
+PackageName: express
+SPDXID: SPDXRef-Package-express
+PackageVersion: 4.18.2
+FilesAnalyzed: false
+PackageChecksum: SHA1: 85ed0cf3d8d4b3f2f2b7d0e8c4f4a6f0b7a9c8d1
+PackageLicenseConcluded: MIT
+PackageLicenseDeclared: MIT
+PackageCopyrightText: <text>Copyright (c) 2009-2014
+TJ Holowaychuk</text>
+ExternalRef: PACKAGE-MANAGER purl pkg:npm/express@4.18.2
+ExternalRefComment: the npm package
+
+FileName: ./index.js
+SPDXID: SPDXRef-File-index
+FileChecksum: SHA1: d6a770ba38583ed4bb4525bd96e50461655d2758
+LicenseConcluded: MIT
+
+Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-express
+Relationship: SPDXRef-Package-express CONTAINS SPDXRef-File-index
+RelationshipComment: the files of the package`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"document": map[string]interface{}{
+			"spdxVersion": "SPDX-2.3",
+			"dataLicense": "CC0-1.0",
+			"SPDXID":      "SPDXRef-DOCUMENT",
+			"name":        "example",
+			"creationInfo": map[string]interface{}{
+				"creators": []interface{}{"Tool: syft", "Organization: Example"},
+				"created":  "2022-01-01T00:00:00Z",
+			},
+			"packages": []interface{}{
+				map[string]interface{}{
+					"name":          "express",
+					"SPDXID":        "SPDXRef-Package-express",
+					"versionInfo":   "4.18.2",
+					"filesAnalyzed": false,
+					"checksums": []interface{}{
+						map[string]interface{}{"algorithm": "SHA1", "checksumValue": "85ed0cf3d8d4b3f2f2b7d0e8c4f4a6f0b7a9c8d1"},
+					},
+					"licenseConcluded": "MIT",
+					"licenseDeclared":  "MIT",
+					"copyrightText":    "Copyright (c) 2009-2014\nTJ Holowaychuk",
+					"externalRefs": []interface{}{
+						map[string]interface{}{
+							"referenceCategory": "PACKAGE-MANAGER",
+							"referenceType":     "purl",
+							"referenceLocator":  "pkg:npm/express@4.18.2",
+							"comment":           "the npm package",
+						},
+					},
+				},
+			},
+			"files": []interface{}{
+				map[string]interface{}{
+					"fileName": "./index.js",
+					"SPDXID":   "SPDXRef-File-index",
+					"checksums": []interface{}{
+						map[string]interface{}{"algorithm": "SHA1", "checksumValue": "d6a770ba38583ed4bb4525bd96e50461655d2758"},
+					},
+					"licenseConcluded": "MIT",
+				},
+			},
+			"relationships": []interface{}{
+				map[string]interface{}{
+					"spdxElementId":      "SPDXRef-DOCUMENT",
+					"relationshipType":   "DESCRIBES",
+					"relatedSpdxElement": "SPDXRef-Package-express",
+				},
+				map[string]interface{}{
+					"spdxElementId":      "SPDXRef-Package-express",
+					"relationshipType":   "CONTAINS",
+					"relatedSpdxElement": "SPDXRef-File-index",
+					"comment":            "the files of the package",
+				},
+			},
+		},
+		"packages": []interface{}{
+			map[string]interface{}{
+				"id":      "SPDXRef-Package-express",
+				"name":    "express",
+				"version": "4.18.2",
+				"license": "MIT",
+				"purl":    "pkg:npm/express@4.18.2",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected SBOM. expected %v actual %v", expected, input)
+	}
+}
+
+func TestSPDXParserInvalid(t *testing.T) {
+	parser := &Parser{}
+
+	for _, sample := range []string{
+		"DocumentName: example",
+		"SPDXVersion: SPDX-2.3\nnot a tag",
+		"SPDXVersion: SPDX-2.3\nRelationship: SPDXRef-DOCUMENT DESCRIBES",
+		"SPDXVersion: SPDX-2.3\nPackageName: a\nPackageCopyrightText: <text>unclosed",
+	} {
+		var input interface{}
+		if err := parser.Unmarshal([]byte(sample), &input); err == nil {
+			t.Errorf("expected an error for %q", sample)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package spdx
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+type fieldKind int
+
+const (
+	kindText fieldKind = iota
+	kindList
+	kindBool
+	kindChecksum
+	kindExternalRef
+	kindExternalRefComment
+)
+
+type field struct {
+	name string
+	kind fieldKind
+}
+
+// The fields of the tags of each kind of element, which are named the
+// same as the fields of the elements in the JSON format.
+var (
+	documentFields = map[string]field{
+		"SPDXVersion":       {"spdxVersion", kindText},
+		"DataLicense":       {"dataLicense", kindText},
+		"SPDXID":            {"SPDXID", kindText},
+		"DocumentName":      {"name", kindText},
+		"DocumentNamespace": {"documentNamespace", kindText},
+		"DocumentComment":   {"comment", kindText},
+	}
+
+	creationInfoFields = map[string]field{
+		"Creator":            {"creators", kindList},
+		"Created":            {"created", kindText},
+		"CreatorComment":     {"comment", kindText},
+		"LicenseListVersion": {"licenseListVersion", kindText},
+	}
+
+	packageFields = map[string]field{
+		"PackageName":                 {"name", kindText},
+		"SPDXID":                      {"SPDXID", kindText},
+		"PackageVersion":              {"versionInfo", kindText},
+		"PackageFileName":             {"packageFileName", kindText},
+		"PackageSupplier":             {"supplier", kindText},
+		"PackageOriginator":           {"originator", kindText},
+		"PackageDownloadLocation":     {"downloadLocation", kindText},
+		"FilesAnalyzed":               {"filesAnalyzed", kindBool},
+		"PackageChecksum":             {"checksums", kindChecksum},
+		"PackageHomePage":             {"homepage", kindText},
+		"PackageSourceInfo":           {"sourceInfo", kindText},
+		"PackageLicenseConcluded":     {"licenseConcluded", kindText},
+		"PackageLicenseInfoFromFiles": {"licenseInfoFromFiles", kindList},
+		"PackageLicenseDeclared":      {"licenseDeclared", kindText},
+		"PackageLicenseComments":      {"licenseComments", kindText},
+		"PackageCopyrightText":        {"copyrightText", kindText},
+		"PackageSummary":              {"summary", kindText},
+		"PackageDescription":          {"description", kindText},
+		"PackageComment":              {"comment", kindText},
+		"PrimaryPackagePurpose":       {"primaryPackagePurpose", kindText},
+		"ExternalRef":                 {"externalRefs", kindExternalRef},
+		"ExternalRefComment":          {"externalRefs", kindExternalRefComment},
+	}
+
+	fileFields = map[string]field{
+		"FileName":          {"fileName", kindText},
+		"SPDXID":            {"SPDXID", kindText},
+		"FileType":          {"fileTypes", kindList},
+		"FileChecksum":      {"checksums", kindChecksum},
+		"LicenseConcluded":  {"licenseConcluded", kindText},
+		"LicenseInfoInFile": {"licenseInfoInFiles", kindList},
+		"LicenseComments":   {"licenseComments", kindText},
+		"FileCopyrightText": {"copyrightText", kindText},
+		"FileComment":       {"comment", kindText},
+		"FileNotice":        {"noticeText", kindText},
+	}
+
+	licenseFields = map[string]field{
+		"LicenseID":      {"licenseId", kindText},
+		"ExtractedText":  {"extractedText", kindText},
+		"LicenseName":    {"name", kindText},
+		"LicenseComment": {"comment", kindText},
+	}
+)
+
+// parseTagValue parses a document in the tag-value format into the structure
+// of the JSON format. The packages, files and extracted licenses are started
+// by their PackageName, FileName and LicenseID tags, and the other tags belong
+// to the element that was started last. The tags that are not known are kept
+// with their own names, as a list when they are repeated within an element.
+func parseTagValue(data []byte) (map[string]interface{}, error) {
+	lines, err := tagValueLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{})
+	creationInfo := make(map[string]interface{})
+	var relationships []interface{}
+
+	element := doc
+	fields := documentFields
+	for _, line := range lines {
+		switch line.tag {
+		case "PackageName":
+			element, fields = newElement(doc, "packages"), packageFields
+		case "FileName":
+			element, fields = newElement(doc, "files"), fileFields
+		case "LicenseID":
+			element, fields = newElement(doc, "hasExtractedLicensingInfos"), licenseFields
+
+		case "Relationship":
+			parts := strings.Fields(line.value)
+			if len(parts) != 3 {
+				return nil, fmt.Errorf("line %d: relationship %q must be in the form of element type related-element", line.number, line.value)
+			}
+
+			relationships = append(relationships, map[string]interface{}{
+				"spdxElementId":      parts[0],
+				"relationshipType":   parts[1],
+				"relatedSpdxElement": parts[2],
+			})
+			continue
+
+		case "RelationshipComment":
+			if len(relationships) == 0 {
+				return nil, fmt.Errorf("line %d: relationship comment without a relationship", line.number)
+			}
+
+			relationships[len(relationships)-1].(map[string]interface{})["comment"] = line.value
+			continue
+		}
+
+		if f, ok := creationInfoFields[line.tag]; ok {
+			if err := setField(creationInfo, f, line.value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.number, err)
+			}
+
+			continue
+		}
+
+		f, ok := fields[line.tag]
+		if !ok {
+			setUnknown(element, line.tag, line.value)
+			continue
+		}
+
+		if err := setField(element, f, line.value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+	}
+
+	if len(creationInfo) > 0 {
+		doc["creationInfo"] = creationInfo
+	}
+
+	if len(relationships) > 0 {
+		doc["relationships"] = relationships
+	}
+
+	return doc, nil
+}
+
+// newElement appends a new element to the list of the given key of
+// the document, and returns it.
+func newElement(doc map[string]interface{}, key string) map[string]interface{} {
+	element := make(map[string]interface{})
+	elements, _ := doc[key].([]interface{})
+	doc[key] = append(elements, element)
+
+	return element
+}
+
+func setField(element map[string]interface{}, f field, value string) error {
+	switch f.kind {
+	case kindList:
+		values, _ := element[f.name].([]interface{})
+		element[f.name] = append(values, value)
+
+	case kindBool:
+		switch value {
+		case "true":
+			element[f.name] = true
+		case "false":
+			element[f.name] = false
+		default:
+			return fmt.Errorf("%s must be true or false, got %q", f.name, value)
+		}
+
+	case kindChecksum:
+		algorithmValue := strings.SplitN(value, ":", 2)
+		if len(algorithmValue) != 2 {
+			return fmt.Errorf("checksum %q must be in the form of algorithm: value", value)
+		}
+
+		checksums, _ := element[f.name].([]interface{})
+		element[f.name] = append(checksums, map[string]interface{}{
+			"algorithm":     strings.TrimSpace(algorithmValue[0]),
+			"checksumValue": strings.TrimSpace(algorithmValue[1]),
+		})
+
+	case kindExternalRef:
+		parts := strings.Fields(value)
+		if len(parts) != 3 {
+			return fmt.Errorf("external reference %q must be in the form of category type locator", value)
+		}
+
+		refs, _ := element[f.name].([]interface{})
+		element[f.name] = append(refs, map[string]interface{}{
+			"referenceCategory": parts[0],
+			"referenceType":     parts[1],
+			"referenceLocator":  parts[2],
+		})
+
+	case kindExternalRefComment:
+		refs, _ := element[f.name].([]interface{})
+		if len(refs) == 0 {
+			return fmt.Errorf("external reference comment without an external reference")
+		}
+
+		refs[len(refs)-1].(map[string]interface{})["comment"] = value
+
+	default:
+		element[f.name] = value
+	}
+
+	return nil
+}
+
+func setUnknown(element map[string]interface{}, tag string, value string) {
+	switch existing := element[tag].(type) {
+	case nil:
+		element[tag] = value
+	case string:
+		element[tag] = []interface{}{existing, value}
+	case []interface{}:
+		element[tag] = append(existing, value)
+	}
+}
+
+type tagValueLine struct {
+	number int
+	tag    string
+	value  string
+}
+
+// tagValueLines returns the tags and values of the lines that are not empty
+// or comments. Values that are enclosed in <text> and </text> can span
+// several lines, and are returned without the enclosing tags.
+func tagValueLines(data []byte) ([]tagValueLine, error) {
+	var lines []tagValueLine
+	var text *tagValueLine
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	number := 0
+	for scanner.Scan() {
+		number++
+		raw := scanner.Text()
+
+		if text != nil {
+			if i := strings.Index(raw, "</text>"); i >= 0 {
+				text.value += "\n" + raw[:i]
+				lines = append(lines, *text)
+				text = nil
+				continue
+			}
+
+			text.value += "\n" + raw
+			continue
+		}
+
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		tagValue := strings.SplitN(trimmed, ":", 2)
+		if len(tagValue) != 2 || strings.TrimSpace(tagValue[0]) == "" {
+			return nil, fmt.Errorf("line %d: %q must be in the form of tag: value", number, trimmed)
+		}
+
+		line := tagValueLine{number: number, tag: strings.TrimSpace(tagValue[0]), value: strings.TrimSpace(tagValue[1])}
+		if strings.HasPrefix(line.value, "<text>") {
+			line.value = strings.TrimPrefix(line.value, "<text>")
+			if i := strings.Index(line.value, "</text>"); i >= 0 {
+				line.value = line.value[:i]
+			} else {
+				text = &line
+				continue
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if text != nil {
+		return nil, fmt.Errorf("line %d: <text> is not closed", text.number)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,147 @@
+package systemd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Parser is a parser for systemd unit files, such as .service, .timer and
+// .socket units.
+//
+// The result is a map of section names, e.g. Unit, Service and Install, to
+// the directives of the section. All values are strings. A directive that is
+// set more than once within a section, such as ExecStart or Environment, is a
+// list of its values, and the same as with systemd, an empty assignment
+// clears the values that were set before it.
+type Parser struct{}
+
+// Unmarshal unmarshals systemd unit files.
+func (s *Parser) Unmarshal(p []byte, v interface{}) error {
+	lines, err := logicalLines(p)
+	if err != nil {
+		return fmt.Errorf("read lines: %w", err)
+	}
+
+	result := make(map[string]map[string][]string)
+	var section string
+	for _, line := range lines {
+		if strings.HasPrefix(line.text, "[") {
+			if !strings.HasSuffix(line.text, "]") {
+				return fmt.Errorf("line %d: invalid section header %q", line.number, line.text)
+			}
+
+			section = strings.TrimSpace(line.text[1 : len(line.text)-1])
+			if section == "" {
+				return fmt.Errorf("line %d: empty section name", line.number)
+			}
+
+			if _, ok := result[section]; !ok {
+				result[section] = make(map[string][]string)
+			}
+
+			continue
+		}
+
+		if section == "" {
+			return fmt.Errorf("line %d: assignment outside of a section", line.number)
+		}
+
+		keyValue := strings.SplitN(line.text, "=", 2)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+			return fmt.Errorf("line %d: %q must be in the form of key=value", line.number, line.text)
+		}
+
+		key := strings.TrimSpace(keyValue[0])
+		value := strings.TrimSpace(keyValue[1])
+		if value == "" {
+			result[section][key] = []string{}
+			continue
+		}
+
+		result[section][key] = append(result[section][key], value)
+	}
+
+	units := make(map[string]map[string]interface{})
+	for section, directives := range result {
+		units[section] = make(map[string]interface{})
+		for key, values := range directives {
+			switch len(values) {
+			case 0:
+				units[section][key] = ""
+			case 1:
+				units[section][key] = values[0]
+			default:
+				units[section][key] = values
+			}
+		}
+	}
+
+	j, err := json.Marshal(units)
+	if err != nil {
+		return fmt.Errorf("marshal systemd unit to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal systemd unit json: %w", err)
+	}
+
+	return nil
+}
+
+type logicalLine struct {
+	number int
+	text   string
+}
+
+// logicalLines returns the lines that are not empty or comments, where lines
+// that end with a backslash are joined with the next line by a space. The
+// number of a line is the number of the first line that it consists of.
+func logicalLines(p []byte) ([]logicalLine, error) {
+	var lines []logicalLine
+	var continued *logicalLine
+
+	scanner := bufio.NewScanner(bytes.NewReader(p))
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimSpace(scanner.Text())
+
+		// Comments within continued lines are ignored, the same as with
+		// systemd, and do not end the continuation.
+		if strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		if continued == nil {
+			if text == "" {
+				continue
+			}
+
+			continued = &logicalLine{number: number}
+		} else {
+			continued.text += " "
+		}
+
+		if strings.HasSuffix(text, `\`) {
+			continued.text += strings.TrimSpace(strings.TrimSuffix(text, `\`))
+			continue
+		}
+
+		continued.text += text
+		lines = append(lines, *continued)
+		continued = nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if continued != nil {
+		lines = append(lines, *continued)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,68 @@
+package systemd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSystemdParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `# Comment
+[Unit]
+Description=Web server
+After=network.target
+
+[Service]
+User=www-data
+Restart = on-failure
+ExecStartPre=/usr/sbin/nginx -t
+ExecStart=
+ExecStart=/usr/sbin/nginx \
+    -g 'daemon off;'
+Environment=A=1
+Environment=B=2
+; Another comment
+ExecStartPost=/bin/true
+ExecStartPost=
+
+[Install]
+WantedBy=multi-user.target`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"Unit": map[string]interface{}{
+			"Description": "Web server",
+			"After":       "network.target",
+		},
+		"Service": map[string]interface{}{
+			"User":          "www-data",
+			"Restart":       "on-failure",
+			"ExecStartPre":  "/usr/sbin/nginx -t",
+			"ExecStart":     "/usr/sbin/nginx -g 'daemon off;'",
+			"Environment":   []interface{}{"A=1", "B=2"},
+			"ExecStartPost": "",
+		},
+		"Install": map[string]interface{}{
+			"WantedBy": "multi-user.target",
+		},
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected unit. expected %v actual %v", expected, input)
+	}
+}
+
+func TestSystemdParserInvalid(t *testing.T) {
+	parser := &Parser{}
+
+	for _, sample := range []string{"User=root", "[Service\nUser=root", "[Service]\nUser"} {
+		var input interface{}
+		if err := parser.Unmarshal([]byte(sample), &input); err == nil {
+			t.Errorf("expected an error for %q", sample)
+		}
+	}
+}
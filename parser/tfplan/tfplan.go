@@ -0,0 +1,53 @@
+package tfplan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Parser is a parser for Terraform plans that have been
+// rendered as JSON (e.g. terraform show -json).
+type Parser struct{}
+
+// Unmarshal unmarshals Terraform plans in the JSON format.
+//
+// Both the 0.1 and the 1.x versions of the plan format are supported. The
+// top level keys of the plan, such as resource_changes, planned_values and
+// configuration, are made available to the policies as is.
+func (tp *Parser) Unmarshal(p []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(p))
+
+	var plan map[string]interface{}
+	if err := decoder.Decode(&plan); err != nil {
+		return fmt.Errorf("decode plan: %w", err)
+	}
+
+	formatVersion, ok := plan["format_version"].(string)
+	if !ok {
+		return fmt.Errorf("plan is missing the format_version field")
+	}
+
+	majorVersion := strings.SplitN(formatVersion, ".", 2)[0]
+	if majorVersion != "0" && majorVersion != "1" {
+		return fmt.Errorf("unsupported plan format version: %s", formatVersion)
+	}
+
+	// Plans that do not contain any changes omit the resource_changes key
+	// entirely. Always include it so policies can safely iterate over it.
+	if _, ok := plan["resource_changes"]; !ok {
+		plan["resource_changes"] = []interface{}{}
+	}
+
+	j, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("marshal plan to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal plan json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package tfplan
+
+import (
+	"testing"
+)
+
+func TestTerraformPlanParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `{
+  "format_version": "1.0",
+  "terraform_version": "1.0.0",
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "aws_s3_bucket.example",
+          "type": "aws_s3_bucket",
+          "values": {"acl": "private"}
+        }
+      ]
+    }
+  },
+  "resource_changes": [
+    {
+      "address": "aws_s3_bucket.example",
+      "type": "aws_s3_bucket",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"acl": "private"}
+      }
+    }
+  ],
+  "configuration": {
+    "root_module": {}
+  }
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	inputMap := input.(map[string]interface{})
+	changes := inputMap["resource_changes"].([]interface{})
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 resource change, got %v", len(changes))
+	}
+
+	after := changes[0].(map[string]interface{})["change"].(map[string]interface{})["after"].(map[string]interface{})
+	if after["acl"] != "private" {
+		t.Errorf("expected after.acl to be private, got %v", after["acl"])
+	}
+
+	if _, ok := inputMap["planned_values"]; !ok {
+		t.Error("expected planned_values to be present")
+	}
+}
+
+func TestTerraformPlanParserFormatVersions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"legacy format", `{"format_version": "0.1"}`, false},
+		{"current format", `{"format_version": "1.2"}`, false},
+		{"unsupported format", `{"format_version": "2.0"}`, true},
+		{"missing format", `{"resource_changes": []}`, true},
+		{"invalid json", `{`, true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var input interface{}
+			err := (&Parser{}).Unmarshal([]byte(testCase.input), &input)
+			if testCase.wantErr != (err != nil) {
+				t.Fatalf("unexpected error result. expected error %v, got %v", testCase.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			inputMap := input.(map[string]interface{})
+			if _, ok := inputMap["resource_changes"].([]interface{}); !ok {
+				t.Error("expected resource_changes to always be present")
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package toml
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -9,11 +11,80 @@ import (
 // Parser is a TOML parser.
 type Parser struct{}
 
+// The names of the locations that the TOML decoder uses for
+// date and time values that do not have an offset.
+const (
+	localDatetime = "datetime-local"
+	localDate     = "date-local"
+	localTime     = "time-local"
+)
+
 // Unmarshal unmarshals TOML files.
 func (tp *Parser) Unmarshal(p []byte, v interface{}) error {
-	if err := toml.Unmarshal(p, v); err != nil {
+	var content interface{}
+	if err := toml.Unmarshal(p, &content); err != nil {
 		return fmt.Errorf("unmarshal toml: %w", err)
 	}
 
+	j, err := json.Marshal(normalize(content))
+	if err != nil {
+		return fmt.Errorf("marshal toml to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal toml json: %w", err)
+	}
+
 	return nil
 }
+
+// normalize converts arrays of tables into lists of values, so that they
+// are represented the same as any other array, and converts dates and times
+// into strings in the format they were written in, so that policies can
+// compare them.
+func normalize(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = normalize(item)
+		}
+
+		return value
+
+	case []map[string]interface{}:
+		items := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			items = append(items, normalize(item))
+		}
+
+		return items
+
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalize(item)
+		}
+
+		return value
+
+	case time.Time:
+		return formatTime(value)
+
+	default:
+		return value
+	}
+}
+
+// formatTime formats the given time in RFC 3339 format. Local dates and
+// times, which do not have an offset, are formatted without one.
+func formatTime(t time.Time) string {
+	switch t.Location().String() {
+	case localDatetime:
+		return t.Format("2006-01-02T15:04:05.999999999")
+	case localDate:
+		return t.Format("2006-01-02")
+	case localTime:
+		return t.Format("15:04:05.999999999")
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
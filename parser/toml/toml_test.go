@@ -1,6 +1,7 @@
 package toml
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -28,3 +29,64 @@ func TestTomlParser(t *testing.T) {
 		t.Error("there should be at least one item defined in the parsed file, but none found")
 	}
 }
+
+func TestTomlParserArrayOfTables(t *testing.T) {
+	parser := &Parser{}
+	sample := `[[servers]]
+name = "alpha"
+
+  [[servers.ports]]
+  port = 80
+
+  [[servers.ports]]
+  port = 443
+
+[[servers]]
+name = "beta"`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{
+				"name": "alpha",
+				"ports": []interface{}{
+					map[string]interface{}{"port": float64(80)},
+					map[string]interface{}{"port": float64(443)},
+				},
+			},
+			map[string]interface{}{"name": "beta"},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected array of tables. expected %v actual %v", expected, input)
+	}
+}
+
+func TestTomlParserDatetimes(t *testing.T) {
+	parser := &Parser{}
+	sample := `offset = 1979-05-27T07:32:00-08:00
+local = 1979-05-27T07:32:00.5
+date = 1979-05-27
+time = 07:32:00`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"offset": "1979-05-27T07:32:00-08:00",
+		"local":  "1979-05-27T07:32:00.5",
+		"date":   "1979-05-27",
+		"time":   "07:32:00",
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected datetimes. expected %v actual %v", expected, input)
+	}
+}
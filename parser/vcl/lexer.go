@@ -0,0 +1,120 @@
+package vcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenSymbol
+)
+
+// token is a token of a VCL file. The text of a string token is the
+// contents of the string, without its quotes.
+type token struct {
+	kind  tokenKind
+	text  string
+	line  int
+	start int
+	end   int
+}
+
+// symbols are the operators and punctuation of VCL, where the
+// symbols that start with another symbol are listed first.
+var symbols = []string{
+	"==", "!=", "!~", "&&", "||", "+=", "-=", "*=", "/=", "<=", ">=",
+	"{", "}", "(", ")", ";", ",", "=", "~", "!", "<", ">", "+", "-", "*", "/", "%", ":",
+}
+
+// tokenize splits the given VCL into tokens, skipping whitespace and the
+// comments that start with #, // or are enclosed in /* and */.
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+
+		case c == '#' || strings.HasPrefix(source[i:], "//"):
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(source[i:], "/*"):
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+
+			line += strings.Count(source[i:i+2+end], "\n")
+			i += 2 + end + 2
+
+		// Long strings are enclosed in {" and "}, and can span several lines.
+		case strings.HasPrefix(source[i:], `{"`):
+			end := strings.Index(source[i+2:], `"}`)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated long string", line)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: source[i+2 : i+2+end], line: line, start: i, end: i + 2 + end + 2})
+			line += strings.Count(source[i:i+2+end], "\n")
+			i += 2 + end + 2
+
+		case c == '"':
+			end := strings.IndexAny(source[i+1:], "\"\n")
+			if end < 0 || source[i+1+end] != '"' {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: source[i+1 : i+1+end], line: line, start: i, end: i + 1 + end + 1})
+			i += 1 + end + 1
+
+		case isWordStart(c):
+			start := i
+			for i < len(source) && isWordPart(source[i]) {
+				i++
+			}
+
+			tokens = append(tokens, token{kind: tokenWord, text: source[start:i], line: line, start: start, end: i})
+
+		default:
+			symbol := ""
+			for _, candidate := range symbols {
+				if strings.HasPrefix(source[i:], candidate) {
+					symbol = candidate
+					break
+				}
+			}
+
+			if symbol == "" {
+				return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+			}
+
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol, line: line, start: i, end: i + len(symbol)})
+			i += len(symbol)
+		}
+	}
+
+	return tokens, nil
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// isWordPart reports whether the character can be part of a word, which
+// includes dashes and colons for names such as req.http.X-Forwarded-For
+// and req.http.Cookie:session.
+func isWordPart(c byte) bool {
+	return isWordStart(c) || c == '-' || c == ':'
+}
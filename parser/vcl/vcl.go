@@ -3,18 +3,34 @@ package vcl
 import (
 	"encoding/json"
 	"fmt"
-
-	"github.com/KeisukeYamashita/go-vcl/vcl"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 // Parser is a VCL parser.
 type Parser struct{}
 
 // Unmarshal unmarshals VCL files.
+//
+// The VCL is represented as an outline of its declarations, rather than by
+// parsing its expressions. Backends, probes and directors are objects of their
+// attributes, without the leading dot, keyed by their names. ACLs are lists of
+// their entries, tables are objects of their entries, and subroutines are lists
+// of their statements. Statements have a type, such as set, unset, return or if,
+// and the expressions of the statements are kept as text, e.g.
+//
+//	{"type": "if", "condition": "req.method == \"PURGE\"", "body": [...]}
 func (p *Parser) Unmarshal(b []byte, v interface{}) error {
-	result := make(map[string]interface{})
-	if errs := vcl.Decode(b, &result); len(errs) > 0 {
-		return fmt.Errorf("decode vcl: %w", errs[0])
+	tokens, err := tokenize(string(b))
+	if err != nil {
+		return fmt.Errorf("tokenize vcl: %w", err)
+	}
+
+	vclParser := vclParser{source: string(b), tokens: tokens}
+	result, err := vclParser.parse()
+	if err != nil {
+		return fmt.Errorf("parse vcl: %w", err)
 	}
 
 	j, err := json.Marshal(result)
@@ -28,3 +44,542 @@ func (p *Parser) Unmarshal(b []byte, v interface{}) error {
 
 	return nil
 }
+
+type vclParser struct {
+	source string
+	tokens []token
+	pos    int
+}
+
+func (p *vclParser) parse() (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	declarations := func(kind string) map[string]interface{} {
+		if _, ok := result[kind]; !ok {
+			result[kind] = make(map[string]interface{})
+		}
+
+		return result[kind].(map[string]interface{})
+	}
+
+	for !p.done() {
+		keyword := p.next()
+		if keyword.text == ";" {
+			continue
+		}
+
+		if keyword.kind != tokenWord {
+			return nil, fmt.Errorf("line %d: unexpected %q", keyword.line, keyword.text)
+		}
+
+		switch keyword.text {
+		case "vcl":
+			version, err := p.until(";")
+			if err != nil {
+				return nil, err
+			}
+
+			result["vcl"] = p.text(version)
+
+		case "import":
+			statement, err := p.until(";")
+			if err != nil {
+				return nil, err
+			}
+
+			if len(statement) > 0 {
+				imports, _ := result["import"].([]interface{})
+				result["import"] = append(imports, statement[0].text)
+			}
+
+		case "include":
+			statement, err := p.until(";")
+			if err != nil {
+				return nil, err
+			}
+
+			if len(statement) > 0 {
+				includes, _ := result["include"].([]interface{})
+				result["include"] = append(includes, statement[0].text)
+			}
+
+		case "acl":
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+
+			entries, err := p.acl()
+			if err != nil {
+				return nil, fmt.Errorf("acl %s: %w", name, err)
+			}
+
+			declarations("acl")[name] = entries
+
+		case "sub":
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+
+			statements, err := p.block()
+			if err != nil {
+				return nil, fmt.Errorf("sub %s: %w", name, err)
+			}
+
+			// Subroutines that are defined more than once are concatenated.
+			existing, _ := declarations("sub")[name].([]interface{})
+			declarations("sub")[name] = append(existing, statements...)
+
+		case "table":
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+
+			entries, err := p.table()
+			if err != nil {
+				return nil, fmt.Errorf("table %s: %w", name, err)
+			}
+
+			declarations("table")[name] = entries
+
+		// Backends, probes, directors and any other declarations are
+		// objects of their attributes. Directors also have a type, which
+		// is given after their name.
+		default:
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+
+			var declarationType string
+			if !p.done() && p.peek().kind == tokenWord {
+				declarationType = p.next().text
+			}
+
+			attributes, err := p.attributes()
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", keyword.text, name, err)
+			}
+
+			if declarationType != "" {
+				attributes["type"] = declarationType
+			}
+
+			declarations(keyword.text)[name] = attributes
+		}
+	}
+
+	return result, nil
+}
+
+// attributes parses a block of attributes, such as .host = "example.com";
+// whose values are either values or blocks of attributes themselves. Blocks
+// without a name, such as the backends of directors, are listed as backends.
+func (p *vclParser) attributes() (map[string]interface{}, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	attributes := make(map[string]interface{})
+	for {
+		if p.done() {
+			return nil, fmt.Errorf("unexpected end of file")
+		}
+
+		current := p.next()
+		switch {
+		case current.text == "}":
+			return attributes, nil
+
+		case current.text == ";":
+			continue
+
+		case current.text == "{":
+			p.pos--
+			backend, err := p.attributes()
+			if err != nil {
+				return nil, err
+			}
+
+			backends, _ := attributes["backends"].([]interface{})
+			attributes["backends"] = append(backends, backend)
+
+		case current.kind == tokenWord && strings.HasPrefix(current.text, "."):
+			name := strings.TrimPrefix(current.text, ".")
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+
+			if !p.done() && p.peek().text == "{" {
+				nested, err := p.attributes()
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+
+				attributes[name] = nested
+				continue
+			}
+
+			value, err := p.until(";")
+			if err != nil {
+				return nil, err
+			}
+
+			attributes[name] = p.value(value)
+
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", current.line, current.text)
+		}
+	}
+}
+
+// acl parses the entries of an ACL, such as "192.168.0.0"/24, which are
+// represented without their quotes, e.g. 192.168.0.0/24 and !localhost.
+func (p *vclParser) acl() ([]interface{}, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	entries := []interface{}{}
+	for {
+		if p.done() {
+			return nil, fmt.Errorf("unexpected end of file")
+		}
+
+		if p.peek().text == "}" {
+			p.next()
+			return entries, nil
+		}
+
+		entry, err := p.until(";")
+		if err != nil {
+			return nil, err
+		}
+
+		var text strings.Builder
+		for _, part := range entry {
+			if part.text != "(" && part.text != ")" {
+				text.WriteString(part.text)
+			}
+		}
+
+		if text.Len() > 0 {
+			entries = append(entries, text.String())
+		}
+	}
+}
+
+// table parses the entries of a table, such as "key": "value",
+// which are separated by commas.
+func (p *vclParser) table() (map[string]interface{}, error) {
+	// The type of the values of the table is optional.
+	if !p.done() && p.peek().kind == tokenWord {
+		p.next()
+	}
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]interface{})
+	for {
+		if p.done() {
+			return nil, fmt.Errorf("unexpected end of file")
+		}
+
+		key := p.next()
+		if key.text == "}" && key.kind == tokenSymbol {
+			return entries, nil
+		}
+
+		if key.text == "," && key.kind == tokenSymbol {
+			continue
+		}
+
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		var value []token
+		for !p.done() && p.peek().text != "," && p.peek().text != "}" {
+			value = append(value, p.next())
+		}
+
+		entries[key.text] = p.value(value)
+	}
+}
+
+// block parses a block of statements.
+func (p *vclParser) block() ([]interface{}, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	statements := []interface{}{}
+	for {
+		if p.done() {
+			return nil, fmt.Errorf("unexpected end of file")
+		}
+
+		current := p.peek()
+		if current.text == "}" && current.kind == tokenSymbol {
+			p.next()
+			return statements, nil
+		}
+
+		if current.text == ";" && current.kind == tokenSymbol {
+			p.next()
+			continue
+		}
+
+		statement, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+
+		statements = append(statements, statement)
+	}
+}
+
+func (p *vclParser) statement() (map[string]interface{}, error) {
+	if p.peek().text == "{" {
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{"type": "block", "body": body}, nil
+	}
+
+	keyword := p.next()
+	if keyword.kind != tokenWord {
+		return nil, fmt.Errorf("line %d: unexpected %q", keyword.line, keyword.text)
+	}
+
+	switch keyword.text {
+	case "if":
+		return p.ifStatement()
+
+	case "set", "add":
+		target, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		operator := p.next()
+		value, err := p.until(";")
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{"type": keyword.text, "target": target, "operator": operator.text, "value": p.text(value)}, nil
+
+	case "unset", "remove":
+		target, err := p.until(";")
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{"type": keyword.text, "target": p.text(target)}, nil
+
+	default:
+		rest, err := p.until(";")
+		if err != nil {
+			return nil, err
+		}
+
+		statement := map[string]interface{}{"type": keyword.text}
+
+		// The values of return statements are enclosed in parentheses,
+		// e.g. return (pass); which are not part of the value.
+		value := p.text(rest)
+		if keyword.text == "return" && strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+			value = strings.TrimSpace(value[1 : len(value)-1])
+		}
+
+		if value != "" {
+			statement["value"] = value
+		}
+
+		return statement, nil
+	}
+}
+
+// ifStatement parses the condition and the body of an if statement, where
+// the else if branches are nested in the else branch of the statement.
+func (p *vclParser) ifStatement() (map[string]interface{}, error) {
+	condition, err := p.parenthesized()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+
+	statement := map[string]interface{}{"type": "if", "condition": condition, "body": body}
+	if p.done() {
+		return statement, nil
+	}
+
+	switch p.peek().text {
+	case "elseif", "elsif", "elif":
+		p.next()
+		branch, err := p.ifStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		statement["else"] = []interface{}{branch}
+
+	case "else":
+		p.next()
+		if !p.done() && p.peek().text == "if" {
+			p.next()
+			branch, err := p.ifStatement()
+			if err != nil {
+				return nil, err
+			}
+
+			statement["else"] = []interface{}{branch}
+			break
+		}
+
+		elseBody, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+
+		statement["else"] = elseBody
+	}
+
+	return statement, nil
+}
+
+// parenthesized returns the text that is enclosed in parentheses.
+func (p *vclParser) parenthesized() (string, error) {
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+
+	var enclosed []token
+	depth := 1
+	for {
+		if p.done() {
+			return "", fmt.Errorf("unexpected end of file")
+		}
+
+		current := p.next()
+		if current.kind == tokenSymbol && current.text == "(" {
+			depth++
+		}
+
+		if current.kind == tokenSymbol && current.text == ")" {
+			depth--
+			if depth == 0 {
+				return p.text(enclosed), nil
+			}
+		}
+
+		enclosed = append(enclosed, current)
+	}
+}
+
+// name returns the next token, which is the name of a declaration.
+func (p *vclParser) name() (string, error) {
+	if p.done() {
+		return "", fmt.Errorf("unexpected end of file")
+	}
+
+	name := p.next()
+	if name.kind == tokenSymbol {
+		return "", fmt.Errorf("line %d: expected a name, got %q", name.line, name.text)
+	}
+
+	return name.text, nil
+}
+
+// until returns the tokens up to the given symbol, and skips the symbol.
+func (p *vclParser) until(symbol string) ([]token, error) {
+	var tokens []token
+	for {
+		if p.done() {
+			return nil, fmt.Errorf("unexpected end of file, expected %q", symbol)
+		}
+
+		current := p.next()
+		if current.kind == tokenSymbol && current.text == symbol {
+			return tokens, nil
+		}
+
+		tokens = append(tokens, current)
+	}
+}
+
+func (p *vclParser) expect(symbol string) error {
+	if p.done() {
+		return fmt.Errorf("unexpected end of file, expected %q", symbol)
+	}
+
+	current := p.next()
+	if current.kind != tokenSymbol || current.text != symbol {
+		return fmt.Errorf("line %d: expected %q, got %q", current.line, symbol, current.text)
+	}
+
+	return nil
+}
+
+func (p *vclParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *vclParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *vclParser) next() token {
+	current := p.tokens[p.pos]
+	p.pos++
+	return current
+}
+
+var lineBreaks = regexp.MustCompile(`\s*\n\s*`)
+
+// text returns the source of the given tokens, as it is written in the file,
+// where line breaks and the indentation that follows them are a single space.
+func (p *vclParser) text(tokens []token) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	source := p.source[tokens[0].start:tokens[len(tokens)-1].end]
+	return lineBreaks.ReplaceAllString(source, " ")
+}
+
+// value returns the value of an attribute or an entry. A single string is
+// its contents, several strings are a list of their contents, and a number
+// is a number. Other values, such as durations, are their source.
+func (p *vclParser) value(tokens []token) interface{} {
+	if len(tokens) == 1 && tokens[0].kind == tokenString {
+		return tokens[0].text
+	}
+
+	if len(tokens) == 1 && tokens[0].kind == tokenWord {
+		if number, err := strconv.ParseFloat(tokens[0].text, 64); err == nil {
+			return number
+		}
+	}
+
+	var strings []interface{}
+	for _, current := range tokens {
+		if current.kind != tokenString {
+			return p.text(tokens)
+		}
+
+		strings = append(strings, current.text)
+	}
+
+	return strings
+}
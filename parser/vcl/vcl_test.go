@@ -1,6 +1,9 @@
 package vcl
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestVCLParser(t *testing.T) {
 	parser := &Parser{}
@@ -24,3 +27,160 @@ func TestVCLParser(t *testing.T) {
 		t.Error("there should be at least one item defined in the parsed file, but none found")
 	}
 }
+
+func TestVCLParserOutline(t *testing.T) {
+	parser := &Parser{}
+	sample := `vcl 4.0;
+import std;
+
+backend default {
+    .host = "127.0.0.1";
+    .port = "8080";
+    .probe = {
+        .url = "/health";
+        .interval = 5s;
+    }
+}
+
+acl purge {
+    "localhost";
+    "192.168.55.0"/24;
+    !"10.0.0.1";
+}
+
+sub vcl_recv {
+    # Only allow purging from the purge ACL.
+    if (req.method == "PURGE") {
+        if (!client.ip ~ purge) {
+            return (synth(405, "Not allowed."));
+        }
+        return (purge);
+    } else if (req.method != "GET") {
+        return (pass);
+    }
+    set req.http.X-Forwarded-For = client.ip;
+    unset req.http.Cookie;
+}
+
+sub vcl_backend_response {
+    set beresp.ttl = 1h;
+}`
+
+	expected := map[string]interface{}{
+		"vcl":    "4.0",
+		"import": []interface{}{"std"},
+		"backend": map[string]interface{}{
+			"default": map[string]interface{}{
+				"host": "127.0.0.1",
+				"port": "8080",
+				"probe": map[string]interface{}{
+					"url":      "/health",
+					"interval": "5s",
+				},
+			},
+		},
+		"acl": map[string]interface{}{
+			"purge": []interface{}{"localhost", "192.168.55.0/24", "!10.0.0.1"},
+		},
+		"sub": map[string]interface{}{
+			"vcl_recv": []interface{}{
+				map[string]interface{}{
+					"type":      "if",
+					"condition": `req.method == "PURGE"`,
+					"body": []interface{}{
+						map[string]interface{}{
+							"type":      "if",
+							"condition": "!client.ip ~ purge",
+							"body": []interface{}{
+								map[string]interface{}{"type": "return", "value": `synth(405, "Not allowed.")`},
+							},
+						},
+						map[string]interface{}{"type": "return", "value": "purge"},
+					},
+					"else": []interface{}{
+						map[string]interface{}{
+							"type":      "if",
+							"condition": `req.method != "GET"`,
+							"body": []interface{}{
+								map[string]interface{}{"type": "return", "value": "pass"},
+							},
+						},
+					},
+				},
+				map[string]interface{}{"type": "set", "target": "req.http.X-Forwarded-For", "operator": "=", "value": "client.ip"},
+				map[string]interface{}{"type": "unset", "target": "req.http.Cookie"},
+			},
+			"vcl_backend_response": []interface{}{
+				map[string]interface{}{"type": "set", "target": "beresp.ttl", "operator": "=", "value": "1h"},
+			},
+		},
+	}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected outline. expected %v actual %v", expected, input)
+	}
+}
+
+func TestVCLParserFastly(t *testing.T) {
+	parser := &Parser{}
+	sample := `table redirects {
+    "/old": "/new",
+    "/legacy": "/current",
+}
+
+director origins random {
+    .quorum = 50%;
+    { .backend = F_origin_a; .weight = 1; }
+    { .backend = F_origin_b; .weight = 2; }
+}`
+
+	expected := map[string]interface{}{
+		"table": map[string]interface{}{
+			"redirects": map[string]interface{}{"/old": "/new", "/legacy": "/current"},
+		},
+		"director": map[string]interface{}{
+			"origins": map[string]interface{}{
+				"type":   "random",
+				"quorum": "50%",
+				"backends": []interface{}{
+					map[string]interface{}{"backend": "F_origin_a", "weight": float64(1)},
+					map[string]interface{}{"backend": "F_origin_b", "weight": float64(2)},
+				},
+			},
+		},
+	}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected outline. expected %v actual %v", expected, input)
+	}
+}
+
+func TestVCLParserErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "unterminated block", input: `sub vcl_recv { set req.http.Host = "example.com";`},
+		{name: "unterminated string", input: `backend default { .host = "127.0.0.1; }`},
+		{name: "missing semicolon", input: `sub vcl_recv { return (pass) }`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var input interface{}
+			if err := (&Parser{}).Unmarshal([]byte(testCase.input), &input); err == nil {
+				t.Error("parser should have thrown an error")
+			}
+		})
+	}
+}
@@ -3,24 +3,134 @@ package xml
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"strings"
 
-	x "github.com/basgys/goxml2json"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// attributePrefix is the prefix of the keys of attributes, which
+	// distinguishes them from child elements with the same name.
+	attributePrefix = "@"
+
+	// textKey is the key of the text content of elements
+	// that also have attributes or child elements.
+	textKey = "#text"
 )
 
 // Parser is an XML parser.
 type Parser struct{}
 
-// Unmarshal unmarshals XML files.
-func (xml *Parser) Unmarshal(p []byte, v interface{}) error {
-	res, err := x.Convert(bytes.NewReader(p))
+// element is an element that is being decoded.
+type element struct {
+	name     string
+	children map[string]interface{}
+	text     strings.Builder
+}
+
+// Unmarshal unmarshals XML files. Elements are converted into maps of their
+// attributes, which are prefixed with @, and their child elements, where
+// repeated child elements are lists. Elements that only contain text are the
+// text itself, otherwise the text is under the #text key. The names of
+// elements and attributes keep their namespace prefix, e.g. soap:Envelope.
+func (xp *Parser) Unmarshal(p []byte, v interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(p))
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	root := &element{children: make(map[string]interface{})}
+	stack := []*element{root}
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unmarshal xml: %w", err)
+		}
+
+		current := stack[len(stack)-1]
+		switch token := token.(type) {
+		case xml.StartElement:
+			child := &element{
+				name:     qualifiedName(token.Name),
+				children: make(map[string]interface{}),
+			}
+
+			for _, attribute := range token.Attr {
+				child.children[attributePrefix+qualifiedName(attribute.Name)] = attribute.Value
+			}
+
+			stack = append(stack, child)
+
+		case xml.EndElement:
+			// Raw tokens are not checked by the decoder, as the namespaces
+			// are not resolved, so the elements are matched here instead.
+			if current == root || qualifiedName(token.Name) != current.name {
+				return fmt.Errorf("unmarshal xml: unexpected end element </%s> at offset %d", qualifiedName(token.Name), decoder.InputOffset())
+			}
+
+			stack = stack[:len(stack)-1]
+			addChild(stack[len(stack)-1].children, current.name, current.value())
+
+		case xml.CharData:
+			current.text.Write(token)
+		}
+	}
+
+	if len(stack) > 1 {
+		return fmt.Errorf("unmarshal xml: element <%s> is not closed", stack[len(stack)-1].name)
+	}
+
+	j, err := json.Marshal(root.children)
 	if err != nil {
-		return fmt.Errorf("unmarshal xml: %w", err)
+		return fmt.Errorf("marshal xml to json: %w", err)
 	}
 
-	if err := json.Unmarshal(res.Bytes(), v); err != nil {
+	if err := json.Unmarshal(j, v); err != nil {
 		return fmt.Errorf("convert xml to json: %w", err)
 	}
 
 	return nil
 }
+
+// value returns the value of the element once all of its content is decoded.
+func (e *element) value() interface{} {
+	text := strings.TrimSpace(e.text.String())
+	if len(e.children) == 0 {
+		return text
+	}
+
+	if text != "" {
+		e.children[textKey] = text
+	}
+
+	return e.children
+}
+
+// addChild adds the value of a child element to the given children. When
+// there is more than one element with the same name, they become a list.
+func addChild(children map[string]interface{}, name string, value interface{}) {
+	existing, ok := children[name]
+	if !ok {
+		children[name] = value
+		return
+	}
+
+	if list, ok := existing.([]interface{}); ok {
+		children[name] = append(list, value)
+		return
+	}
+
+	children[name] = []interface{}{existing, value}
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	return name.Space + ":" + name.Local
+}
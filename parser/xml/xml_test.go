@@ -1,6 +1,9 @@
 package xml
 
 import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -28,3 +31,96 @@ func TestXMLParser(t *testing.T) {
 		t.Error("there should be at least one item defined in the parsed file, but none found")
 	}
 }
+
+func TestXMLParserAttributesAndNamespaces(t *testing.T) {
+	parser := &Parser{}
+	sample := `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope" xmlns:m="http://example.com/stock">
+	<soap:Body>
+		<m:GetPrice currency="USD">
+			<m:Item id="1">Apple</m:Item>
+			<m:Item id="2">Pear</m:Item>
+			<m:Count>3</m:Count>
+			<m:Note/>
+		</m:GetPrice>
+	</soap:Body>
+</soap:Envelope>`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"soap:Envelope": map[string]interface{}{
+			"@xmlns:soap": "http://www.w3.org/2003/05/soap-envelope",
+			"@xmlns:m":    "http://example.com/stock",
+			"soap:Body": map[string]interface{}{
+				"m:GetPrice": map[string]interface{}{
+					"@currency": "USD",
+					"m:Item": []interface{}{
+						map[string]interface{}{"@id": "1", "#text": "Apple"},
+						map[string]interface{}{"@id": "2", "#text": "Pear"},
+					},
+					"m:Count": "3",
+					"m:Note":  "",
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected XML. expected %v actual %v", expected, input)
+	}
+}
+
+func TestXMLParserPom(t *testing.T) {
+	parser := &Parser{}
+	sample, err := ioutil.ReadFile(filepath.Join("testdata", "pom.xml"))
+	if err != nil {
+		t.Fatalf("read pom: %v", err)
+	}
+
+	var input map[string]interface{}
+	if err := parser.Unmarshal(sample, &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	project := input["project"].(map[string]interface{})
+	if project["@xmlns"] != "http://maven.apache.org/POM/4.0.0" {
+		t.Errorf("expected the default namespace attribute, got %v", project["@xmlns"])
+	}
+
+	if project["@xsi:schemaLocation"] == nil {
+		t.Error("expected the prefixed schema location attribute")
+	}
+
+	plugins := project["build"].(map[string]interface{})["plugins"].(map[string]interface{})["plugin"].([]interface{})
+	if len(plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got %v", len(plugins))
+	}
+
+	if version := plugins[1].(map[string]interface{})["version"]; version != "2.18.1" {
+		t.Errorf("expected the version of the second plugin to be 2.18.1, got %v", version)
+	}
+}
+
+func TestXMLParserErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "mismatched end element", input: `<a><b></a></b>`},
+		{name: "unclosed element", input: `<a><b></b>`},
+		{name: "invalid syntax", input: `<a <b>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input interface{}
+			if err := (&Parser{}).Unmarshal([]byte(tt.input), &input); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// resolveMergeKeys returns the given YAML document with its aliases and merge
+// keys (<<) resolved into concrete values, following the merge semantics of
+// YAML 1.1: the keys of a mapping always take precedence over the keys that
+// are merged into it, wherever the merge key appears in the mapping, and when
+// a sequence of mappings is merged, the earlier mappings take precedence.
+//
+// When the document cannot be resolved, e.g. because a merge key is not a
+// mapping, the document is returned as is, so that the error is reported
+// when the document is unmarshaled.
+func resolveMergeKeys(contents []byte) []byte {
+	if !bytes.Contains(contents, []byte("<<")) {
+		return contents
+	}
+
+	var node yamlv3.Node
+	if err := yamlv3.Unmarshal(contents, &node); err != nil {
+		return contents
+	}
+
+	resolved, err := resolveNode(&node, make(map[*yamlv3.Node]bool))
+	if err != nil {
+		return contents
+	}
+
+	resolvedContents, err := yamlv3.Marshal(resolved)
+	if err != nil {
+		return contents
+	}
+
+	return resolvedContents
+}
+
+// resolveNode returns a copy of the given node, where the aliases are replaced
+// with the nodes they refer to and the merge keys are merged into their
+// mappings. The nodes that are being resolved are tracked to detect aliases
+// that refer to themselves.
+func resolveNode(node *yamlv3.Node, resolving map[*yamlv3.Node]bool) (*yamlv3.Node, error) {
+	if resolving[node] {
+		return nil, errors.New("alias refers to itself")
+	}
+
+	resolving[node] = true
+	defer delete(resolving, node)
+
+	if node.Kind == yamlv3.AliasNode {
+		return resolveNode(node.Alias, resolving)
+	}
+
+	resolved := *node
+	resolved.Anchor = ""
+	resolved.Content = nil
+	if node.Kind == yamlv3.MappingNode {
+		return resolveMapping(node, &resolved, resolving)
+	}
+
+	for _, child := range node.Content {
+		resolvedChild, err := resolveNode(child, resolving)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved.Content = append(resolved.Content, resolvedChild)
+	}
+
+	return &resolved, nil
+}
+
+// resolveMapping adds the keys of the given mapping to the resolved mapping,
+// followed by the keys of the merged mappings that the mapping does not have.
+func resolveMapping(node *yamlv3.Node, resolved *yamlv3.Node, resolving map[*yamlv3.Node]bool) (*yamlv3.Node, error) {
+	keys := make(map[string]bool)
+	var merged []*yamlv3.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Kind == yamlv3.ScalarNode && key.ShortTag() == "!!merge" {
+			mappings, err := mergedMappings(value, resolving)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", key.Line, err)
+			}
+
+			merged = append(merged, mappings...)
+			continue
+		}
+
+		resolvedKey, err := resolveNode(key, resolving)
+		if err != nil {
+			return nil, err
+		}
+
+		resolvedValue, err := resolveNode(value, resolving)
+		if err != nil {
+			return nil, err
+		}
+
+		if resolvedKey.Kind == yamlv3.ScalarNode {
+			keys[resolvedKey.Value] = true
+		}
+
+		resolved.Content = append(resolved.Content, resolvedKey, resolvedValue)
+	}
+
+	for _, mapping := range merged {
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			key, value := mapping.Content[i], mapping.Content[i+1]
+			if key.Kind == yamlv3.ScalarNode {
+				if keys[key.Value] {
+					continue
+				}
+
+				keys[key.Value] = true
+			}
+
+			resolved.Content = append(resolved.Content, key, value)
+		}
+	}
+
+	return resolved, nil
+}
+
+// mergedMappings returns the resolved mappings of the value of a merge key,
+// which is either a mapping or a sequence of mappings.
+func mergedMappings(value *yamlv3.Node, resolving map[*yamlv3.Node]bool) ([]*yamlv3.Node, error) {
+	resolved, err := resolveNode(value, resolving)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolved.Kind == yamlv3.MappingNode {
+		return []*yamlv3.Node{resolved}, nil
+	}
+
+	if resolved.Kind != yamlv3.SequenceNode {
+		return nil, errors.New("map merge requires map or sequence of maps as the value")
+	}
+
+	for _, item := range resolved.Content {
+		if item.Kind != yamlv3.MappingNode {
+			return nil, errors.New("map merge requires map or sequence of maps as the value")
+		}
+	}
+
+	return resolved.Content, nil
+}
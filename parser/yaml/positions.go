@@ -0,0 +1,83 @@
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/open-policy-agent/conftest/parser/position"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Positions returns the positions of the values in the given YAML document(s).
+// When the contents contain multiple documents, the paths are prefixed with the
+// index of the document, and the document itself is located at its index.
+func (yp *Parser) Positions(p []byte) (map[string]position.Position, error) {
+	positions := make(map[string]position.Position)
+
+	subDocuments := separateSubDocuments(p)
+	if len(subDocuments) == 0 {
+		return positions, nil
+	}
+
+	if len(subDocuments) == 1 {
+		var document yamlv3.Node
+		if err := yamlv3.Unmarshal(subDocuments[0].contents, &document); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml: %w", err)
+		}
+
+		addPositions(positions, "", subDocuments[0].line, &document)
+		return positions, nil
+	}
+
+	// The documents are split the same way as Unmarshal splits them so that
+	// the document indexes match, which means the line numbers of every
+	// document need to be offset by the lines that come before it.
+	for i, subDocument := range subDocuments {
+		var document yamlv3.Node
+		if err := yamlv3.Unmarshal(subDocument.contents, &document); err != nil {
+			return nil, fmt.Errorf("unmarshal subdocument yaml: %w", err)
+		}
+
+		addPositions(positions, strconv.Itoa(i), subDocument.line, &document)
+	}
+
+	return positions, nil
+}
+
+func addPositions(positions map[string]position.Position, path string, lineOffset int, node *yamlv3.Node) {
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		if len(node.Content) == 0 {
+			return
+		}
+
+		if path != "" {
+			positions[path] = newPosition(node.Content[0], lineOffset)
+		}
+
+		addPositions(positions, path, lineOffset, node.Content[0])
+	case yamlv3.AliasNode:
+		addPositions(positions, path, lineOffset, node.Alias)
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yamlv3.ScalarNode {
+				continue
+			}
+
+			currentPath := position.Join(path, key.Value)
+			positions[currentPath] = newPosition(key, lineOffset)
+			addPositions(positions, currentPath, lineOffset, node.Content[i+1])
+		}
+	case yamlv3.SequenceNode:
+		for i, item := range node.Content {
+			currentPath := position.Join(path, strconv.Itoa(i))
+			positions[currentPath] = newPosition(item, lineOffset)
+			addPositions(positions, currentPath, lineOffset, item)
+		}
+	}
+}
+
+func newPosition(node *yamlv3.Node, lineOffset int) position.Position {
+	return position.Position{Line: node.Line + lineOffset, Column: node.Column}
+}
@@ -7,10 +7,12 @@ import (
 	"github.com/ghodss/yaml"
 )
 
-// Parser is a YAML parser.
+// Parser is a YAML parser. Aliases and merge keys are resolved into
+// concrete values before the documents are unmarshaled.
 type Parser struct{}
 
-// Unmarshal unmarshals YAML files.
+// Unmarshal unmarshals YAML files. When the file contains more than
+// one document, the documents are unmarshaled as a list.
 func (yp *Parser) Unmarshal(p []byte, v interface{}) error {
 	subDocuments := separateSubDocuments(p)
 	if len(subDocuments) > 1 {
@@ -21,27 +23,92 @@ func (yp *Parser) Unmarshal(p []byte, v interface{}) error {
 		return nil
 	}
 
-	if err := yaml.Unmarshal(p, v); err != nil {
+	if len(subDocuments) == 1 {
+		p = subDocuments[0].contents
+	}
+
+	if err := yaml.Unmarshal(resolveMergeKeys(p), v); err != nil {
 		return fmt.Errorf("unmarshal yaml: %w", err)
 	}
 
 	return nil
 }
 
-func separateSubDocuments(data []byte) [][]byte {
-	linebreak := "\n"
-	if bytes.Contains(data, []byte("\r\n---\r\n")) {
-		linebreak = "\r\n"
+// document is a single document of a YAML stream.
+type document struct {
+	contents []byte
+
+	// line is the number of lines that come before the document.
+	line int
+}
+
+// separateSubDocuments splits the given YAML stream into its documents, on the
+// lines that only contain a document marker (--- or ...), which may be followed
+// by a comment. Documents that are empty, or only contain comments, are dropped,
+// e.g. the document before the first marker of a stream that starts with one.
+func separateSubDocuments(data []byte) []document {
+	var documents []document
+	var contents []byte
+	var start int
+	for i, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if !isDocumentMarker(line) {
+			contents = append(contents, line...)
+			continue
+		}
+
+		if !isEmptyDocument(contents) {
+			documents = append(documents, document{contents: contents, line: start})
+		}
+
+		contents = nil
+		start = i + 1
+	}
+
+	if !isEmptyDocument(contents) {
+		documents = append(documents, document{contents: contents, line: start})
+	}
+
+	return documents
+}
+
+func isDocumentMarker(line []byte) bool {
+	line = bytes.TrimRight(line, " \t\r\n")
+	for _, marker := range []string{"---", "..."} {
+		if !bytes.HasPrefix(line, []byte(marker)) {
+			continue
+		}
+
+		rest := line[len(marker):]
+		if len(rest) == 0 {
+			return true
+		}
+
+		// A comment needs to be separated from the marker by whitespace.
+		comment := bytes.TrimLeft(rest, " \t")
+		if len(comment) < len(rest) && comment[0] == '#' {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isEmptyDocument(contents []byte) bool {
+	for _, line := range bytes.Split(contents, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			return false
+		}
 	}
 
-	return bytes.Split(data, []byte(linebreak+"---"+linebreak))
+	return true
 }
 
-func unmarshalMultipleDocuments(subDocuments [][]byte, v interface{}) error {
+func unmarshalMultipleDocuments(subDocuments []document, v interface{}) error {
 	var documentStore []interface{}
 	for _, subDocument := range subDocuments {
 		var documentObject interface{}
-		if err := yaml.Unmarshal(subDocument, &documentObject); err != nil {
+		if err := yaml.Unmarshal(resolveMergeKeys(subDocument.contents), &documentObject); err != nil {
 			return fmt.Errorf("unmarshal subdocument yaml: %w", err)
 		}
 
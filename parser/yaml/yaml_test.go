@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/open-policy-agent/conftest/parser/position"
 	"github.com/open-policy-agent/conftest/parser/yaml"
 )
 
@@ -45,6 +46,36 @@ nice: true`),
 				},
 				shouldError: false,
 			},
+			{
+				name: "empty subdocs and comments on markers",
+				controlConfigs: []byte(`---
+# only a comment
+---
+sample: true
+--- # the second document
+
+---
+hello: true
+...
+`),
+				expectedResult: []interface{}{
+					map[string]interface{}{
+						"sample": true,
+					},
+					map[string]interface{}{
+						"hello": true,
+					},
+				},
+				shouldError: false,
+			},
+			{
+				name:           "a single config with a trailing marker",
+				controlConfigs: []byte("sample: true\r\n---\r\n"),
+				expectedResult: map[string]interface{}{
+					"sample": true,
+				},
+				shouldError: false,
+			},
 		}
 
 		for _, test := range testTable {
@@ -67,3 +98,167 @@ nice: true`),
 		}
 	})
 }
+
+func TestYAMLMergeKeys(t *testing.T) {
+	testTable := []struct {
+		name     string
+		config   string
+		expected interface{}
+	}{
+		{
+			name: "keys of the mapping override merged keys",
+			config: `base: &base
+  image: nginx
+  replicas: 1
+app:
+  replicas: 3
+  <<: *base`,
+			expected: map[string]interface{}{
+				"base": map[string]interface{}{"image": "nginx", "replicas": float64(1)},
+				"app":  map[string]interface{}{"image": "nginx", "replicas": float64(3)},
+			},
+		},
+		{
+			name: "earlier mappings of a sequence take precedence",
+			config: `first: &first {port: 80, name: first}
+second: &second {port: 8080, protocol: TCP}
+service:
+  <<: [*first, *second]`,
+			expected: map[string]interface{}{
+				"first":   map[string]interface{}{"port": float64(80), "name": "first"},
+				"second":  map[string]interface{}{"port": float64(8080), "protocol": "TCP"},
+				"service": map[string]interface{}{"port": float64(80), "name": "first", "protocol": "TCP"},
+			},
+		},
+		{
+			name: "nested merges",
+			config: `defaults: &defaults
+  resources: &resources
+    cpu: 100m
+    memory: 128Mi
+base: &base
+  <<: *defaults
+  image: nginx
+containers:
+  - <<: *base
+    name: web
+    resources:
+      <<: *resources
+      memory: 256Mi`,
+			expected: map[string]interface{}{
+				"defaults": map[string]interface{}{"resources": map[string]interface{}{"cpu": "100m", "memory": "128Mi"}},
+				"base": map[string]interface{}{
+					"image":     "nginx",
+					"resources": map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+				},
+				"containers": []interface{}{
+					map[string]interface{}{
+						"name":      "web",
+						"image":     "nginx",
+						"resources": map[string]interface{}{"cpu": "100m", "memory": "256Mi"},
+					},
+				},
+			},
+		},
+		{
+			name: "merges in multiple documents",
+			config: `base: &base {enabled: yes}
+app:
+  <<: *base
+  enabled: no
+---
+other: true`,
+			expected: []interface{}{
+				map[string]interface{}{
+					"base": map[string]interface{}{"enabled": true},
+					"app":  map[string]interface{}{"enabled": false},
+				},
+				map[string]interface{}{"other": true},
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			var actual interface{}
+			if err := new(yaml.Parser).Unmarshal([]byte(test.config), &actual); err != nil {
+				t.Fatalf("errors unmarshalling: %v", err)
+			}
+
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Errorf("Expected\n%v\n to equal\n%v", actual, test.expected)
+			}
+		})
+	}
+
+	t.Run("merging a value that is not a mapping", func(t *testing.T) {
+		var actual interface{}
+		if err := new(yaml.Parser).Unmarshal([]byte("list: &list [1]\napp:\n  <<: *list"), &actual); err == nil {
+			t.Error("expected an error when merging a sequence of values that are not mappings")
+		}
+	})
+}
+
+func TestYAMLPositions(t *testing.T) {
+	testTable := []struct {
+		name     string
+		input    []byte
+		expected map[string]position.Position
+	}{
+		{
+			name: "a single document",
+			input: []byte(`spec:
+  containers:
+    - name: nginx
+      image: nginx:latest`),
+			expected: map[string]position.Position{
+				"spec":                    {Line: 1, Column: 1},
+				"spec.containers":         {Line: 2, Column: 3},
+				"spec.containers.0":       {Line: 3, Column: 7},
+				"spec.containers.0.name":  {Line: 3, Column: 7},
+				"spec.containers.0.image": {Line: 4, Column: 7},
+			},
+		},
+		{
+			name: "multiple documents",
+			input: []byte(`---
+sample: true
+---
+hello: true`),
+			expected: map[string]position.Position{
+				"0":        {Line: 2, Column: 1},
+				"0.sample": {Line: 2, Column: 1},
+				"1":        {Line: 4, Column: 1},
+				"1.hello":  {Line: 4, Column: 1},
+			},
+		},
+		{
+			name: "multiple documents with empty documents",
+			input: []byte(`---
+---
+sample: true
+---
+
+hello: true`),
+			expected: map[string]position.Position{
+				"0":        {Line: 3, Column: 1},
+				"0.sample": {Line: 3, Column: 1},
+				"1":        {Line: 6, Column: 1},
+				"1.hello":  {Line: 6, Column: 1},
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			positions, err := new(yaml.Parser).Positions(test.input)
+			if err != nil {
+				t.Fatalf("get positions: %v", err)
+			}
+
+			if !reflect.DeepEqual(test.expected, positions) {
+				t.Errorf("Expected\n%v\n to equal\n%v\n", positions, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package policy
+
+import (
+	"github.com/open-policy-agent/conftest/output"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// ruleAnnotations returns the METADATA annotations of the rules in the
+// compiled policies, keyed by the path of the rule (e.g. data.main.deny).
+//
+// Annotations with the document scope apply to every definition of a rule.
+// When a rule is defined multiple times, the results of the rule can not be
+// attributed to a single definition, so the annotations of the first annotated
+// definition are used.
+func ruleAnnotations(compiler *ast.Compiler) map[string]*output.Annotations {
+	annotations := make(map[string]*output.Annotations)
+	documentScoped := make(map[string]bool)
+	locations := make(map[string]*ast.Location)
+
+	for _, ref := range compiler.GetAnnotationSet().Flatten() {
+		if ref.Annotations == nil {
+			continue
+		}
+
+		scope := ref.Annotations.Scope
+		if scope != "rule" && scope != "document" {
+			continue
+		}
+
+		path := ref.Path.String()
+		if documentScoped[path] {
+			continue
+		}
+
+		if scope == "rule" {
+			if location, ok := locations[path]; ok && !before(ref.Location, location) {
+				continue
+			}
+		}
+
+		annotations[path] = newAnnotations(ref.Annotations)
+		documentScoped[path] = scope == "document"
+		locations[path] = ref.Location
+	}
+
+	return annotations
+}
+
+func newAnnotations(annotations *ast.Annotations) *output.Annotations {
+	result := output.Annotations{
+		Title:       annotations.Title,
+		Description: annotations.Description,
+		Custom:      annotations.Custom,
+	}
+
+	for _, resource := range annotations.RelatedResources {
+		result.RelatedResources = append(result.RelatedResources, resource.Ref.String())
+	}
+
+	return &result
+}
+
+func before(a *ast.Location, b *ast.Location) bool {
+	if a == nil || b == nil {
+		return a != nil
+	}
+
+	if a.File != b.File {
+		return a.File < b.File
+	}
+
+	return a.Row < b.Row
+}
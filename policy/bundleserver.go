@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/downloader"
+	"github.com/open-policy-agent/conftest/parser"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+)
+
+// remoteBundle is a bundle of policies and data that was fetched from a
+// bundle server, such as the bundle.tar.gz files that OPA downloads.
+type remoteBundle struct {
+	url    string
+	bundle bundle.Bundle
+}
+
+// roots returns the roots of the bundle, which are the slash separated paths
+// of the data and the packages that the bundle owns. A bundle without roots
+// in its manifest owns all of the data.
+func (b remoteBundle) roots() []string {
+	if b.bundle.Manifest.Roots == nil {
+		return []string{""}
+	}
+
+	return *b.bundle.Manifest.Roots
+}
+
+// splitBundleURLs splits the given policy paths into the paths on the file
+// system and the URLs of the bundles to fetch from a bundle server.
+func splitBundleURLs(policyPaths []string) ([]string, []string) {
+	var localPaths, urls []string
+	for _, policyPath := range policyPaths {
+		if parser.IsURL(policyPath) {
+			urls = append(urls, policyPath)
+		} else {
+			localPaths = append(localPaths, policyPath)
+		}
+	}
+
+	return localPaths, urls
+}
+
+// fetchBundles fetches the bundles at the given URLs. The bundles are read the
+// same as OPA reads them, so their data and policies must be within the roots
+// of their manifests, and their policies are parsed as the given version of Rego.
+func fetchBundles(ctx context.Context, urls []string, regoVersion ast.RegoVersion) ([]remoteBundle, error) {
+	var bundles []remoteBundle
+	for _, bundleURL := range urls {
+		fetched, err := fetchBundle(ctx, bundleURL, regoVersion)
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s: %w", bundleURL, err)
+		}
+
+		bundles = append(bundles, remoteBundle{url: bundleURL, bundle: fetched})
+	}
+
+	for i := range bundles {
+		for j := i + 1; j < len(bundles); j++ {
+			if root, other, ok := overlappingRoots(bundles[i].roots(), bundles[j].roots()); ok {
+				return nil, fmt.Errorf("root %q of bundle %s overlaps with root %q of bundle %s", root, bundles[i].url, other, bundles[j].url)
+			}
+		}
+	}
+
+	return bundles, nil
+}
+
+func fetchBundle(ctx context.Context, bundleURL string, regoVersion ast.RegoVersion) (bundle.Bundle, error) {
+	response, err := downloader.Get(ctx, bundleURL)
+	if err != nil {
+		return bundle.Bundle{}, err
+	}
+	defer response.Body.Close()
+
+	fetched, err := bundle.NewReader(response.Body).WithProcessAnnotations(true).WithRegoVersion(regoVersion).Read()
+	if err != nil {
+		return bundle.Bundle{}, fmt.Errorf("read bundle: %w", err)
+	}
+
+	return fetched, nil
+}
+
+// addBundleModules adds the policies of the given bundles to the modules,
+// keyed by the URL of the bundle followed by the path of the policy within
+// the bundle. An error is returned when any of the other modules have a
+// package within the roots of a bundle, as the bundle owns its roots.
+func addBundleModules(modules map[string]*ast.Module, bundles []remoteBundle) error {
+	for file, module := range modules {
+		packagePath, err := module.Package.Path.Ptr()
+		if err != nil {
+			continue
+		}
+
+		for _, remote := range bundles {
+			if bundle.RootPathsContain(remote.roots(), packagePath) {
+				return fmt.Errorf("package %s of %s is within the roots %v of bundle %s", module.Package.Path, file, remote.roots(), remote.url)
+			}
+		}
+	}
+
+	for _, remote := range bundles {
+		for _, moduleFile := range remote.bundle.Modules {
+			modules[remote.url+"/"+strings.TrimPrefix(moduleFile.Path, "/")] = moduleFile.Parsed
+		}
+	}
+
+	return nil
+}
+
+// addBundleData merges the data of the given bundles into the data. An error
+// is returned when the data already defines a value within the roots of a
+// bundle, as the bundle owns its roots.
+func addBundleData(data map[string]interface{}, bundles []remoteBundle) (map[string]interface{}, error) {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	for _, remote := range bundles {
+		for _, root := range remote.roots() {
+			if key, ok := definesPath(data, root); ok {
+				return nil, fmt.Errorf("data.%s is within the root %q of bundle %s", key, root, remote.url)
+			}
+		}
+	}
+
+	for _, remote := range bundles {
+		if err := mergeDocument(data, remote.bundle.Data); err != nil {
+			return nil, fmt.Errorf("merge data of bundle %s: %w", remote.url, err)
+		}
+	}
+
+	return data, nil
+}
+
+// definesPath reports whether the data defines a value at, or within, the
+// given slash separated path, and returns the dot separated key of the value.
+func definesPath(data map[string]interface{}, root string) (string, bool) {
+	var keys []string
+	var value interface{} = data
+	for _, key := range strings.Split(root, "/") {
+		if key == "" {
+			continue
+		}
+
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+
+		value, ok = object[key]
+		if !ok {
+			return "", false
+		}
+
+		keys = append(keys, key)
+	}
+
+	// The root of all of the data is only defined when the data has any keys.
+	if object, ok := value.(map[string]interface{}); ok && len(keys) == 0 {
+		for key := range object {
+			return key, true
+		}
+
+		return "", false
+	}
+
+	return strings.Join(keys, "."), true
+}
+
+// overlappingRoots returns the first of the roots that overlap with each other.
+func overlappingRoots(roots []string, others []string) (string, string, bool) {
+	for _, root := range roots {
+		for _, other := range others {
+			if bundle.RootPathsOverlap(root, other) {
+				return root, other, true
+			}
+		}
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,26 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// loadCapabilities reads the OPA capabilities file at the given path, e.g. the
+// capabilities file of an OPA release with the builtins that policies must
+// not use removed.
+func loadCapabilities(path string) (*ast.Capabilities, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	capabilities, err := ast.LoadCapabilitiesJSON(file)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return capabilities, nil
+}
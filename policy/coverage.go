@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/conftest/output"
+
+	"github.com/open-policy-agent/opa/cover"
+	"github.com/open-policy-agent/opa/topdown"
+)
+
+// coverageTracer records which lines of the policies are evaluated. Queries
+// can be evaluated concurrently, so access to the coverage is synchronized.
+type coverageTracer struct {
+	mu    sync.Mutex
+	cover *cover.Cover
+}
+
+func (c *coverageTracer) Enabled() bool {
+	return true
+}
+
+func (c *coverageTracer) Config() topdown.TraceConfig {
+	return c.cover.Config()
+}
+
+func (c *coverageTracer) TraceEvent(event topdown.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cover.TraceEvent(event)
+}
+
+// EnableCoverage records the coverage of the policies for every query
+// that is evaluated from then on. The coverage is aggregated across all
+// of the files and namespaces that are checked.
+func (e *Engine) EnableCoverage() {
+	e.coverage = &coverageTracer{cover: cover.New()}
+}
+
+// Coverage returns a report of the lines and rules of the policies that have
+// been evaluated since coverage was enabled. A rule is covered when it has
+// produced a result for at least one of the inputs.
+func (e *Engine) Coverage() output.CoverageReport {
+	var report output.CoverageReport
+	if e.coverage == nil {
+		return report
+	}
+
+	e.coverage.mu.Lock()
+	coverReport := e.coverage.cover.Report(e.Modules())
+	e.coverage.mu.Unlock()
+
+	for _, fileReport := range coverReport.Files {
+		report.CoveredLines += countLines(fileReport.Covered)
+		report.TotalLines += countLines(fileReport.Covered) + countLines(fileReport.NotCovered)
+	}
+
+	for file, module := range e.Modules() {
+		namespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
+		for _, rule := range module.Rules {
+			name := rule.Head.Name.String()
+			if !e.isRule(name) {
+				continue
+			}
+
+			ruleCoverage := output.RuleCoverage{
+				Namespace: namespace,
+				Rule:      name,
+				File:      file,
+			}
+			if rule.Head.Location != nil {
+				ruleCoverage.Line = rule.Head.Location.Row
+				ruleCoverage.Covered = coverReport.IsCovered(rule.Head.Location.File, rule.Head.Location.Row)
+			}
+
+			report.TotalRules++
+			if ruleCoverage.Covered {
+				report.CoveredRules++
+			}
+
+			report.Rules = append(report.Rules, ruleCoverage)
+		}
+	}
+
+	// For consistency when printing the report, sort the rules by their location.
+	sort.Slice(report.Rules, func(i, j int) bool {
+		if report.Rules[i].File != report.Rules[j].File {
+			return report.Rules[i].File < report.Rules[j].File
+		}
+
+		return report.Rules[i].Line < report.Rules[j].Line
+	})
+
+	return report
+}
+
+// countLines returns the number of distinct lines in the given ranges.
+// The ranges of a report can overlap when a line has multiple expressions.
+func countLines(ranges []cover.Range) int {
+	lines := make(map[int]bool)
+	for _, r := range ranges {
+		for row := r.Start.Row; row <= r.End.Row; row++ {
+			lines[row] = true
+		}
+	}
+
+	return len(lines)
+}
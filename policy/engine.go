@@ -1,23 +1,30 @@
 package policy
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/parser/position"
 
 	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
 	"github.com/open-policy-agent/opa/loader"
 	"github.com/open-policy-agent/opa/rego"
 	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/storage/inmem"
 	"github.com/open-policy-agent/opa/topdown"
+	"github.com/open-policy-agent/opa/topdown/print"
 	"github.com/open-policy-agent/opa/version"
 )
 
@@ -28,24 +35,242 @@ type Engine struct {
 	store    storage.Store
 	policies map[string]string
 	docs     map[string]string
+	bundles  map[string]*bundle.Bundle
+	sources  map[string]*ast.Module
+	options  Options
+
+	// policyPaths are the paths that the policies were loaded from.
+	policyPaths []string
+
+	annotations map[string]*output.Annotations
+
+	positions     map[string]map[string]position.Position
+	selectedRules []string
+	coverage      *coverageTracer
+	tracing       bool
+	reportPasses  bool
+
+	// exceptions are the exceptions of the data that apply to all of
+	// the policies, in addition to their exception rules.
+	exceptions []sharedException
+
+	// remoteBundles are the bundles of Rego policies that were fetched from
+	// bundle servers, whose data is loaded along with the data paths.
+	remoteBundles []remoteBundle
+}
+
+// Options are the options for compiling the policies.
+type Options struct {
+	// Strict enables the strict mode of the compiler, which reports
+	// unused imports, unused local variables and other common mistakes
+	// in the policies as errors.
+	Strict bool
+
+	// DataParser is the name of the parser that is used to parse all of the
+	// data files, regardless of their extension. When it is not set, only
+	// JSON and YAML data files are loaded.
+	DataParser string
+
+	// FollowSymlinks follows the symbolic links to directories when
+	// loading the policies, which are not followed otherwise.
+	FollowSymlinks bool
+
+	// RulePrefixes are the prefixes of additional rules that are evaluated by
+	// Check, along with the severity of their results, e.g. critical=critical
+	// evaluates the critical and critical_* rules. The results of the rules
+	// are failures when their severity is at least FailSeverity, and warnings
+	// otherwise. FailSeverity defaults to DefaultFailSeverity.
+	RulePrefixes map[string]string
+	FailSeverity string
+
+	// RegoVersion is the version of Rego that the policies are written in,
+	// which is either RegoV0 or RegoV1. When empty, the policies are
+	// compiled as DefaultRegoVersion, unless the manifest of a bundle
+	// declares its rego_version.
+	RegoVersion string
+
+	// Capabilities is the path to an OPA capabilities file, which lists the
+	// builtins that the policies are allowed to use. Policies that use other
+	// builtins, e.g. http.send, fail to compile. When empty, all of the
+	// builtins are allowed.
+	Capabilities string
+
+	// AbortOnError stops the check at the first runtime error of the
+	// evaluation of a rule, e.g. a conflict between the values of a rule,
+	// which is returned as the error of the check. Otherwise, the error is
+	// reported as a failure of the rule with a severity of error, and the
+	// other rules are still evaluated.
+	AbortOnError bool
+
+	// NamespaceMap maps the policy paths to the namespaces that the packages
+	// of their policies are moved under, e.g. bundles/a to team.a moves the
+	// package main of the policies in bundles/a to team.a.main, so that the
+	// policies of several paths that use the same packages do not collide.
+	NamespaceMap map[string]string
+
+	// AllowEmpty loads an engine without any policies when none are found
+	// in the policy paths, instead of returning an error.
+	AllowEmpty bool
+
+	// Logger logs the revisions of the bundles that are fetched from bundle
+	// servers. When nil, the revisions are not logged.
+	Logger *log.Logger
+
+	// capabilities are the capabilities that are read from the file.
+	capabilities *ast.Capabilities
 }
 
 // Load returns an Engine after loading all of the specified policies.
+//
+// Policies that have been compiled to WASM are loaded from bundles, and are
+// evaluated using OPA's WASM runtime instead of being interpreted from source.
+//
+// Policy paths that are http:// or https:// URLs are bundles that are fetched
+// from a bundle server, such as the bundle.tar.gz files that OPA downloads.
+// The same as with OPA, a bundle owns the roots of its manifest, so it is an
+// error for the other policies and data to define packages or values within
+// the roots.
 func Load(ctx context.Context, policyPaths []string) (*Engine, error) {
-	policies, err := loader.AllRegos(policyPaths)
+	return load(ctx, policyPaths, Options{})
+}
+
+func load(ctx context.Context, policyPaths []string, options Options) (*Engine, error) {
+	if err := validateSeverities(options); err != nil {
+		return nil, fmt.Errorf("validate severities: %w", err)
+	}
+
+	if err := validateRegoVersion(options.RegoVersion); err != nil {
+		return nil, fmt.Errorf("validate rego version: %w", err)
+	}
+
+	if options.Capabilities != "" {
+		capabilities, err := loadCapabilities(options.Capabilities)
+		if err != nil {
+			return nil, fmt.Errorf("load capabilities: %w", err)
+		}
+
+		options.capabilities = capabilities
+	}
+
+	localPaths, bundleURLs := splitBundleURLs(policyPaths)
+	bundles, sourcePaths, err := loadBundles(localPaths)
+	if err != nil {
+		return nil, fmt.Errorf("load bundles: %w", err)
+	}
+
+	var fetched []remoteBundle
+	if len(bundleURLs) > 0 {
+		fetched, err = fetchBundles(ctx, bundleURLs, parserRegoVersion(options.RegoVersion))
+		if err != nil {
+			return nil, fmt.Errorf("fetch bundles: %w", err)
+		}
+	}
+
+	// The policies of each path are parsed as the version of Rego that is
+	// given, or that the manifest of the bundle at the path declares.
+	pathsByVersion, err := groupByRegoVersion(options.RegoVersion, sourcePaths)
 	if err != nil {
-		return nil, fmt.Errorf("load: %w", err)
-	} else if len(policies.Modules) == 0 {
+		return nil, fmt.Errorf("rego version: %w", err)
+	}
+
+	modules := make(map[string]*ast.Module)
+	for regoVersion, paths := range pathsByVersion {
+		if options.FollowSymlinks {
+			paths, err = findPolicyFiles(paths)
+			if err != nil {
+				return nil, fmt.Errorf("find policies: %w", err)
+			}
+		}
+
+		// Annotations are only parsed from the comments of the policies when
+		// the loader is told to process them.
+		// The loader follows every link it finds, so links to directories are
+		// skipped unless they are followed, in which case the policy files
+		// have already been found without following the links in cycles.
+		policies, err := loader.NewFileLoader().WithProcessAnnotation(true).WithRegoVersion(regoVersion).Filtered(paths, func(path string, info os.FileInfo, depth int) bool {
+			if info.IsDir() && depth > 0 && isSymlink(path) {
+				return true
+			}
+
+			return !info.IsDir() && !strings.HasSuffix(info.Name(), bundle.RegoExt)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("load: %w", err)
+		}
+
+		for path, module := range policies.ParsedModules() {
+			modules[path] = module
+		}
+	}
+
+	// The bundles that contain policies compiled to WASM are evaluated the
+	// same as the compiled bundles on the file system.
+	var remoteBundles []remoteBundle
+	for _, remote := range fetched {
+		if options.Logger != nil {
+			options.Logger.Printf("Loaded bundle %s at revision %s", remote.url, remote.bundle.Manifest.Revision)
+		}
+
+		if len(remote.bundle.WasmModules) == 0 {
+			remoteBundles = append(remoteBundles, remote)
+			continue
+		}
+
+		if !wasmEnabled {
+			return nil, fmt.Errorf("%s contains policies compiled to WASM, which requires conftest to be built with cgo enabled", remote.url)
+		}
+
+		remoteBundle := remote.bundle
+		bundles[remote.url] = &remoteBundle
+	}
+
+	if err := addBundleModules(modules, remoteBundles); err != nil {
+		return nil, fmt.Errorf("add bundle policies: %w", err)
+	}
+
+	if len(modules) == 0 && len(bundles) == 0 && !options.AllowEmpty {
 		return nil, fmt.Errorf("no policies found in %v", policyPaths)
 	}
 
-	compiler, err := policies.Compiler()
+	engine, err := newEngine(ctx, policyPaths, modules, bundles, options)
+	if err != nil {
+		return nil, err
+	}
+	engine.remoteBundles = remoteBundles
+
+	// The data of the bundles is available to the policies even when the
+	// engine is loaded without any data paths.
+	if len(remoteBundles) > 0 && engine.store == nil {
+		data, err := addBundleData(nil, remoteBundles)
+		if err != nil {
+			return nil, fmt.Errorf("add bundle data: %w", err)
+		}
+
+		engine.store = inmem.NewFromObject(data)
+	}
+
+	return engine, nil
+}
+
+// newEngine returns an Engine after compiling the given modules, which were
+// loaded from the given policy paths, and bundles using the given options.
+func newEngine(ctx context.Context, policyPaths []string, modules map[string]*ast.Module, bundles map[string]*bundle.Bundle, options Options) (*Engine, error) {
+	if err := remapNamespaces(modules, options.NamespaceMap); err != nil {
+		return nil, fmt.Errorf("remap namespaces: %w", err)
+	}
+
+	var store storage.Store
+	if len(bundles) > 0 {
+		store = inmem.New()
+	}
+
+	compiler, err := newCompiler(ctx, store, modules, bundles, options)
 	if err != nil {
 		return nil, fmt.Errorf("get compiler: %w", err)
 	}
 
 	policyContents := make(map[string]string)
-	for path, module := range policies.ParsedModules() {
+	for path, module := range modules {
 		path = filepath.Clean(path)
 		path = filepath.ToSlash(path)
 
@@ -53,61 +278,272 @@ func Load(ctx context.Context, policyPaths []string) (*Engine, error) {
 	}
 
 	engine := Engine{
-		modules:  policies.ParsedModules(),
+		modules:  compiler.Modules,
 		compiler: compiler,
+		store:    store,
 		policies: policyContents,
+		bundles:  bundles,
+		sources:  modules,
+		options:  options,
+
+		policyPaths: policyPaths,
 	}
+	engine.annotations = ruleAnnotations(compiler)
 
 	return &engine, nil
 }
 
 // LoadWithData returns an Engine after loading all of the specified policies and data paths.
+//
+// Data paths that are http:// or https:// URLs are fetched when the policies are
+// loaded, and are parsed based on the content type of the response. Data paths
+// that are oci:// references are pulled from the registry, and the JSON and
+// YAML files of the artifacts are loaded the same as the files of a directory.
+//
+// The documents of all of the data paths are merged into the root of the data,
+// except for data paths in the form of name=path, e.g. team-a=data/a, whose
+// documents are mounted under the name instead, as data.team_a. The hyphens of
+// the names are replaced with underscores, and the names can be dot separated
+// to mount the documents under nested keys, e.g. teams.a as data.teams.a.
 func LoadWithData(ctx context.Context, policyPaths []string, dataPaths []string) (*Engine, error) {
-	engine, err := Load(ctx, policyPaths)
+	return LoadWithOptions(ctx, policyPaths, dataPaths, Options{})
+}
+
+// LoadWithOptions returns an Engine after loading all of the specified policies and
+// data paths, compiling the policies using the given options.
+func LoadWithOptions(ctx context.Context, policyPaths []string, dataPaths []string, options Options) (*Engine, error) {
+	engine, err := load(ctx, policyPaths, options)
 	if err != nil {
 		return nil, fmt.Errorf("loading policies: %w", err)
 	}
 
+	data, documentContents, err := loadMountedDocuments(ctx, dataPaths, options)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = addBundleData(data, engine.remoteBundles)
+	if err != nil {
+		return nil, fmt.Errorf("add bundle data: %w", err)
+	}
+
+	store := inmem.NewFromObject(data)
+
+	// The compiled bundles need to be activated in the store that contains the
+	// documents, for their WASM entrypoints to be able to read the documents.
+	if len(engine.bundles) > 0 {
+		compiler, err := newCompiler(ctx, store, engine.sources, engine.bundles, engine.options)
+		if err != nil {
+			return nil, fmt.Errorf("get compiler: %w", err)
+		}
+
+		engine.compiler = compiler
+		engine.modules = compiler.Modules
+		engine.annotations = ruleAnnotations(compiler)
+	}
+
+	exceptions, err := parseSharedExceptions(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse exceptions: %w", err)
+	}
+
+	engine.store = store
+	engine.docs = documentContents
+	engine.exceptions = exceptions
+
+	return engine, nil
+}
+
+// loadDocuments loads the documents of the given data paths, and returns the
+// data that contains the documents along with the contents of each of the
+// documents, keyed by their paths.
+func loadDocuments(ctx context.Context, dataPaths []string, options Options) (map[string]interface{}, map[string]string, error) {
+	// Data paths that are URLs are fetched over HTTP instead of being loaded
+	// from the file system, while OCI artifacts are pulled into a temporary
+	// directory, which is removed once the documents have been loaded.
+	var localPaths, urls, references []string
+	for _, dataPath := range dataPaths {
+		if parser.IsURL(dataPath) {
+			urls = append(urls, dataPath)
+		} else if isOCIReference(dataPath) {
+			references = append(references, dataPath)
+		} else {
+			localPaths = append(localPaths, dataPath)
+		}
+	}
+
+	var err error
+	pulled := &pulledData{}
+	if len(references) > 0 {
+		pulled, err = pullData(ctx, references)
+		if err != nil {
+			return nil, nil, fmt.Errorf("pull data: %w", err)
+		}
+		defer pulled.remove()
+
+		localPaths = append(localPaths, pulled.paths()...)
+	}
+
 	// FilteredPaths will recursively find all file paths that contain a valid document
-	// extension from the given list of data paths.
-	allDocumentPaths, err := loader.FilteredPaths(dataPaths, func(abspath string, info os.FileInfo, depth int) bool {
-		if info.IsDir() {
+	// extension from the given list of data paths. When a data parser is given,
+	// every file is a document, as it is parsed regardless of its extension.
+	allDocumentPaths, err := loader.FilteredPaths(localPaths, func(abspath string, info os.FileInfo, depth int) bool {
+		if info.IsDir() || options.DataParser != "" {
 			return false
 		}
 		return !contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(info.Name()))
 	})
 	if err != nil {
-		return nil, fmt.Errorf("filter data paths: %w", err)
+		return nil, nil, fmt.Errorf("filter data paths: %w", err)
 	}
 
-	documents, err := loader.NewFileLoader().All(allDocumentPaths)
-	if err != nil {
-		return nil, fmt.Errorf("load documents: %w", err)
+	var data map[string]interface{}
+	if options.DataParser != "" {
+		data, err = parseDocuments(allDocumentPaths, options.DataParser)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse documents: %w", err)
+		}
+	} else {
+		documents, err := loader.NewFileLoader().All(allDocumentPaths)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load documents: %w", err)
+		}
+		data = documents.Documents
 	}
-	store, err := documents.Store()
+
+	remoteDocuments, err := fetchDocuments(ctx, urls, options.DataParser)
 	if err != nil {
-		return nil, fmt.Errorf("get documents store: %w", err)
+		return nil, nil, fmt.Errorf("fetch documents: %w", err)
+	}
+
+	for _, remote := range remoteDocuments {
+		if err := mergeDocument(data, remote.document); err != nil {
+			return nil, nil, fmt.Errorf("merge %s: %w", remote.url, err)
+		}
 	}
 
 	documentContents := make(map[string]string)
 	for _, documentPath := range allDocumentPaths {
 		contents, err := ioutil.ReadFile(documentPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read file: %w", err)
+		}
+
+		documentContents[pulled.documentName(documentPath)] = string(contents)
+	}
+
+	for _, remote := range remoteDocuments {
+		documentContents[remote.url] = string(remote.contents)
+	}
+
+	return data, documentContents, nil
+}
+
+// parseDocuments parses the given data files with the given parser, and returns
+// the data that contains the documents. The same as data files that are loaded
+// based on their extension, the documents are merged into the root of the data.
+func parseDocuments(paths []string, parserName string) (map[string]interface{}, error) {
+	documentParser, err := parser.New(parserName)
+	if err != nil {
+		return nil, fmt.Errorf("new parser: %w", err)
+	}
+
+	data := make(map[string]interface{})
+	for _, path := range paths {
+		contents, err := ioutil.ReadFile(path)
 		if err != nil {
 			return nil, fmt.Errorf("read file: %w", err)
 		}
 
-		documentPath = filepath.Clean(documentPath)
-		documentPath = filepath.ToSlash(documentPath)
-		documentContents[documentPath] = string(contents)
+		var document interface{}
+		if err := documentParser.Unmarshal(contents, &document); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+
+		// Empty files do not contain any data.
+		if document == nil {
+			continue
+		}
+
+		object, ok := document.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("data file %s must contain an object", path)
+		}
+
+		if err := mergeDocument(data, object); err != nil {
+			return nil, fmt.Errorf("merge %s: %w", path, err)
+		}
 	}
 
-	engine.store = store
-	engine.docs = documentContents
+	return data, nil
+}
 
-	return engine, nil
+// mergeDocument merges the given document into the data. Objects are merged
+// recursively, and any other value that is defined more than once is a conflict.
+func mergeDocument(data map[string]interface{}, document map[string]interface{}) error {
+	for key, value := range document {
+		existing, ok := data[key]
+		if !ok {
+			data[key] = value
+			continue
+		}
+
+		existingObject, existingIsObject := existing.(map[string]interface{})
+		object, isObject := value.(map[string]interface{})
+		if !existingIsObject || !isObject {
+			return fmt.Errorf("%s is defined more than once", key)
+		}
+
+		if err := mergeDocument(existingObject, object); err != nil {
+			return fmt.Errorf("%s.%w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// Copy returns a copy of the engine that shares the compiled policies and the
+// data of the engine, without the positions, the selected rules, the coverage
+// and the tracing that are set for an evaluation. Loading the policies once
+// and copying the engine for every evaluation allows the evaluations to run
+// concurrently, each with its own settings.
+func (e *Engine) Copy() *Engine {
+	copied := *e
+	copied.positions = nil
+	copied.selectedRules = nil
+	copied.coverage = nil
+	copied.tracing = false
+	copied.reportPasses = false
+
+	return &copied
+}
+
+// SetPositions sets the positions of the values in the configurations, keyed by
+// the file name of the configuration, that are used to locate the results of Check.
+//
+// A result is located using the path metadata of the result (e.g. deny[{"msg": msg, "path": "spec.replicas"}]),
+// which can either be a dot separated string or an array of keys. When a result
+// does not have a path, results of multi-document configurations are located at
+// the start of their document.
+func (e *Engine) SetPositions(positions map[string]map[string]position.Position) {
+	e.positions = positions
+}
+
+// SetRules limits the rules that are evaluated by Check to the rules with the given
+// names (e.g. deny or warn_labels). When no rules are given, all rules are evaluated.
+func (e *Engine) SetRules(rules []string) {
+	e.selectedRules = rules
+}
+
+// EnableReportPasses reports the rules that passed, i.e. that were evaluated
+// without any failures, warnings or exceptions, in the Passes of the results
+// of Check, with the name and the annotations of each of the rules.
+func (e *Engine) EnableReportPasses() {
+	e.reportPasses = true
 }
 
 // Check executes all of the loaded policies against the input and returns the results.
+// It is safe to call Check from multiple goroutines concurrently.
 func (e *Engine) Check(ctx context.Context, configs map[string]interface{}, namespace string) ([]output.CheckResult, error) {
 	var checkResults []output.CheckResult
 	for path, config := range configs {
@@ -123,16 +559,21 @@ func (e *Engine) Check(ctx context.Context, configs map[string]interface{}, name
 				FileName: path,
 				Namespace: namespace,
 			}
-			for _, subconfig := range subconfigs {
+			for i, subconfig := range subconfigs {
 				result, err := e.check(ctx, path, subconfig, namespace)
 				if err != nil {
 					return nil, fmt.Errorf("check: %w", err)
 				}
+				e.locate(&result, strconv.Itoa(i))
 
 				checkResult.Successes = checkResult.Successes + result.Successes
 				checkResult.Failures = append(checkResult.Failures, result.Failures...)
 				checkResult.Warnings = append(checkResult.Warnings, result.Warnings...)
 				checkResult.Exceptions = append(checkResult.Exceptions, result.Exceptions...)
+				checkResult.Passes = append(checkResult.Passes, result.Passes...)
+				checkResult.Rules = append(checkResult.Rules, result.Rules...)
+				checkResult.Outputs = append(checkResult.Outputs, result.Outputs...)
+				checkResult.Queries = append(checkResult.Queries, result.Queries...)
 			}
 			checkResults = append(checkResults, checkResult)
 			continue
@@ -142,6 +583,7 @@ func (e *Engine) Check(ctx context.Context, configs map[string]interface{}, name
 		if err != nil {
 			return nil, fmt.Errorf("check: %w", err)
 		}
+		e.locate(&checkResult, "")
 
 		checkResults = append(checkResults, checkResult)
 	}
@@ -151,14 +593,75 @@ func (e *Engine) Check(ctx context.Context, configs map[string]interface{}, name
 
 // CheckCombined combines the input and evaluates the policies against the combined result.
 func (e *Engine) CheckCombined(ctx context.Context, configs map[string]interface{}, namespace string) (output.CheckResult, error) {
-	combinedConfigs := parser.CombineConfigurations(configs)
+	results, err := e.CheckCombinedNamespaces(ctx, configs, []string{namespace})
+	if err != nil {
+		return output.CheckResult{}, err
+	}
+
+	return results[0], nil
+}
+
+// CheckCombinedNamespaces combines the input once and evaluates the policies of
+// each of the given namespaces against the combined result. The results are in
+// the order of the namespaces, with one result for each namespace.
+func (e *Engine) CheckCombinedNamespaces(ctx context.Context, configs map[string]interface{}, namespaces []string) ([]output.CheckResult, error) {
+	return e.CheckCombinedAs(ctx, "Combined", configs, namespaces)
+}
+
+// CheckCombinedAs is the same as CheckCombinedNamespaces, but the results are
+// reported under the given name instead of Combined, such as the name of one
+// of several groups of configurations that are each combined.
+func (e *Engine) CheckCombinedAs(ctx context.Context, name string, configs map[string]interface{}, namespaces []string) ([]output.CheckResult, error) {
+	return e.CheckCombinedWithMetadata(ctx, name, configs, nil, namespaces)
+}
+
+// CheckCombinedWithMetadata is the same as CheckCombinedAs, but when any
+// metadata is given, the combined input is an object that contains the list
+// of the combined configurations under files, and each of the keys of the
+// metadata (e.g. __meta__), so that the metadata is only added once.
+func (e *Engine) CheckCombinedWithMetadata(ctx context.Context, name string, configs map[string]interface{}, metadata map[string]interface{}, namespaces []string) ([]output.CheckResult, error) {
+	input := parser.CombineConfigurations(configs)["Combined"]
+	if len(metadata) > 0 {
+		combined := map[string]interface{}{"files": input}
+		for key, value := range metadata {
+			combined[key] = value
+		}
+
+		input = combined
+	}
+
+	var results []output.CheckResult
+	for _, namespace := range namespaces {
+		result, err := e.check(ctx, name, input, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("check: %w", err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Eval evaluates the given query against the given input, and returns the
+// result set of the query as is, without interpreting the values as the
+// results of rules. This is useful to inspect the value of any rule or data
+// document, e.g. data.kubernetes.is_deployment.
+func (e *Engine) Eval(ctx context.Context, input interface{}, query string) (rego.ResultSet, error) {
+	options := []func(r *rego.Rego){
+		rego.Input(input),
+		rego.Query(query),
+		rego.Compiler(e.Compiler()),
+		rego.Store(e.Store()),
+		rego.Runtime(e.Runtime()),
+	}
 
-	result, err := e.check(ctx, "Combined", combinedConfigs["Combined"], namespace)
+	resultSet, err := rego.New(options...).Eval(ctx)
 	if err != nil {
-		return output.CheckResult{}, fmt.Errorf("check: %w", err)
+		return nil, fmt.Errorf("evaluating query: %w", err)
 	}
 
-	return result, nil
+	return resultSet, nil
 }
 
 // Namespaces returns all of the namespaces in the engine.
@@ -173,9 +676,75 @@ func (e *Engine) Namespaces() []string {
 		namespaces = append(namespaces, namespace)
 	}
 
+	for _, namespace := range e.wasmNamespaces() {
+		if !contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
 	return namespaces
 }
 
+// RootNamespaces returns the namespaces of the policies that are in the
+// directories that the policies were loaded from, or are the files that the
+// policies were loaded from, but not the namespaces of the policies in their
+// subdirectories, such as shared libraries. The namespaces of policies that
+// have been compiled to WASM are always included.
+func (e *Engine) RootNamespaces() []string {
+	var roots []string
+	for _, policyPath := range e.policyPaths {
+		root, err := filepath.Abs(policyPath)
+		if err != nil {
+			continue
+		}
+
+		roots = append(roots, root)
+	}
+
+	var paths []string
+	for path := range e.sources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var namespaces []string
+	for _, path := range paths {
+		absolutePath, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+
+		if !contains(roots, absolutePath) && !contains(roots, filepath.Dir(absolutePath)) {
+			continue
+		}
+
+		namespace := strings.Replace(e.sources[path].Package.Path.String(), "data.", "", 1)
+		if !contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	for _, namespace := range e.wasmNamespaces() {
+		if !contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	return namespaces
+}
+
+// Rules returns the names of the rules in the given namespace that are
+// evaluated by Check (e.g. deny, violation and warn rules), in lexical order.
+func (e *Engine) Rules(namespace string) []string {
+	var rules []string
+	for rule := range e.ruleCounts(namespace) {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	return rules
+}
+
 // Documents returns all of the documents loaded into the engine.
 // The result is a map where the key is the filepath of the document
 // and its value is the raw contents of the loaded document.
@@ -225,7 +794,9 @@ func (e *Engine) Runtime() *ast.Term {
 	return ast.NewTerm(obj)
 }
 
-func (e *Engine) check(ctx context.Context, path string, config interface{}, namespace string) (output.CheckResult, error) {
+// ruleCounts returns the rules in the given namespace that are evaluated by Check,
+// and the number of times that each of the rules is defined.
+func (e *Engine) ruleCounts(namespace string) map[string]int {
 
 	// When performing policy evaluation using Check, there are a few rules that are special (e.g. warn and deny).
 	// In order to validate the inputs against the policies, these rules need to be identified and how often
@@ -239,19 +810,46 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 
 		for r := range module.Rules {
 			currentRule := module.Rules[r].Head.Name.String()
-			if isFailure(currentRule) || isWarning(currentRule) {
+			if e.isRule(currentRule) {
 				rules[currentRule]++
 			}
 		}
 	}
 
+	// Rules that are only available as entrypoints of policies compiled to WASM
+	// are evaluated once, as their individual definitions are not known.
+	for _, rule := range e.wasmRules(namespace) {
+		if _, ok := rules[rule]; !ok && e.isRule(rule) {
+			rules[rule] = 1
+		}
+	}
+
+	return rules
+}
+
+func (e *Engine) check(ctx context.Context, path string, config interface{}, namespace string) (output.CheckResult, error) {
+
+	rules := e.ruleCounts(namespace)
+	if len(e.selectedRules) > 0 {
+		for rule := range rules {
+			if !contains(e.selectedRules, rule) {
+				delete(rules, rule)
+			}
+		}
+	}
+
 	checkResult := output.CheckResult{
 		FileName: path,
 		Namespace: namespace,
 	}
 	for rule, count := range rules {
-		exceptionQuery := fmt.Sprintf("data.%s.exception[_][_] == %q", namespace, removeRulePrefix(rule))
+		exceptionQuery := fmt.Sprintf("data.%s.exception[_][_] == %q", namespace, e.ruleName(rule))
 		exceptionQueryResult, err := e.query(ctx, config, exceptionQuery, namespace)
+		if failure, ok := e.evaluationError(rule, err); ok {
+			checkResult.Failures = append(checkResult.Failures, failure)
+			checkResult.Rules = append(checkResult.Rules, output.EvaluatedRule{Name: rule, Outcome: output.OutcomeFail})
+			continue
+		}
 		if err != nil {
 			return output.CheckResult{}, fmt.Errorf("query exception: %w", err)
 		}
@@ -262,18 +860,41 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 			// When an exception is found, set the message of the exception
 			// to the query that triggered the exception so that it is known
 			// which exception was trigged.
-			if exceptionResult.Passed() {
+			//
+			// The exception query can match more than once, e.g. when multiple exception
+			// rules name the same rule, but a rule is only excepted once.
+			if exceptionResult.Passed() && len(exceptions) == 0 {
 				exceptionResult.Message = exceptionQuery
+				exceptionResult.Rule = rule
 				exceptions = append(exceptions, exceptionResult)
 			}
 		}
 
+		// The shared exceptions of the data apply the same as the exception
+		// rules of the policies, when the policies do not except the rule.
+		if len(exceptions) == 0 {
+			if exception, ok := e.sharedException(path, config, namespace, rule); ok {
+				exceptions = append(exceptions, exception)
+			}
+		}
+
 		ruleQuery := fmt.Sprintf("data.%s.%s", namespace, rule)
 		ruleQueryResult, err := e.query(ctx, config, ruleQuery, namespace)
+		if failure, ok := e.evaluationError(rule, err); ok {
+			checkResult.Failures = append(checkResult.Failures, failure)
+			checkResult.Rules = append(checkResult.Rules, output.EvaluatedRule{Name: rule, Outcome: output.OutcomeFail})
+			continue
+		}
 		if err != nil {
 			return output.CheckResult{}, fmt.Errorf("query rule: %w", err)
 		}
 
+		for _, printed := range ruleQueryResult.Outputs {
+			checkResult.Outputs = append(checkResult.Outputs, output.PrintOutput{Rule: rule, Message: printed})
+		}
+
+		severity, failure := e.ruleSeverity(rule)
+
 		var failures []output.Result
 		var warnings []output.Result
 		for _, ruleResult := range ruleQueryResult.Results {
@@ -281,7 +902,11 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 				continue
 			}
 
-			if isFailure(rule) {
+			ruleResult.Rule = rule
+			ruleResult.Severity = severity
+			ruleResult.Annotations = e.annotations[ruleQuery]
+
+			if failure {
 				failures = append(failures, ruleResult)
 			} else {
 				warnings = append(warnings, ruleResult)
@@ -300,6 +925,12 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 		checkResult.Warnings = append(checkResult.Warnings, warnings...)
 		checkResult.Exceptions = append(checkResult.Exceptions, exceptions...)
 
+		if e.reportPasses && successes > 0 && len(failures)+len(warnings)+len(exceptions) == 0 {
+			checkResult.Passes = append(checkResult.Passes, output.Result{Rule: rule, Severity: severity, Annotations: e.annotations[ruleQuery]})
+		}
+
+		checkResult.Rules = append(checkResult.Rules, output.EvaluatedRule{Name: rule, Outcome: ruleOutcome(failures, warnings, exceptions)})
+
 		checkResult.Queries = append(checkResult.Queries, exceptionQueryResult)
 		checkResult.Queries = append(checkResult.Queries, ruleQueryResult)
 	}
@@ -307,6 +938,94 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 	return checkResult, nil
 }
 
+// ruleOutcome returns the outcome of a rule given the results it produced.
+func ruleOutcome(failures []output.Result, warnings []output.Result, exceptions []output.Result) string {
+	switch {
+	case len(exceptions) > 0:
+		return output.OutcomeException
+	case len(failures) > 0:
+		return output.OutcomeFail
+	case len(warnings) > 0:
+		return output.OutcomeWarn
+	default:
+		return output.OutcomePass
+	}
+}
+
+// evaluationError returns the failure of the given rule that reports the
+// given error of its evaluation, when the error is a runtime error of the
+// policy, such as a conflict or a type error, and the check is not aborted
+// on errors. The code and the location of the error in the policy are added
+// to the metadata of the failure.
+func (e *Engine) evaluationError(rule string, err error) (output.Result, bool) {
+	var evalErr *topdown.Error
+	if err == nil || e.options.AbortOnError || !errors.As(err, &evalErr) || topdown.IsCancel(err) {
+		return output.Result{}, false
+	}
+
+	metadata := map[string]interface{}{
+		"severity": "error",
+		"code":     evalErr.Code,
+	}
+	if evalErr.Location != nil {
+		metadata["location"] = evalErr.Location.String()
+	}
+
+	failure := output.Result{
+		Message:  evalErr.Error(),
+		Rule:     rule,
+		Metadata: metadata,
+	}
+
+	return failure, true
+}
+
+// locate sets the line and column of the results in the given check result
+// from the positions of its file. The document is the index of the document
+// that was checked when the file contains multiple documents.
+func (e *Engine) locate(checkResult *output.CheckResult, document string) {
+	positions, ok := e.positions[checkResult.FileName]
+	if !ok {
+		return
+	}
+
+	locateResults := func(results []output.Result) {
+		for i := range results {
+			key := position.Join(document, getResultPath(results[i]))
+			if key == "" {
+				continue
+			}
+
+			if pos, ok := positions[key]; ok {
+				results[i].Line = pos.Line
+				results[i].Column = pos.Column
+			}
+		}
+	}
+
+	locateResults(checkResult.Failures)
+	locateResults(checkResult.Warnings)
+	locateResults(checkResult.Exceptions)
+}
+
+// getResultPath returns the path of the value that produced the result
+// from the metadata of the result, in the form of a dot separated path.
+func getResultPath(result output.Result) string {
+	switch path := result.Metadata["path"].(type) {
+	case string:
+		return path
+	case []interface{}:
+		var keys []string
+		for _, key := range path {
+			keys = append(keys, fmt.Sprint(key))
+		}
+
+		return strings.Join(keys, ".")
+	default:
+		return ""
+	}
+}
+
 // query is a low-level method that has no notion of a failed policy or successful policy.
 // It only returns the result of executing a single query against the input.
 //
@@ -315,30 +1034,28 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 // data.main.warn to query the warn rule in the main namespace
 func (e *Engine) query(ctx context.Context, input interface{}, query string, namespace string) (output.QueryResult, error) {
 	stdout := topdown.NewBufferTracer()
+	printHook := &bufferPrintHook{}
 	options := []func(r *rego.Rego){
 		rego.Input(input),
 		rego.Query(query),
 		rego.Compiler(e.Compiler()),
 		rego.Store(e.Store()),
 		rego.Runtime(e.Runtime()),
-		rego.QueryTracer(stdout),
+		rego.EnablePrintStatements(true),
+		rego.PrintHook(printHook),
+	}
+	if e.tracing {
+		options = append(options, rego.QueryTracer(stdout))
 	}
+	if e.coverage != nil {
+		options = append(options, rego.QueryTracer(e.coverage))
+	}
+
 	resultSet, err := rego.New(options...).Eval(ctx)
 	if err != nil {
 		return output.QueryResult{}, fmt.Errorf("evaluating policy: %w", err)
 	}
 
-	// After the evaluation of the policy, the results of the trace (stdout) will be populated
-	// for the query. Once populated, format the trace results into a human readable format.
-	buf := new(bytes.Buffer)
-	topdown.PrettyTrace(buf, *stdout)
-	var traces []string
-	for _, line := range strings.Split(buf.String(), "\n") {
-		if len(line) > 0 {
-			traces = append(traces, line)
-		}
-	}
-
 	var results []output.Result
 	for _, result := range resultSet {
 		for _, expression := range result.Expressions {
@@ -383,12 +1100,34 @@ func (e *Engine) query(ctx context.Context, input interface{}, query string, nam
 	queryResult := output.QueryResult{
 		Query:   query,
 		Results: results,
-		Traces:  traces,
+		Outputs: printHook.outputs,
+	}
+
+	// After the evaluation of the policy, the results of the trace (stdout) will be populated
+	// for the query. Once populated, format the trace results into a human readable format.
+	if e.tracing {
+		queryResult.Traces = PrettyTrace(*stdout)
+		queryResult.TraceEvents = TraceEvents(*stdout)
 	}
 
 	return queryResult, nil
 }
 
+// bufferPrintHook captures the output of the print statements that are
+// called while evaluating a query, prefixed with the location of the call.
+type bufferPrintHook struct {
+	outputs []string
+}
+
+func (b *bufferPrintHook) Print(ctx print.Context, message string) error {
+	if ctx.Location != nil {
+		message = fmt.Sprintf("%s:%d: %s", ctx.Location.File, ctx.Location.Row, message)
+	}
+
+	b.outputs = append(b.outputs, message)
+	return nil
+}
+
 func isWarning(rule string) bool {
 	warningRegex := regexp.MustCompile("^warn(_[a-zA-Z0-9]+)*$")
 	return warningRegex.MatchString(rule)
@@ -1,10 +1,26 @@
 package policy
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/parser/position"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
 )
 
 func TestException(t *testing.T) {
@@ -172,3 +188,1250 @@ func TestIsFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	policies := []string{"../examples/kubernetes/policy"}
+	engine, err := Load(ctx, policies)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configFiles := []string{"../examples/kubernetes/deployment.yaml"}
+	configs, err := parser.ParseConfigurations(configFiles)
+	if err != nil {
+		t.Fatalf("loading configs: %v", err)
+	}
+
+	const workers = 8
+	failures := make(chan int, workers)
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			results, err := engine.Check(ctx, configs, "main")
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			failures <- len(results[0].Failures)
+		}()
+	}
+
+	for i := 0; i < workers; i++ {
+		select {
+		case err := <-errs:
+			t.Fatalf("could not process policy file: %s", err)
+		case actualFailures := <-failures:
+			const expectedFailures = 4
+			if actualFailures != expectedFailures {
+				t.Errorf("Concurrent check failure. Got %v failures, expected %v", actualFailures, expectedFailures)
+			}
+		}
+	}
+}
+
+func TestCheckPositions(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny[{"msg": msg, "path": ["spec", "replicas"]}] {
+	input.spec.replicas < 2
+	msg := "too few replicas"
+}
+
+warn[msg] {
+	input.kind == "Deployment"
+	msg := "no path"
+}`
+	engine := loadPolicy(t, ctx, policy)
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{
+			"kind": "Deployment",
+			"spec": map[string]interface{}{"replicas": 1},
+		},
+	}
+	engine.SetPositions(map[string]map[string]position.Position{
+		"deployment.yaml": {
+			"spec.replicas": {Line: 3, Column: 3},
+		},
+	})
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	failure := results[0].Failures[0]
+	if failure.Line != 3 || failure.Column != 3 {
+		t.Errorf("Unexpected failure position. Got %v:%v, expected 3:3", failure.Line, failure.Column)
+	}
+
+	warning := results[0].Warnings[0]
+	if warning.Line != 0 || warning.Column != 0 {
+		t.Errorf("Unexpected warning position. Got %v:%v, expected none", warning.Line, warning.Column)
+	}
+}
+
+func TestCheckPrintOutput(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny[msg] {
+	print("kind is", input.kind)
+	msg := "denied"
+}`
+	policyDir := writeFiles(t, map[string]string{"policy.rego": policy})
+	policyPath := filepath.Join(policyDir, "policy.rego")
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Outputs) != 1 {
+		t.Fatalf("Unexpected print outputs. Got %v, expected 1 output", results[0].Outputs)
+	}
+
+	printed := results[0].Outputs[0]
+	expected := policyPath + ":4: kind is Deployment"
+	if printed.Rule != "deny" || printed.Message != expected {
+		t.Errorf("Unexpected print output. Got %v: %v, expected deny: %v", printed.Rule, printed.Message, expected)
+	}
+}
+
+func TestCheckEvaluationErrors(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+kind = "a" { input.a }
+kind = "b" { input.b }
+
+deny[msg] {
+	kind == "a"
+	msg := "kind is a"
+}
+
+warn[msg] {
+	input.a
+	msg := "a is set"
+}`
+	policyDir := writeFiles(t, map[string]string{"policy.rego": policy})
+	policyPath := filepath.Join(policyDir, "policy.rego")
+
+	configs := map[string]interface{}{
+		"conflict.yaml": map[string]interface{}{"a": true, "b": true},
+	}
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if len(results[0].Failures) != 1 || len(results[0].Warnings) != 1 {
+		t.Fatalf("Unexpected results. Got %v failures and %v warnings, expected 1 of each", results[0].Failures, results[0].Warnings)
+	}
+
+	failure := results[0].Failures[0]
+	if failure.Rule != "deny" || failure.Metadata["severity"] != "error" || failure.Metadata["code"] != "eval_conflict_error" {
+		t.Errorf("Unexpected failure: %+v", failure)
+	}
+
+	if failure.Metadata["location"] != policyPath+":4" {
+		t.Errorf("Unexpected location of the error. Got %v, expected %v", failure.Metadata["location"], policyPath+":4")
+	}
+
+	engine, err = LoadWithOptions(ctx, []string{policyDir}, nil, Options{AbortOnError: true})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	if _, err := engine.Check(ctx, configs, "main"); err == nil {
+		t.Error("expected the check to be aborted on the evaluation error")
+	}
+}
+
+func TestCheckSelectedRules(t *testing.T) {
+	ctx := context.Background()
+
+	policies := []string{"../examples/kubernetes/policy"}
+	engine, err := Load(ctx, policies)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	expectedRules := []string{"deny", "violation", "warn"}
+	if actualRules := engine.Rules("main"); !reflect.DeepEqual(expectedRules, actualRules) {
+		t.Errorf("Unexpected rules. Got %v, expected %v", actualRules, expectedRules)
+	}
+
+	configFiles := []string{"../examples/kubernetes/deployment.yaml"}
+	configs, err := parser.ParseConfigurations(configFiles)
+	if err != nil {
+		t.Fatalf("loading configs: %v", err)
+	}
+
+	engine.SetRules([]string{"violation"})
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	const expectedFailures = 1
+	actualFailures := len(results[0].Failures)
+	if actualFailures != expectedFailures {
+		t.Errorf("Selected rules test failure. Got %v failures, expected %v", actualFailures, expectedFailures)
+	}
+
+	for _, failure := range results[0].Failures {
+		if failure.Rule != "violation" {
+			t.Errorf("Selected rules test failure. Got a failure from rule %v, expected only violation", failure.Rule)
+		}
+	}
+}
+
+func TestCheckReportPasses(t *testing.T) {
+	ctx := context.Background()
+
+	policies := []string{"../examples/kubernetes/policy"}
+	engine, err := Load(ctx, policies)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configFiles := []string{"../examples/kubernetes/deployment.yaml"}
+	configs, err := parser.ParseConfigurations(configFiles)
+	if err != nil {
+		t.Fatalf("loading configs: %v", err)
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Passes) > 0 {
+		t.Errorf("passes should only be reported when enabled, got %v", results[0].Passes)
+	}
+
+	engine.EnableReportPasses()
+	results, err = engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	passes := results[0].Passes
+	if len(passes) != 1 || passes[0].Rule != "warn" || !passes[0].Passed() {
+		t.Errorf("Unexpected passes. Got %v, expected a pass of warn", passes)
+	}
+
+	if copied := engine.Copy(); copied.reportPasses {
+		t.Error("copies of the engine should not report passes")
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	ctx := context.Background()
+
+	policies := []string{"../examples/kubernetes/policy"}
+	engine, err := Load(ctx, policies)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configFiles := []string{"../examples/kubernetes/deployment.yaml"}
+	configs, err := parser.ParseConfigurations(configFiles)
+	if err != nil {
+		t.Fatalf("loading configs: %v", err)
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	outcomes := make(map[string]string)
+	for _, rule := range results[0].Rules {
+		outcomes[rule.Name] = rule.Outcome
+	}
+
+	expected := map[string]string{
+		"deny":      output.OutcomeFail,
+		"violation": output.OutcomeFail,
+		"warn":      output.OutcomePass,
+	}
+	if !reflect.DeepEqual(outcomes, expected) {
+		t.Errorf("Unexpected outcomes of the rules. Got %v, expected %v", outcomes, expected)
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	ctx := context.Background()
+
+	policies := []string{"../examples/kubernetes/policy"}
+	engine, err := Load(ctx, policies)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+	engine.EnableCoverage()
+
+	configFiles := []string{"../examples/kubernetes/service.yaml"}
+	configs, err := parser.ParseConfigurations(configFiles)
+	if err != nil {
+		t.Fatalf("loading configs: %v", err)
+	}
+
+	if _, err := engine.Check(ctx, configs, "main"); err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	report := engine.Coverage()
+
+	const expectedRules = 5
+	if report.TotalRules != expectedRules {
+		t.Errorf("Coverage test failure. Got %v rules, expected %v", report.TotalRules, expectedRules)
+	}
+
+	// The service only triggers the warning for services.
+	const expectedCoveredRules = 1
+	if report.CoveredRules != expectedCoveredRules {
+		t.Errorf("Coverage test failure. Got %v covered rules, expected %v", report.CoveredRules, expectedCoveredRules)
+	}
+
+	for _, rule := range report.Rules {
+		if rule.Covered && rule.Rule != "warn" {
+			t.Errorf("Coverage test failure. Rule %v at %v:%v should not be covered", rule.Rule, rule.File, rule.Line)
+		}
+	}
+
+	if report.CoveredLines == 0 || report.CoveredLines >= report.TotalLines {
+		t.Errorf("Coverage test failure. Got %v of %v lines covered", report.CoveredLines, report.TotalLines)
+	}
+}
+
+func TestRootNamespaces(t *testing.T) {
+	policies := map[string]string{
+		"main.rego":           "package main\n\nimport data.lib.kubernetes\n\ndeny[msg] {\n  kubernetes.is_deployment\n  msg := \"deployment\"\n}",
+		"lib/kubernetes.rego": "package lib.kubernetes\n\nis_deployment {\n  input.kind == \"Deployment\"\n}",
+	}
+	policyDir := writeFiles(t, policies)
+
+	engine, err := Load(context.Background(), []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	namespaces := engine.Namespaces()
+	sort.Strings(namespaces)
+	if expected := []string{"lib.kubernetes", "main"}; !reflect.DeepEqual(namespaces, expected) {
+		t.Errorf("Unexpected namespaces. expected %v, actual %v", expected, namespaces)
+	}
+
+	if expected, actual := []string{"main"}, engine.RootNamespaces(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected root namespaces. expected %v, actual %v", expected, actual)
+	}
+
+	engine, err = Load(context.Background(), []string{policyDir, filepath.Join(policyDir, "lib", "kubernetes.rego")})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	if expected, actual := []string{"lib.kubernetes", "main"}, engine.RootNamespaces(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected root namespaces of a policy file. expected %v, actual %v", expected, actual)
+	}
+}
+
+func TestTracing(t *testing.T) {
+	ctx := context.Background()
+
+	policies := []string{"../examples/kubernetes/policy"}
+	engine, err := Load(ctx, policies)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configFiles := []string{"../examples/kubernetes/service.yaml"}
+	configs, err := parser.ParseConfigurations(configFiles)
+	if err != nil {
+		t.Fatalf("loading configs: %v", err)
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	for _, query := range results[0].Queries {
+		if len(query.Traces) > 0 || len(query.TraceEvents) > 0 {
+			t.Errorf("Tracing test failure. Query %v should not be traced when tracing is not enabled", query.Query)
+		}
+	}
+
+	engine.EnableTracing()
+	results, err = engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	for _, query := range results[0].Queries {
+		if len(query.Traces) == 0 || len(query.TraceEvents) == 0 {
+			t.Errorf("Tracing test failure. Query %v should be traced when tracing is enabled", query.Query)
+		}
+
+		for _, event := range query.TraceEvents {
+			if event.Op == "" {
+				t.Errorf("Tracing test failure. Query %v has an event without an operation", query.Query)
+			}
+		}
+	}
+}
+
+func TestCheckExceptions(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny_replicas[msg] {
+	input.spec.replicas < 2
+	msg := "too few replicas"
+}
+
+exception[rules] {
+	input.kind == "Deployment"
+	rules := ["replicas"]
+}
+
+exception[rules] {
+	input.metadata.name == "example"
+	rules := ["replicas", "labels"]
+}`
+	engine := loadPolicy(t, ctx, policy)
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{
+			"kind":     "Deployment",
+			"metadata": map[string]interface{}{"name": "example"},
+			"spec":     map[string]interface{}{"replicas": 1},
+		},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	// Both of the exceptions apply to the same rule, which is only excepted once.
+	if len(results[0].Exceptions) != 1 {
+		t.Errorf("Exceptions test failure. Got %v exceptions, expected 1", len(results[0].Exceptions))
+	}
+
+	if results[0].Successes != 0 || len(results[0].Failures) != 0 {
+		t.Errorf("Exceptions test failure. Got %v successes and %v failures, expected none", results[0].Successes, len(results[0].Failures))
+	}
+}
+
+func TestCheckSharedExceptions(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny_run_as_root[msg] {
+	not input.runAsNonRoot
+	msg := "Containers must not run as root"
+}
+
+deny_latest[msg] {
+	input.tag == "latest"
+	msg := "Images must be pinned"
+}`
+	exceptions := `exceptions:
+- rules: [run_as_root]
+  match:
+    metadata:
+      name: can-run-as-root
+  reason: Needs to bind to port 80
+- rules: [latest]
+  namespaces: [main]
+  files: ["legacy/*.yaml"]
+`
+	dir := writeFiles(t, map[string]string{
+		"policy.rego":             policy,
+		"exceptions.yaml":         exceptions,
+		"invalid/exceptions.yaml": "exceptions:\n- reason: no rules\n",
+	})
+	policyPath := filepath.Join(dir, "policy.rego")
+	exceptionsPath := filepath.Join(dir, "exceptions.yaml")
+
+	engine, err := LoadWithData(ctx, []string{policyPath}, []string{exceptionsPath})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml":        map[string]interface{}{"metadata": map[string]interface{}{"name": "can-run-as-root"}, "tag": "latest"},
+		"legacy/deployment.yaml": map[string]interface{}{"metadata": map[string]interface{}{"name": "legacy"}, "tag": "latest"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	rules := make(map[string][]string)
+	for _, result := range results {
+		for _, failure := range result.Failures {
+			rules[result.FileName] = append(rules[result.FileName], "failure:"+failure.Rule)
+		}
+
+		for _, exception := range result.Exceptions {
+			rules[result.FileName] = append(rules[result.FileName], "exception:"+exception.Rule)
+		}
+
+		sort.Strings(rules[result.FileName])
+	}
+
+	expected := map[string][]string{
+		"deployment.yaml":        {"exception:deny_run_as_root", "failure:deny_latest"},
+		"legacy/deployment.yaml": {"exception:deny_latest", "failure:deny_run_as_root"},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Unexpected results. expected %v, got %v", expected, rules)
+	}
+
+	for _, result := range results {
+		for _, exception := range result.Exceptions {
+			if exception.Rule == "deny_run_as_root" && (exception.Message != "data.exceptions[0]" || exception.Metadata["reason"] != "Needs to bind to port 80") {
+				t.Errorf("Unexpected exception: %+v", exception)
+			}
+		}
+	}
+
+	if _, err := LoadWithData(ctx, []string{policyPath}, []string{filepath.Join(dir, "invalid", "exceptions.yaml")}); err == nil {
+		t.Error("expected an error for an exception without rules")
+	}
+}
+
+func TestLoadStrict(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny[msg] {
+	unused := input.kind
+	msg := "always"
+}`
+	policyDir := writeFiles(t, map[string]string{"policy.rego": policy})
+	if _, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{}); err != nil {
+		t.Fatalf("loading policies without strict mode: %v", err)
+	}
+
+	_, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{Strict: true})
+	if err == nil {
+		t.Fatal("loading policies in strict mode should fail")
+	}
+
+	if !strings.Contains(err.Error(), "unused") {
+		t.Errorf("Unexpected error. Got %v, expected an error about the unused variable", err)
+	}
+}
+
+func TestLoadRegoVersion(t *testing.T) {
+	ctx := context.Background()
+
+	v0 := writeFiles(t, map[string]string{"policy.rego": "package main\n\ndeny[msg] {\n\tmsg := \"always\"\n}"})
+	v1 := writeFiles(t, map[string]string{"policy.rego": `package main
+
+deny contains msg if {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`})
+
+	for _, regoVersion := range []string{"", RegoV0} {
+		if _, err := LoadWithOptions(ctx, []string{v0}, nil, Options{RegoVersion: regoVersion}); err != nil {
+			t.Errorf("loading policies as rego version %q: %v", regoVersion, err)
+		}
+
+		if _, err := LoadWithOptions(ctx, []string{v1}, nil, Options{RegoVersion: regoVersion}); err == nil {
+			t.Errorf("loading policies of rego v1 as rego version %q should fail", regoVersion)
+		}
+	}
+
+	engine, err := LoadWithOptions(ctx, []string{v1}, nil, Options{RegoVersion: RegoV1})
+	if err != nil {
+		t.Fatalf("loading policies as rego v1: %v", err)
+	}
+
+	results, err := engine.Check(ctx, map[string]interface{}{"deployment.yaml": map[string]interface{}{"kind": "Deployment"}}, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if len(results[0].Failures) != 1 || results[0].Failures[0].Message != "deployments are not allowed" {
+		t.Errorf("Unexpected failures of rego v1. Got %v, expected the deployment to be denied", results[0].Failures)
+	}
+
+	for regoVersion, policyDir := range map[string]string{RegoV1: v0, "v2": v1} {
+		if _, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{RegoVersion: regoVersion}); err == nil {
+			t.Errorf("loading policies of %s as rego version %s should fail", policyDir, regoVersion)
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(v1, ".manifest"), []byte(`{"rego_version": 1}`), os.ModePerm); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	if _, err := LoadWithOptions(ctx, []string{v0, v1}, nil, Options{}); err != nil {
+		t.Errorf("loading a bundle of rego v1 with policies of rego v0: %v", err)
+	}
+
+	if _, err := LoadWithOptions(ctx, []string{v1}, nil, Options{RegoVersion: RegoV0}); err == nil {
+		t.Error("loading policies as rego v0 should take precedence over the manifest")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(v1, ".manifest"), []byte(`{"rego_version": 2}`), os.ModePerm); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	if _, err := LoadWithOptions(ctx, []string{v1}, nil, Options{}); err == nil || !strings.Contains(err.Error(), "unknown rego_version") {
+		t.Errorf("Unexpected error for an unknown rego_version. Got %v, expected an error about the unknown version", err)
+	}
+}
+
+func TestLoadCapabilities(t *testing.T) {
+	ctx := context.Background()
+
+	capabilities := ast.CapabilitiesForThisVersion()
+	var builtins []*ast.Builtin
+	for _, builtin := range capabilities.Builtins {
+		if builtin.Name != ast.HTTPSend.Name {
+			builtins = append(builtins, builtin)
+		}
+	}
+	capabilities.Builtins = builtins
+
+	contents, err := json.Marshal(capabilities)
+	if err != nil {
+		t.Fatalf("marshal capabilities: %v", err)
+	}
+
+	directory := writeFiles(t, map[string]string{
+		"capabilities.json": string(contents),
+		"allowed/policy.rego": `package main
+
+deny[msg] {
+	msg := sprintf("%s is not allowed", [input.kind])
+}`,
+		"disallowed/policy.rego": `package main
+
+deny[msg] {
+	response := http.send({"method": "get", "url": "https://example.com"})
+	msg := response.body
+}`,
+	})
+
+	for _, name := range []string{"allowed", "disallowed"} {
+		if _, err := LoadWithOptions(ctx, []string{filepath.Join(directory, name)}, nil, Options{}); err != nil {
+			t.Errorf("loading the %s policies without capabilities: %v", name, err)
+		}
+	}
+
+	options := Options{Capabilities: filepath.Join(directory, "capabilities.json")}
+	if _, err := LoadWithOptions(ctx, []string{filepath.Join(directory, "allowed")}, nil, options); err != nil {
+		t.Errorf("loading the allowed policies with capabilities: %v", err)
+	}
+
+	_, err = LoadWithOptions(ctx, []string{filepath.Join(directory, "disallowed")}, nil, options)
+	if err == nil || !strings.Contains(err.Error(), "http.send") {
+		t.Errorf("Unexpected error. Got %v, expected an error about the disallowed http.send builtin", err)
+	}
+
+	options = Options{Capabilities: filepath.Join(directory, "missing.json")}
+	if _, err := LoadWithOptions(ctx, []string{filepath.Join(directory, "allowed")}, nil, options); err == nil {
+		t.Error("loading policies with a missing capabilities file should fail")
+	}
+}
+
+func TestCheckAnnotations(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+# METADATA
+# title: Replicas
+# description: Deployments must have at least two replicas.
+# custom:
+#   url: https://example.com/replicas
+deny[msg] {
+	input.spec.replicas < 2
+	msg := "too few replicas"
+}
+
+warn[msg] {
+	input.kind == "Deployment"
+	msg := "not annotated"
+}`
+	engine := loadPolicy(t, ctx, policy)
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{
+			"kind": "Deployment",
+			"spec": map[string]interface{}{"replicas": 1},
+		},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	failure := results[0].Failures[0]
+	if failure.Annotations == nil {
+		t.Fatal("Annotations test failure. Expected the failure to be annotated")
+	}
+
+	if failure.Annotations.Title != "Replicas" || failure.Annotations.URL() != "https://example.com/replicas" {
+		t.Errorf("Annotations test failure. Got unexpected annotations: %+v", failure.Annotations)
+	}
+
+	if warning := results[0].Warnings[0]; warning.Annotations != nil {
+		t.Errorf("Annotations test failure. Expected the warning to not be annotated, got: %+v", warning.Annotations)
+	}
+}
+
+func TestCheckCombinedNamespaces(t *testing.T) {
+	ctx := context.Background()
+
+	policies := map[string]string{
+		"replicas.rego": `package replicas
+deny[msg] {
+	input[_].contents.spec.replicas < 2
+	msg := "too few replicas"
+}`,
+		"names.rego": `package names
+deny[msg] {
+	count({name | name := input[_].contents.metadata.name}) < count(input)
+	msg := "duplicate names"
+}`,
+	}
+	engine, err := Load(ctx, []string{writeFiles(t, policies)})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"first.yaml":  map[string]interface{}{"metadata": map[string]interface{}{"name": "example"}, "spec": map[string]interface{}{"replicas": 1}},
+		"second.yaml": map[string]interface{}{"metadata": map[string]interface{}{"name": "example"}, "spec": map[string]interface{}{"replicas": 3}},
+	}
+
+	results, err := engine.CheckCombinedNamespaces(ctx, configs, []string{"replicas", "names"})
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Combined test failure. Got %v results, expected one for each namespace", len(results))
+	}
+
+	for i, namespace := range []string{"replicas", "names"} {
+		if results[i].Namespace != namespace || results[i].FileName != "Combined" {
+			t.Errorf("Combined test failure. Got result for %v in %v, expected Combined in %v", results[i].FileName, results[i].Namespace, namespace)
+		}
+
+		if len(results[i].Failures) != 1 {
+			t.Errorf("Combined test failure. Got %v failures in %v, expected 1", len(results[i].Failures), namespace)
+		}
+	}
+}
+
+func TestLoadWithDataParser(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny[msg] {
+	not data.users.admin
+	msg := "admin is not a user"
+}
+
+deny[msg] {
+	data.limits.replicas != 3
+	msg := "replicas is not limited"
+}`
+	directory := writeFiles(t, map[string]string{
+		"policy/policy.rego":      policy,
+		"data/users.txt":          "users:\n  admin: true\n",
+		"data/nested/limits.json": `{"limits": {"replicas": 3}}`,
+		"conflict/users.txt":      "users: []\n",
+		"conflict/more-users.txt": "users:\n  admin: true\n",
+	})
+	policyDir := filepath.Join(directory, "policy")
+
+	engine, err := LoadWithOptions(ctx, []string{policyDir}, []string{filepath.Join(directory, "data")}, Options{DataParser: parser.YAML})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	results, err := engine.Check(ctx, map[string]interface{}{"config.yaml": map[string]interface{}{}}, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 0 {
+		t.Errorf("Data parser test failure. Got %v failures, expected none", results[0].Failures)
+	}
+
+	if _, err := LoadWithOptions(ctx, []string{policyDir}, []string{filepath.Join(directory, "conflict")}, Options{DataParser: parser.YAML}); err == nil {
+		t.Error("loading data that defines a value more than once should fail")
+	}
+}
+
+func TestLoadWithDataMounts(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny[msg] {
+	data.team_a.limits.replicas != 3
+	msg := "replicas of team a are not limited"
+}
+
+deny[msg] {
+	data.teams.b.limits.replicas != 5
+	msg := "replicas of team b are not limited"
+}
+
+deny[msg] {
+	not data.shared
+	msg := "shared data is not loaded"
+}`
+	directory := writeFiles(t, map[string]string{
+		"policy/policy.rego": policy,
+		"a/limits.yaml":      "limits:\n  replicas: 3\n",
+		"b/limits.yaml":      "limits:\n  replicas: 5\n",
+		"shared/data.yaml":   "shared: true\nteam_a: {}\n",
+	})
+
+	policyPaths := []string{filepath.Join(directory, "policy")}
+	dataPaths := []string{
+		"team-a=" + filepath.Join(directory, "a"),
+		"teams.b=" + filepath.Join(directory, "b"),
+		filepath.Join(directory, "shared", "data.yaml"),
+	}
+
+	// The shared data also defines team_a, which collides with the mount.
+	if _, err := LoadWithData(ctx, policyPaths, dataPaths); err == nil {
+		t.Fatal("mounting data under a key that is already defined should fail")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(directory, "shared", "data.yaml"), []byte("shared: true\n"), os.ModePerm); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	engine, err := LoadWithData(ctx, policyPaths, dataPaths)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	results, err := engine.Check(ctx, map[string]interface{}{"config.yaml": map[string]interface{}{}}, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 0 {
+		t.Errorf("Data mount test failure. Got %v failures, expected none", results[0].Failures)
+	}
+}
+
+func TestLoadNamespaceMap(t *testing.T) {
+	ctx := context.Background()
+
+	policies := map[string]string{
+		"a/main.rego": `package main
+
+import data.lib.names
+
+deny[msg] {
+	names.is_denied(input.name)
+	msg := "denied by a"
+}`,
+		"a/lib.rego": `package lib.names
+
+is_denied(name) {
+	name == "a"
+}`,
+		"b/main.rego": `package main
+
+deny[msg] {
+	input.name == "a"
+	msg := "denied by b"
+}`,
+		"c/main.rego": `package team.a.main
+
+deny[msg] {
+	msg := "denied by c"
+}`,
+	}
+	policyDir := writeFiles(t, policies)
+
+	a := filepath.Join(policyDir, "a")
+	b := filepath.Join(policyDir, "b")
+	c := filepath.Join(policyDir, "c")
+
+	options := Options{NamespaceMap: map[string]string{a: "team.a"}}
+	engine, err := LoadWithOptions(ctx, []string{a, b}, nil, options)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	namespaces := engine.Namespaces()
+	sort.Strings(namespaces)
+	expected := []string{"main", "team.a.lib.names", "team.a.main"}
+	if !reflect.DeepEqual(namespaces, expected) {
+		t.Errorf("Unexpected namespaces. Got %v, expected %v", namespaces, expected)
+	}
+
+	configs := map[string]interface{}{"config.json": map[string]interface{}{"name": "a"}}
+	for namespace, message := range map[string]string{"main": "denied by b", "team.a.main": "denied by a"} {
+		results, err := engine.Check(ctx, configs, namespace)
+		if err != nil {
+			t.Fatalf("check %s: %v", namespace, err)
+		}
+
+		if len(results[0].Failures) != 1 || results[0].Failures[0].Message != message {
+			t.Errorf("Unexpected failures of %s. Got %v, expected %q", namespace, results[0].Failures, message)
+		}
+	}
+
+	if _, err := LoadWithOptions(ctx, []string{a, c}, nil, options); err == nil {
+		t.Error("expected the remapped namespace to conflict with the namespace of the other policies")
+	}
+}
+
+func TestLoadFollowSymlinks(t *testing.T) {
+	ctx := context.Background()
+
+	directory := writeFiles(t, map[string]string{
+		"policy/main.rego":   "package main\ndeny[msg] { msg := \"main\" }\n",
+		"shared/shared.rego": "package shared\ndeny[msg] { msg := \"shared\" }\n",
+	})
+	policyDir := filepath.Join(directory, "policy")
+	sharedDir := filepath.Join(directory, "shared")
+
+	// The shared directory links back to the policy directory, which
+	// would be a cycle if the links were followed indefinitely.
+	if err := os.Symlink(sharedDir, filepath.Join(policyDir, "shared")); err != nil {
+		t.Fatalf("create symlink: %v", err)
+	}
+	if err := os.Symlink(policyDir, filepath.Join(sharedDir, "policy")); err != nil {
+		t.Fatalf("create symlink: %v", err)
+	}
+
+	engine, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{})
+	if err != nil {
+		t.Fatalf("load policies: %v", err)
+	}
+
+	if namespaces := engine.Namespaces(); !reflect.DeepEqual(namespaces, []string{"main"}) {
+		t.Errorf("symlinks should not be followed by default, got namespaces %v", namespaces)
+	}
+
+	engine, err = LoadWithOptions(ctx, []string{policyDir}, nil, Options{FollowSymlinks: true})
+	if err != nil {
+		t.Fatalf("load policies following symlinks: %v", err)
+	}
+
+	namespaces := engine.Namespaces()
+	sort.Strings(namespaces)
+	if !reflect.DeepEqual(namespaces, []string{"main", "shared"}) {
+		t.Errorf("symlinks should be followed, got namespaces %v", namespaces)
+	}
+
+	if _, err := LoadWithOptions(ctx, []string{policyDir, sharedDir}, nil, Options{FollowSymlinks: true}); err == nil {
+		t.Error("expected an error for policies that are found through more than one path")
+	}
+}
+
+func TestLoadWithRemoteData(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny[msg] {
+	not data.images.allowed["nginx"]
+	msg := "nginx is not an allowed image"
+}
+
+deny[msg] {
+	data.limits.replicas != 3
+	msg := "replicas is not limited"
+}`
+	directory := writeFiles(t, map[string]string{"policy.rego": policy})
+
+	os.Setenv("CONFTEST_HTTP_TOKEN", "secret")
+	defer os.Unsetenv("CONFTEST_HTTP_TOKEN")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/images":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			fmt.Fprint(w, `{"images": {"allowed": {"nginx": true}}}`)
+		case "/limits.yaml":
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, "limits:\n  replicas: 3\n")
+		case "/unknown":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<html></html>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	engine, err := LoadWithData(ctx, []string{directory}, []string{server.URL + "/images", server.URL + "/limits.yaml"})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	results, err := engine.Check(ctx, map[string]interface{}{"config.yaml": map[string]interface{}{}}, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 0 {
+		t.Errorf("Remote data test failure. Got %v failures, expected none", results[0].Failures)
+	}
+
+	for _, path := range []string{"/missing.json", "/unknown"} {
+		if _, err := LoadWithData(ctx, []string{directory}, []string{server.URL + path}); err == nil {
+			t.Errorf("loading data from %s should fail", path)
+		}
+	}
+
+	os.Setenv("CONFTEST_HTTP_TOKEN", "wrong")
+	if _, err := LoadWithData(ctx, []string{directory}, []string{server.URL + "/images"}); err == nil {
+		t.Error("loading data without a valid token should fail")
+	}
+}
+
+func TestLoadWithBundleServer(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package k8s.main
+
+deny[msg] {
+	not data.k8s.images.allowed[input.image]
+	msg := sprintf("%s is not an allowed image", [input.image])
+}`
+	module, err := ast.ParseModule("/k8s/policy.rego", policy)
+	if err != nil {
+		t.Fatalf("parse policy: %v", err)
+	}
+
+	var archive bytes.Buffer
+	err = bundle.NewWriter(&archive).Write(bundle.Bundle{
+		Manifest: bundle.Manifest{Revision: "7864d60", Roots: &[]string{"k8s"}},
+		Modules:  []bundle.ModuleFile{{URL: "/k8s/policy.rego", Path: "/k8s/policy.rego", Raw: []byte(policy), Parsed: module}},
+		Data:     map[string]interface{}{"k8s": map[string]interface{}{"images": map[string]interface{}{"allowed": map[string]interface{}{"nginx": true}}}},
+	})
+	if err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bundle.tar.gz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	bundleURL := server.URL + "/bundle.tar.gz"
+
+	var logs bytes.Buffer
+	engine, err := LoadWithOptions(ctx, []string{bundleURL}, nil, Options{Logger: log.New(&logs, "", 0)})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	expectedLog := fmt.Sprintf("Loaded bundle %s at revision 7864d60\n", bundleURL)
+	if logs.String() != expectedLog {
+		t.Errorf("unexpected log. expected %q, got %q", expectedLog, logs.String())
+	}
+
+	configs := map[string]interface{}{
+		"nginx.yaml": map[string]interface{}{"image": "nginx"},
+		"redis.yaml": map[string]interface{}{"image": "redis"},
+	}
+	results, err := engine.Check(ctx, configs, "k8s.main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	failures := make(map[string]int)
+	for _, result := range results {
+		failures[result.FileName] = len(result.Failures)
+	}
+
+	expectedFailures := map[string]int{"nginx.yaml": 0, "redis.yaml": 1}
+	if !reflect.DeepEqual(failures, expectedFailures) {
+		t.Errorf("unexpected failures. expected %v, got %v", expectedFailures, failures)
+	}
+
+	directory := writeFiles(t, map[string]string{
+		"policy.rego": "package k8s.extra\n\ndeny[msg] { msg := \"extra\" }",
+		"data.json":   `{"k8s": {"images": {}}}`,
+	})
+
+	if _, err := Load(ctx, []string{bundleURL, filepath.Join(directory, "policy.rego")}); err == nil {
+		t.Error("loading a policy within the roots of the bundle should fail")
+	}
+
+	if _, err := LoadWithData(ctx, []string{bundleURL}, []string{filepath.Join(directory, "data.json")}); err == nil {
+		t.Error("loading data within the roots of the bundle should fail")
+	}
+
+	if _, err := Load(ctx, []string{server.URL + "/missing.tar.gz"}); err == nil {
+		t.Error("loading a missing bundle should fail")
+	}
+}
+
+func TestCheckRulePrefixes(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+critical_privileged[msg] {
+	input.privileged
+	msg := "privileged container"
+}
+
+info[msg] {
+	not input.labels
+	msg := "no labels"
+}
+
+medium_replicas[msg] {
+	input.replicas < 2
+	msg := "too few replicas"
+}
+
+exception[rules] {
+	input.canary
+	rules := ["replicas"]
+}`
+	directory := writeFiles(t, map[string]string{"policy.rego": policy})
+
+	options := Options{
+		RulePrefixes: map[string]string{"critical": "critical", "info": "info", "medium": "medium"},
+		FailSeverity: "high",
+	}
+	engine, err := LoadWithOptions(ctx, []string{directory}, nil, options)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	expectedRules := []string{"critical_privileged", "info", "medium_replicas"}
+	if actualRules := engine.Rules("main"); !reflect.DeepEqual(expectedRules, actualRules) {
+		t.Errorf("Unexpected rules. Got %v, expected %v", actualRules, expectedRules)
+	}
+
+	configs := map[string]interface{}{"config.json": map[string]interface{}{"privileged": true, "replicas": 1}}
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 1 || results[0].Failures[0].Severity != "critical" {
+		t.Errorf("expected the critical result to be a failure, got %v", results[0].Failures)
+	}
+
+	var warnings []string
+	for _, warning := range results[0].Warnings {
+		warnings = append(warnings, warning.Severity)
+	}
+	sort.Strings(warnings)
+
+	if !reflect.DeepEqual([]string{"info", "medium"}, warnings) {
+		t.Errorf("expected the results below the fail severity to be warnings, got %v", results[0].Warnings)
+	}
+
+	configs = map[string]interface{}{"config.json": map[string]interface{}{"replicas": 1, "canary": true}}
+	results, err = engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Exceptions) != 1 || results[0].Exceptions[0].Rule != "medium_replicas" {
+		t.Errorf("expected the exception to refer to the rule without its prefix, got %v", results[0].Exceptions)
+	}
+
+	for _, invalid := range []Options{
+		{RulePrefixes: map[string]string{"critical": "severe"}},
+		{RulePrefixes: map[string]string{"deny": "high"}},
+		{RulePrefixes: map[string]string{"critical": "critical"}, FailSeverity: "severe"},
+	} {
+		if _, err := LoadWithOptions(ctx, []string{directory}, nil, invalid); err == nil {
+			t.Errorf("loading policies with options %v should fail", invalid)
+		}
+	}
+}
+
+// writeFiles writes the given files, keyed by their slash separated paths, to
+// a temporary directory that is removed when the test finishes, and returns
+// the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	directory := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(directory, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	return directory
+}
+
+// loadPolicy loads the given policy with the default options.
+func loadPolicy(t *testing.T, ctx context.Context, policy string) *Engine {
+	t.Helper()
+
+	engine, err := Load(ctx, []string{writeFiles(t, map[string]string{"policy.rego": policy})})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	return engine
+}
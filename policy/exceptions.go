@@ -0,0 +1,153 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+// sharedExceptionsKey is the key of the data document that contains the
+// exceptions that are shared by the policies, e.g. a file of exceptions
+// that is maintained separately from the policies.
+const sharedExceptionsKey = "exceptions"
+
+// sharedException excepts the given rules of the policies, the same as when a
+// policy names the rules in an exception rule. The rules are excepted in all
+// of the namespaces, for all of the files and inputs, unless the namespaces,
+// the files or the input to match are given. Files are glob patterns of the
+// paths of the files, and the input matches when it contains all of the
+// values of Match, e.g. {"metadata": {"name": "can-run-as-root"}}.
+type sharedException struct {
+	Rules      []string               `json:"rules"`
+	Namespaces []string               `json:"namespaces,omitempty"`
+	Files      []string               `json:"files,omitempty"`
+	Match      map[string]interface{} `json:"match,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+}
+
+// parseSharedExceptions returns the exceptions of the exceptions data document
+// of the given data, when the document is a list of exceptions. Other values
+// of the document are not exceptions, and are left to the policies.
+func parseSharedExceptions(data map[string]interface{}) ([]sharedException, error) {
+	document, ok := data[sharedExceptionsKey].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	contents, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("marshal exceptions: %w", err)
+	}
+
+	var exceptions []sharedException
+	if err := json.Unmarshal(contents, &exceptions); err != nil {
+		return nil, fmt.Errorf("data.%s must be a list of exceptions: %w", sharedExceptionsKey, err)
+	}
+
+	for i, exception := range exceptions {
+		if len(exception.Rules) == 0 {
+			return nil, fmt.Errorf("data.%s[%d] must name the rules that it excepts", sharedExceptionsKey, i)
+		}
+
+		for _, pattern := range exception.Files {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("data.%s[%d]: invalid file pattern %q: %w", sharedExceptionsKey, i, pattern, err)
+			}
+		}
+	}
+
+	return exceptions, nil
+}
+
+// sharedException returns the exception of the given rule that is found in the
+// shared exceptions for the given input of the file at the given path in the
+// given namespace. The message of the exception is the exception in the data
+// document.
+func (e *Engine) sharedException(filePath string, input interface{}, namespace string, rule string) (output.Result, bool) {
+	for i, exception := range e.exceptions {
+		if !contains(exception.Rules, e.ruleName(rule)) && !contains(exception.Rules, rule) {
+			continue
+		}
+
+		if len(exception.Namespaces) > 0 && !contains(exception.Namespaces, namespace) {
+			continue
+		}
+
+		if len(exception.Files) > 0 && !matchesFile(exception.Files, filePath) {
+			continue
+		}
+
+		if len(exception.Match) > 0 && !matchesInput(exception.Match, input) {
+			continue
+		}
+
+		result := output.Result{
+			Message: fmt.Sprintf("data.%s[%d]", sharedExceptionsKey, i),
+			Rule:    rule,
+		}
+		if exception.Reason != "" {
+			result.Metadata = map[string]interface{}{"reason": exception.Reason}
+		}
+
+		return result, true
+	}
+
+	return output.Result{}, false
+}
+
+// matchesFile reports whether the file at the given path matches one of the
+// given patterns. Patterns without a slash match the name of the file, and
+// other patterns match its slash separated path.
+func matchesFile(patterns []string, filePath string) bool {
+	filePath = filepath.ToSlash(filePath)
+	for _, pattern := range patterns {
+		name := filePath
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(filePath)
+		}
+
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesInput reports whether the given input contains the given value. An
+// object contains another object when it contains all of the values of its
+// keys, and any other value only contains an equal value.
+func matchesInput(value interface{}, input interface{}) bool {
+	if object, ok := value.(map[string]interface{}); ok {
+		inputObject, ok := input.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		for key, value := range object {
+			if !matchesInput(value, inputObject[key]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// The values are compared as JSON, as the numbers of the input are
+	// not necessarily of the same type as the numbers of the data.
+	expected, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	actual, err := json.Marshal(input)
+	if err != nil {
+		return false
+	}
+
+	return string(expected) == string(actual)
+}
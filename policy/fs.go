@@ -0,0 +1,225 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/storage/inmem"
+)
+
+// LoadFS returns an Engine after loading all of the policies and data of the
+// specified paths of the given file system, such as an embed.FS that a suite
+// of policies is compiled into, so that the policies can be distributed as
+// part of a single binary. The paths are slash separated paths of the file
+// system, e.g. policy, and directories are loaded recursively.
+//
+// The same as LoadWithOptions, data files are nested under the names of the
+// directories they are in, relative to the data path, and data paths in the
+// form of name=path are mounted under the name. Policies that have been
+// compiled to WASM, and data paths that are URLs, are not supported.
+func LoadFS(ctx context.Context, fsys fs.FS, policyPaths []string, dataPaths []string, options Options) (*Engine, error) {
+	if err := validateSeverities(options); err != nil {
+		return nil, fmt.Errorf("validate severities: %w", err)
+	}
+
+	if err := validateRegoVersion(options.RegoVersion); err != nil {
+		return nil, fmt.Errorf("validate rego version: %w", err)
+	}
+
+	if options.Capabilities != "" {
+		capabilities, err := loadCapabilities(options.Capabilities)
+		if err != nil {
+			return nil, fmt.Errorf("load capabilities: %w", err)
+		}
+
+		options.capabilities = capabilities
+	}
+
+	modules := make(map[string]*ast.Module)
+	err := walkFS(fsys, policyPaths, func(root string, filePath string) error {
+		if !strings.HasSuffix(filePath, bundle.RegoExt) {
+			return nil
+		}
+
+		contents, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+
+		module, err := ast.ParseModuleWithOpts(filePath, string(contents), ast.ParserOptions{ProcessAnnotation: true, RegoVersion: parserRegoVersion(options.RegoVersion)})
+		if err != nil {
+			return err
+		}
+
+		modules[filePath] = module
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	if len(modules) == 0 && !options.AllowEmpty {
+		return nil, fmt.Errorf("no policies found in %v", policyPaths)
+	}
+
+	engine, err := newEngine(ctx, policyPaths, modules, nil, options)
+	if err != nil {
+		return nil, fmt.Errorf("loading policies: %w", err)
+	}
+
+	paths, mounts, err := splitDataMounts(dataPaths)
+	if err != nil {
+		return nil, fmt.Errorf("split data mounts: %w", err)
+	}
+
+	data, documentContents, err := loadFSDocuments(fsys, paths, options.DataParser)
+	if err != nil {
+		return nil, fmt.Errorf("load documents: %w", err)
+	}
+
+	for _, mount := range mounts {
+		mountData, mountContents, err := loadFSDocuments(fsys, []string{mount.path}, options.DataParser)
+		if err != nil {
+			return nil, fmt.Errorf("load documents of mount %s: %w", mount.name, err)
+		}
+
+		data, err = mountDocument(data, mount, mountData)
+		if err != nil {
+			return nil, err
+		}
+
+		for path, contents := range mountContents {
+			documentContents[path] = contents
+		}
+	}
+
+	exceptions, err := parseSharedExceptions(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse exceptions: %w", err)
+	}
+
+	engine.store = inmem.NewFromObject(data)
+	engine.docs = documentContents
+	engine.exceptions = exceptions
+
+	return engine, nil
+}
+
+// loadFSDocuments loads the data files of the given paths of the file system,
+// and returns the data that contains the documents along with the contents of
+// each of the files. When a data parser is given, every file is parsed with
+// it and merged into the root of the data, the same as with LoadWithOptions.
+func loadFSDocuments(fsys fs.FS, dataPaths []string, dataParser string) (map[string]interface{}, map[string]string, error) {
+	data := make(map[string]interface{})
+	documentContents := make(map[string]string)
+	err := walkFS(fsys, dataPaths, func(root string, filePath string) error {
+		parserName := dataParser
+		if parserName == "" {
+			switch path.Ext(filePath) {
+			case ".json":
+				parserName = parser.JSON
+			case ".yaml", ".yml":
+				parserName = parser.YAML
+			default:
+				return nil
+			}
+		}
+
+		documentParser, err := parser.New(parserName)
+		if err != nil {
+			return fmt.Errorf("new parser: %w", err)
+		}
+
+		contents, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+
+		var document interface{}
+		if err := documentParser.Unmarshal(contents, &document); err != nil {
+			return fmt.Errorf("parse %s: %w", filePath, err)
+		}
+
+		documentContents[filePath] = string(contents)
+
+		// Empty files do not contain any data.
+		if document == nil {
+			return nil
+		}
+
+		object, ok := document.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("data file %s must contain an object", filePath)
+		}
+
+		if dataParser == "" {
+			object = nestDocument(object, strings.TrimPrefix(path.Dir(filePath), root))
+		}
+
+		if err := mergeDocument(data, object); err != nil {
+			return fmt.Errorf("merge %s: %w", filePath, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, documentContents, nil
+}
+
+// nestDocument nests the given document under each of the names of the
+// directories of the given slash separated path, e.g. a/b nests the
+// document under b, which is nested under a.
+func nestDocument(document map[string]interface{}, directory string) map[string]interface{} {
+	names := strings.Split(strings.Trim(directory, "/"), "/")
+	for i := len(names) - 1; i >= 0; i-- {
+		if names[i] == "" || names[i] == "." {
+			continue
+		}
+
+		document = map[string]interface{}{names[i]: document}
+	}
+
+	return document
+}
+
+// walkFS calls the given function for each of the files of the given paths
+// of the file system, along with the path that the file was found in. The
+// directories of the paths are walked recursively.
+func walkFS(fsys fs.FS, paths []string, walk func(root string, filePath string) error) error {
+	for _, root := range paths {
+		root = path.Clean(root)
+		err := fs.WalkDir(fsys, root, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			// A file that is given as the path itself is the root of its
+			// directory, so that its document is not nested.
+			walkRoot := root
+			if filePath == root {
+				walkRoot = path.Dir(root)
+			}
+
+			return walk(walkRoot, filePath)
+		})
+		if err != nil {
+			return fmt.Errorf("walk %s: %w", root, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadFS(t *testing.T) {
+	ctx := context.Background()
+
+	fsys := fstest.MapFS{
+		"policy/main.rego": {Data: []byte(`package main
+
+deny[msg] {
+	not data.images.allowed[input.image]
+	msg := sprintf("image %s is not allowed", [input.image])
+}`)},
+		"policy/README.md":         {Data: []byte("# policies")},
+		"data/images/allowed.yaml": {Data: []byte("allowed:\n  nginx: true\n")},
+		"data/ignored.txt":         {Data: []byte("not data")},
+	}
+
+	engine, err := LoadFS(ctx, fsys, []string{"policy"}, []string{"data"}, Options{})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	if _, ok := engine.Policies()["policy/main.rego"]; !ok {
+		t.Errorf("Expected the policy to be loaded, got %v", engine.Policies())
+	}
+
+	if _, ok := engine.Documents()["data/images/allowed.yaml"]; !ok || len(engine.Documents()) != 1 {
+		t.Errorf("Expected the data document to be loaded, got %v", engine.Documents())
+	}
+
+	configs := map[string]interface{}{
+		"allowed.yaml": map[string]interface{}{"image": "nginx"},
+		"denied.yaml":  map[string]interface{}{"image": "redis"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	failures := make(map[string]int)
+	for _, result := range results {
+		failures[result.FileName] = len(result.Failures)
+	}
+
+	if failures["allowed.yaml"] != 0 || failures["denied.yaml"] != 1 {
+		t.Errorf("Unexpected failures. Got %v, expected only denied.yaml to fail", failures)
+	}
+
+	if _, err := LoadFS(ctx, fsys, []string{"data"}, nil, Options{}); err == nil {
+		t.Error("expected an error when no policies are found")
+	}
+}
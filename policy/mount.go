@@ -0,0 +1,137 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var mountNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*(\.[a-zA-Z_][a-zA-Z0-9_-]*)*$`)
+
+// dataMount is a data path whose documents are mounted under a name, rather
+// than being merged into the root of the data.
+type dataMount struct {
+	name string
+	path string
+}
+
+// keys returns the keys of the data that the documents are mounted under,
+// where the hyphens of the name are replaced with underscores so that the
+// documents can be referred to in policies, e.g. team-a as data.team_a.
+func (m dataMount) keys() []string {
+	return strings.Split(strings.ReplaceAll(m.name, "-", "_"), ".")
+}
+
+// splitDataMounts splits the given data paths into the paths that are merged
+// into the root of the data and the paths in the form of name=path, which are
+// mounted under the name. A path that exists as it is given is never a mount.
+func splitDataMounts(dataPaths []string) ([]string, []dataMount, error) {
+	var paths []string
+	var mounts []dataMount
+	for _, dataPath := range dataPaths {
+		keyValue := strings.SplitN(dataPath, "=", 2)
+		if len(keyValue) != 2 || !mountNameRegex.MatchString(keyValue[0]) {
+			paths = append(paths, dataPath)
+			continue
+		}
+
+		if _, err := os.Stat(dataPath); err == nil {
+			paths = append(paths, dataPath)
+			continue
+		}
+
+		if keyValue[1] == "" {
+			return nil, nil, fmt.Errorf("data mount %q must be in the form of name=path", dataPath)
+		}
+
+		mounts = append(mounts, dataMount{name: keyValue[0], path: keyValue[1]})
+	}
+
+	return paths, mounts, nil
+}
+
+// DataPath returns the path that the documents of the given data path are
+// loaded from, which is the path of the data paths in the form of name=path.
+func DataPath(dataPath string) string {
+	_, mounts, err := splitDataMounts([]string{dataPath})
+	if err != nil || len(mounts) == 0 {
+		return dataPath
+	}
+
+	return mounts[0].path
+}
+
+// loadMountedDocuments loads the documents of the given data paths. The
+// documents of the data paths in the form of name=path are loaded on their
+// own and mounted under the name, and the documents of the other data paths
+// are merged into the root of the data.
+func loadMountedDocuments(ctx context.Context, dataPaths []string, options Options) (map[string]interface{}, map[string]string, error) {
+	paths, mounts, err := splitDataMounts(dataPaths)
+	if err != nil {
+		return nil, nil, fmt.Errorf("split data mounts: %w", err)
+	}
+
+	data, documentContents, err := loadDocuments(ctx, paths, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, mount := range mounts {
+		mountData, mountContents, err := loadDocuments(ctx, []string{mount.path}, options)
+		if err != nil {
+			return nil, nil, fmt.Errorf("mount %s: %w", mount.name, err)
+		}
+
+		data, err = mountDocument(data, mount, mountData)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for path, contents := range mountContents {
+			documentContents[path] = contents
+		}
+	}
+
+	return data, documentContents, nil
+}
+
+// mountDocument sets the given document in the data under the keys of the
+// given mount. It is an error for the other documents to already define a
+// value for the keys, so that the documents of a mount never collide with
+// the documents of the other data paths.
+func mountDocument(data map[string]interface{}, mount dataMount, document map[string]interface{}) (map[string]interface{}, error) {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	if document == nil {
+		document = make(map[string]interface{})
+	}
+
+	keys := mount.keys()
+	parent := data
+	for _, key := range keys[:len(keys)-1] {
+		existing, ok := parent[key]
+		if !ok {
+			existing = make(map[string]interface{})
+			parent[key] = existing
+		}
+
+		object, ok := existing.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mount %s: %s is already defined", mount.name, key)
+		}
+
+		parent = object
+	}
+
+	key := keys[len(keys)-1]
+	if _, ok := parent[key]; ok {
+		return nil, fmt.Errorf("mount %s: %s is already defined", mount.name, strings.Join(keys, "."))
+	}
+
+	parent[key] = document
+	return data, nil
+}
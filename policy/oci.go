@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/downloader"
+)
+
+// isOCIReference reports whether the given data path is a reference to an
+// artifact in an OCI registry, e.g. oci://ghcr.io/org/data:v1.
+func isOCIReference(path string) bool {
+	return strings.HasPrefix(path, "oci://")
+}
+
+// pulledData are the data documents of OCI artifacts that were pulled into
+// a temporary directory, with a directory for each artifact.
+type pulledData struct {
+	directory string
+
+	// references are the references of the artifacts, keyed by the
+	// directory that each artifact was pulled into.
+	references map[string]string
+}
+
+// pullData pulls the OCI artifacts of the given references, using the same
+// registry credentials as when pulling policies, e.g. from the Docker config.
+func pullData(ctx context.Context, references []string) (*pulledData, error) {
+	directory, err := ioutil.TempDir("", "conftest-data")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+
+	pulled := &pulledData{directory: directory, references: make(map[string]string)}
+	for i, reference := range references {
+		dst := filepath.Join(directory, strconv.Itoa(i))
+		if err := downloader.Download(ctx, dst, []string{reference}); err != nil {
+			pulled.remove()
+			return nil, fmt.Errorf("pull %s: %w", reference, err)
+		}
+
+		pulled.references[dst] = reference
+	}
+
+	return pulled, nil
+}
+
+// paths returns the directories that the artifacts were pulled into.
+func (p *pulledData) paths() []string {
+	var paths []string
+	for path := range p.references {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// documentName returns the name of the given data file of an artifact,
+// which is the reference of the artifact followed by the path of the file
+// within the artifact, or the cleaned path for the other data files.
+func (p *pulledData) documentName(path string) string {
+	for directory, reference := range p.references {
+		relative, err := filepath.Rel(directory, path)
+		if err == nil && !strings.HasPrefix(relative, "..") {
+			return reference + "/" + filepath.ToSlash(relative)
+		}
+	}
+
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func (p *pulledData) remove() {
+	os.RemoveAll(p.directory)
+}
@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPulledDataDocumentName(t *testing.T) {
+	directory := filepath.Join("tmp", "conftest-data")
+	pulled := &pulledData{
+		directory: directory,
+		references: map[string]string{
+			filepath.Join(directory, "0"): "oci://ghcr.io/org/data:v1",
+		},
+	}
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{path: filepath.Join(directory, "0", "exclusions", "services.yaml"), expected: "oci://ghcr.io/org/data:v1/exclusions/services.yaml"},
+		{path: filepath.Join("data", ".", "services.yaml"), expected: "data/services.yaml"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := pulled.documentName(testCase.path); actual != testCase.expected {
+			t.Errorf("Unexpected document name of %v. expected %v, actual %v", testCase.path, testCase.expected, actual)
+		}
+	}
+}
+
+func TestLoadWithUnreachableOCIData(t *testing.T) {
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	if err := ioutil.WriteFile(filepath.Join(directory, "policy.rego"), []byte("package main"), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	_, err = LoadWithData(context.Background(), []string{directory}, []string{"oci://127.0.0.1:1/data:v1"})
+	if err == nil {
+		t.Fatal("expected an error when the data cannot be pulled")
+	}
+
+	if !strings.Contains(err.Error(), "oci://127.0.0.1:1/data:v1") {
+		t.Errorf("expected the error to include the reference, got: %v", err)
+	}
+}
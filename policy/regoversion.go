@@ -0,0 +1,110 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// The versions of Rego that policies can be written in.
+const (
+	RegoV0 = "v0"
+	RegoV1 = "v1"
+)
+
+// DefaultRegoVersion is the version of Rego that policies are compiled as when
+// the version is not given, which is the default of the version of OPA that
+// conftest is built with.
+const DefaultRegoVersion = RegoV0
+
+// validateRegoVersion returns an error when the given version of Rego is unknown.
+func validateRegoVersion(regoVersion string) error {
+	switch regoVersion {
+	case "", RegoV0, RegoV1:
+		return nil
+	default:
+		return fmt.Errorf("unknown rego version %q, valid versions are: %v", regoVersion, []string{RegoV0, RegoV1})
+	}
+}
+
+// parserRegoVersion returns the version of Rego that the parser of OPA
+// parses policies as for the given version, which defaults to
+// DefaultRegoVersion when it is empty.
+func parserRegoVersion(regoVersion string) ast.RegoVersion {
+	if regoVersion == "" {
+		regoVersion = DefaultRegoVersion
+	}
+
+	if regoVersion == RegoV1 {
+		return ast.RegoV1
+	}
+
+	return ast.RegoV0
+}
+
+// groupByRegoVersion groups the given policy paths by the version of Rego that
+// their policies are parsed as. A version that is given explicitly applies to
+// all of the paths. Otherwise, the paths of bundles whose manifest declares a
+// rego_version are parsed as that version, and the other paths are parsed as
+// DefaultRegoVersion.
+func groupByRegoVersion(regoVersion string, policyPaths []string) (map[ast.RegoVersion][]string, error) {
+	groups := make(map[ast.RegoVersion][]string)
+	for _, policyPath := range policyPaths {
+		pathVersion := parserRegoVersion(regoVersion)
+		if regoVersion == "" {
+			manifestVersion, ok, err := manifestRegoVersion(policyPath)
+			if err != nil {
+				return nil, fmt.Errorf("read manifest of %s: %w", policyPath, err)
+			}
+
+			if ok {
+				pathVersion = manifestVersion
+			}
+		}
+
+		groups[pathVersion] = append(groups[pathVersion], policyPath)
+	}
+
+	return groups, nil
+}
+
+// manifestRegoVersion returns the rego_version that is declared in the
+// .manifest file of the bundle in the given directory, and whether the
+// directory has a manifest that declares a version.
+func manifestRegoVersion(directory string) (ast.RegoVersion, bool, error) {
+	if info, err := os.Stat(directory); err != nil || !info.IsDir() {
+		return ast.RegoV0, false, nil
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(directory, ".manifest"))
+	if os.IsNotExist(err) {
+		return ast.RegoV0, false, nil
+	}
+	if err != nil {
+		return ast.RegoV0, false, fmt.Errorf("read file: %w", err)
+	}
+
+	var manifest struct {
+		RegoVersion *int `json:"rego_version"`
+	}
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		return ast.RegoV0, false, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if manifest.RegoVersion == nil {
+		return ast.RegoV0, false, nil
+	}
+
+	switch *manifest.RegoVersion {
+	case 0:
+		return ast.RegoV0, true, nil
+	case 1:
+		return ast.RegoV1, true, nil
+	default:
+		return ast.RegoV0, false, fmt.Errorf("unknown rego_version %d, valid versions are: [0 1]", *manifest.RegoVersion)
+	}
+}
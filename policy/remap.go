@@ -0,0 +1,181 @@
+package policy
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// ParseNamespaceMap parses the given mappings, in the form of
+// path=namespace (e.g. bundles/a=team.a), into a map of the policy
+// paths to the namespaces that their packages are moved under.
+func ParseNamespaceMap(mappings []string) (map[string]string, error) {
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[string]string)
+	for _, mapping := range mappings {
+		keyValue := strings.SplitN(mapping, "=", 2)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+			return nil, fmt.Errorf("namespace mapping %q must be in the form of path=namespace", mapping)
+		}
+
+		parsed[filepath.Clean(strings.TrimSpace(keyValue[0]))] = strings.TrimSpace(keyValue[1])
+	}
+
+	return parsed, nil
+}
+
+// remapNamespaces moves the packages of the modules that are loaded from each
+// of the paths of the given map under its namespace, e.g. package main of
+// the policies in bundles/a becomes package team.a.main for bundles/a=team.a.
+// The references of the modules to the packages of the same path are moved
+// along with them, so that the policies of a path keep referring to each
+// other. An error is returned when a moved package is also the package of
+// the policies of another path, as their rules would be merged.
+func remapNamespaces(modules map[string]*ast.Module, namespaceMap map[string]string) error {
+	if len(namespaceMap) == 0 {
+		return nil
+	}
+
+	prefixes := make(map[string]ast.Ref)
+	for path, namespace := range namespaceMap {
+		prefix, err := ast.ParseRef("data." + namespace)
+		if err != nil || namespace == "" || !isNamespaceRef(prefix) {
+			return fmt.Errorf("invalid namespace %q of %s", namespace, path)
+		}
+
+		prefixes[path] = prefix
+	}
+
+	// The modules are grouped by the most specific of the mapped paths that
+	// they are loaded from, and the modules of other paths are left as is.
+	groups := make(map[string][]string)
+	for file := range modules {
+		path := mappedPath(file, namespaceMap)
+		groups[path] = append(groups[path], file)
+	}
+
+	for path := range namespaceMap {
+		if len(groups[path]) == 0 {
+			return fmt.Errorf("no policies found in %s of the namespace mappings", path)
+		}
+	}
+
+	packages := make(map[string]map[string]bool)
+	for path, files := range groups {
+		packages[path] = make(map[string]bool)
+		for _, file := range files {
+			packages[path][modules[file].Package.Path.String()] = true
+		}
+	}
+
+	for path, files := range groups {
+		prefix, ok := prefixes[path]
+		if !ok {
+			continue
+		}
+
+		for _, file := range files {
+			_, err := ast.TransformRefs(modules[file], func(ref ast.Ref) (ast.Value, error) {
+				if !refersToPackage(ref, packages[path]) {
+					return ref, nil
+				}
+
+				return prefix.Concat(ref[1:]), nil
+			})
+			if err != nil {
+				return fmt.Errorf("remap %s: %w", file, err)
+			}
+		}
+	}
+
+	owners := make(map[string]string)
+	var paths []string
+	for path := range groups {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		for _, file := range groups[path] {
+			pkg := strings.TrimPrefix(modules[file].Package.Path.String(), "data.")
+			owner, ok := owners[pkg]
+			if ok && owner != path && (prefixes[path] != nil || prefixes[owner] != nil) {
+				return fmt.Errorf("namespace %s of the policies in %s conflicts with the policies in %s", pkg, describePath(path), describePath(owner))
+			}
+
+			owners[pkg] = path
+		}
+	}
+
+	return nil
+}
+
+// mappedPath returns the most specific of the paths of the given map that
+// contains the given file, or an empty path when none of them contain it.
+func mappedPath(file string, namespaceMap map[string]string) string {
+	var longest string
+	for path := range namespaceMap {
+		if len(path) <= len(longest) {
+			continue
+		}
+
+		relative, err := filepath.Rel(path, filepath.Clean(file))
+		if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		longest = path
+	}
+
+	return longest
+}
+
+// refersToPackage reports whether the given reference refers to one of the
+// given packages, or to a document within one of them.
+func refersToPackage(ref ast.Ref, packages map[string]bool) bool {
+	if !ref.HasPrefix(ast.DefaultRootRef) {
+		return false
+	}
+
+	for i := 2; i <= len(ref); i++ {
+		if _, ok := ref[i-1].Value.(ast.String); !ok {
+			return false
+		}
+
+		if packages[ref[:i].String()] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNamespaceRef reports whether the given reference is the reference of a
+// namespace, whose terms after the root document are all strings.
+func isNamespaceRef(ref ast.Ref) bool {
+	if len(ref) < 2 || !ref.HasPrefix(ast.DefaultRootRef) {
+		return false
+	}
+
+	for _, term := range ref[1:] {
+		if _, ok := term.Value.(ast.String); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return "the unmapped policy paths"
+	}
+
+	return path
+}
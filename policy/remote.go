@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/downloader"
+	"github.com/open-policy-agent/conftest/parser"
+)
+
+// remoteDocument is a document that was fetched over HTTP.
+type remoteDocument struct {
+	url      string
+	contents []byte
+	document map[string]interface{}
+}
+
+// fetchDocuments fetches the documents at the given URLs. The documents are
+// parsed with the given parser, or based on the content type of the responses
+// when it is not set.
+func fetchDocuments(ctx context.Context, urls []string, parserName string) ([]remoteDocument, error) {
+	var documents []remoteDocument
+	for _, documentURL := range urls {
+		document, err := fetchDocument(ctx, documentURL, parserName)
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s: %w", documentURL, err)
+		}
+
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+
+func fetchDocument(ctx context.Context, documentURL string, parserName string) (remoteDocument, error) {
+	response, err := downloader.Get(ctx, documentURL)
+	if err != nil {
+		return remoteDocument{}, err
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return remoteDocument{}, fmt.Errorf("read body: %w", err)
+	}
+
+	if parserName == "" {
+		parserName, err = documentParserName(response.Header.Get("Content-Type"), response.Request.URL)
+		if err != nil {
+			return remoteDocument{}, err
+		}
+	}
+
+	documentParser, err := parser.New(parserName)
+	if err != nil {
+		return remoteDocument{}, fmt.Errorf("new parser: %w", err)
+	}
+
+	var document interface{}
+	if err := documentParser.Unmarshal(contents, &document); err != nil {
+		return remoteDocument{}, fmt.Errorf("parse: %w", err)
+	}
+
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		return remoteDocument{}, fmt.Errorf("data must contain an object")
+	}
+
+	return remoteDocument{url: documentURL, contents: contents, document: object}, nil
+}
+
+// documentParserName returns the name of the parser of a document with the
+// given content type. Servers that do not send a known content type, such as
+// file servers that send text/plain, are supported by falling back to the
+// extension of the path of the URL.
+func documentParserName(contentType string, documentURL *url.URL) (string, error) {
+	if parserName, ok := parser.NameFromContentType(contentType); ok {
+		return parserName, nil
+	}
+
+	switch strings.ToLower(path.Ext(documentURL.Path)) {
+	case ".json":
+		return parser.JSON, nil
+	case ".yaml", ".yml":
+		return parser.YAML, nil
+	case ".toml":
+		return parser.TOML, nil
+	}
+
+	return "", fmt.Errorf("unsupported content type %q, expected JSON, YAML or TOML", contentType)
+}
@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severities are the severities of the results of the rules that have one of
+// the additional rule prefixes, from the least to the most severe.
+var Severities = []string{"info", "low", "medium", "high", "critical"}
+
+// DefaultFailSeverity is the lowest severity of the results that are failures
+// when it is not given, so that only informational results are warnings.
+const DefaultFailSeverity = "low"
+
+var (
+	rulePrefixRegex = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9]*$")
+	ruleSuffixRegex = regexp.MustCompile("^(_[a-zA-Z0-9]+)*$")
+)
+
+// ParseRulePrefixes parses the given rule prefixes, in the form of
+// prefix=severity (e.g. critical=critical), into a map of the prefixes
+// to their severities.
+func ParseRulePrefixes(rulePrefixes []string) (map[string]string, error) {
+	if len(rulePrefixes) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[string]string)
+	for _, rulePrefix := range rulePrefixes {
+		keyValue := strings.SplitN(rulePrefix, "=", 2)
+		if len(keyValue) != 2 {
+			return nil, fmt.Errorf("rule prefix %q must be in the form of prefix=severity", rulePrefix)
+		}
+
+		parsed[strings.TrimSpace(keyValue[0])] = strings.ToLower(strings.TrimSpace(keyValue[1]))
+	}
+
+	return parsed, nil
+}
+
+// validateSeverities returns an error when the rule prefixes of the given
+// options are not valid names of rules, are the prefixes of the rules that
+// are already evaluated, or when any of the severities are unknown.
+func validateSeverities(options Options) error {
+	for prefix, severity := range options.RulePrefixes {
+		if !rulePrefixRegex.MatchString(prefix) {
+			return fmt.Errorf("rule prefix %q must only contain letters and digits", prefix)
+		}
+
+		if contains([]string{"deny", "violation", "warn", "exception"}, prefix) {
+			return fmt.Errorf("rule prefix %q is reserved", prefix)
+		}
+
+		if severityLevel(severity) < 0 {
+			return fmt.Errorf("unknown severity %q of rule prefix %q, valid severities are: %v", severity, prefix, Severities)
+		}
+	}
+
+	if options.FailSeverity != "" && severityLevel(options.FailSeverity) < 0 {
+		return fmt.Errorf("unknown fail severity %q, valid severities are: %v", options.FailSeverity, Severities)
+	}
+
+	return nil
+}
+
+// severityLevel returns the level of the given severity, where a higher level
+// is more severe. The level of an unknown severity is -1.
+func severityLevel(severity string) int {
+	for level, known := range Severities {
+		if strings.EqualFold(severity, known) {
+			return level
+		}
+	}
+
+	return -1
+}
+
+// rulePrefix returns the additional rule prefix of the given rule, which is
+// the longest of the prefixes that the rule is named after, e.g. critical for
+// the critical and critical_images rules.
+func (e *Engine) rulePrefix(rule string) (string, bool) {
+	var longest string
+	for prefix := range e.options.RulePrefixes {
+		if len(prefix) <= len(longest) {
+			continue
+		}
+
+		if strings.HasPrefix(rule, prefix) && ruleSuffixRegex.MatchString(rule[len(prefix):]) {
+			longest = prefix
+		}
+	}
+
+	return longest, longest != ""
+}
+
+// isRule reports whether the given rule is evaluated by Check, which are the
+// deny, violation and warn rules, and the rules with an additional prefix.
+func (e *Engine) isRule(rule string) bool {
+	if isFailure(rule) || isWarning(rule) {
+		return true
+	}
+
+	_, ok := e.rulePrefix(rule)
+	return ok
+}
+
+// ruleSeverity returns the severity of the results of the given rule, and
+// whether the results are failures, which are the results with at least the
+// fail severity. Only the rules with an additional prefix have a severity.
+func (e *Engine) ruleSeverity(rule string) (string, bool) {
+	prefix, ok := e.rulePrefix(rule)
+	if !ok {
+		return "", isFailure(rule)
+	}
+
+	failSeverity := e.options.FailSeverity
+	if failSeverity == "" {
+		failSeverity = DefaultFailSeverity
+	}
+
+	severity := strings.ToLower(e.options.RulePrefixes[prefix])
+	return severity, severityLevel(severity) >= severityLevel(failSeverity)
+}
+
+// ruleName returns the name of the given rule without its prefix, which
+// is the name of the rule that exceptions refer to.
+func (e *Engine) ruleName(rule string) string {
+	if prefix, ok := e.rulePrefix(rule); ok {
+		return strings.TrimPrefix(rule, prefix+"_")
+	}
+
+	return removeRulePrefix(rule)
+}
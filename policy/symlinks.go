@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/bundle"
+)
+
+// findPolicyFiles returns the paths of the policy files in the given paths,
+// following the symbolic links to directories. A link to a directory that
+// contains the link is not followed, which breaks the cycle, and an error is
+// returned when the same policy file is found through more than one path, as
+// one of the modules would otherwise silently shadow the other.
+func findPolicyFiles(paths []string) ([]string, error) {
+	finder := policyFinder{found: make(map[string]string)}
+	for _, path := range paths {
+		if err := finder.find(path, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return finder.files, nil
+}
+
+type policyFinder struct {
+	files []string
+
+	// found maps the resolved path of each policy file that was
+	// found to the path that the policy file was found through.
+	found map[string]string
+}
+
+func (f *policyFinder) find(path string, ancestors []string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("get file info: %w", err)
+	}
+
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("resolve symlinks: %w", err)
+	}
+
+	if !info.IsDir() {
+		if existing, ok := f.found[resolvedPath]; ok {
+			return fmt.Errorf("policy %s is the same file as %s", path, existing)
+		}
+
+		f.found[resolvedPath] = path
+		f.files = append(f.files, path)
+		return nil
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor == resolvedPath {
+			return nil
+		}
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("read dir: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		entryPath := filepath.Join(path, name)
+		entryInfo, err := os.Stat(entryPath)
+		if err != nil {
+			return fmt.Errorf("get file info: %w", err)
+		}
+
+		if !entryInfo.IsDir() && !strings.HasSuffix(name, bundle.RegoExt) {
+			continue
+		}
+
+		if err := f.find(entryPath, append(ancestors, resolvedPath)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeSymlink != 0
+}
@@ -0,0 +1,58 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/output"
+
+	"github.com/open-policy-agent/opa/topdown"
+)
+
+// EnableTracing records the trace of every query that is evaluated from
+// then on, both as human readable lines and as the events of the trace.
+func (e *Engine) EnableTracing() {
+	e.tracing = true
+}
+
+// PrettyTrace formats the given trace events into human readable lines.
+func PrettyTrace(events []*topdown.Event) []string {
+	buf := new(bytes.Buffer)
+	topdown.PrettyTrace(buf, events)
+
+	var traces []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if len(line) > 0 {
+			traces = append(traces, line)
+		}
+	}
+
+	return traces
+}
+
+// TraceEvents converts the given trace events into events that can be
+// included in the output, where the nodes of the events are their Rego.
+func TraceEvents(events []*topdown.Event) []output.TraceEvent {
+	traceEvents := make([]output.TraceEvent, 0, len(events))
+	for _, event := range events {
+		traceEvent := output.TraceEvent{
+			Op:       string(event.Op),
+			QueryID:  event.QueryID,
+			ParentID: event.ParentID,
+			Message:  event.Message,
+		}
+
+		if event.Node != nil {
+			traceEvent.Node = fmt.Sprint(event.Node)
+		}
+
+		if event.Location != nil {
+			traceEvent.Location = fmt.Sprintf("%s:%d", event.Location.File, event.Location.Row)
+		}
+
+		traceEvents = append(traceEvents, traceEvent)
+	}
+
+	return traceEvents
+}
@@ -0,0 +1,179 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/loader"
+	"github.com/open-policy-agent/opa/metrics"
+	"github.com/open-policy-agent/opa/storage"
+)
+
+// wasmEnabled is true when conftest is built with support for evaluating
+// policies that have been compiled to WASM.
+var wasmEnabled bool
+
+// loadBundles loads the policy paths that are bundles of policies compiled to WASM,
+// and returns the remaining paths that contain Rego source files.
+//
+// A path is considered to be a compiled bundle when it is a bundle archive
+// (e.g. built with opa build -t wasm), or a directory that contains a .wasm file.
+func loadBundles(policyPaths []string) (map[string]*bundle.Bundle, []string, error) {
+	bundles := make(map[string]*bundle.Bundle)
+	var sourcePaths []string
+	for _, policyPath := range policyPaths {
+		compiled, err := isCompiledBundle(policyPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("detect bundle: %w", err)
+		}
+
+		if !compiled {
+			sourcePaths = append(sourcePaths, policyPath)
+			continue
+		}
+
+		if !wasmEnabled {
+			return nil, nil, fmt.Errorf("%s contains policies compiled to WASM, which requires conftest to be built with cgo enabled", policyPath)
+		}
+
+		policyBundle, err := loader.NewFileLoader().AsBundle(policyPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load bundle %s: %w", policyPath, err)
+		}
+
+		if len(policyBundle.WasmModules) == 0 {
+			return nil, nil, fmt.Errorf("bundle %s does not contain any policies compiled to WASM", policyPath)
+		}
+
+		bundles[filepath.ToSlash(filepath.Clean(policyPath))] = policyBundle
+	}
+
+	return bundles, sourcePaths, nil
+}
+
+func isCompiledBundle(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get file info: %w", err)
+	}
+
+	if !info.IsDir() {
+		return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz"), nil
+	}
+
+	var hasWasm bool
+	walk := func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("walk path: %w", err)
+		}
+
+		if !info.IsDir() && filepath.Ext(currentPath) == ".wasm" {
+			hasWasm = true
+			return filepath.SkipDir
+		}
+
+		return nil
+	}
+
+	if err := filepath.Walk(path, walk); err != nil {
+		return false, err
+	}
+
+	return hasWasm, nil
+}
+
+// newCompiler compiles the given modules. When there are compiled bundles, the
+// bundles are activated in the given store, which makes their WASM entrypoints
+// available when evaluating queries against the store.
+func newCompiler(ctx context.Context, store storage.Store, modules map[string]*ast.Module, bundles map[string]*bundle.Bundle, options Options) (*ast.Compiler, error) {
+
+	// Print statements are removed from the policies during compilation by default,
+	// so the compiler must be told to keep them in order to capture their output.
+	compiler := ast.NewCompiler().WithEnablePrintStatements(true).WithStrict(options.Strict)
+	if options.capabilities != nil {
+		compiler = compiler.WithCapabilities(options.capabilities)
+	}
+
+	if len(bundles) == 0 {
+		compiler.Compile(modules)
+		if compiler.Failed() {
+			return nil, compiler.Errors
+		}
+
+		return compiler, nil
+	}
+
+	txn, err := store.NewTransaction(ctx, storage.WriteParams)
+	if err != nil {
+		return nil, fmt.Errorf("new transaction: %w", err)
+	}
+
+	activateOptions := bundle.ActivateOpts{
+		Ctx:          ctx,
+		Store:        store,
+		Txn:          txn,
+		Compiler:     compiler,
+		Metrics:      metrics.New(),
+		Bundles:      bundles,
+		ExtraModules: modules,
+	}
+	if err := bundle.Activate(&activateOptions); err != nil {
+		store.Abort(ctx, txn)
+		return nil, fmt.Errorf("activate bundles: %w", err)
+	}
+
+	if err := store.Commit(ctx, txn); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return compiler, nil
+}
+
+// wasmRules returns the rules in the given namespace that are
+// entrypoints of the policies compiled to WASM.
+func (e *Engine) wasmRules(namespace string) []string {
+	var rules []string
+	for _, policyBundle := range e.bundles {
+		for _, resolver := range policyBundle.Manifest.WasmResolvers {
+			entrypoint := strings.Split(strings.Trim(resolver.Entrypoint, "/"), "/")
+			if len(entrypoint) < 2 {
+				continue
+			}
+
+			if strings.Join(entrypoint[:len(entrypoint)-1], ".") == namespace {
+				rules = append(rules, entrypoint[len(entrypoint)-1])
+			}
+		}
+	}
+
+	return rules
+}
+
+// wasmNamespaces returns the namespaces of the entrypoints
+// of the policies compiled to WASM.
+func (e *Engine) wasmNamespaces() []string {
+	var namespaces []string
+	for _, policyBundle := range e.bundles {
+		for _, resolver := range policyBundle.Manifest.WasmResolvers {
+			entrypoint := strings.Split(strings.Trim(resolver.Entrypoint, "/"), "/")
+			if len(entrypoint) < 2 {
+				continue
+			}
+
+			namespace := strings.Join(entrypoint[:len(entrypoint)-1], ".")
+			if !contains(namespaces, namespace) {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+	}
+
+	return namespaces
+}
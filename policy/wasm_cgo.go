@@ -0,0 +1,12 @@
+//go:build cgo
+// +build cgo
+
+package policy
+
+// The WASM runtime that OPA uses to evaluate compiled policies
+// depends on cgo, so it is only available when cgo is enabled.
+import _ "github.com/open-policy-agent/opa/features/wasm"
+
+func init() {
+	wasmEnabled = true
+}
@@ -0,0 +1,88 @@
+//go:build cgo
+// +build cgo
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/compile"
+)
+
+func TestCheckWasmBundle(t *testing.T) {
+	ctx := context.Background()
+
+	directory, err := ioutil.TempDir("", "conftest")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	policy := `package kubernetes
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := sprintf("found %v", [input.kind])
+}`
+	sourceDir := filepath.Join(directory, "source")
+	if err := os.MkdirAll(sourceDir, os.ModePerm); err != nil {
+		t.Fatalf("create dir: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(sourceDir, "policy.rego"), []byte(policy), os.ModePerm); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	compiled := new(bytes.Buffer)
+	compiler := compile.New().WithTarget("wasm").WithEntrypoints("kubernetes/deny").WithPaths(sourceDir).WithOutput(compiled)
+	if err := compiler.Build(ctx); err != nil {
+		t.Fatalf("build bundle: %v", err)
+	}
+
+	// Remove the Rego source from the bundle to verify that the
+	// policies are evaluated from the compiled WASM.
+	compiledBundle, err := bundle.NewReader(compiled).Read()
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+	compiledBundle.Modules = nil
+
+	bundlePath := filepath.Join(directory, "bundle.tar.gz")
+	bundleFile, err := os.Create(bundlePath)
+	if err != nil {
+		t.Fatalf("create bundle: %v", err)
+	}
+	defer bundleFile.Close()
+
+	if err := bundle.NewWriter(bundleFile).Write(compiledBundle); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+
+	engine, err := Load(ctx, []string{bundlePath})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	namespaces := engine.Namespaces()
+	if len(namespaces) != 1 || namespaces[0] != "kubernetes" {
+		t.Errorf("Unexpected namespaces. Got %v, expected [kubernetes]", namespaces)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+	}
+
+	results, err := engine.Check(ctx, configs, "kubernetes")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 1 || results[0].Failures[0].Message != "found Deployment" {
+		t.Errorf("Unexpected failures. Got %v, expected a single failure", results[0].Failures)
+	}
+}